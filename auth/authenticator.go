@@ -0,0 +1,198 @@
+package auth
+
+import (
+	"bytes"
+	"ccg-api/configuration"
+	"ccg-api/constants"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	golaConstants "github.com/inclusi-blog/gola-utils/constants"
+	"github.com/inclusi-blog/gola-utils/golaerror"
+	"github.com/inclusi-blog/gola-utils/logging"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	ApiKeyHeader        = "X-Api-Key"
+	ClientIDHeader      = "X-Client-Id"
+	TimestampHeader     = "X-Timestamp"
+	SignatureHeader     = "X-Signature"
+	defaultReplayWindow = 5 * time.Minute
+	// defaultMaxSignedBodySize leaves room for attachments, whose content is base64 encoded in the body
+	defaultMaxSignedBodySize = 32 << 20
+)
+
+// Authenticator is the middleware in front of every route that sends mail or manages suppressions.
+// Requests either carry an API key or are signed by the client, see Signature.
+type Authenticator interface {
+	Authenticate(ctx *gin.Context)
+}
+
+type credentials struct {
+	client     Client
+	apiKeyHash []byte
+	hmacSecret []byte
+}
+
+type authenticator struct {
+	enabled      bool
+	clients      []credentials
+	replayWindow time.Duration
+	maxBodySize  int64
+	replays      ReplayCache
+	now          func() time.Time
+}
+
+// NewAuthenticator lets every request through anonymously unless auth is enabled. Clients whose secret
+// environment variable is empty cannot sign requests. An API key hash that is not a hex sha256 digest is an error,
+// since the client would otherwise be locked out without notice.
+func NewAuthenticator(config configuration.Auth, secretFunc func(client configuration.AuthClient) string, replays ReplayCache) (Authenticator, error) {
+	replayWindow := defaultReplayWindow
+	if config.ReplayWindowInSeconds > 0 {
+		replayWindow = time.Duration(config.ReplayWindowInSeconds) * time.Second
+	}
+	maxBodySize := int64(defaultMaxSignedBodySize)
+	if config.MaxSignedBodyInBytes > 0 {
+		maxBodySize = config.MaxSignedBodyInBytes
+	}
+	var clients []credentials
+	for _, clientConfig := range config.Clients {
+		apiKeyHash, err := hex.DecodeString(clientConfig.ApiKeyHash)
+		if err != nil || (len(apiKeyHash) != 0 && len(apiKeyHash) != sha256.Size) {
+			return nil, fmt.Errorf("api_key_hash of client %s is not a hex sha256 digest", clientConfig.ID)
+		}
+		clients = append(clients, credentials{
+			client:     Client{ID: clientConfig.ID, Tenant: clientConfig.Tenant},
+			apiKeyHash: apiKeyHash,
+			hmacSecret: []byte(secretFunc(clientConfig)),
+		})
+	}
+	return &authenticator{
+		enabled:      config.Enabled,
+		clients:      clients,
+		replayWindow: replayWindow,
+		maxBodySize:  maxBodySize,
+		replays:      replays,
+		now:          time.Now,
+	}, nil
+}
+
+func (authenticator *authenticator) Authenticate(ctx *gin.Context) {
+	if !authenticator.enabled {
+		ctx.Next()
+		return
+	}
+	logger := logging.GetLogger(ctx).WithField("class", "Authenticator").WithField("method", "Authenticate")
+
+	var client Client
+	authError := &constants.UnauthenticatedError
+	if apiKey := ctx.GetHeader(ApiKeyHeader); apiKey != "" {
+		if keyClient, authenticated := authenticator.byApiKey(apiKey); authenticated {
+			client, authError = keyClient, nil
+		}
+	} else if clientID := ctx.GetHeader(ClientIDHeader); clientID != "" {
+		client, authError = authenticator.bySignature(ctx, clientID)
+	}
+	if authError != nil {
+		logger.Warnf("Rejecting unauthenticated request to %s", ctx.Request.URL.Path)
+		ctx.AbortWithStatusJSON(constants.GetGolaHttpCode(authError.ErrorCode), authError)
+		return
+	}
+
+	SetClient(ctx, client)
+	ctx.Set(golaConstants.LOGGER_KEY, logging.GetLogger(ctx).WithField("client_id", client.ID))
+	ctx.Next()
+}
+
+// byApiKey compares against every client, so the time taken does not tell which hash came close
+func (authenticator *authenticator) byApiKey(apiKey string) (Client, bool) {
+	digest := sha256.Sum256([]byte(apiKey))
+	var match *credentials
+	for index := range authenticator.clients {
+		candidate := &authenticator.clients[index]
+		if len(candidate.apiKeyHash) > 0 && subtle.ConstantTimeCompare(digest[:], candidate.apiKeyHash) == 1 {
+			match = candidate
+		}
+	}
+	if match == nil {
+		return Client{}, false
+	}
+	return match.client, true
+}
+
+// bySignature reads at most maxBodySize of the body to verify it, a larger body is refused before it is signed over
+func (authenticator *authenticator) bySignature(ctx *gin.Context, clientID string) (Client, *golaerror.Error) {
+	logger := logging.GetLogger(ctx).WithField("class", "Authenticator").WithField("method", "bySignature")
+	candidate := authenticator.credentialsOf(clientID)
+	if candidate == nil || len(candidate.hmacSecret) == 0 {
+		logger.Warnf("No signing secret for client %s", clientID)
+		return Client{}, &constants.UnauthenticatedError
+	}
+
+	timestamp := ctx.GetHeader(TimestampHeader)
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return Client{}, &constants.UnauthenticatedError
+	}
+	now := authenticator.now()
+	if age := now.Sub(time.Unix(seconds, 0)); age > authenticator.replayWindow || age < -authenticator.replayWindow {
+		logger.Warnf("Signed request of client %s is outside the replay window", clientID)
+		return Client{}, &constants.UnauthenticatedError
+	}
+
+	var body []byte
+	if ctx.Request.Body != nil {
+		body, err = io.ReadAll(http.MaxBytesReader(ctx.Writer, ctx.Request.Body, authenticator.maxBodySize))
+		if err != nil && int64(len(body)) >= authenticator.maxBodySize {
+			logger.Warnf("Signed request of client %s has a body over %d bytes", clientID, authenticator.maxBodySize)
+			return Client{}, &constants.PayloadTooLargeError
+		}
+		if err != nil {
+			logger.Error("Failed to read request body ", err)
+			return Client{}, &constants.UnauthenticatedError
+		}
+		ctx.Request.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	expected := Signature(candidate.hmacSecret, timestamp, ctx.Request.Method, ctx.Request.URL.RequestURI(), body)
+	signature := ctx.GetHeader(SignatureHeader)
+	if !hmac.Equal([]byte(signature), []byte(expected)) {
+		return Client{}, &constants.UnauthenticatedError
+	}
+	// a timestamp may lie up to a window in the future, so signatures are remembered for both sides of now
+	firstUse, err := authenticator.replays.FirstUse(signature, now, 2*authenticator.replayWindow)
+	if err != nil {
+		logger.Error("Failed to check signature against the replay cache ", err)
+		return Client{}, &constants.InternalServerError
+	}
+	if !firstUse {
+		logger.Warnf("Rejecting replayed request of client %s", clientID)
+		return Client{}, &constants.UnauthenticatedError
+	}
+	return candidate.client, nil
+}
+
+func (authenticator *authenticator) credentialsOf(clientID string) *credentials {
+	for index := range authenticator.clients {
+		if authenticator.clients[index].client.ID == clientID {
+			return &authenticator.clients[index]
+		}
+	}
+	return nil
+}
+
+// Signature is the hex HMAC-SHA256 over the unix timestamp, method, request URI and hex SHA-256 of the body,
+// each on its own line
+func Signature(secret []byte, timestamp string, method string, requestURI string, body []byte) string {
+	bodyDigest := sha256.Sum256(body)
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(timestamp + "\n" + method + "\n" + requestURI + "\n" + hex.EncodeToString(bodyDigest[:])))
+	return hex.EncodeToString(mac.Sum(nil))
+}
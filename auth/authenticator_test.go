@@ -0,0 +1,268 @@
+package auth
+
+import (
+	"bytes"
+	"ccg-api/auth/mocks"
+	"ccg-api/configuration"
+	"ccg-api/constants"
+	redismocks "ccg-api/email/redis/mocks"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/inclusi-blog/gola-utils/golaerror"
+	"github.com/stretchr/testify/suite"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+const (
+	apiKey     = "some-api-key"
+	hmacSecret = "some-hmac-secret"
+	body       = `{"from":"noreply@gola.xyz"}`
+)
+
+type authenticatorTestSuite struct {
+	suite.Suite
+	recorder      *httptest.ResponseRecorder
+	context       *gin.Context
+	authenticator *authenticator
+	now           time.Time
+	nextCalled    bool
+}
+
+func TestAuthenticatorTestSuite(t *testing.T) {
+	suite.Run(t, new(authenticatorTestSuite))
+}
+
+func (suite *authenticatorTestSuite) SetupTest() {
+	suite.now = time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	suite.nextCalled = false
+
+	apiKeyHash := sha256.Sum256([]byte(apiKey))
+	configured, err := NewAuthenticator(configuration.Auth{
+		Enabled:               true,
+		ReplayWindowInSeconds: 300,
+		Clients: []configuration.AuthClient{
+			{ID: "key-client", ApiKeyHash: hex.EncodeToString(apiKeyHash[:]), Tenant: "gola"},
+			{ID: "signing-client", HmacSecretEnv: "SIGNING_CLIENT_SECRET"},
+			{ID: "unconfigured-client", HmacSecretEnv: "UNCONFIGURED_CLIENT_SECRET"},
+		},
+	}, func(client configuration.AuthClient) string {
+		if client.HmacSecretEnv == "SIGNING_CLIENT_SECRET" {
+			return hmacSecret
+		}
+		return ""
+	}, NewMemoryReplayCache())
+	suite.Nil(err)
+	suite.authenticator = configured.(*authenticator)
+	suite.authenticator.now = func() time.Time {
+		return suite.now
+	}
+}
+
+func (suite *authenticatorTestSuite) authenticate(request *http.Request) {
+	recorder := httptest.NewRecorder()
+	context, engine := gin.CreateTestContext(recorder)
+	engine.POST("/api/ccg/v1/email/send", suite.authenticator.Authenticate, func(ctx *gin.Context) {
+		suite.nextCalled = true
+		suite.context = ctx
+	})
+	context.Request = request
+	engine.HandleContext(context)
+	suite.recorder = recorder
+}
+
+func (suite *authenticatorTestSuite) signedRequest(clientID string, timestamp time.Time, secret string) *http.Request {
+	request, _ := http.NewRequest(http.MethodPost, "/api/ccg/v1/email/send", bytes.NewBufferString(body))
+	unixTimestamp := strconv.FormatInt(timestamp.Unix(), 10)
+	request.Header.Set(ClientIDHeader, clientID)
+	request.Header.Set(TimestampHeader, unixTimestamp)
+	request.Header.Set(SignatureHeader, Signature([]byte(secret), unixTimestamp, http.MethodPost, "/api/ccg/v1/email/send", []byte(body)))
+	return request
+}
+
+func (suite *authenticatorTestSuite) assertUnauthenticated() {
+	suite.False(suite.nextCalled)
+	suite.Equal(http.StatusUnauthorized, suite.recorder.Code)
+	response := golaerror.Error{}
+	_ = json.Unmarshal(suite.recorder.Body.Bytes(), &response)
+	suite.Equal(constants.UnauthenticatedCode, response.ErrorCode)
+}
+
+func (suite *authenticatorTestSuite) TestAuthenticate_ShouldAttachClientOfApiKey() {
+	request, _ := http.NewRequest(http.MethodPost, "/api/ccg/v1/email/send", bytes.NewBufferString(body))
+	request.Header.Set(ApiKeyHeader, apiKey)
+
+	suite.authenticate(request)
+
+	suite.True(suite.nextCalled)
+	client, authenticated := ClientFrom(suite.context)
+	suite.True(authenticated)
+	suite.Equal(Client{ID: "key-client", Tenant: "gola"}, client)
+}
+
+func (suite *authenticatorTestSuite) TestAuthenticate_ShouldRejectUnknownApiKey() {
+	request, _ := http.NewRequest(http.MethodPost, "/api/ccg/v1/email/send", bytes.NewBufferString(body))
+	request.Header.Set(ApiKeyHeader, "other-api-key")
+
+	suite.authenticate(request)
+
+	suite.assertUnauthenticated()
+}
+
+func (suite *authenticatorTestSuite) TestAuthenticate_ShouldRejectRequestWithoutCredentials() {
+	request, _ := http.NewRequest(http.MethodPost, "/api/ccg/v1/email/send", bytes.NewBufferString(body))
+
+	suite.authenticate(request)
+
+	suite.assertUnauthenticated()
+}
+
+func (suite *authenticatorTestSuite) TestAuthenticate_ShouldAttachClientOfSignedRequestAndKeepBodyReadable() {
+	suite.authenticate(suite.signedRequest("signing-client", suite.now.Add(-time.Minute), hmacSecret))
+
+	suite.True(suite.nextCalled)
+	client, authenticated := ClientFrom(suite.context)
+	suite.True(authenticated)
+	suite.Equal("signing-client", client.ID)
+	requestBody, _ := io.ReadAll(suite.context.Request.Body)
+	suite.Equal(body, string(requestBody))
+}
+
+func (suite *authenticatorTestSuite) TestAuthenticate_ShouldRejectRequestSignedWithWrongSecret() {
+	suite.authenticate(suite.signedRequest("signing-client", suite.now, "other-secret"))
+
+	suite.assertUnauthenticated()
+}
+
+func (suite *authenticatorTestSuite) TestAuthenticate_ShouldRejectRequestWhoseBodyWasChanged() {
+	request := suite.signedRequest("signing-client", suite.now, hmacSecret)
+	request.Body = io.NopCloser(bytes.NewBufferString(`{"from":"ceo@gola.xyz"}`))
+
+	suite.authenticate(request)
+
+	suite.assertUnauthenticated()
+}
+
+func (suite *authenticatorTestSuite) TestAuthenticate_ShouldRejectRequestOutsideReplayWindow() {
+	suite.authenticate(suite.signedRequest("signing-client", suite.now.Add(-6*time.Minute), hmacSecret))
+
+	suite.assertUnauthenticated()
+}
+
+func (suite *authenticatorTestSuite) TestAuthenticate_ShouldRejectReplayedRequest() {
+	suite.authenticate(suite.signedRequest("signing-client", suite.now, hmacSecret))
+	suite.True(suite.nextCalled)
+	suite.nextCalled = false
+
+	suite.authenticate(suite.signedRequest("signing-client", suite.now, hmacSecret))
+
+	suite.assertUnauthenticated()
+}
+
+func (suite *authenticatorTestSuite) TestAuthenticate_ShouldRejectSignedRequestOfClientWithoutSecret() {
+	suite.authenticate(suite.signedRequest("unconfigured-client", suite.now, ""))
+
+	suite.assertUnauthenticated()
+}
+
+func (suite *authenticatorTestSuite) TestAuthenticate_ShouldLetRequestsThroughAnonymouslyWhenDisabled() {
+	suite.authenticator.enabled = false
+	request, _ := http.NewRequest(http.MethodPost, "/api/ccg/v1/email/send", bytes.NewBufferString(body))
+
+	suite.authenticate(request)
+
+	suite.True(suite.nextCalled)
+	_, authenticated := ClientFrom(suite.context)
+	suite.False(authenticated)
+}
+
+func (suite *authenticatorTestSuite) TestAuthenticate_ShouldRefuseSignedRequestWithBodyOverLimit() {
+	suite.authenticator.maxBodySize = int64(len(body) - 1)
+
+	suite.authenticate(suite.signedRequest("signing-client", suite.now, hmacSecret))
+
+	suite.False(suite.nextCalled)
+	suite.Equal(http.StatusRequestEntityTooLarge, suite.recorder.Code)
+}
+
+func (suite *authenticatorTestSuite) TestAuthenticate_ShouldAcceptSignedRequestWithBodyAtLimit() {
+	suite.authenticator.maxBodySize = int64(len(body))
+
+	suite.authenticate(suite.signedRequest("signing-client", suite.now, hmacSecret))
+
+	suite.True(suite.nextCalled)
+}
+
+func (suite *authenticatorTestSuite) TestAuthenticate_ShouldRememberSignatureForTwiceTheReplayWindow() {
+	mockCtrl := gomock.NewController(suite.T())
+	defer mockCtrl.Finish()
+	replays := mocks.NewMockReplayCache(mockCtrl)
+	replays.EXPECT().FirstUse(gomock.Any(), suite.now, 10*time.Minute).Return(true, nil)
+	suite.authenticator.replays = replays
+
+	suite.authenticate(suite.signedRequest("signing-client", suite.now, hmacSecret))
+
+	suite.True(suite.nextCalled)
+}
+
+func (suite *authenticatorTestSuite) TestAuthenticate_ShouldRejectSignedRequestWhenReplayCacheFails() {
+	mockCtrl := gomock.NewController(suite.T())
+	defer mockCtrl.Finish()
+	replays := mocks.NewMockReplayCache(mockCtrl)
+	replays.EXPECT().FirstUse(gomock.Any(), gomock.Any(), gomock.Any()).Return(false, errors.New("connection refused"))
+	suite.authenticator.replays = replays
+
+	suite.authenticate(suite.signedRequest("signing-client", suite.now, hmacSecret))
+
+	suite.False(suite.nextCalled)
+	suite.Equal(http.StatusInternalServerError, suite.recorder.Code)
+}
+
+func (suite *authenticatorTestSuite) TestMemoryReplayCache_ShouldForgetSignaturesOnceTheyLeaveTheWindow() {
+	replays := NewMemoryReplayCache().(*memoryReplayCache)
+
+	suite.True(replays.FirstUse("first", suite.now, time.Minute))
+	suite.False(replays.FirstUse("first", suite.now.Add(30*time.Second), time.Minute))
+	suite.True(replays.FirstUse("second", suite.now.Add(2*time.Minute), time.Minute))
+
+	suite.Len(replays.order, 1)
+	suite.Len(replays.seenAt, 1)
+	suite.True(replays.FirstUse("first", suite.now.Add(2*time.Minute), time.Minute))
+}
+
+func (suite *authenticatorTestSuite) TestRedisReplayCache_ShouldSetSignatureOnlyIfAbsentForTheWindow() {
+	mockCtrl := gomock.NewController(suite.T())
+	defer mockCtrl.Finish()
+	client := redismocks.NewMockClient(mockCtrl)
+	gomock.InOrder(
+		client.EXPECT().Do("SET", "ccg:replay:some-signature", "1", "NX", "PX", "600000").Return("OK", nil),
+		client.EXPECT().Do("SET", "ccg:replay:some-signature", "1", "NX", "PX", "600000").Return(nil, nil),
+	)
+	replays := NewRedisReplayCache(client)
+
+	firstUse, err := replays.FirstUse("some-signature", suite.now, 10*time.Minute)
+	suite.Nil(err)
+	suite.True(firstUse)
+	firstUse, err = replays.FirstUse("some-signature", suite.now, 10*time.Minute)
+	suite.Nil(err)
+	suite.False(firstUse)
+}
+
+func (suite *authenticatorTestSuite) TestNewAuthenticator_ShouldFailForApiKeyHashThatIsNotHexSha256() {
+	for _, apiKeyHash := range []string{"not-hex", "abcd"} {
+		_, err := NewAuthenticator(configuration.Auth{
+			Enabled: true,
+			Clients: []configuration.AuthClient{{ID: "key-client", ApiKeyHash: apiKeyHash}},
+		}, func(client configuration.AuthClient) string { return "" }, NewMemoryReplayCache())
+
+		suite.NotNil(err, apiKeyHash)
+	}
+}
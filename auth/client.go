@@ -0,0 +1,26 @@
+package auth
+
+import "github.com/gin-gonic/gin"
+
+const clientKey = "auth_client"
+
+// Client is the caller a request was authenticated as. Tenant is the only tenant it may send for, any when empty.
+type Client struct {
+	ID     string
+	Tenant string
+}
+
+// ClientFrom returns the client the request was authenticated as, nothing when authentication is disabled
+func ClientFrom(ctx *gin.Context) (Client, bool) {
+	value, ok := ctx.Get(clientKey)
+	if !ok {
+		return Client{}, false
+	}
+	client, ok := value.(Client)
+	return client, ok
+}
+
+// SetClient attaches the authenticated client to the request
+func SetClient(ctx *gin.Context, client Client) {
+	ctx.Set(clientKey, client)
+}
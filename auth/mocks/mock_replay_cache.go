@@ -0,0 +1,49 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: auth/replay_cache.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	gomock "github.com/golang/mock/gomock"
+	reflect "reflect"
+	time "time"
+)
+
+// MockReplayCache is a mock of ReplayCache interface
+type MockReplayCache struct {
+	ctrl     *gomock.Controller
+	recorder *MockReplayCacheMockRecorder
+}
+
+// MockReplayCacheMockRecorder is the mock recorder for MockReplayCache
+type MockReplayCacheMockRecorder struct {
+	mock *MockReplayCache
+}
+
+// NewMockReplayCache creates a new mock instance
+func NewMockReplayCache(ctrl *gomock.Controller) *MockReplayCache {
+	mock := &MockReplayCache{ctrl: ctrl}
+	mock.recorder = &MockReplayCacheMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockReplayCache) EXPECT() *MockReplayCacheMockRecorder {
+	return m.recorder
+}
+
+// FirstUse mocks base method
+func (m *MockReplayCache) FirstUse(signature string, now time.Time, window time.Duration) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FirstUse", signature, now, window)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FirstUse indicates an expected call of FirstUse
+func (mr *MockReplayCacheMockRecorder) FirstUse(signature, now, window interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FirstUse", reflect.TypeOf((*MockReplayCache)(nil).FirstUse), signature, now, window)
+}
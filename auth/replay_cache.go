@@ -0,0 +1,71 @@
+package auth
+
+// mockgen -source=auth/replay_cache.go -destination=auth/mocks/mock_replay_cache.go -package=mocks
+import (
+	"ccg-api/email/redis"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	MemoryReplayStore = "memory"
+	RedisReplayStore  = "redis"
+	replayKeyPrefix   = "ccg:replay:"
+)
+
+// ReplayCache remembers signatures until they fall out of the window, so a captured request cannot be resent.
+// FirstUse records the signature and reports whether it was not seen within the window before.
+type ReplayCache interface {
+	FirstUse(signature string, now time.Time, window time.Duration) (bool, error)
+}
+
+type memoryReplayCache struct {
+	mutex  sync.Mutex
+	seenAt map[string]time.Time
+	// order holds the signatures oldest first, so that expired ones are evicted from its front
+	order []seenSignature
+}
+
+type seenSignature struct {
+	signature string
+	at        time.Time
+}
+
+// NewMemoryReplayCache remembers signatures per instance, behind a load balancer a request replayed to another
+// instance is accepted again, so it only suits a single replica
+func NewMemoryReplayCache() ReplayCache {
+	return &memoryReplayCache{seenAt: map[string]time.Time{}}
+}
+
+func (cache *memoryReplayCache) FirstUse(signature string, now time.Time, window time.Duration) (bool, error) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	for len(cache.order) > 0 && now.Sub(cache.order[0].at) > window {
+		delete(cache.seenAt, cache.order[0].signature)
+		cache.order = cache.order[1:]
+	}
+	if _, seen := cache.seenAt[signature]; seen {
+		return false, nil
+	}
+	cache.seenAt[signature] = now
+	cache.order = append(cache.order, seenSignature{signature: signature, at: now})
+	return true, nil
+}
+
+type redisReplayCache struct {
+	client redis.Client
+}
+
+// NewRedisReplayCache shares signatures between instances, redis expires them once the window has passed
+func NewRedisReplayCache(client redis.Client) ReplayCache {
+	return redisReplayCache{client: client}
+}
+
+func (cache redisReplayCache) FirstUse(signature string, now time.Time, window time.Duration) (bool, error) {
+	reply, err := cache.client.Do("SET", replayKeyPrefix+signature, "1", "NX", "PX", strconv.FormatInt(window.Milliseconds(), 10))
+	if err != nil {
+		return false, err
+	}
+	return reply == "OK", nil
+}
@@ -7,29 +7,270 @@ type ConfigData struct {
 	TracingServiceName string `json:"tracing_service_name" binding:"required"`
 	TracingOCAgentHost string `json:"tracing_oc_agent_host" binding:"required"`
 	LogLevel           string `json:"log_level" binding:"required"`
+	Auth               Auth   `json:"auth"`
+	// MetricsAddress serves the metrics apart from the API, on a port that is not exposed publicly
+	MetricsAddress string `json:"metrics_address"`
+}
+
+// Auth lets clients authenticate with an API key, whose sha256 hex digest is configured, or by signing requests with
+// the HMAC secret in the environment variable HmacSecretEnv. Signed requests older than the replay window are rejected,
+// as are signed requests whose body is over MaxSignedBodyInBytes. Signatures seen within the window are kept in
+// ReplayStore, redis when several replicas serve requests; the memory store is a fallback for a single replica.
+type Auth struct {
+	Enabled               bool         `json:"enabled"`
+	ReplayWindowInSeconds int          `json:"replay_window_in_seconds"`
+	MaxSignedBodyInBytes  int64        `json:"max_signed_body_in_bytes"`
+	ReplayStore           string       `json:"replay_store"`
+	Clients               []AuthClient `json:"clients"`
+}
+
+// AuthClient is matched to its sender policy by ID. A client with a Tenant only sends mail of that tenant.
+type AuthClient struct {
+	ID            string `json:"id"`
+	ApiKeyHash    string `json:"api_key_hash"`
+	HmacSecretEnv string `json:"hmac_secret_env"`
+	Tenant        string `json:"tenant"`
 }
 
 type Email struct {
-	SmtpHost                         string   `json:"smtp_host"`
-	SmtpPort                         int      `json:"smtp_port"`
-	Username                         string   `json:"username"`
-	InsecureSkipVerify               bool     `json:"insecure_skip_verify"`
-	ValidMensuvadiEmailDomains       []string `json:"valid_mensuvadi_email_domains"`
-	DefaultMensuvadiEmailSender      string   `json:"default_mensuvadi_email_sender"`
-	UnsupportedAttachmentExtensions  []string `json:"unsupported_attachment_extensions"`
-	PermissibleAttachmentSizeInBytes int      `json:"permissible_attachment_size_in_bytes"`
-	BaseTemplateFilePath             string   `json:"base_template_file_path"`
-	LogoUrls                         LogoUrls `json:"logo_urls"`
-	OtherUrls                        Urls     `json:"urls"`
+	SmtpHost                         string         `json:"smtp_host"`
+	SmtpPort                         int            `json:"smtp_port"`
+	Username                         string         `json:"username"`
+	InsecureSkipVerify               bool           `json:"insecure_skip_verify"`
+	ValidMensuvadiEmailDomains       []string       `json:"valid_mensuvadi_email_domains"`
+	SenderPolicies                   []SenderPolicy `json:"sender_policies"`
+	UnsupportedAttachmentExtensions  []string       `json:"unsupported_attachment_extensions"`
+	PermissibleAttachmentSizeInBytes int            `json:"permissible_attachment_size_in_bytes"`
+	MaxRecipients                    int            `json:"max_recipients"`
+	MaxBatchSize                     int            `json:"max_batch_size"`
+	BatchWorkers                     int            `json:"batch_workers"`
+	MaxScheduleAheadInDays           int            `json:"max_schedule_ahead_in_days"`
+	AllowedCustomHeaders             []string       `json:"allowed_custom_headers"`
+	BaseTemplateFilePath             string         `json:"base_template_file_path"`
+	TemplateDirectory                string         `json:"template_directory"`
+	LogoUrls                         LogoUrls       `json:"logo_urls"`
+	EmbedLogos                       bool           `json:"embed_logos"`
+	LogoFiles                        LogoFiles      `json:"logo_files"`
+	OtherUrls                        Urls           `json:"urls"`
+	FooterText                       string         `json:"footer_text"`
+	Tenants                          []Tenant       `json:"tenants"`
+	DefaultTenant                    string         `json:"default_tenant"`
+	Outbox                           Outbox         `json:"outbox"`
+	SendRetryPolicy                  RetryPolicy    `json:"send_retry_policy"`
+	MessageStatus                    MessageStatus  `json:"message_status"`
+	Suppression                      Suppression    `json:"suppression"`
+	Bounce                           Bounce         `json:"bounce"`
+	Webhooks                         []Webhook      `json:"webhooks"`
+	Tracking                         Tracking       `json:"tracking"`
+	Unsubscribe                      Unsubscribe    `json:"unsubscribe"`
+	Categories                       []Category     `json:"categories"`
+	DefaultCategory                  string         `json:"default_category"`
+	RateLimiting                     RateLimiting   `json:"rate_limiting"`
+	Idempotency                      Idempotency    `json:"idempotency"`
+	Redis                            Redis          `json:"redis"`
+	Dkim                             Dkim           `json:"dkim"`
+	Transport                        Transport      `json:"transport"`
+	Relays                           []Relay        `json:"relays"`
+	RelayCircuitBreaker              CircuitBreaker `json:"relay_circuit_breaker"`
+	ConnectionPool                   ConnectionPool `json:"smtp_connection_pool"`
+}
+
+// Tenant is a product we send mail for, with its own brand and SMTP credentials. Settings it leaves empty fall back to
+// the ones of Email. Emails are sent for the tenant they name, else the tenant of their client, else DefaultTenant.
+type Tenant struct {
+	Name                 string    `json:"name"`
+	ValidEmailDomains    []string  `json:"valid_email_domains"`
+	DefaultSender        string    `json:"default_sender"`
+	DisplayName          string    `json:"display_name"`
+	BaseTemplateFilePath string    `json:"base_template_file_path"`
+	LogoUrls             LogoUrls  `json:"logo_urls"`
+	LogoFiles            LogoFiles `json:"logo_files"`
+	OtherUrls            Urls      `json:"urls"`
+	FooterText           string    `json:"footer_text"`
+	Relays               []Relay   `json:"relays"`
+}
+
+// ConnectionPool keeps SMTP sessions to every relay open between messages, a zero MaxIdleConnections dials per message
+type ConnectionPool struct {
+	MaxIdleConnections         int `json:"max_idle_connections"`
+	IdleTimeoutInSeconds       int `json:"idle_timeout_in_seconds"`
+	KeepaliveIntervalInSeconds int `json:"keepalive_interval_in_seconds"`
+}
+
+// Relay is an SMTP relay tried in ascending Priority, relays of equal priority share traffic by Weight.
+// Without relays the smtp_host of Email is the only relay.
+type Relay struct {
+	Name               string `json:"name"`
+	Host               string `json:"host"`
+	Port               int    `json:"port"`
+	Username           string `json:"username"`
+	PasswordEnv        string `json:"password_env"`
+	InsecureSkipVerify bool   `json:"insecure_skip_verify"`
+	Priority           int    `json:"priority"`
+	Weight             int    `json:"weight"`
+}
+
+// CircuitBreaker takes a relay out of rotation for the cool-down after FailureThreshold consecutive failures
+type CircuitBreaker struct {
+	FailureThreshold  int `json:"failure_threshold"`
+	CooldownInSeconds int `json:"cooldown_in_seconds"`
+}
+
+// Transport selects how mail leaves the service, one of smtp (default), http_api, maildir or sendmail
+type Transport struct {
+	Type     string            `json:"type"`
+	HttpApi  HttpApiTransport  `json:"http_api"`
+	Maildir  MaildirTransport  `json:"maildir"`
+	Sendmail SendmailTransport `json:"sendmail"`
+}
+
+type HttpApiTransport struct {
+	Url              string `json:"url"`
+	TimeoutInSeconds int    `json:"timeout_in_seconds"`
+}
+
+type MaildirTransport struct {
+	Directory string `json:"directory"`
+}
+
+type SendmailTransport struct {
+	Path string   `json:"path"`
+	Args []string `json:"args"`
+}
+
+type Dkim struct {
+	Keys []DkimKey `json:"keys"`
+}
+
+// DkimKey signs mail sent from Domain, which must be one of ValidMensuvadiEmailDomains or of the domains of a tenant
+type DkimKey struct {
+	Domain         string `json:"domain"`
+	Selector       string `json:"selector"`
+	PrivateKeyPath string `json:"private_key_path"`
+}
+
+// Idempotency records live in memory unless Store is redis, which lets a retry reach any instance
+type Idempotency struct {
+	WindowInSeconds int    `json:"window_in_seconds"`
+	Store           string `json:"store"`
+}
+
+type MessageStatus struct {
+	Directory       string `json:"directory"`
+	RetentionInDays int    `json:"retention_in_days"`
+}
+
+type Suppression struct {
+	Directory string `json:"directory"`
+}
+
+// Bounce reads delivery status notifications from a local maildir or a POP3 mailbox, the POP3 password comes from BOUNCE_MAILBOX_PASSWORD
+type Bounce struct {
+	Enabled               bool          `json:"enabled"`
+	Source                string        `json:"source"`
+	PollIntervalInSeconds int           `json:"poll_interval_in_seconds"`
+	Maildir               BounceMaildir `json:"maildir"`
+	Pop3                  Pop3Mailbox   `json:"pop3"`
+}
+
+type BounceMaildir struct {
+	Directory string `json:"directory"`
+}
+
+type Pop3Mailbox struct {
+	Host               string `json:"host"`
+	Port               int    `json:"port"`
+	Username           string `json:"username"`
+	InsecureSkipVerify bool   `json:"insecure_skip_verify"`
+}
+
+// Webhook accepts delivery events posted by Provider, authenticated with the secret in the environment variable SecretEnv
+type Webhook struct {
+	Provider  string `json:"provider"`
+	SecretEnv string `json:"secret_env"`
+}
+
+// Tracking links and open pixels point at BaseUrl, the signing secret comes from TRACKING_TOKEN_SECRET
+type Tracking struct {
+	BaseUrl string `json:"base_url"`
+}
+
+// Unsubscribe links are only added to emails of categories that honour preferences, the signing secret comes from UNSUBSCRIBE_TOKEN_SECRET
+type Unsubscribe struct {
+	BaseUrl             string `json:"base_url"`
+	PreferenceDirectory string `json:"preference_directory"`
+}
+
+// Category with BypassPreferences, like transactional or security mail, is sent regardless of opt-outs
+type Category struct {
+	Name              string `json:"name"`
+	BypassPreferences bool   `json:"bypass_preferences"`
+}
+
+// SenderPolicy lets Client send as the addresses matching AllowedSenders, exact addresses or patterns like
+// *@narratenet.com, and its DefaultSender. DisplayName is shown for senders without one. Without AllowedCategories
+// every category may be sent. The policy of client * applies to clients without their own and to anonymous requests.
+type SenderPolicy struct {
+	Client            string   `json:"client"`
+	AllowedSenders    []string `json:"allowed_senders"`
+	DefaultSender     string   `json:"default_sender"`
+	DisplayName       string   `json:"display_name"`
+	AllowedCategories []string `json:"allowed_categories"`
+}
+
+// RateLimiting counts every email against token buckets of the authenticated client, the sender domain and each
+// recipient address. Buckets live in memory unless Store is redis, which shares them between instances.
+type RateLimiting struct {
+	Enabled      bool        `json:"enabled"`
+	Store        string      `json:"store"`
+	Client       []RateLimit `json:"client"`
+	SenderDomain []RateLimit `json:"sender_domain"`
+	Recipient    []RateLimit `json:"recipient"`
+}
+
+// RateLimit allows Burst emails, refilled evenly over PeriodInSeconds. With a Category it only counts emails of that category.
+type RateLimit struct {
+	Category        string `json:"category"`
+	Burst           int    `json:"burst"`
+	PeriodInSeconds int    `json:"period_in_seconds"`
+}
+
+// Redis is shared by the rate limiter, the idempotency store and the replay cache when their store is redis.
+// A zero PoolSize or TimeoutInMilliseconds keeps the client default.
+type Redis struct {
+	Address               string `json:"address"`
+	Database              int    `json:"database"`
+	PasswordEnv           string `json:"password_env"`
+	PoolSize              int    `json:"pool_size"`
+	TimeoutInMilliseconds int    `json:"timeout_in_milliseconds"`
+}
+
+type Outbox struct {
+	Enabled               bool        `json:"enabled"`
+	Directory             string      `json:"directory"`
+	Workers               int         `json:"workers"`
+	PollIntervalInSeconds int         `json:"poll_interval_in_seconds"`
+	ClaimTimeoutInSeconds int         `json:"claim_timeout_in_seconds"`
+	RetryPolicy           RetryPolicy `json:"retry_policy"`
+}
+
+type RetryPolicy struct {
+	MaxAttempts            int     `json:"max_attempts"`
+	InitialBackoffInMillis int     `json:"initial_backoff_in_millis"`
+	MaxBackoffInMillis     int     `json:"max_backoff_in_millis"`
+	Multiplier             float64 `json:"multiplier"`
+	JitterFactor           float64 `json:"jitter_factor"`
 }
 
 type Urls struct {
 	HelpCenter    string `json:"help_center_url"`
 	PrivacyPolicy string `json:"privacy_policy_url"`
-	Unsubscribe   string `json:"unsubscribe_url"`
 	FAQUrl        string `json:"faq_url"`
 }
 
+// LogoFiles holds local paths of the same logos as LogoUrls, for embedding them inline
+type LogoFiles LogoUrls
+
 type LogoUrls struct {
 	Mensuvadi       string `json:"mensuvadi"`
 	Facebook        string `json:"facebook"`
@@ -7,21 +7,87 @@ import (
 )
 
 const (
-	PayloadValidationErrorCode string = "ERR_CCG_SERVICE_PAYLOAD_INVALID"
-	InternalServerErrorCode    string = "ERR_CCG_SERVICE_INTERNAL_SERVER_ERROR"
-	CCGServiceFailureCode      string = "ERR_CCG_SERVICE_SERVICE_FAILURE"
+	PayloadValidationErrorCode      string = "ERR_CCG_SERVICE_PAYLOAD_INVALID"
+	InternalServerErrorCode         string = "ERR_CCG_SERVICE_INTERNAL_SERVER_ERROR"
+	CCGServiceFailureCode           string = "ERR_CCG_SERVICE_SERVICE_FAILURE"
+	AsyncSendDisabledCode           string = "ERR_CCG_SERVICE_ASYNC_SEND_DISABLED"
+	PermanentDeliveryFailureCode    string = "ERR_CCG_SERVICE_PERMANENT_DELIVERY_FAILURE"
+	MessageNotFoundCode             string = "ERR_CCG_SERVICE_MESSAGE_NOT_FOUND"
+	IdempotencyKeyReusedCode        string = "ERR_CCG_SERVICE_IDEMPOTENCY_KEY_REUSED"
+	IdempotentRequestInProgressCode string = "ERR_CCG_SERVICE_IDEMPOTENT_REQUEST_IN_PROGRESS"
+	TemplateNotFoundCode            string = "ERR_CCG_SERVICE_TEMPLATE_NOT_FOUND"
+	MissingTemplateVariablesCode    string = "ERR_CCG_SERVICE_MISSING_TEMPLATE_VARIABLES"
+	BatchSizeExceededCode           string = "ERR_CCG_SERVICE_BATCH_SIZE_EXCEEDED"
+	MessageNotCancellableCode       string = "ERR_CCG_SERVICE_MESSAGE_NOT_CANCELLABLE"
+	SuppressionNotFoundCode         string = "ERR_CCG_SERVICE_SUPPRESSION_NOT_FOUND"
+	AllRecipientsSuppressedCode     string = "ERR_CCG_SERVICE_ALL_RECIPIENTS_SUPPRESSED"
+	InvalidUnsubscribeTokenCode     string = "ERR_CCG_SERVICE_INVALID_UNSUBSCRIBE_TOKEN"
+	UnknownWebhookProviderCode      string = "ERR_CCG_SERVICE_UNKNOWN_WEBHOOK_PROVIDER"
+	InvalidWebhookCredentialsCode   string = "ERR_CCG_SERVICE_INVALID_WEBHOOK_CREDENTIALS"
+	InvalidTrackingLinkCode         string = "ERR_CCG_SERVICE_INVALID_TRACKING_LINK"
+	UnauthenticatedCode             string = "ERR_CCG_SERVICE_UNAUTHENTICATED"
+	SenderNotAllowedCode            string = "ERR_CCG_SERVICE_SENDER_NOT_ALLOWED"
+	RateLimitExceededCode           string = "ERR_CCG_SERVICE_RATE_LIMIT_EXCEEDED"
+	CategoryNotAllowedCode          string = "ERR_CCG_SERVICE_CATEGORY_NOT_ALLOWED"
+	TenantNotAllowedCode            string = "ERR_CCG_SERVICE_TENANT_NOT_ALLOWED"
+	SingleRecipientRequiredCode     string = "ERR_CCG_SERVICE_SINGLE_RECIPIENT_REQUIRED"
+	PayloadTooLargeCode             string = "ERR_CCG_SERVICE_PAYLOAD_TOO_LARGE"
 )
 
 var (
-	CCGServiceFailureError = golaerror.Error{ErrorCode: CCGServiceFailureCode, ErrorMessage: "Failed to communicate with ccg service"}
-	PayloadValidationError = golaerror.Error{ErrorCode: PayloadValidationErrorCode, ErrorMessage: "One or more of the request parameters are missing or invalid"}
-	InternalServerError    = golaerror.Error{ErrorCode: InternalServerErrorCode, ErrorMessage: "something went wrong"}
+	CCGServiceFailureError           = golaerror.Error{ErrorCode: CCGServiceFailureCode, ErrorMessage: "Failed to communicate with ccg service"}
+	PayloadValidationError           = golaerror.Error{ErrorCode: PayloadValidationErrorCode, ErrorMessage: "One or more of the request parameters are missing or invalid"}
+	InternalServerError              = golaerror.Error{ErrorCode: InternalServerErrorCode, ErrorMessage: "something went wrong"}
+	AsyncSendDisabledError           = golaerror.Error{ErrorCode: AsyncSendDisabledCode, ErrorMessage: "Asynchronous send is not enabled"}
+	PermanentDeliveryFailureError    = golaerror.Error{ErrorCode: PermanentDeliveryFailureCode, ErrorMessage: "Email was permanently rejected by the mail server"}
+	MessageNotFoundError             = golaerror.Error{ErrorCode: MessageNotFoundCode, ErrorMessage: "No email found with the given message id"}
+	IdempotencyKeyReusedError        = golaerror.Error{ErrorCode: IdempotencyKeyReusedCode, ErrorMessage: "Idempotency key was already used with a different request"}
+	IdempotentRequestInProgressError = golaerror.Error{ErrorCode: IdempotentRequestInProgressCode, ErrorMessage: "A request with the same idempotency key is still being processed"}
+	TemplateNotFoundError            = golaerror.Error{ErrorCode: TemplateNotFoundCode, ErrorMessage: "No template registered with the given name"}
+	MissingTemplateVariablesError    = golaerror.Error{ErrorCode: MissingTemplateVariablesCode, ErrorMessage: "One or more variables required by the template are missing"}
+	BatchSizeExceededError           = golaerror.Error{ErrorCode: BatchSizeExceededCode, ErrorMessage: "Batch has more messages than allowed"}
+	MessageNotCancellableError       = golaerror.Error{ErrorCode: MessageNotCancellableCode, ErrorMessage: "Email is already being delivered or is no longer pending"}
+	SuppressionNotFoundError         = golaerror.Error{ErrorCode: SuppressionNotFoundCode, ErrorMessage: "Address or domain is not suppressed"}
+	AllRecipientsSuppressedError     = golaerror.Error{ErrorCode: AllRecipientsSuppressedCode, ErrorMessage: "Every recipient of the email is suppressed"}
+	InvalidUnsubscribeTokenError     = golaerror.Error{ErrorCode: InvalidUnsubscribeTokenCode, ErrorMessage: "Unsubscribe link is invalid"}
+	UnknownWebhookProviderError      = golaerror.Error{ErrorCode: UnknownWebhookProviderCode, ErrorMessage: "No webhook is configured for the provider"}
+	InvalidWebhookCredentialsError   = golaerror.Error{ErrorCode: InvalidWebhookCredentialsCode, ErrorMessage: "Webhook call could not be authenticated"}
+	InvalidTrackingLinkError         = golaerror.Error{ErrorCode: InvalidTrackingLinkCode, ErrorMessage: "Link is invalid"}
+	UnauthenticatedError             = golaerror.Error{ErrorCode: UnauthenticatedCode, ErrorMessage: "Request could not be authenticated"}
+	SenderNotAllowedError            = golaerror.Error{ErrorCode: SenderNotAllowedCode, ErrorMessage: "Client is not allowed to send from the given address"}
+	CategoryNotAllowedError          = golaerror.Error{ErrorCode: CategoryNotAllowedCode, ErrorMessage: "Client is not allowed to send emails of the given category"}
+	RateLimitExceededError           = golaerror.Error{ErrorCode: RateLimitExceededCode, ErrorMessage: "Too many emails, retry after the given number of seconds"}
+	TenantNotAllowedError            = golaerror.Error{ErrorCode: TenantNotAllowedCode, ErrorMessage: "Client is not allowed to send emails of the given tenant"}
+	SingleRecipientRequiredError     = golaerror.Error{ErrorCode: SingleRecipientRequiredCode, ErrorMessage: "Emails of the given category carry a personal unsubscribe link and must have a single recipient"}
+	PayloadTooLargeError             = golaerror.Error{ErrorCode: PayloadTooLargeCode, ErrorMessage: "Request body is larger than allowed"}
 )
 
 var ErrorCodeHttpStatusCodeMap = map[string]int{
-	PayloadValidationErrorCode: http.StatusBadRequest,
-	InternalServerErrorCode:    http.StatusInternalServerError,
-	CCGServiceFailureCode:      http.StatusInternalServerError,
+	PayloadValidationErrorCode:      http.StatusBadRequest,
+	InternalServerErrorCode:         http.StatusInternalServerError,
+	CCGServiceFailureCode:           http.StatusInternalServerError,
+	AsyncSendDisabledCode:           http.StatusNotImplemented,
+	PermanentDeliveryFailureCode:    http.StatusUnprocessableEntity,
+	MessageNotFoundCode:             http.StatusNotFound,
+	IdempotencyKeyReusedCode:        http.StatusConflict,
+	IdempotentRequestInProgressCode: http.StatusConflict,
+	TemplateNotFoundCode:            http.StatusNotFound,
+	MissingTemplateVariablesCode:    http.StatusBadRequest,
+	BatchSizeExceededCode:           http.StatusRequestEntityTooLarge,
+	MessageNotCancellableCode:       http.StatusConflict,
+	SuppressionNotFoundCode:         http.StatusNotFound,
+	AllRecipientsSuppressedCode:     http.StatusUnprocessableEntity,
+	InvalidUnsubscribeTokenCode:     http.StatusBadRequest,
+	UnknownWebhookProviderCode:      http.StatusNotFound,
+	InvalidWebhookCredentialsCode:   http.StatusUnauthorized,
+	InvalidTrackingLinkCode:         http.StatusNotFound,
+	UnauthenticatedCode:             http.StatusUnauthorized,
+	SenderNotAllowedCode:            http.StatusForbidden,
+	RateLimitExceededCode:           http.StatusTooManyRequests,
+	CategoryNotAllowedCode:          http.StatusForbidden,
+	TenantNotAllowedCode:            http.StatusForbidden,
+	SingleRecipientRequiredCode:     http.StatusBadRequest,
+	PayloadTooLargeCode:             http.StatusRequestEntityTooLarge,
 }
 
 func GetGolaHttpCode(golaErrCode string) int {
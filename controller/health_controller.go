@@ -1,18 +1,44 @@
 package controller
 
 import (
+	"ccg-api/email/relay"
 	"ccg-api/models/response"
 	"github.com/gin-gonic/gin"
 	"net/http"
 )
 
+type RelayStatusProvider interface {
+	Statuses() []relay.Status
+}
+
+// HealthController reports the circuit state of every SMTP relay when Relays is set. The route is public,
+// so it leaves out relay hosts and errors.
 type HealthController struct {
+	Relays RelayStatusProvider
 }
 
 func (HealthController HealthController) GetHealth(ctx *gin.Context) {
 	healthResponse := response.HealthResponse{
 		Status: "UP",
 	}
+	if HealthController.Relays != nil {
+		statuses := HealthController.Relays.Statuses()
+		for _, status := range statuses {
+			healthResponse.Relays = append(healthResponse.Relays, response.RelayHealth{Name: status.Name, State: string(status.State)})
+		}
+		if !anyRelayAvailable(statuses) {
+			healthResponse.Status = "DEGRADED"
+		}
+	}
 
 	ctx.JSON(http.StatusOK, healthResponse)
 }
+
+func anyRelayAvailable(statuses []relay.Status) bool {
+	for _, status := range statuses {
+		if status.State != relay.Open {
+			return true
+		}
+	}
+	return false
+}
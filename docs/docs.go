@@ -25,9 +25,502 @@ var doc = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
+        "/api/ccg/t/c/{token}": {
+            "get": {
+                "description": "API opened when a link of a tracked email is clicked, counts a click of the message and redirects to the original link",
+                "tags": [
+                    "Tracking"
+                ],
+                "summary": "API behind the links of a tracked email",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Tracking token from the link",
+                        "name": "token",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "302": {
+                        "description": "Redirect to the original link",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "If the token is invalid",
+                        "schema": {
+                            "$ref": "#/definitions/golaerror.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/ccg/t/o/{token}": {
+            "get": {
+                "description": "API loaded by the mail client when it shows the images of a tracked email, counts an open of the message,\nThe pixel is served even for invalid tokens so that the email renders the same",
+                "produces": [
+                    "image/gif"
+                ],
+                "tags": [
+                    "Tracking"
+                ],
+                "summary": "API behind the open pixel of a tracked email",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Tracking token from the pixel url",
+                        "name": "token",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "1x1 transparent GIF",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/ccg/v1/email": {
+            "get": {
+                "description": "API to list emails accepted from the authenticated client, most recent first\nIf NextCursor is set in the response then, passing it back as cursor lists the next page\nRecipients are matched by the sha256 hex digest of the lower cased address",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Email"
+                ],
+                "summary": "API to list the delivery status of emails",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "sha256 hex digest of a recipient address",
+                        "name": "recipient_hash",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Sender address",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Accepted at or after (RFC3339)",
+                        "name": "since",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Accepted at or before (RFC3339)",
+                        "name": "until",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum number of emails, defaults to 100",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "NextCursor of the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/http_request_response.MessageStatusListResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "If any filter is invalid",
+                        "schema": {
+                            "$ref": "#/definitions/golaerror.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/golaerror.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/ccg/v1/email/batch": {
+            "post": {
+                "description": "API to send either a list of messages, each shaped like a send request, or one template to a list of recipients each with their own variables,\nEvery item is validated and sent on its own, the result of each item is returned at its index with the status code it would have got from the single send APIs\nIf an Idempotency-Key is sent then, a retry with the same key and payload replays the original response",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Email"
+                ],
+                "summary": "API to send a batch of emails",
+                "parameters": [
+                    {
+                        "description": "Batch Email Request",
+                        "name": "batchEmailRequest",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/http_request_response.BatchEmailRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Unique key to safely retry the request",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/http_request_response.BatchEmailResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "If the batch is empty or has both messages and a template",
+                        "schema": {
+                            "$ref": "#/definitions/golaerror.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "If the request carries no valid API key or signature",
+                        "schema": {
+                            "$ref": "#/definitions/golaerror.Error"
+                        }
+                    },
+                    "409": {
+                        "description": "If the Idempotency-Key was used with a different payload or is still being processed",
+                        "schema": {
+                            "$ref": "#/definitions/golaerror.Error"
+                        }
+                    },
+                    "413": {
+                        "description": "If the batch has more items than allowed",
+                        "schema": {
+                            "$ref": "#/definitions/golaerror.Error"
+                        }
+                    }
+                }
+            }
+        },
         "/api/ccg/v1/email/send": {
             "post": {
-                "description": "API to send email,\nIf IncludeBaseTemplate is true then, header/footer (logos + disclaimer) is included",
+                "description": "API to send email,\nIf IncludeBaseTemplate is true then, header/footer (logos + disclaimer) is included\nIf Async is true then, the email is accepted into the outbox and delivered in the background\nIf SendAt is set then, the email is held in the outbox and delivered at that time, until then it can be cancelled\nSuppressed recipients are dropped and listed with their reason in the response\nFrom may carry a display name, as in \"Gola Support\" \u003csupport@gola.xyz\u003e, and defaults to the default sender of the client or else of the tenant\nTenant picks the product the email is sent for, its domains, base template and relays, and defaults to the tenant of the client or the default tenant\nCategory defaults to the configured default, recipients who opted out of it are dropped unless it bypasses preferences\nIf Track is true then, links of the HTML body are rewritten to count clicks and an open pixel is added\nIf an Idempotency-Key is sent then, a retry with the same key and payload replays the original response",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Email"
+                ],
+                "summary": "API to send email",
+                "parameters": [
+                    {
+                        "description": "Email Request",
+                        "name": "emailRequest",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/http_request_response.EmailRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Unique key to safely retry the request",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/http_request_response.SendEmailResponse"
+                        }
+                    },
+                    "202": {
+                        "description": "If Async is true or SendAt is set",
+                        "schema": {
+                            "$ref": "#/definitions/http_request_response.SendEmailResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "If To/Subject/Body are empty, From is invalid or missing without a default sender, or Category or Tenant is not configured",
+                        "schema": {
+                            "$ref": "#/definitions/golaerror.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "If the request carries no valid API key or signature",
+                        "schema": {
+                            "$ref": "#/definitions/golaerror.Error"
+                        }
+                    },
+                    "403": {
+                        "description": "If the sender is not of a domain of the tenant, the sender policy of the client does not allow the sender address or category, or the client is bound to another tenant",
+                        "schema": {
+                            "$ref": "#/definitions/golaerror.Error"
+                        }
+                    },
+                    "409": {
+                        "description": "If the Idempotency-Key was used with a different payload or is still being processed",
+                        "schema": {
+                            "$ref": "#/definitions/golaerror.Error"
+                        }
+                    },
+                    "422": {
+                        "description": "If the mail server permanently rejected the email or every recipient is suppressed",
+                        "schema": {
+                            "$ref": "#/definitions/golaerror.Error"
+                        }
+                    },
+                    "429": {
+                        "description": "If the client, sender domain or a recipient is over its rate limit, Retry-After tells when to retry",
+                        "schema": {
+                            "$ref": "#/definitions/golaerror.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/golaerror.Error"
+                        }
+                    },
+                    "501": {
+                        "description": "If Async is true or SendAt is set but the outbox is not enabled",
+                        "schema": {
+                            "$ref": "#/definitions/golaerror.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/ccg/v1/email/send-template": {
+            "post": {
+                "description": "API to send email whose subject, HTML and text bodies are rendered server side from the named template and variables,\nEvery variable listed as required by the template must be present\nIf Async is true then, the email is accepted into the outbox and delivered in the background\nIf SendAt is set then, the email is held in the outbox and delivered at that time, until then it can be cancelled\nSuppressed recipients are dropped and listed with their reason in the response\nFrom may carry a display name, as in \"Gola Support\" \u003csupport@gola.xyz\u003e, and defaults to the default sender of the client or else of the tenant\nTenant picks the product the email is sent for, its domains, base template and relays, and defaults to the tenant of the client or the default tenant\nCategory defaults to the configured default, recipients who opted out of it are dropped unless it bypasses preferences\nIf Track is true then, links of the HTML body are rewritten to count clicks and an open pixel is added\nIf an Idempotency-Key is sent then, a retry with the same key and payload replays the original response",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Email"
+                ],
+                "summary": "API to send email rendered from a registered template",
+                "parameters": [
+                    {
+                        "description": "Template Email Request",
+                        "name": "templateEmailRequest",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/http_request_response.TemplateEmailRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Unique key to safely retry the request",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/http_request_response.SendEmailResponse"
+                        }
+                    },
+                    "202": {
+                        "description": "If Async is true or SendAt is set",
+                        "schema": {
+                            "$ref": "#/definitions/http_request_response.SendEmailResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "If To/TemplateName are empty, From is invalid or missing without a default sender, Tenant is not configured, or required variables are missing",
+                        "schema": {
+                            "$ref": "#/definitions/golaerror.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "If the request carries no valid API key or signature",
+                        "schema": {
+                            "$ref": "#/definitions/golaerror.Error"
+                        }
+                    },
+                    "403": {
+                        "description": "If the sender is not of a domain of the tenant, the sender policy of the client does not allow the sender address or category, or the client is bound to another tenant",
+                        "schema": {
+                            "$ref": "#/definitions/golaerror.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "If no template is registered with the given name",
+                        "schema": {
+                            "$ref": "#/definitions/golaerror.Error"
+                        }
+                    },
+                    "409": {
+                        "description": "If the Idempotency-Key was used with a different payload or is still being processed",
+                        "schema": {
+                            "$ref": "#/definitions/golaerror.Error"
+                        }
+                    },
+                    "422": {
+                        "description": "If the mail server permanently rejected the email or every recipient is suppressed",
+                        "schema": {
+                            "$ref": "#/definitions/golaerror.Error"
+                        }
+                    },
+                    "429": {
+                        "description": "If the client, sender domain or a recipient is over its rate limit, Retry-After tells when to retry",
+                        "schema": {
+                            "$ref": "#/definitions/golaerror.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/golaerror.Error"
+                        }
+                    },
+                    "501": {
+                        "description": "If Async is true or SendAt is set but the outbox is not enabled",
+                        "schema": {
+                            "$ref": "#/definitions/golaerror.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/ccg/v1/email/{id}": {
+            "get": {
+                "description": "API to get the lifecycle of an accepted email, with timestamps and the last error if any\nOnly emails sent by the authenticated client are found",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Email"
+                ],
+                "summary": "API to get the delivery status of an email",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Message Id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/http_request_response.MessageStatusResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "If no email was accepted with the given id",
+                        "schema": {
+                            "$ref": "#/definitions/golaerror.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/golaerror.Error"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "API to cancel an email that is still waiting in the outbox, typically one scheduled with SendAt",
+                "tags": [
+                    "Email"
+                ],
+                "summary": "API to cancel a scheduled email",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Message Id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "If the email was cancelled"
+                    },
+                    "404": {
+                        "description": "If no email was accepted from the client with the given id",
+                        "schema": {
+                            "$ref": "#/definitions/golaerror.Error"
+                        }
+                    },
+                    "409": {
+                        "description": "If the email is already being delivered or is no longer pending",
+                        "schema": {
+                            "$ref": "#/definitions/golaerror.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/golaerror.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/ccg/v1/suppressions": {
+            "get": {
+                "description": "API to list every suppressed address or domain, most recent first",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Suppression"
+                ],
+                "summary": "API to list suppressed addresses and domains",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/http_request_response.SuppressionListResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/golaerror.Error"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "API to stop every future email to an address, or to every address of a domain,\nSuppressing an address that is already suppressed keeps and returns the original entry",
                 "consumes": [
                     "application/json"
                 ],
@@ -35,24 +528,227 @@ var doc = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Email"
+                    "Suppression"
                 ],
-                "summary": "API to send email",
+                "summary": "API to suppress an address or domain",
                 "parameters": [
                     {
-                        "description": "Email Request",
-                        "name": "emailRequest",
+                        "description": "Suppression Request",
+                        "name": "suppressionRequest",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/http_request_response.EmailRequest"
+                            "$ref": "#/definitions/http_request_response.SuppressionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/http_request_response.SuppressionResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "If the address is neither an email address nor a domain, or the reason is unknown",
+                        "schema": {
+                            "$ref": "#/definitions/golaerror.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/golaerror.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/ccg/v1/suppressions/import": {
+            "post": {
+                "description": "API to import a csv of address,reason lines, the header row is optional and a missing reason is taken as manual,\nInvalid lines are skipped and reported with their line number, every valid line is imported",
+                "consumes": [
+                    "text/csv"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Suppression"
+                ],
+                "summary": "API to suppress addresses and domains in bulk from a csv",
+                "parameters": [
+                    {
+                        "description": "address,reason lines",
+                        "name": "suppressions",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/http_request_response.SuppressionImportResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "If the body is not a valid csv",
+                        "schema": {
+                            "$ref": "#/definitions/golaerror.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/golaerror.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/ccg/v1/suppressions/{address}": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Suppression"
+                ],
+                "summary": "API to get the suppression of an address or domain",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Email address or domain",
+                        "name": "address",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/http_request_response.SuppressionResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "If the address or domain is not suppressed",
+                        "schema": {
+                            "$ref": "#/definitions/golaerror.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/golaerror.Error"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "tags": [
+                    "Suppression"
+                ],
+                "summary": "API to lift the suppression of an address or domain",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Email address or domain",
+                        "name": "address",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "If the suppression was lifted"
+                    },
+                    "404": {
+                        "description": "If the address or domain is not suppressed",
+                        "schema": {
+                            "$ref": "#/definitions/golaerror.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/golaerror.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/ccg/v1/unsubscribe/{token}": {
+            "get": {
+                "description": "API opened from the unsubscribe link in the email body, opts the recipient out of the category of the email and shows a confirmation page",
+                "produces": [
+                    "text/html"
+                ],
+                "tags": [
+                    "Unsubscribe"
+                ],
+                "summary": "API behind the unsubscribe link of an email",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Unsubscribe token from the link",
+                        "name": "token",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Confirmation page",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "If the token is invalid",
+                        "schema": {
+                            "$ref": "#/definitions/golaerror.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/golaerror.Error"
                         }
                     }
+                }
+            },
+            "post": {
+                "description": "API posted to by mail clients from the List-Unsubscribe header, opts the recipient out of the category of the email",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Unsubscribe"
+                ],
+                "summary": "API for one-click unsubscribe (RFC 8058)",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Unsubscribe token from the List-Unsubscribe header",
+                        "name": "token",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
                 "responses": {
-                    "204": {},
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/http_request_response.UnsubscribeResponse"
+                        }
+                    },
                     "400": {
-                        "description": "If From/To/Subject/Body are empty",
+                        "description": "If the token is invalid",
                         "schema": {
                             "$ref": "#/definitions/golaerror.Error"
                         }
@@ -65,6 +761,63 @@ var doc = `{
                     }
                 }
             }
+        },
+        "/api/ccg/v1/webhooks/{provider}": {
+            "post": {
+                "description": "API called by the email provider with delivered, bounced, complaint, open and click events of our messages,\nHard bounces and complaints suppress the recipient, delivered, bounced and complaint events update the message status",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhook"
+                ],
+                "summary": "API for email providers to post delivery events",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Provider name e.g. brevo",
+                        "name": "provider",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Bearer secret configured for the provider's webhook",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/http_request_response.WebhookResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "If the payload cannot be parsed",
+                        "schema": {
+                            "$ref": "#/definitions/golaerror.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "If the call cannot be authenticated",
+                        "schema": {
+                            "$ref": "#/definitions/golaerror.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "If no webhook is configured for the provider",
+                        "schema": {
+                            "$ref": "#/definitions/golaerror.Error"
+                        }
+                    }
+                }
+            }
         }
     },
     "definitions": {
@@ -91,32 +844,192 @@ var doc = `{
             "properties": {
                 "base64_encoded_data": {
                     "type": "string",
-                    "example": "base64 encoded value"
+                    "example": "base64 encoded value"
+                },
+                "content_id": {
+                    "type": "string",
+                    "example": "logo@gola.xyz"
+                },
+                "disposition": {
+                    "description": "Use attachment as default",
+                    "type": "string",
+                    "example": "inline"
+                },
+                "file_name": {
+                    "type": "string",
+                    "example": "fileName.pdf"
+                }
+            }
+        },
+        "http_request_response.BatchEmailRequest": {
+            "type": "object",
+            "properties": {
+                "messages": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/http_request_response.EmailRequest"
+                    }
+                },
+                "template": {
+                    "type": "object",
+                    "$ref": "#/definitions/http_request_response.BatchTemplateRequest"
+                }
+            }
+        },
+        "http_request_response.BatchEmailResponse": {
+            "type": "object",
+            "properties": {
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/http_request_response.BatchItemResult"
+                    }
+                }
+            }
+        },
+        "http_request_response.BatchItemResult": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "object",
+                    "$ref": "#/definitions/golaerror.Error"
+                },
+                "index": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "message_id": {
+                    "type": "string",
+                    "example": "9b2f6c1e-3f0a-4a8e-8f5e-2d7c1b0a9e11"
+                },
+                "status_code": {
+                    "type": "integer",
+                    "example": 200
+                },
+                "suppressed": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.SuppressedRecipient"
+                    }
+                }
+            }
+        },
+        "http_request_response.BatchTemplateRequest": {
+            "type": "object",
+            "properties": {
+                "async": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "bcc": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "ghi@gmail.com"
+                    ]
+                },
+                "category": {
+                    "type": "string",
+                    "example": "marketing"
+                },
+                "cc": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "def@gmail.com"
+                    ]
+                },
+                "from": {
+                    "type": "string",
+                    "example": "Gola Support \u003cabc@gola.xyz\u003e"
+                },
+                "headers": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "recipients": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/http_request_response.TemplateRecipient"
+                    }
+                },
+                "reply_to": {
+                    "type": "string",
+                    "example": "support@gola.xyz"
                 },
-                "file_name": {
+                "send_at": {
                     "type": "string",
-                    "example": "fileName.pdf"
+                    "example": "2022-01-02T09:00:00+05:30"
+                },
+                "template_name": {
+                    "type": "string",
+                    "example": "welcome"
+                },
+                "tenant": {
+                    "type": "string",
+                    "example": "narratenet"
+                },
+                "track": {
+                    "type": "boolean",
+                    "example": false
                 }
             }
         },
         "http_request_response.EmailRequest": {
             "type": "object",
             "required": [
-                "from",
                 "message_body",
                 "subject",
                 "to"
             ],
             "properties": {
+                "async": {
+                    "type": "boolean",
+                    "example": false
+                },
                 "attachments": {
                     "type": "array",
                     "items": {
                         "$ref": "#/definitions/http_request_response.Attachment"
                     }
                 },
+                "bcc": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "ghi@gmail.com"
+                    ]
+                },
+                "category": {
+                    "type": "string",
+                    "example": "marketing"
+                },
+                "cc": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "def@gmail.com"
+                    ]
+                },
                 "from": {
                     "type": "string",
-                    "example": "abc@gola.xyz"
+                    "example": "Gola Support \u003cabc@gola.xyz\u003e"
+                },
+                "headers": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
                 },
                 "include_base_template": {
                     "type": "boolean",
@@ -126,10 +1039,22 @@ var doc = `{
                     "type": "object",
                     "$ref": "#/definitions/http_request_response.MessageBody"
                 },
+                "reply_to": {
+                    "type": "string",
+                    "example": "support@gola.xyz"
+                },
+                "send_at": {
+                    "type": "string",
+                    "example": "2022-01-02T09:00:00+05:30"
+                },
                 "subject": {
                     "type": "string",
                     "example": "base64 encoded value"
                 },
+                "tenant": {
+                    "type": "string",
+                    "example": "narratenet"
+                },
                 "to": {
                     "type": "array",
                     "items": {
@@ -138,6 +1063,10 @@ var doc = `{
                     "example": [
                         "abc@gmail.com"
                     ]
+                },
+                "track": {
+                    "type": "boolean",
+                    "example": false
                 }
             }
         },
@@ -151,12 +1080,335 @@ var doc = `{
                     "type": "string",
                     "example": "base64 encoded value"
                 },
+                "base64_encoded_plain_text_content": {
+                    "description": "PlainTextContent is the text alternative of an html Content, generated from the html when not given",
+                    "type": "string",
+                    "example": "base64 encoded value"
+                },
                 "mime_type": {
                     "description": "Use text/plain as default",
                     "type": "string",
                     "example": "text/html"
                 }
             }
+        },
+        "http_request_response.MessageStatusListResponse": {
+            "type": "object",
+            "properties": {
+                "messages": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/http_request_response.MessageStatusResponse"
+                    }
+                },
+                "next_cursor": {
+                    "type": "string",
+                    "example": "1582418054000000000_9b2f6c1e-3f0a-4a8e-8f5e-2d7c1b0a9e11"
+                }
+            }
+        },
+        "http_request_response.MessageStatusResponse": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "from": {
+                    "type": "string",
+                    "example": "abc@gola.xyz"
+                },
+                "history": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/http_request_response.StateTransitionResponse"
+                    }
+                },
+                "last_error": {
+                    "type": "string"
+                },
+                "message_id": {
+                    "type": "string",
+                    "example": "9b2f6c1e-3f0a-4a8e-8f5e-2d7c1b0a9e11"
+                },
+                "outcomes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/http_request_response.RecipientOutcomeResponse"
+                    }
+                },
+                "recipient_hashes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "status": {
+                    "type": "string",
+                    "example": "sent"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "http_request_response.RecipientOutcomeResponse": {
+            "type": "object",
+            "properties": {
+                "at": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "recipient_hash": {
+                    "type": "string",
+                    "example": "6f2a3d1c0e9b8a7f6e5d4c3b2a1f0e9d8c7b6a5f4e3d2c1b0a9f8e7d6c5b4a39"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "delivered"
+                }
+            }
+        },
+        "http_request_response.SendEmailResponse": {
+            "type": "object",
+            "properties": {
+                "message_id": {
+                    "type": "string",
+                    "example": "9b2f6c1e-3f0a-4a8e-8f5e-2d7c1b0a9e11"
+                },
+                "suppressed": {
+                    "description": "Suppressed lists the recipients that were dropped, the email still goes to everyone else",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.SuppressedRecipient"
+                    }
+                }
+            }
+        },
+        "http_request_response.StateTransitionResponse": {
+            "type": "object",
+            "properties": {
+                "at": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "sending"
+                }
+            }
+        },
+        "http_request_response.SuppressionImportRejection": {
+            "type": "object",
+            "properties": {
+                "address": {
+                    "type": "string",
+                    "example": "not-an-address"
+                },
+                "error": {
+                    "type": "string",
+                    "example": "One or more of the request parameters are missing or invalid"
+                },
+                "line": {
+                    "type": "integer",
+                    "example": 3
+                }
+            }
+        },
+        "http_request_response.SuppressionImportResponse": {
+            "type": "object",
+            "properties": {
+                "imported": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "rejected": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/http_request_response.SuppressionImportRejection"
+                    }
+                }
+            }
+        },
+        "http_request_response.SuppressionListResponse": {
+            "type": "object",
+            "properties": {
+                "suppressions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/http_request_response.SuppressionResponse"
+                    }
+                }
+            }
+        },
+        "http_request_response.SuppressionRequest": {
+            "type": "object",
+            "required": [
+                "address",
+                "reason"
+            ],
+            "properties": {
+                "address": {
+                    "type": "string",
+                    "example": "abc@gmail.com"
+                },
+                "reason": {
+                    "type": "string",
+                    "example": "manual"
+                }
+            }
+        },
+        "http_request_response.SuppressionResponse": {
+            "type": "object",
+            "properties": {
+                "address": {
+                    "type": "string",
+                    "example": "abc@gmail.com"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "reason": {
+                    "type": "string",
+                    "example": "hard_bounce"
+                },
+                "source": {
+                    "type": "string",
+                    "example": "api"
+                }
+            }
+        },
+        "http_request_response.TemplateEmailRequest": {
+            "type": "object",
+            "required": [
+                "template_name",
+                "to"
+            ],
+            "properties": {
+                "async": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "bcc": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "ghi@gmail.com"
+                    ]
+                },
+                "category": {
+                    "type": "string",
+                    "example": "marketing"
+                },
+                "cc": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "def@gmail.com"
+                    ]
+                },
+                "from": {
+                    "type": "string",
+                    "example": "Gola Support \u003cabc@gola.xyz\u003e"
+                },
+                "headers": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "reply_to": {
+                    "type": "string",
+                    "example": "support@gola.xyz"
+                },
+                "send_at": {
+                    "type": "string",
+                    "example": "2022-01-02T09:00:00+05:30"
+                },
+                "template_name": {
+                    "type": "string",
+                    "example": "password_reset"
+                },
+                "tenant": {
+                    "type": "string",
+                    "example": "narratenet"
+                },
+                "to": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "abc@gmail.com"
+                    ]
+                },
+                "track": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "variables": {
+                    "type": "object",
+                    "additionalProperties": true
+                }
+            }
+        },
+        "http_request_response.TemplateRecipient": {
+            "type": "object",
+            "properties": {
+                "to": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "abc@gmail.com"
+                    ]
+                },
+                "variables": {
+                    "type": "object",
+                    "additionalProperties": true
+                }
+            }
+        },
+        "http_request_response.UnsubscribeResponse": {
+            "type": "object",
+            "properties": {
+                "category": {
+                    "type": "string",
+                    "example": "marketing"
+                }
+            }
+        },
+        "http_request_response.WebhookResponse": {
+            "type": "object",
+            "properties": {
+                "accepted": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "models.SuppressedRecipient": {
+            "type": "object",
+            "properties": {
+                "address": {
+                    "type": "string"
+                },
+                "reason": {
+                    "type": "string"
+                }
+            }
         }
     }
 }`
@@ -0,0 +1,132 @@
+package bounce
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"net/textproto"
+	"strings"
+)
+
+var ErrNotDeliveryReport = errors.New("message is not a delivery status notification")
+
+// Report is what a delivery status notification (RFC 3464) tells about one of our messages
+type Report struct {
+	OriginalMessageID string
+	Recipients        []FailedRecipient
+}
+
+type FailedRecipient struct {
+	Address    string
+	Action     string
+	Status     string
+	Diagnostic string
+}
+
+// IsHardBounce is a permanent failure, the address is not worth sending to again
+func (recipient FailedRecipient) IsHardBounce() bool {
+	return strings.EqualFold(recipient.Action, "failed") && strings.HasPrefix(recipient.Status, "5.")
+}
+
+func (recipient FailedRecipient) Error() string {
+	if recipient.Diagnostic == "" {
+		return recipient.Status
+	}
+	return recipient.Status + " " + recipient.Diagnostic
+}
+
+// ParseReport reads a multipart/report with report-type delivery-status. The original message id is taken from the
+// returned message or its headers, the last part of the report.
+func ParseReport(reader io.Reader) (Report, error) {
+	message, err := mail.ReadMessage(reader)
+	if err != nil {
+		return Report{}, err
+	}
+	mediaType, params, err := mime.ParseMediaType(message.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/report" || !strings.EqualFold(params["report-type"], "delivery-status") {
+		return Report{}, ErrNotDeliveryReport
+	}
+
+	var report Report
+	foundStatus := false
+	parts := multipart.NewReader(message.Body, params["boundary"])
+	for {
+		part, err := parts.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return Report{}, err
+		}
+		partType, _, _ := mime.ParseMediaType(part.Header.Get("Content-Type"))
+		switch partType {
+		case "message/delivery-status", "message/global-delivery-status":
+			report.Recipients, err = parseDeliveryStatus(part)
+			if err != nil {
+				return Report{}, err
+			}
+			foundStatus = true
+		case "message/rfc822", "text/rfc822-headers", "message/global", "message/global-headers":
+			headers, err := textproto.NewReader(bufio.NewReader(part)).ReadMIMEHeader()
+			if err != nil && err != io.EOF {
+				return Report{}, err
+			}
+			report.OriginalMessageID = strings.TrimSpace(headers.Get("Message-ID"))
+		}
+	}
+	if !foundStatus {
+		return Report{}, ErrNotDeliveryReport
+	}
+	return report, nil
+}
+
+// parseDeliveryStatus skips the per message fields and collects every per recipient block that did not deliver
+func parseDeliveryStatus(reader io.Reader) ([]FailedRecipient, error) {
+	fieldReader := textproto.NewReader(bufio.NewReader(reader))
+	if _, err := fieldReader.ReadMIMEHeader(); err != nil {
+		if err == io.EOF {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var recipients []FailedRecipient
+	for {
+		fields, err := fieldReader.ReadMIMEHeader()
+		if len(fields) > 0 {
+			recipient := FailedRecipient{
+				Address:    recipientAddress(fields.Get("Final-Recipient")),
+				Action:     strings.ToLower(strings.TrimSpace(fields.Get("Action"))),
+				Status:     strings.TrimSpace(fields.Get("Status")),
+				Diagnostic: typedValue(fields.Get("Diagnostic-Code")),
+			}
+			if recipient.Address == "" {
+				recipient.Address = recipientAddress(fields.Get("Original-Recipient"))
+			}
+			if recipient.Address != "" && (recipient.Action == "failed" || recipient.Action == "delayed") {
+				recipients = append(recipients, recipient)
+			}
+		}
+		if err == io.EOF {
+			return recipients, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// typedValue drops the address or diagnostic type, e.g. rfc822; or smtp;
+func typedValue(field string) string {
+	if separator := strings.Index(field, ";"); separator >= 0 {
+		field = field[separator+1:]
+	}
+	return strings.TrimSpace(field)
+}
+
+func recipientAddress(field string) string {
+	return strings.ToLower(strings.Trim(typedValue(field), "<>"))
+}
@@ -0,0 +1,83 @@
+package bounce
+
+import (
+	"github.com/stretchr/testify/suite"
+	"strings"
+	"testing"
+)
+
+const hardBounceReport = "From: Mail Delivery System <MAILER-DAEMON@mx.gola.xyz>\r\n" +
+	"To: bounces@gola.xyz\r\n" +
+	"Subject: Undelivered Mail Returned to Sender\r\n" +
+	"MIME-Version: 1.0\r\n" +
+	"Content-Type: multipart/report; report-type=delivery-status; boundary=\"report-boundary\"\r\n" +
+	"\r\n" +
+	"--report-boundary\r\n" +
+	"Content-Type: text/plain\r\n" +
+	"\r\n" +
+	"Your message could not be delivered.\r\n" +
+	"--report-boundary\r\n" +
+	"Content-Type: message/delivery-status\r\n" +
+	"\r\n" +
+	"Reporting-MTA: dns; mx.gola.xyz\r\n" +
+	"Arrival-Date: Mon, 3 Jan 2022 09:00:00 +0530\r\n" +
+	"\r\n" +
+	"Final-Recipient: rfc822; Missing@Gmail.com\r\n" +
+	"Action: failed\r\n" +
+	"Status: 5.1.1\r\n" +
+	"Diagnostic-Code: smtp; 550 5.1.1 user unknown\r\n" +
+	"\r\n" +
+	"Final-Recipient: rfc822; <full@gmail.com>\r\n" +
+	"Action: delayed\r\n" +
+	"Status: 4.2.2\r\n" +
+	"\r\n" +
+	"Final-Recipient: rfc822; someone@gmail.com\r\n" +
+	"Action: delivered\r\n" +
+	"Status: 2.0.0\r\n" +
+	"\r\n" +
+	"--report-boundary\r\n" +
+	"Content-Type: text/rfc822-headers\r\n" +
+	"\r\n" +
+	"From: gola@gola.xyz\r\n" +
+	"Message-ID: <9b2f6c1e-3f0a-4a8e-8f5e-2d7c1b0a9e11@gola.xyz>\r\n" +
+	"Subject: Hi!\r\n" +
+	"\r\n" +
+	"--report-boundary--\r\n"
+
+type dsnTestSuite struct {
+	suite.Suite
+}
+
+func TestDsnTestSuite(t *testing.T) {
+	suite.Run(t, new(dsnTestSuite))
+}
+
+func (suite *dsnTestSuite) TestParseReport_ShouldReturnUndeliveredRecipientsAndOriginalMessageID() {
+	report, err := ParseReport(strings.NewReader(hardBounceReport))
+
+	suite.Nil(err)
+	suite.Equal("<9b2f6c1e-3f0a-4a8e-8f5e-2d7c1b0a9e11@gola.xyz>", report.OriginalMessageID)
+	suite.Equal([]FailedRecipient{
+		{Address: "missing@gmail.com", Action: "failed", Status: "5.1.1", Diagnostic: "550 5.1.1 user unknown"},
+		{Address: "full@gmail.com", Action: "delayed", Status: "4.2.2"},
+	}, report.Recipients)
+	suite.True(report.Recipients[0].IsHardBounce())
+	suite.False(report.Recipients[1].IsHardBounce())
+}
+
+func (suite *dsnTestSuite) TestParseReport_ShouldRejectMessageThatIsNotDeliveryReport() {
+	message := "From: someone@gmail.com\r\nContent-Type: text/plain\r\n\r\nOut of office until Monday\r\n"
+
+	_, err := ParseReport(strings.NewReader(message))
+
+	suite.Equal(ErrNotDeliveryReport, err)
+}
+
+func (suite *dsnTestSuite) TestParseReport_ShouldRejectReportWithoutDeliveryStatus() {
+	message := "Content-Type: multipart/report; report-type=delivery-status; boundary=\"b\"\r\n\r\n" +
+		"--b\r\nContent-Type: text/plain\r\n\r\nUndeliverable\r\n--b--\r\n"
+
+	_, err := ParseReport(strings.NewReader(message))
+
+	suite.Equal(ErrNotDeliveryReport, err)
+}
@@ -0,0 +1,99 @@
+package bounce
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const pop3Timeout = 30 * time.Second
+
+type pop3Source struct {
+	username string
+	password string
+	dial     func() (net.Conn, error)
+}
+
+// NewPop3Source reads a mailbox over POP3 with implicit TLS, usually on port 995
+func NewPop3Source(host string, port int, username string, password string, insecureSkipVerify bool) Source {
+	address := net.JoinHostPort(host, strconv.Itoa(port))
+	tlsConfig := &tls.Config{ServerName: host, InsecureSkipVerify: insecureSkipVerify}
+	return pop3Source{
+		username: username,
+		password: password,
+		dial: func() (net.Conn, error) {
+			return tls.DialWithDialer(&net.Dialer{Timeout: pop3Timeout}, "tcp", address, tlsConfig)
+		},
+	}
+}
+
+// Drain marks every handled message for deletion, the server only deletes them once the session ends with QUIT
+func (source pop3Source) Drain(handle func(content []byte)) error {
+	connection, err := source.dial()
+	if err != nil {
+		return err
+	}
+	_ = connection.SetDeadline(time.Now().Add(pop3Timeout))
+	client := textproto.NewConn(connection)
+	defer client.Close()
+
+	if _, err := readPop3Response(client); err != nil {
+		return err
+	}
+	if _, err := pop3Command(client, "USER %s", source.username); err != nil {
+		return err
+	}
+	if _, err := pop3Command(client, "PASS %s", source.password); err != nil {
+		return err
+	}
+	if _, err := pop3Command(client, "LIST"); err != nil {
+		return err
+	}
+	listing, err := client.ReadDotLines()
+	if err != nil {
+		return err
+	}
+
+	for _, line := range listing {
+		number := strings.Fields(line)
+		if len(number) == 0 {
+			continue
+		}
+		_ = connection.SetDeadline(time.Now().Add(pop3Timeout))
+		if _, err := pop3Command(client, "RETR %s", number[0]); err != nil {
+			return err
+		}
+		content, err := client.ReadDotBytes()
+		if err != nil {
+			return err
+		}
+		handle(content)
+		if _, err := pop3Command(client, "DELE %s", number[0]); err != nil {
+			return err
+		}
+	}
+	_, err = pop3Command(client, "QUIT")
+	return err
+}
+
+func pop3Command(client *textproto.Conn, format string, args ...interface{}) (string, error) {
+	if err := client.PrintfLine(format, args...); err != nil {
+		return "", err
+	}
+	return readPop3Response(client)
+}
+
+func readPop3Response(client *textproto.Conn) (string, error) {
+	line, err := client.ReadLine()
+	if err != nil {
+		return "", err
+	}
+	if !strings.HasPrefix(line, "+OK") {
+		return "", fmt.Errorf("pop3 server responded with %s", line)
+	}
+	return strings.TrimSpace(strings.TrimPrefix(line, "+OK")), nil
+}
@@ -0,0 +1,85 @@
+package bounce
+
+import (
+	"bytes"
+	"ccg-api/email/email-client/email_client_request"
+	"ccg-api/email/event"
+	"ccg-api/email/models"
+	"ccg-api/email/suppression"
+	"ccg-api/util"
+	"github.com/gin-gonic/gin"
+	"github.com/inclusi-blog/gola-utils/logging"
+	"time"
+)
+
+const defaultPollInterval = time.Minute
+
+type Processor interface {
+	Start()
+}
+
+type processor struct {
+	source       Source
+	recorder     event.Recorder
+	pollInterval time.Duration
+}
+
+func NewProcessor(source Source, recorder event.Recorder, pollIntervalInSeconds int) Processor {
+	pollInterval := defaultPollInterval
+	if pollIntervalInSeconds > 0 {
+		pollInterval = time.Duration(pollIntervalInSeconds) * time.Second
+	}
+	return &processor{
+		source:       source,
+		recorder:     recorder,
+		pollInterval: pollInterval,
+	}
+}
+
+func (processor *processor) Start() {
+	go processor.poll()
+}
+
+func (processor *processor) poll() {
+	ticker := time.NewTicker(processor.pollInterval)
+	defer ticker.Stop()
+	for {
+		processor.drain(util.NewBackgroundContext())
+		<-ticker.C
+	}
+}
+
+func (processor *processor) drain(ctx *gin.Context) {
+	logger := logging.GetLogger(ctx).WithField("class", "BounceProcessor").WithField("method", "drain")
+	if err := processor.source.Drain(func(content []byte) {
+		processor.handle(ctx, content)
+	}); err != nil {
+		logger.Error("Failed to read bounce mailbox ", err)
+	}
+}
+
+// handle reports every recipient of the notification, hard bounces get suppressed and mark our original message as bounced
+func (processor *processor) handle(ctx *gin.Context, content []byte) {
+	logger := logging.GetLogger(ctx).WithField("class", "BounceProcessor").WithField("method", "handle")
+	report, err := ParseReport(bytes.NewReader(content))
+	if err != nil {
+		logger.Warnf("Skipping message in bounce mailbox, %s", err)
+		return
+	}
+
+	messageID := email_client_request.MessageIDFromHeader(report.OriginalMessageID)
+	for _, recipient := range report.Recipients {
+		eventType := models.DeferredEvent
+		if recipient.IsHardBounce() {
+			eventType = models.HardBounceEvent
+		}
+		processor.recorder.Record(ctx, models.DeliveryEvent{
+			Source:     suppression.BounceSource,
+			Type:       eventType,
+			MessageID:  messageID,
+			Recipient:  recipient.Address,
+			Reason:     recipient.Error(),
+			OccurredAt: time.Now(),
+		})
+	}
+}
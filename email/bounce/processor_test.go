@@ -0,0 +1,63 @@
+package bounce
+
+import (
+	"ccg-api/email/event/mocks"
+	"ccg-api/email/models"
+	"ccg-api/email/suppression"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/suite"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type processorTestSuite struct {
+	suite.Suite
+	context   *gin.Context
+	mockCtrl  *gomock.Controller
+	recorder  *mocks.MockRecorder
+	processor *processor
+}
+
+func TestProcessorTestSuite(t *testing.T) {
+	suite.Run(t, new(processorTestSuite))
+}
+
+func (suite *processorTestSuite) SetupTest() {
+	suite.context, _ = gin.CreateTestContext(httptest.NewRecorder())
+	suite.context.Request, _ = http.NewRequest("GET", "/", nil)
+	suite.mockCtrl = gomock.NewController(suite.T())
+	suite.recorder = mocks.NewMockRecorder(suite.mockCtrl)
+	suite.processor = NewProcessor(nil, suite.recorder, 0).(*processor)
+}
+
+func (suite *processorTestSuite) TearDownTest() {
+	suite.mockCtrl.Finish()
+}
+
+func (suite *processorTestSuite) TestHandle_ShouldRecordEventForEveryUndeliveredRecipient() {
+	var events []models.DeliveryEvent
+	suite.recorder.EXPECT().Record(suite.context, gomock.Any()).Do(func(ctx *gin.Context, event models.DeliveryEvent) {
+		suite.False(event.OccurredAt.IsZero())
+		event.OccurredAt = time.Time{}
+		events = append(events, event)
+	}).Times(2)
+
+	suite.processor.handle(suite.context, []byte(hardBounceReport))
+
+	suite.Equal(models.DeliveryEvent{
+		Source:    suppression.BounceSource,
+		Type:      models.HardBounceEvent,
+		MessageID: "9b2f6c1e-3f0a-4a8e-8f5e-2d7c1b0a9e11",
+		Recipient: "missing@gmail.com",
+		Reason:    "5.1.1 550 5.1.1 user unknown",
+	}, events[0])
+	suite.Equal(models.DeferredEvent, events[1].Type)
+	suite.Equal("full@gmail.com", events[1].Recipient)
+}
+
+func (suite *processorTestSuite) TestHandle_ShouldIgnoreMessageThatIsNotDeliveryReport() {
+	suite.processor.handle(suite.context, []byte("Content-Type: text/plain\r\n\r\nOut of office\r\n"))
+}
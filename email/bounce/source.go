@@ -0,0 +1,61 @@
+package bounce
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"sort"
+	"strings"
+)
+
+const (
+	MaildirSource = "maildir"
+	Pop3Source    = "pop3"
+)
+
+// Source is a mailbox that receives delivery status notifications for our messages
+type Source interface {
+	// Drain hands every waiting message to handle and then removes it from the mailbox, so that it is never processed twice
+	Drain(handle func(content []byte)) error
+}
+
+type maildirSource struct {
+	directory string
+}
+
+// NewMaildirSource reads the new folder of a maildir that the MTA delivers bounces into
+func NewMaildirSource(directory string) (Source, error) {
+	for _, subDirectory := range []string{"tmp", "new", "cur"} {
+		if err := os.MkdirAll(path.Join(directory, subDirectory), 0755); err != nil {
+			return nil, err
+		}
+	}
+	return maildirSource{directory: directory}, nil
+}
+
+// Drain moves handled messages into cur marked as seen, as a mail client would
+func (source maildirSource) Drain(handle func(content []byte)) error {
+	newDirectory := path.Join(source.directory, "new")
+	entries, err := ioutil.ReadDir(newDirectory)
+	if err != nil {
+		return err
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Name() < entries[j].Name()
+	})
+	for _, entry := range entries {
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		filePath := path.Join(newDirectory, entry.Name())
+		content, err := ioutil.ReadFile(filePath)
+		if err != nil {
+			return err
+		}
+		handle(content)
+		if err := os.Rename(filePath, path.Join(source.directory, "cur", entry.Name()+":2,S")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
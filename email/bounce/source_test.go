@@ -0,0 +1,120 @@
+package bounce
+
+import (
+	"bufio"
+	"github.com/stretchr/testify/suite"
+	"io/ioutil"
+	"net"
+	"os"
+	"path"
+	"strings"
+	"testing"
+)
+
+type sourceTestSuite struct {
+	suite.Suite
+	directory string
+}
+
+func TestSourceTestSuite(t *testing.T) {
+	suite.Run(t, new(sourceTestSuite))
+}
+
+func (suite *sourceTestSuite) SetupTest() {
+	suite.directory = path.Join(os.TempDir(), "ccg-bounce-source-unit-test-dir")
+	_ = os.RemoveAll(suite.directory)
+}
+
+func (suite *sourceTestSuite) TearDownTest() {
+	_ = os.RemoveAll(suite.directory)
+}
+
+func (suite *sourceTestSuite) TestMaildirDrain_ShouldHandleNewMessagesAndMoveThemToCur() {
+	source, err := NewMaildirSource(suite.directory)
+	suite.Nil(err)
+	_ = ioutil.WriteFile(path.Join(suite.directory, "new", "1.bounce"), []byte("first"), 0644)
+	_ = ioutil.WriteFile(path.Join(suite.directory, "new", "2.bounce"), []byte("second"), 0644)
+
+	var handled []string
+	suite.Nil(source.Drain(func(content []byte) {
+		handled = append(handled, string(content))
+	}))
+
+	suite.Equal([]string{"first", "second"}, handled)
+	remaining, _ := ioutil.ReadDir(path.Join(suite.directory, "new"))
+	suite.Empty(remaining)
+	_, err = os.Stat(path.Join(suite.directory, "cur", "1.bounce:2,S"))
+	suite.Nil(err)
+}
+
+func (suite *sourceTestSuite) TestMaildirDrain_ShouldNotHandleMessageTwice() {
+	source, _ := NewMaildirSource(suite.directory)
+	_ = ioutil.WriteFile(path.Join(suite.directory, "new", "1.bounce"), []byte("first"), 0644)
+	_ = source.Drain(func(content []byte) {})
+
+	handled := 0
+	suite.Nil(source.Drain(func(content []byte) { handled++ }))
+
+	suite.Equal(0, handled)
+}
+
+func (suite *sourceTestSuite) TestPop3Drain_ShouldRetrieveAndDeleteEveryMessage() {
+	server, client := net.Pipe()
+	var commands []string
+	go func() {
+		defer server.Close()
+		reader := bufio.NewReader(server)
+		write := func(lines ...string) {
+			for _, line := range lines {
+				_, _ = server.Write([]byte(line + "\r\n"))
+			}
+		}
+		write("+OK POP3 ready")
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				return
+			}
+			command := strings.TrimSpace(line)
+			commands = append(commands, command)
+			switch {
+			case command == "LIST":
+				write("+OK 1 message", "1 42", ".")
+			case command == "RETR 1":
+				write("+OK 42 octets", "Subject: Undelivered", "", "..dot stuffed line", ".")
+			case command == "QUIT":
+				write("+OK bye")
+				return
+			default:
+				write("+OK")
+			}
+		}
+	}()
+	source := pop3Source{username: "bounces", password: "secret", dial: func() (net.Conn, error) { return client, nil }}
+
+	var handled []string
+	suite.Nil(source.Drain(func(content []byte) {
+		handled = append(handled, string(content))
+	}))
+
+	suite.Equal([]string{"Subject: Undelivered\n\n.dot stuffed line\n"}, handled)
+	suite.Equal([]string{"USER bounces", "PASS secret", "LIST", "RETR 1", "DELE 1", "QUIT"}, commands)
+}
+
+func (suite *sourceTestSuite) TestPop3Drain_ShouldFailWhenLoginIsRejected() {
+	server, client := net.Pipe()
+	go func() {
+		defer server.Close()
+		reader := bufio.NewReader(server)
+		_, _ = server.Write([]byte("+OK POP3 ready\r\n"))
+		_, _ = reader.ReadString('\n')
+		_, _ = server.Write([]byte("+OK\r\n"))
+		_, _ = reader.ReadString('\n')
+		_, _ = server.Write([]byte("-ERR invalid password\r\n"))
+	}()
+	source := pop3Source{username: "bounces", password: "wrong", dial: func() (net.Conn, error) { return client, nil }}
+
+	err := source.Drain(func(content []byte) {})
+
+	suite.EqualError(err, "pop3 server responded with -ERR invalid password")
+}
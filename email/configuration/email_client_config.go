@@ -3,6 +3,7 @@ package configuration
 import (
 	"ccg-api/configuration"
 	"os"
+	"strings"
 )
 
 type EmailClientConfig interface {
@@ -13,20 +14,127 @@ type EmailClientConfig interface {
 	InsecureSkipVerify() bool
 	TempDir() string
 	ValidGolaEmailDomain() []string
-	DefaultGolaEmailSender() string
+	AllValidGolaEmailDomains() []string
+	Tenants() []configuration.Tenant
+	DefaultTenant() string
+	ForTenant(name string) EmailClientConfig
+	DefaultSender() string
+	DisplayName() string
+	FooterText() string
+	SenderPolicies() []configuration.SenderPolicy
 	UnsupportedAttachmentExtensions() []string
 	PermissibleTotalSizeOfAttachments() int
+	MaxRecipients() int
+	MaxBatchSize() int
+	BatchWorkers() int
+	MaxScheduleAheadInDays() int
+	AllowedCustomHeaders() []string
 	BaseTemplateFilePath() string
+	TemplateDirectory() string
 	LogoUrls() configuration.LogoUrls
+	EmbedLogos() bool
+	LogoFiles() configuration.LogoFiles
 	OtherUrls() configuration.Urls
+	Outbox() configuration.Outbox
+	SendRetryPolicy() configuration.RetryPolicy
+	MessageStatus() configuration.MessageStatus
+	Suppression() configuration.Suppression
+	Bounce() configuration.Bounce
+	BounceMailboxPassword() string
+	Webhooks() []configuration.Webhook
+	WebhookSecret(webhook configuration.Webhook) string
+	Unsubscribe() configuration.Unsubscribe
+	Categories() []configuration.Category
+	DefaultCategory() string
+	RateLimiting() configuration.RateLimiting
+	Redis() configuration.Redis
+	RedisPassword() string
+	Idempotency() configuration.Idempotency
+	Dkim() configuration.Dkim
+	Transport() configuration.Transport
+	Relays() []configuration.Relay
+	RelayPassword(relay configuration.Relay) string
+	RelayCircuitBreaker() configuration.CircuitBreaker
+	ConnectionPool() configuration.ConnectionPool
+	ApiKey() string
+	UnsubscribeSecret() string
+	Tracking() configuration.Tracking
+	TrackingSecret() string
 }
 
 type emailClientConfig struct {
-	email configuration.Email
+	email         configuration.Email
+	defaultSender string
+	displayName   string
 }
 
 func NewEmailClientConfig(email configuration.Email) EmailClientConfig {
-	return emailClientConfig{email}
+	return emailClientConfig{email: email}
+}
+
+// ForTenant is the config of the named tenant, whose empty settings fall back to the top level ones. An empty or
+// unknown name gets the default tenant, or the top level config when there is no default tenant either.
+func (config emailClientConfig) ForTenant(name string) EmailClientConfig {
+	tenant, found := config.tenant(name)
+	if !found {
+		tenant, found = config.tenant(config.email.DefaultTenant)
+	}
+	if !found {
+		return config
+	}
+	email := config.email
+	if len(tenant.ValidEmailDomains) > 0 {
+		email.ValidMensuvadiEmailDomains = tenant.ValidEmailDomains
+	}
+	if tenant.BaseTemplateFilePath != "" {
+		email.BaseTemplateFilePath = tenant.BaseTemplateFilePath
+	}
+	if tenant.LogoUrls != (configuration.LogoUrls{}) {
+		email.LogoUrls = tenant.LogoUrls
+	}
+	if tenant.LogoFiles != (configuration.LogoFiles{}) {
+		email.LogoFiles = tenant.LogoFiles
+	}
+	if tenant.OtherUrls != (configuration.Urls{}) {
+		email.OtherUrls = tenant.OtherUrls
+	}
+	if tenant.FooterText != "" {
+		email.FooterText = tenant.FooterText
+	}
+	if len(tenant.Relays) > 0 {
+		email.Relays = tenant.Relays
+	}
+	return emailClientConfig{email: email, defaultSender: tenant.DefaultSender, displayName: tenant.DisplayName}
+}
+
+func (config emailClientConfig) tenant(name string) (configuration.Tenant, bool) {
+	for _, tenant := range config.email.Tenants {
+		if name != "" && tenant.Name == name {
+			return tenant, true
+		}
+	}
+	return configuration.Tenant{}, false
+}
+
+func (config emailClientConfig) Tenants() []configuration.Tenant {
+	return config.email.Tenants
+}
+
+func (config emailClientConfig) DefaultTenant() string {
+	return config.email.DefaultTenant
+}
+
+// DefaultSender is the sender of the tenant for emails without one, empty outside a tenant
+func (config emailClientConfig) DefaultSender() string {
+	return config.defaultSender
+}
+
+func (config emailClientConfig) DisplayName() string {
+	return config.displayName
+}
+
+func (config emailClientConfig) FooterText() string {
+	return config.email.FooterText
 }
 
 func (config emailClientConfig) OtherUrls() configuration.Urls {
@@ -49,6 +157,22 @@ func (config emailClientConfig) Password() string {
 	return os.Getenv("SMTP_CLIENT_PASSWORD")
 }
 
+func (config emailClientConfig) ApiKey() string {
+	return os.Getenv("EMAIL_API_KEY")
+}
+
+func (config emailClientConfig) UnsubscribeSecret() string {
+	return os.Getenv("UNSUBSCRIBE_TOKEN_SECRET")
+}
+
+func (config emailClientConfig) BounceMailboxPassword() string {
+	return os.Getenv("BOUNCE_MAILBOX_PASSWORD")
+}
+
+func (config emailClientConfig) TrackingSecret() string {
+	return os.Getenv("TRACKING_TOKEN_SECRET")
+}
+
 func (config emailClientConfig) InsecureSkipVerify() bool {
 	return config.email.InsecureSkipVerify
 }
@@ -61,8 +185,27 @@ func (config emailClientConfig) ValidGolaEmailDomain() []string {
 	return config.email.ValidMensuvadiEmailDomains
 }
 
-func (config emailClientConfig) DefaultGolaEmailSender() string {
-	return config.email.DefaultMensuvadiEmailSender
+// AllValidGolaEmailDomains are the domains any tenant may send from
+func (config emailClientConfig) AllValidGolaEmailDomains() []string {
+	seen := map[string]bool{}
+	var domains []string
+	add := func(candidates []string) {
+		for _, domain := range candidates {
+			if !seen[strings.ToLower(domain)] {
+				seen[strings.ToLower(domain)] = true
+				domains = append(domains, domain)
+			}
+		}
+	}
+	add(config.email.ValidMensuvadiEmailDomains)
+	for _, tenant := range config.email.Tenants {
+		add(tenant.ValidEmailDomains)
+	}
+	return domains
+}
+
+func (config emailClientConfig) SenderPolicies() []configuration.SenderPolicy {
+	return config.email.SenderPolicies
 }
 
 func (config emailClientConfig) UnsupportedAttachmentExtensions() []string {
@@ -73,10 +216,145 @@ func (config emailClientConfig) PermissibleTotalSizeOfAttachments() int {
 	return config.email.PermissibleAttachmentSizeInBytes
 }
 
+func (config emailClientConfig) MaxRecipients() int {
+	return config.email.MaxRecipients
+}
+
+func (config emailClientConfig) MaxBatchSize() int {
+	return config.email.MaxBatchSize
+}
+
+func (config emailClientConfig) BatchWorkers() int {
+	return config.email.BatchWorkers
+}
+
+func (config emailClientConfig) MaxScheduleAheadInDays() int {
+	return config.email.MaxScheduleAheadInDays
+}
+
+func (config emailClientConfig) AllowedCustomHeaders() []string {
+	return config.email.AllowedCustomHeaders
+}
+
 func (config emailClientConfig) BaseTemplateFilePath() string {
 	return config.email.BaseTemplateFilePath
 }
 
+func (config emailClientConfig) TemplateDirectory() string {
+	return config.email.TemplateDirectory
+}
+
 func (config emailClientConfig) LogoUrls() configuration.LogoUrls {
 	return config.email.LogoUrls
 }
+
+func (config emailClientConfig) EmbedLogos() bool {
+	return config.email.EmbedLogos
+}
+
+func (config emailClientConfig) LogoFiles() configuration.LogoFiles {
+	return config.email.LogoFiles
+}
+
+func (config emailClientConfig) Outbox() configuration.Outbox {
+	return config.email.Outbox
+}
+
+func (config emailClientConfig) SendRetryPolicy() configuration.RetryPolicy {
+	return config.email.SendRetryPolicy
+}
+
+func (config emailClientConfig) MessageStatus() configuration.MessageStatus {
+	return config.email.MessageStatus
+}
+
+func (config emailClientConfig) Suppression() configuration.Suppression {
+	return config.email.Suppression
+}
+
+func (config emailClientConfig) Bounce() configuration.Bounce {
+	return config.email.Bounce
+}
+
+func (config emailClientConfig) Webhooks() []configuration.Webhook {
+	return config.email.Webhooks
+}
+
+func (config emailClientConfig) WebhookSecret(webhook configuration.Webhook) string {
+	if len(webhook.SecretEnv) == 0 {
+		return ""
+	}
+	return os.Getenv(webhook.SecretEnv)
+}
+
+func (config emailClientConfig) Tracking() configuration.Tracking {
+	return config.email.Tracking
+}
+
+func (config emailClientConfig) Unsubscribe() configuration.Unsubscribe {
+	return config.email.Unsubscribe
+}
+
+func (config emailClientConfig) Categories() []configuration.Category {
+	return config.email.Categories
+}
+
+func (config emailClientConfig) DefaultCategory() string {
+	return config.email.DefaultCategory
+}
+
+func (config emailClientConfig) RateLimiting() configuration.RateLimiting {
+	return config.email.RateLimiting
+}
+
+func (config emailClientConfig) Redis() configuration.Redis {
+	return config.email.Redis
+}
+
+func (config emailClientConfig) RedisPassword() string {
+	if len(config.email.Redis.PasswordEnv) == 0 {
+		return ""
+	}
+	return os.Getenv(config.email.Redis.PasswordEnv)
+}
+
+func (config emailClientConfig) Idempotency() configuration.Idempotency {
+	return config.email.Idempotency
+}
+
+func (config emailClientConfig) Dkim() configuration.Dkim {
+	return config.email.Dkim
+}
+
+func (config emailClientConfig) Transport() configuration.Transport {
+	return config.email.Transport
+}
+
+// Relays falls back to the single relay described by smtp_host when no relays are configured
+func (config emailClientConfig) Relays() []configuration.Relay {
+	if len(config.email.Relays) > 0 {
+		return config.email.Relays
+	}
+	return []configuration.Relay{{
+		Name:               "default",
+		Host:               config.email.SmtpHost,
+		Port:               config.email.SmtpPort,
+		Username:           config.email.Username,
+		InsecureSkipVerify: config.email.InsecureSkipVerify,
+	}}
+}
+
+func (config emailClientConfig) RelayPassword(relay configuration.Relay) string {
+	if len(relay.PasswordEnv) == 0 {
+		return config.Password()
+	}
+	return os.Getenv(relay.PasswordEnv)
+}
+
+func (config emailClientConfig) RelayCircuitBreaker() configuration.CircuitBreaker {
+	return config.email.RelayCircuitBreaker
+}
+
+func (config emailClientConfig) ConnectionPool() configuration.ConnectionPool {
+	return config.email.ConnectionPool
+}
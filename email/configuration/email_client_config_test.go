@@ -0,0 +1,78 @@
+package configuration
+
+import (
+	"ccg-api/configuration"
+	"github.com/stretchr/testify/suite"
+	"testing"
+)
+
+type emailClientConfigTestSuite struct {
+	suite.Suite
+	config EmailClientConfig
+}
+
+func TestEmailClientConfigTestSuite(t *testing.T) {
+	suite.Run(t, new(emailClientConfigTestSuite))
+}
+
+func (suite *emailClientConfigTestSuite) SetupTest() {
+	suite.config = NewEmailClientConfig(configuration.Email{
+		SmtpHost:                   "smtp-relay.sendinblue.com",
+		SmtpPort:                   587,
+		ValidMensuvadiEmailDomains: []string{"gola.xyz"},
+		BaseTemplateFilePath:       "email_templates/base_email_template.html",
+		LogoUrls:                   configuration.LogoUrls{Mensuvadi: "https://cdn.gola.xyz/logo.png"},
+		OtherUrls:                  configuration.Urls{HelpCenter: "https://help.gola.xyz"},
+		FooterText:                 "© Gola. All rights reserved.",
+		DefaultTenant:              "gola",
+		Tenants: []configuration.Tenant{
+			{Name: "gola", DefaultSender: "support@gola.xyz"},
+			{
+				Name:                 "narratenet",
+				ValidEmailDomains:    []string{"narratenet.com", "GOLA.xyz"},
+				DefaultSender:        "support@narratenet.com",
+				DisplayName:          "Narratenet",
+				BaseTemplateFilePath: "email_templates/narratenet/base_email_template.html",
+				OtherUrls:            configuration.Urls{HelpCenter: "https://help.narratenet.com"},
+				FooterText:           "© Narratenet. All rights reserved.",
+				Relays:               []configuration.Relay{{Name: "narratenet", Host: "smtp.narratenet.com", Port: 587}},
+			},
+		},
+	})
+}
+
+func (suite *emailClientConfigTestSuite) TestForTenant_ShouldOverrideSettingsTheTenantHas() {
+	tenantConfig := suite.config.ForTenant("narratenet")
+
+	suite.Equal([]string{"narratenet.com", "GOLA.xyz"}, tenantConfig.ValidGolaEmailDomain())
+	suite.Equal("support@narratenet.com", tenantConfig.DefaultSender())
+	suite.Equal("Narratenet", tenantConfig.DisplayName())
+	suite.Equal("email_templates/narratenet/base_email_template.html", tenantConfig.BaseTemplateFilePath())
+	suite.Equal(configuration.Urls{HelpCenter: "https://help.narratenet.com"}, tenantConfig.OtherUrls())
+	suite.Equal("© Narratenet. All rights reserved.", tenantConfig.FooterText())
+	suite.Equal([]configuration.Relay{{Name: "narratenet", Host: "smtp.narratenet.com", Port: 587}}, tenantConfig.Relays())
+}
+
+func (suite *emailClientConfigTestSuite) TestForTenant_ShouldFallBackToTopLevelSettingsTheTenantLeavesEmpty() {
+	tenantConfig := suite.config.ForTenant("narratenet")
+
+	suite.Equal(configuration.LogoUrls{Mensuvadi: "https://cdn.gola.xyz/logo.png"}, tenantConfig.LogoUrls())
+	suite.Equal("smtp-relay.sendinblue.com", suite.config.ForTenant("gola").Relays()[0].Host)
+	suite.Equal([]string{"gola.xyz"}, suite.config.ForTenant("gola").ValidGolaEmailDomain())
+}
+
+func (suite *emailClientConfigTestSuite) TestForTenant_ShouldGiveDefaultTenantForEmptyOrUnknownName() {
+	suite.Equal("support@gola.xyz", suite.config.ForTenant("").DefaultSender())
+	suite.Equal("support@gola.xyz", suite.config.ForTenant("unknown").DefaultSender())
+}
+
+func (suite *emailClientConfigTestSuite) TestForTenant_ShouldGiveTopLevelConfigWithoutTenants() {
+	config := NewEmailClientConfig(configuration.Email{ValidMensuvadiEmailDomains: []string{"gola.xyz"}})
+
+	suite.Equal(config, config.ForTenant(""))
+	suite.Empty(config.ForTenant("").DefaultSender())
+}
+
+func (suite *emailClientConfigTestSuite) TestAllValidGolaEmailDomains_ShouldListDomainsOfEveryTenantOnce() {
+	suite.Equal([]string{"gola.xyz", "narratenet.com"}, suite.config.AllValidGolaEmailDomains())
+}
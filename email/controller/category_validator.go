@@ -0,0 +1,26 @@
+package controller
+
+import (
+	"ccg-api/configuration"
+	"github.com/go-playground/validator/v10"
+)
+
+type CategoryValidator struct {
+	categories map[string]bool
+}
+
+func NewCategoryValidator(categories []configuration.Category) *CategoryValidator {
+	knownCategories := map[string]bool{}
+	for _, category := range categories {
+		knownCategories[category.Name] = true
+	}
+	return &CategoryValidator{categories: knownCategories}
+}
+
+func (categoryValidator CategoryValidator) validate(fieldLevel validator.FieldLevel) bool {
+	category, ok := fieldLevel.Field().Interface().(string)
+	if !ok {
+		return false
+	}
+	return categoryValidator.categories[category]
+}
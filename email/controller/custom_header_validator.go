@@ -0,0 +1,36 @@
+package controller
+
+import (
+	"github.com/go-playground/validator/v10"
+	"strings"
+)
+
+type CustomHeaderValidator struct {
+	allowedHeaders []string
+}
+
+func NewCustomHeaderValidator(allowedHeaders []string) *CustomHeaderValidator {
+	return &CustomHeaderValidator{allowedHeaders: allowedHeaders}
+}
+
+func (customHeaderValidator CustomHeaderValidator) validate(fieldLevel validator.FieldLevel) bool {
+	headers, ok := fieldLevel.Field().Interface().(map[string]string)
+	if !ok {
+		return false
+	}
+	for name, value := range headers {
+		if !customHeaderValidator.isAllowed(name) || strings.ContainsAny(value, "\r\n") {
+			return false
+		}
+	}
+	return true
+}
+
+func (customHeaderValidator CustomHeaderValidator) isAllowed(name string) bool {
+	for _, allowedHeader := range customHeaderValidator.allowedHeaders {
+		if strings.EqualFold(name, allowedHeader) {
+			return true
+		}
+	}
+	return false
+}
@@ -1,45 +1,98 @@
 package controller
 
 import (
+	"ccg-api/auth"
 	"ccg-api/constants"
 	configuration2 "ccg-api/email/configuration"
 	"ccg-api/email/http_request_response"
+	"ccg-api/email/idempotency"
+	"ccg-api/email/models"
+	"ccg-api/email/policy"
+	"ccg-api/email/ratelimit"
 	. "ccg-api/email/service"
+	"ccg-api/email/templates"
 	http_util "ccg-api/http-util"
+	"encoding/json"
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
 	"github.com/go-playground/validator/v10/non-standard/validators"
 	"github.com/inclusi-blog/gola-utils/golaerror"
 	"github.com/inclusi-blog/gola-utils/logging"
 	"net/http"
+	"sync"
+)
+
+const (
+	jsonContentType     = "application/json; charset=utf-8"
+	defaultMaxBatchSize = 100
+	defaultBatchWorkers = 8
 )
 
 type EmailController interface {
 	SendEmail(ctx *gin.Context)
+	SendTemplateEmail(ctx *gin.Context)
+	SendBatch(ctx *gin.Context)
+	CancelEmail(ctx *gin.Context)
 }
 
 type emailController struct {
 	config                  configuration2.EmailClientConfig
 	service                 EmailService
+	templateRegistry        templates.Registry
+	idempotencyGuard        idempotency.Guard
+	limiter                 ratelimit.Limiter
+	policies                policy.Policies
+	defaultCategory         string
+	defaultTenant           string
 	httpRequestDeserializer http_util.HttpRequestDeserializer
+	maxBatchSize            int
+	batchWorkers            int
 }
 
-func NewEmailController(service EmailService, config configuration2.EmailClientConfig) EmailController {
+func NewEmailController(
+	service EmailService,
+	templateRegistry templates.Registry,
+	idempotencyGuard idempotency.Guard,
+	limiter ratelimit.Limiter,
+	config configuration2.EmailClientConfig) EmailController {
 	validate := validator.New()
 
-	registerFieldLevelValidator(validate, "validGolaEmailDomain", NewGolaDomainValidator(config.ValidGolaEmailDomain()).validate)
+	// the domain is checked against the tenant of the email once it is known, binding only checks it is one of ours
+	registerFieldLevelValidator(validate, "validGolaEmailDomain", NewGolaDomainValidator(config.AllValidGolaEmailDomains()).validate)
+	registerFieldLevelValidator(validate, "senderName", SenderNameValidator)
 	registerFieldLevelValidator(validate, "validFileExtension", NewFileExtensionValidator(config.UnsupportedAttachmentExtensions()).validate)
 	registerFieldLevelValidator(validate, "uniqueAttachments", UniqueAttachmentValidator)
+	registerFieldLevelValidator(validate, "inlineContentId", InlineContentIDValidator)
+	registerFieldLevelValidator(validate, "recipientsWithinLimit", NewMaxRecipientsValidator(config.MaxRecipients()).validate)
+	registerFieldLevelValidator(validate, "scheduledSendAt", NewScheduledSendAtValidator(config.MaxScheduleAheadInDays()).validate)
+	registerFieldLevelValidator(validate, "knownCategory", NewCategoryValidator(config.Categories()).validate)
+	registerFieldLevelValidator(validate, "knownTenant", NewTenantValidator(config.Tenants()).validate)
+	registerFieldLevelValidator(validate, "allowedHeaders", NewCustomHeaderValidator(config.AllowedCustomHeaders()).validate)
 	registerFieldLevelValidator(validate, "notblank", validators.NotBlank)
 	registerFieldLevelValidator(validate, "notblankbase64", NewNotBlankBase64ContentValidator().validate)
 	registerFieldLevelValidator(validate, "totalAttachmentSizeWithinPermissibleLimit",
 		NewTotalAttachmentSizeWithinLimitValidator(config.PermissibleTotalSizeOfAttachments()).Validate)
 
-	return emailController{
+	controller := emailController{
 		service:                 service,
+		templateRegistry:        templateRegistry,
+		idempotencyGuard:        idempotencyGuard,
+		limiter:                 limiter,
+		policies:                policy.NewPolicies(config.SenderPolicies()),
+		defaultCategory:         config.DefaultCategory(),
+		defaultTenant:           config.DefaultTenant(),
 		httpRequestDeserializer: http_util.NewHttpRequestDeserializer(validate),
 		config:                  config,
+		maxBatchSize:            config.MaxBatchSize(),
+		batchWorkers:            config.BatchWorkers(),
+	}
+	if controller.maxBatchSize <= 0 {
+		controller.maxBatchSize = defaultMaxBatchSize
 	}
+	if controller.batchWorkers <= 0 {
+		controller.batchWorkers = defaultBatchWorkers
+	}
+	return controller
 }
 
 func registerFieldLevelValidator(validate *validator.Validate, tagName string, validateFunction validator.Func) {
@@ -54,12 +107,28 @@ func registerFieldLevelValidator(validate *validator.Validate, tagName string, v
 // @Summary API to send email
 // @Description API to send email,
 // @Description If IncludeBaseTemplate is true then, header/footer (logos + disclaimer) is included
+// @Description If Async is true then, the email is accepted into the outbox and delivered in the background
+// @Description If SendAt is set then, the email is held in the outbox and delivered at that time, until then it can be cancelled
+// @Description Suppressed recipients are dropped and listed with their reason in the response
+// @Description From may carry a display name, as in "Gola Support" <support@gola.xyz>, and defaults to the default sender of the client or else of the tenant
+// @Description Tenant picks the product the email is sent for, its domains, base template and relays, and defaults to the tenant of the client or the default tenant
+// @Description Category defaults to the configured default, recipients who opted out of it are dropped unless it bypasses preferences
+// @Description If Track is true then, links of the HTML body are rewritten to count clicks and an open pixel is added
+// @Description If an Idempotency-Key is sent then, a retry with the same key and payload replays the original response
 // @Accept  json
 // @Produce  json
 // @Param emailRequest body http_request_response.EmailRequest true "Email Request"
-// @Success 204
-// @Failure 400 {object} golaerror.Error "If From/To/Subject/Body are empty"
+// @Param Idempotency-Key header string false "Unique key to safely retry the request"
+// @Success 200 {object} http_request_response.SendEmailResponse
+// @Success 202 {object} http_request_response.SendEmailResponse "If Async is true or SendAt is set"
+// @Failure 400 {object} golaerror.Error "If To/Subject/Body are empty, From is invalid or missing without a default sender, or Category or Tenant is not configured"
+// @Failure 401 {object} golaerror.Error "If the request carries no valid API key or signature"
+// @Failure 403 {object} golaerror.Error "If the sender is not of a domain of the tenant, the sender policy of the client does not allow the sender address or category, or the client is bound to another tenant"
+// @Failure 409 {object} golaerror.Error "If the Idempotency-Key was used with a different payload or is still being processed"
+// @Failure 422 {object} golaerror.Error "If the mail server permanently rejected the email or every recipient is suppressed"
+// @Failure 429 {object} golaerror.Error "If the client, sender domain or a recipient is over its rate limit, Retry-After tells when to retry"
 // @Failure 500 {object} golaerror.Error ""
+// @Failure 501 {object} golaerror.Error "If Async is true or SendAt is set but the outbox is not enabled"
 // @Router /api/ccg/v1/email/send [post]
 func (controller emailController) SendEmail(ctx *gin.Context) {
 	// for swagger import
@@ -71,21 +140,335 @@ func (controller emailController) SendEmail(ctx *gin.Context) {
 		return
 	}
 
+	controller.respondIdempotently(ctx, request, func() (int, interface{}) {
+		return controller.send(ctx, request)
+	})
+}
+
+// SendTemplateEmail godoc
+// @Tags Email
+// @Summary API to send email rendered from a registered template
+// @Description API to send email whose subject, HTML and text bodies are rendered server side from the named template and variables,
+// @Description Every variable listed as required by the template must be present
+// @Description If Async is true then, the email is accepted into the outbox and delivered in the background
+// @Description If SendAt is set then, the email is held in the outbox and delivered at that time, until then it can be cancelled
+// @Description Suppressed recipients are dropped and listed with their reason in the response
+// @Description From may carry a display name, as in "Gola Support" <support@gola.xyz>, and defaults to the default sender of the client or else of the tenant
+// @Description Tenant picks the product the email is sent for, its domains, base template and relays, and defaults to the tenant of the client or the default tenant
+// @Description Category defaults to the configured default, recipients who opted out of it are dropped unless it bypasses preferences
+// @Description If Track is true then, links of the HTML body are rewritten to count clicks and an open pixel is added
+// @Description If an Idempotency-Key is sent then, a retry with the same key and payload replays the original response
+// @Accept  json
+// @Produce  json
+// @Param templateEmailRequest body http_request_response.TemplateEmailRequest true "Template Email Request"
+// @Param Idempotency-Key header string false "Unique key to safely retry the request"
+// @Success 200 {object} http_request_response.SendEmailResponse
+// @Success 202 {object} http_request_response.SendEmailResponse "If Async is true or SendAt is set"
+// @Failure 400 {object} golaerror.Error "If To/TemplateName are empty, From is invalid or missing without a default sender, Tenant is not configured, or required variables are missing"
+// @Failure 404 {object} golaerror.Error "If no template is registered with the given name"
+// @Failure 401 {object} golaerror.Error "If the request carries no valid API key or signature"
+// @Failure 403 {object} golaerror.Error "If the sender is not of a domain of the tenant, the sender policy of the client does not allow the sender address or category, or the client is bound to another tenant"
+// @Failure 409 {object} golaerror.Error "If the Idempotency-Key was used with a different payload or is still being processed"
+// @Failure 422 {object} golaerror.Error "If the mail server permanently rejected the email or every recipient is suppressed"
+// @Failure 429 {object} golaerror.Error "If the client, sender domain or a recipient is over its rate limit, Retry-After tells when to retry"
+// @Failure 500 {object} golaerror.Error ""
+// @Failure 501 {object} golaerror.Error "If Async is true or SendAt is set but the outbox is not enabled"
+// @Router /api/ccg/v1/email/send-template [post]
+func (controller emailController) SendTemplateEmail(ctx *gin.Context) {
+	var request http_request_response.TemplateEmailRequest
+	if bindError := controller.httpRequestDeserializer.ShouldBindJsonBodyIfValid(&request, ctx); bindError != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, &constants.PayloadValidationError)
+		return
+	}
+
+	controller.respondIdempotently(ctx, request, func() (int, interface{}) {
+		rendered, renderError := controller.templateRegistry.Render(ctx, request.TemplateName, request.Variables)
+		if renderError != nil {
+			return errorResponse(renderError)
+		}
+		return controller.deliver(ctx, request.ToEmailModel(rendered), request.Async)
+	})
+}
+
+// SendBatch godoc
+// @Tags Email
+// @Summary API to send a batch of emails
+// @Description API to send either a list of messages, each shaped like a send request, or one template to a list of recipients each with their own variables,
+// @Description Every item is validated and sent on its own, the result of each item is returned at its index with the status code it would have got from the single send APIs
+// @Description If an Idempotency-Key is sent then, a retry with the same key and payload replays the original response
+// @Accept  json
+// @Produce  json
+// @Param batchEmailRequest body http_request_response.BatchEmailRequest true "Batch Email Request"
+// @Param Idempotency-Key header string false "Unique key to safely retry the request"
+// @Success 200 {object} http_request_response.BatchEmailResponse
+// @Failure 400 {object} golaerror.Error "If the batch is empty or has both messages and a template"
+// @Failure 401 {object} golaerror.Error "If the request carries no valid API key or signature"
+// @Failure 409 {object} golaerror.Error "If the Idempotency-Key was used with a different payload or is still being processed"
+// @Failure 413 {object} golaerror.Error "If the batch has more items than allowed"
+// @Router /api/ccg/v1/email/batch [post]
+func (controller emailController) SendBatch(ctx *gin.Context) {
+	var request http_request_response.BatchEmailRequest
+	if bindError := controller.httpRequestDeserializer.ShouldBindJsonBodyIfValid(&request, ctx); bindError != nil ||
+		request.Size() == 0 || (request.IsTemplateBatch() && len(request.Messages) > 0) {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, &constants.PayloadValidationError)
+		return
+	}
+	if request.Size() > controller.maxBatchSize {
+		ctx.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, &constants.BatchSizeExceededError)
+		return
+	}
+
+	controller.respondIdempotently(ctx, request, func() (int, interface{}) {
+		return http.StatusOK, http_request_response.BatchEmailResponse{Results: controller.dispatchBatch(ctx, controller.batchItems(request))}
+	})
+}
+
+// CancelEmail godoc
+// @Tags Email
+// @Summary API to cancel a scheduled email
+// @Description API to cancel an email that is still waiting in the outbox, typically one scheduled with SendAt
+// @Param id path string true "Message Id"
+// @Success 204 "If the email was cancelled"
+// @Failure 404 {object} golaerror.Error "If no email was accepted from the client with the given id"
+// @Failure 409 {object} golaerror.Error "If the email is already being delivered or is no longer pending"
+// @Failure 500 {object} golaerror.Error ""
+// @Router /api/ccg/v1/email/{id} [delete]
+func (controller emailController) CancelEmail(ctx *gin.Context) {
+	client, _ := auth.ClientFrom(ctx)
+	if cancelError := controller.service.Cancel(ctx, client.ID, ctx.Param("id")); cancelError != nil {
+		constants.RespondWithGolaError(ctx, cancelError)
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
+
+type batchItem func(ctx *gin.Context) (int, interface{})
+
+func (controller emailController) batchItems(request http_request_response.BatchEmailRequest) []batchItem {
+	var items []batchItem
+	if request.IsTemplateBatch() {
+		for _, templateRequest := range request.Template.ToTemplateEmailRequests() {
+			templateRequest := templateRequest
+			items = append(items, func(ctx *gin.Context) (int, interface{}) {
+				if validationError := controller.validateItem(ctx, templateRequest); validationError != nil {
+					return errorResponse(validationError)
+				}
+				rendered, renderError := controller.templateRegistry.Render(ctx, templateRequest.TemplateName, templateRequest.Variables)
+				if renderError != nil {
+					return errorResponse(renderError)
+				}
+				return controller.deliver(ctx, templateRequest.ToEmailModel(rendered), templateRequest.Async)
+			})
+		}
+		return items
+	}
+	for _, emailRequest := range request.Messages {
+		emailRequest := emailRequest
+		items = append(items, func(ctx *gin.Context) (int, interface{}) {
+			if validationError := controller.validateItem(ctx, emailRequest); validationError != nil {
+				return errorResponse(validationError)
+			}
+			return controller.send(ctx, emailRequest)
+		})
+	}
+	return items
+}
+
+func (controller emailController) validateItem(ctx *gin.Context, item interface{}) *golaerror.Error {
+	if validationError := controller.httpRequestDeserializer.Validate(item, ctx); validationError != nil {
+		return &constants.PayloadValidationError
+	}
+	return nil
+}
+
+// dispatchBatch sends the items on at most batchWorkers goroutines, each with its own copy of the request context
+func (controller emailController) dispatchBatch(ctx *gin.Context, items []batchItem) []http_request_response.BatchItemResult {
+	results := make([]http_request_response.BatchItemResult, len(items))
+	indexes := make(chan int)
+	var waitGroup sync.WaitGroup
+	workers := controller.batchWorkers
+	if workers > len(items) {
+		workers = len(items)
+	}
+	for worker := 0; worker < workers; worker++ {
+		waitGroup.Add(1)
+		go func() {
+			defer waitGroup.Done()
+			for index := range indexes {
+				statusCode, response := items[index](ctx.Copy())
+				results[index] = newBatchItemResult(index, statusCode, response)
+			}
+		}()
+	}
+	for index := range items {
+		indexes <- index
+	}
+	close(indexes)
+	waitGroup.Wait()
+	return results
+}
+
+func newBatchItemResult(index int, statusCode int, response interface{}) http_request_response.BatchItemResult {
+	result := http_request_response.BatchItemResult{Index: index, StatusCode: statusCode}
+	switch itemResponse := response.(type) {
+	case http_request_response.SendEmailResponse:
+		result.MessageID = itemResponse.MessageID
+		result.Suppressed = itemResponse.Suppressed
+	case *golaerror.Error:
+		result.Error = itemResponse
+	}
+	return result
+}
+
+// respondIdempotently processes the request, unless an Idempotency-Key header makes it a replay of an earlier one.
+// Keys of authenticated clients are scoped to the client, so one client cannot replay the response of another.
+func (controller emailController) respondIdempotently(ctx *gin.Context, request interface{}, process func() (int, interface{})) {
+	logger := logging.GetLogger(ctx).WithField("class", "EmailController").WithField("method", "respondIdempotently")
+	idempotencyKey := ctx.GetHeader(idempotency.KeyHeader)
+	if len(idempotencyKey) == 0 {
+		statusCode, response := process()
+		setRetryAfter(ctx, response)
+		ctx.JSON(statusCode, response)
+		return
+	}
+	if client, authenticated := auth.ClientFrom(ctx); authenticated {
+		idempotencyKey = client.ID + ":" + idempotencyKey
+	}
+
+	previousOutcome, idempotencyError := controller.idempotencyGuard.Begin(ctx, idempotencyKey, request)
+	if idempotencyError != nil {
+		constants.RespondWithGolaError(ctx, idempotencyError)
+		return
+	}
+	if previousOutcome != nil {
+		ctx.Header(idempotency.ReplayedHeader, "true")
+		ctx.Data(previousOutcome.StatusCode, jsonContentType, previousOutcome.Body)
+		return
+	}
+
+	statusCode, response := process()
+	setRetryAfter(ctx, response)
+	responseBody, err := json.Marshal(response)
+	if err != nil {
+		logger.Error("Failed to encode response ", err)
+		statusCode, responseBody = http.StatusInternalServerError, nil
+	}
+	controller.idempotencyGuard.Finish(ctx, idempotencyKey, idempotency.Outcome{StatusCode: statusCode, Body: responseBody})
+	ctx.Data(statusCode, jsonContentType, responseBody)
+}
+
+func (controller emailController) send(ctx *gin.Context, request http_request_response.EmailRequest) (int, interface{}) {
 	if len(request.Body.MimeType) == 0 {
 		request.Body.MimeType = "text/plain"
 	}
 
 	email, emailModelError := request.ToEmailModel(ctx)
 	if emailModelError != nil {
-		constants.RespondWithGolaError(ctx, &constants.PayloadValidationError)
-		return
+		return errorResponse(&constants.PayloadValidationError)
+	}
+	return controller.deliver(ctx, email, request.Async)
+}
+
+// deliver sends scheduled emails through the outbox as well, since they have to outlive the request
+func (controller emailController) deliver(ctx *gin.Context, email models.Email, async bool) (int, interface{}) {
+	client, _ := auth.ClientFrom(ctx)
+	email.ClientID = client.ID
+	if tenantError := controller.applyTenant(ctx, client, &email); tenantError != nil {
+		return errorResponse(tenantError)
+	}
+	if policyError := controller.applyPolicy(ctx, client.ID, &email); policyError != nil {
+		return errorResponse(policyError)
+	}
+	if controller.limiter != nil {
+		if limitError := controller.limiter.Allow(ctx, client.ID, email); limitError != nil {
+			return errorResponse(limitError)
+		}
+	}
+	if async || !email.SendAt.IsZero() {
+		receipt, enqueueError := controller.service.Enqueue(ctx, email)
+		if enqueueError != nil {
+			return errorResponse(enqueueError)
+		}
+		return http.StatusAccepted, http_request_response.NewSendEmailResponse(receipt)
 	}
 
-	emailSendError := controller.service.Send(ctx, email)
+	receipt, emailSendError := controller.service.Send(ctx, email)
 	if emailSendError != nil {
-		constants.RespondWithGolaError(ctx, emailSendError)
-		return
+		return errorResponse(emailSendError)
+	}
+	return http.StatusOK, http_request_response.NewSendEmailResponse(receipt)
+}
+
+// applyTenant sends the email for the tenant it names, else for the tenant of the client or the default tenant.
+// A client bound to a tenant may not send for another one.
+func (controller emailController) applyTenant(ctx *gin.Context, client auth.Client, email *models.Email) *golaerror.Error {
+	if email.Tenant == "" {
+		email.Tenant = client.Tenant
+	}
+	if client.Tenant != "" && email.Tenant != client.Tenant {
+		logging.GetLogger(ctx).WithField("class", "EmailController").WithField("method", "applyTenant").
+			Warnf("Client %q of tenant %s is not allowed to send for tenant %s", client.ID, client.Tenant, email.Tenant)
+		return &constants.TenantNotAllowedError
+	}
+	if email.Tenant == "" {
+		email.Tenant = controller.defaultTenant
+	}
+	return nil
+}
+
+// applyPolicy sends emails without a sender from the default sender of the client, or else of the tenant, whichever
+// is of a domain of the tenant. It rejects senders outside the domains of the tenant as well as senders and categories
+// the sender policy of the client does not allow, and gives senders without a name the display name of the policy, or
+// else of the tenant.
+func (controller emailController) applyPolicy(ctx *gin.Context, clientID string, email *models.Email) *golaerror.Error {
+	logger := logging.GetLogger(ctx).WithField("class", "EmailController").WithField("method", "applyPolicy")
+	tenantConfig := controller.config.ForTenant(email.Tenant)
+	tenantDomains := NewGolaDomainValidator(tenantConfig.ValidGolaEmailDomain())
+	senderPolicy, found := controller.policies.For(clientID)
+	if email.From == "" {
+		for _, defaultSender := range []string{senderPolicy.DefaultSender, tenantConfig.DefaultSender()} {
+			if defaultSender != "" && tenantDomains.allows(defaultSender) {
+				email.From = defaultSender
+				break
+			}
+		}
 	}
+	if email.From == "" {
+		logger.Warnf("No sender given and neither client %q nor tenant %q have a default sender", clientID, email.Tenant)
+		return &constants.PayloadValidationError
+	}
+	if !tenantDomains.allows(email.From) {
+		logger.Warnf("Sender %s is not of a domain of tenant %q", email.From, email.Tenant)
+		return &constants.SenderNotAllowedError
+	}
+	if !found || !senderPolicy.MaySendAs(email.From) {
+		logger.Warnf("Client %q is not allowed to send as %s", clientID, email.From)
+		return &constants.SenderNotAllowedError
+	}
+	category := email.Category
+	if category == "" {
+		category = controller.defaultCategory
+	}
+	if !senderPolicy.MaySendCategory(category) {
+		logger.Warnf("Client %q is not allowed to send %s emails", clientID, category)
+		return &constants.CategoryNotAllowedError
+	}
+	if email.FromName == "" {
+		email.FromName = senderPolicy.DisplayName
+	}
+	if email.FromName == "" {
+		email.FromName = tenantConfig.DisplayName()
+	}
+	return nil
+}
+
+// setRetryAfter tells a rate limited caller when to retry, batch items carry it in the additional data of their error instead
+func setRetryAfter(ctx *gin.Context, response interface{}) {
+	if retryAfter := ratelimit.RetryAfterSeconds(response); retryAfter != "" {
+		ctx.Header("Retry-After", retryAfter)
+	}
+}
 
-	ctx.JSON(http.StatusNoContent, nil)
+func errorResponse(err *golaerror.Error) (int, interface{}) {
+	return constants.GetGolaHttpCode(err.ErrorCode), err
 }
@@ -2,10 +2,17 @@ package controller
 
 import (
 	"bytes"
+	"ccg-api/auth"
+	"ccg-api/configuration"
 	"ccg-api/constants"
 	"ccg-api/email/http_request_response"
+	"ccg-api/email/idempotency"
+	mockIdempotency "ccg-api/email/idempotency/mocks"
 	"ccg-api/email/mocks"
 	"ccg-api/email/models"
+	"ccg-api/email/ratelimit"
+	"ccg-api/email/templates"
+	mockTemplates "ccg-api/email/templates/mocks"
 	"ccg-api/util"
 	"encoding/base64"
 	"encoding/json"
@@ -17,6 +24,7 @@ import (
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 )
 
 type emailControllerTestSuite struct {
@@ -25,8 +33,12 @@ type emailControllerTestSuite struct {
 	recorder     *httptest.ResponseRecorder
 	context      *gin.Context
 	emailService *mocks.MockEmailService
+	guard        *mockIdempotency.MockGuard
+	registry     *mockTemplates.MockRegistry
 	controller   EmailController
 	emailConfig  *mocks.MockEmailClientConfig
+	// narratenetConfig is the config of the narratenet tenant, the gola tenant uses emailConfig
+	narratenetConfig *mocks.MockEmailClientConfig
 }
 
 func TestEmailControllerTestSuite(t *testing.T) {
@@ -41,6 +53,8 @@ func (suite *emailControllerTestSuite) SetupTest() {
 	suite.context, _ = gin.CreateTestContext(suite.recorder)
 	suite.emailService = mocks.NewMockEmailService(suite.mockCtrl)
 	suite.emailConfig = mocks.NewMockEmailClientConfig(suite.mockCtrl)
+	suite.guard = mockIdempotency.NewMockGuard(suite.mockCtrl)
+	suite.registry = mockTemplates.NewMockRegistry(suite.mockCtrl)
 
 	suite.emailConfig.EXPECT().SmtpHost().Return("smtp-host")
 	suite.emailConfig.EXPECT().SmtpPort().Return(1234)
@@ -48,20 +62,80 @@ func (suite *emailControllerTestSuite) SetupTest() {
 	suite.emailConfig.EXPECT().Password().Return("")
 	suite.emailConfig.EXPECT().InsecureSkipVerify().Return(true)
 	suite.emailConfig.EXPECT().TempDir().Return("/tmp")
-	suite.emailConfig.EXPECT().ValidGolaEmailDomain().Return([]string{"gola.xyz"})
-	suite.emailConfig.EXPECT().DefaultGolaEmailSender().Return("gola@gola.xyz")
+	suite.emailConfig.EXPECT().AllValidGolaEmailDomains().Return([]string{"gola.xyz", "narratenet.com"})
+	suite.emailConfig.EXPECT().Tenants().Return([]configuration.Tenant{{Name: "gola"}, {Name: "narratenet"}})
+	suite.emailConfig.EXPECT().DefaultTenant().Return("")
+	suite.emailConfig.EXPECT().ForTenant("").Return(suite.emailConfig).AnyTimes()
+	suite.emailConfig.EXPECT().ForTenant("gola").Return(suite.emailConfig).AnyTimes()
+	suite.emailConfig.EXPECT().ValidGolaEmailDomain().Return([]string{"gola.xyz"}).AnyTimes()
+	suite.emailConfig.EXPECT().DefaultSender().Return("").AnyTimes()
+	suite.emailConfig.EXPECT().DisplayName().Return("").AnyTimes()
+	suite.narratenetConfig = mocks.NewMockEmailClientConfig(suite.mockCtrl)
+	suite.emailConfig.EXPECT().ForTenant("narratenet").Return(suite.narratenetConfig).AnyTimes()
+	suite.narratenetConfig.EXPECT().ValidGolaEmailDomain().Return([]string{"narratenet.com"}).AnyTimes()
+	suite.narratenetConfig.EXPECT().DefaultSender().Return("hello@narratenet.com").AnyTimes()
+	suite.narratenetConfig.EXPECT().DisplayName().Return("Narratenet").AnyTimes()
+	suite.emailConfig.EXPECT().SenderPolicies().Return([]configuration.SenderPolicy{
+		{Client: "*", AllowedSenders: []string{"*@gola.xyz", "*@narratenet.com"}, DefaultSender: "gola@gola.xyz"},
+		{Client: "gola-api", AllowedSenders: []string{"noreply@gola.xyz"}, DefaultSender: "gola@gola.xyz",
+			DisplayName: "Gola", AllowedCategories: []string{"transactional"}},
+	})
+	suite.emailConfig.EXPECT().DefaultCategory().Return("transactional")
 	suite.emailConfig.EXPECT().PermissibleTotalSizeOfAttachments().Return(MaxPermissibleAttachmentSize)
 	suite.emailConfig.EXPECT().UnsupportedAttachmentExtensions().Return([]string{"exe"})
-
-	suite.controller = NewEmailController(suite.emailService, suite.emailConfig)
+	suite.emailConfig.EXPECT().MaxRecipients().Return(3)
+	suite.emailConfig.EXPECT().MaxBatchSize().Return(3)
+	suite.emailConfig.EXPECT().BatchWorkers().Return(2)
+	suite.emailConfig.EXPECT().MaxScheduleAheadInDays().Return(7)
+	suite.emailConfig.EXPECT().Categories().Return([]configuration.Category{{Name: "transactional", BypassPreferences: true}, {Name: "marketing"}})
+	suite.emailConfig.EXPECT().AllowedCustomHeaders().Return([]string{"X-Entity-Ref-ID", "List-Id"})
+
+	limiter := ratelimit.NewLimiter(ratelimit.NewMemoryStore(), configuration.RateLimiting{
+		Recipient: []configuration.RateLimit{{Category: "marketing", Burst: 1, PeriodInSeconds: 3600}},
+	}, "transactional")
+
+	suite.controller = NewEmailController(suite.emailService, suite.registry, suite.guard, limiter, suite.emailConfig)
 }
 
-func (suite emailControllerTestSuite) TestSendEmail_ShouldThrowBadRequestWhenFromEmailIsMissing() {
+func (suite emailControllerTestSuite) TestSendEmail_ShouldSendFromDefaultSenderWhenFromIsMissing() {
 	request := suite.validEmailRequest()
 	request.From = ""
 	requestBody, _ := util.Encode(request)
 	suite.context.Request, _ = http.NewRequest("POST", "/", bytes.NewBufferString(requestBody))
 
+	suite.emailService.EXPECT().Send(suite.context, gomock.Any()).DoAndReturn(func(ctx *gin.Context, email models.Email) (models.SendReceipt, *golaerror.Error) {
+		suite.Equal("gola@gola.xyz", email.From)
+		return models.SendReceipt{MessageID: "some-message-id"}, nil
+	})
+
+	suite.controller.SendEmail(suite.context)
+
+	suite.Equal(http.StatusOK, suite.recorder.Code)
+}
+
+func (suite emailControllerTestSuite) TestSendEmail_ShouldSendWithDisplayNameGivenInFrom() {
+	request := suite.validEmailRequest()
+	request.From = `"Zoë from Gola" <noreply@gola.xyz>`
+	requestBody, _ := util.Encode(request)
+	suite.context.Request, _ = http.NewRequest("POST", "/", bytes.NewBufferString(requestBody))
+
+	suite.emailService.EXPECT().Send(suite.context, gomock.Any()).DoAndReturn(func(ctx *gin.Context, email models.Email) (models.SendReceipt, *golaerror.Error) {
+		suite.Equal("noreply@gola.xyz", email.From)
+		suite.Equal("Zoë from Gola", email.FromName)
+		return models.SendReceipt{MessageID: "some-message-id"}, nil
+	})
+
+	suite.controller.SendEmail(suite.context)
+
+	suite.Equal(http.StatusOK, suite.recorder.Code)
+}
+
+func (suite emailControllerTestSuite) TestSendEmail_ShouldThrowBadRequestWhenDisplayNameOfFromIsTooLong() {
+	request := suite.validEmailRequest()
+	request.From = strings.Repeat("a", 65) + " <noreply@gola.xyz>"
+	requestBody, _ := util.Encode(request)
+	suite.context.Request, _ = http.NewRequest("POST", "/", bytes.NewBufferString(requestBody))
+
 	suite.controller.SendEmail(suite.context)
 
 	suite.Equal(http.StatusBadRequest, suite.recorder.Code)
@@ -70,6 +144,28 @@ func (suite emailControllerTestSuite) TestSendEmail_ShouldThrowBadRequestWhenFro
 	suite.Equal(constants.PayloadValidationErrorCode, response.ErrorCode)
 }
 
+func (suite emailControllerTestSuite) TestSendEmail_ShouldThrowBadRequestWhenEncodedDisplayNameOfFromHasLineBreak() {
+	request := suite.validEmailRequest()
+	request.From = "=?utf-8?q?Gola=0D=0ABcc:_someone@gmail.com?= <noreply@gola.xyz>"
+	requestBody, _ := util.Encode(request)
+	suite.context.Request, _ = http.NewRequest("POST", "/", bytes.NewBufferString(requestBody))
+
+	suite.controller.SendEmail(suite.context)
+
+	suite.Equal(http.StatusBadRequest, suite.recorder.Code)
+}
+
+func (suite emailControllerTestSuite) TestSendEmail_ShouldThrowBadRequestWhenAddressWithDisplayNameIsNotGolaDomain() {
+	request := suite.validEmailRequest()
+	request.From = `"Gola Support" <support@gmail.com>`
+	requestBody, _ := util.Encode(request)
+	suite.context.Request, _ = http.NewRequest("POST", "/", bytes.NewBufferString(requestBody))
+
+	suite.controller.SendEmail(suite.context)
+
+	suite.Equal(http.StatusBadRequest, suite.recorder.Code)
+}
+
 func (suite emailControllerTestSuite) TestSendEmail_ShouldThrowBadRequestWhenFromEmailIsNotValidEmailAddress() {
 	request := suite.validEmailRequest()
 	request.From = "invalid-email.com"
@@ -427,13 +523,16 @@ func (suite emailControllerTestSuite) TestSendEmail_ShouldSendEmailForValidReque
 			FileName: "attachment.pdf",
 			Data:     []byte("Attachment with some data!"),
 		}},
-	})
+	}).Return(models.SendReceipt{MessageID: "some-message-id"}, nil)
 
 	requestBody, _ := util.Encode(request)
 	suite.context.Request, _ = http.NewRequest("POST", "/", bytes.NewBufferString(requestBody))
 
 	suite.controller.SendEmail(suite.context)
-	suite.Equal(http.StatusNoContent, suite.recorder.Code)
+	suite.Equal(http.StatusOK, suite.recorder.Code)
+	response := http_request_response.SendEmailResponse{}
+	_ = json.Unmarshal(suite.recorder.Body.Bytes(), &response)
+	suite.Equal("some-message-id", response.MessageID)
 }
 
 func (suite emailControllerTestSuite) TestSendEmail_ShouldRespondWithInternalErrorIfUnableToSendEmail() {
@@ -454,12 +553,28 @@ func (suite emailControllerTestSuite) TestSendEmail_ShouldRespondWithInternalErr
 	})
 
 	err := &constants.InternalServerError
-	suite.emailService.EXPECT().Send(suite.context, gomock.Any()).Return(err)
+	suite.emailService.EXPECT().Send(suite.context, gomock.Any()).Return(models.SendReceipt{}, err)
+
+	requestBody, _ := util.Encode(request)
+	suite.context.Request, _ = http.NewRequest("POST", "/", bytes.NewBufferString(requestBody))
+
+	suite.controller.SendEmail(suite.context)
+}
+
+func (suite emailControllerTestSuite) TestSendEmail_ShouldRespondWithUnprocessableEntityIfEmailIsPermanentlyRejected() {
+	request := suite.validEmailRequest()
+
+	suite.emailService.EXPECT().Send(suite.context, gomock.Any()).Return(models.SendReceipt{}, &constants.PermanentDeliveryFailureError)
 
 	requestBody, _ := util.Encode(request)
 	suite.context.Request, _ = http.NewRequest("POST", "/", bytes.NewBufferString(requestBody))
 
 	suite.controller.SendEmail(suite.context)
+
+	suite.Equal(http.StatusUnprocessableEntity, suite.recorder.Code)
+	response := golaerror.Error{}
+	_ = json.Unmarshal(suite.recorder.Body.Bytes(), &response)
+	suite.Equal(constants.PermanentDeliveryFailureCode, response.ErrorCode)
 }
 
 func (suite emailControllerTestSuite) TestSendEmail_ShouldSendEmailWithDefaultMimeType() {
@@ -478,17 +593,789 @@ func (suite emailControllerTestSuite) TestSendEmail_ShouldSendEmailWithDefaultMi
 	suite.context.Request, _ = http.NewRequest("POST", "/", bytes.NewBufferString(requestBody))
 
 	suite.controller.SendEmail(suite.context)
-	suite.Equal(http.StatusNoContent, suite.recorder.Code)
+	suite.Equal(http.StatusOK, suite.recorder.Code)
 }
 
-func (suite emailControllerTestSuite) validEmailRequest() http_request_response.EmailRequest {
-	return http_request_response.EmailRequest{
-		From:    "gola@gola.xyz",
-		To:      []string{"some@gmail.com"},
-		Subject: "Hi",
-		Body: http_request_response.MessageBody{
-			Content:  "SGVsbG8h",
-			MimeType: "text/html",
+func (suite emailControllerTestSuite) TestSendEmail_ShouldAcceptEmailIntoOutboxWhenAsyncIsRequested() {
+	request := suite.validEmailRequest()
+	request.Async = true
+
+	suite.emailService.EXPECT().Enqueue(suite.context, gomock.Any()).Return(models.SendReceipt{MessageID: "some-message-id"}, nil)
+
+	requestBody, _ := util.Encode(request)
+	suite.context.Request, _ = http.NewRequest("POST", "/", bytes.NewBufferString(requestBody))
+
+	suite.controller.SendEmail(suite.context)
+
+	suite.Equal(http.StatusAccepted, suite.recorder.Code)
+	response := http_request_response.SendEmailResponse{}
+	_ = json.Unmarshal(suite.recorder.Body.Bytes(), &response)
+	suite.Equal("some-message-id", response.MessageID)
+}
+
+func (suite emailControllerTestSuite) TestSendEmail_ShouldRespondWithErrorWhenAsyncIsRequestedButOutboxIsDisabled() {
+	request := suite.validEmailRequest()
+	request.Async = true
+
+	suite.emailService.EXPECT().Enqueue(suite.context, gomock.Any()).Return(models.SendReceipt{}, &constants.AsyncSendDisabledError)
+
+	requestBody, _ := util.Encode(request)
+	suite.context.Request, _ = http.NewRequest("POST", "/", bytes.NewBufferString(requestBody))
+
+	suite.controller.SendEmail(suite.context)
+
+	suite.Equal(http.StatusNotImplemented, suite.recorder.Code)
+	response := golaerror.Error{}
+	_ = json.Unmarshal(suite.recorder.Body.Bytes(), &response)
+	suite.Equal(constants.AsyncSendDisabledCode, response.ErrorCode)
+}
+
+func (suite emailControllerTestSuite) TestSendEmail_ShouldAcceptEmailIntoOutboxWhenSendAtIsSet() {
+	request := suite.validEmailRequest()
+	sendAt := time.Now().Add(24 * time.Hour).Truncate(time.Second)
+	request.SendAt = &sendAt
+
+	suite.emailService.EXPECT().Enqueue(suite.context, gomock.Any()).DoAndReturn(func(ctx *gin.Context, email models.Email) (models.SendReceipt, *golaerror.Error) {
+		suite.True(sendAt.Equal(email.SendAt))
+		return models.SendReceipt{MessageID: "scheduled-message-id"}, nil
+	})
+
+	requestBody, _ := util.Encode(request)
+	suite.context.Request, _ = http.NewRequest("POST", "/", bytes.NewBufferString(requestBody))
+
+	suite.controller.SendEmail(suite.context)
+
+	suite.Equal(http.StatusAccepted, suite.recorder.Code)
+	response := http_request_response.SendEmailResponse{}
+	_ = json.Unmarshal(suite.recorder.Body.Bytes(), &response)
+	suite.Equal("scheduled-message-id", response.MessageID)
+}
+
+func (suite emailControllerTestSuite) TestSendEmail_ShouldListSuppressedRecipientsInResponse() {
+	request := suite.validEmailRequest()
+	suppressed := []models.SuppressedRecipient{{Address: "bounced@gmail.com", Reason: models.HardBounce}}
+	suite.emailService.EXPECT().Send(suite.context, gomock.Any()).Return(models.SendReceipt{MessageID: "some-message-id", Suppressed: suppressed}, nil)
+
+	requestBody, _ := util.Encode(request)
+	suite.context.Request, _ = http.NewRequest("POST", "/", bytes.NewBufferString(requestBody))
+
+	suite.controller.SendEmail(suite.context)
+
+	suite.Equal(http.StatusOK, suite.recorder.Code)
+	response := http_request_response.SendEmailResponse{}
+	_ = json.Unmarshal(suite.recorder.Body.Bytes(), &response)
+	suite.Equal("some-message-id", response.MessageID)
+	suite.Equal(suppressed, response.Suppressed)
+}
+
+func (suite emailControllerTestSuite) TestSendEmail_ShouldRespondWithUnprocessableEntityWhenEveryRecipientIsSuppressed() {
+	request := suite.validEmailRequest()
+	suite.emailService.EXPECT().Send(suite.context, gomock.Any()).Return(models.SendReceipt{}, &constants.AllRecipientsSuppressedError)
+
+	requestBody, _ := util.Encode(request)
+	suite.context.Request, _ = http.NewRequest("POST", "/", bytes.NewBufferString(requestBody))
+
+	suite.controller.SendEmail(suite.context)
+
+	suite.Equal(http.StatusUnprocessableEntity, suite.recorder.Code)
+	response := golaerror.Error{}
+	_ = json.Unmarshal(suite.recorder.Body.Bytes(), &response)
+	suite.Equal(constants.AllRecipientsSuppressedCode, response.ErrorCode)
+}
+
+func (suite emailControllerTestSuite) TestSendEmail_ShouldThrowBadRequestWhenSendAtIsInThePast() {
+	request := suite.validEmailRequest()
+	sendAt := time.Now().Add(-time.Minute)
+	request.SendAt = &sendAt
+	requestBody, _ := util.Encode(request)
+	suite.context.Request, _ = http.NewRequest("POST", "/", bytes.NewBufferString(requestBody))
+
+	suite.controller.SendEmail(suite.context)
+
+	suite.Equal(http.StatusBadRequest, suite.recorder.Code)
+}
+
+func (suite emailControllerTestSuite) TestSendEmail_ShouldThrowBadRequestWhenSendAtIsTooFarAhead() {
+	request := suite.validEmailRequest()
+	sendAt := time.Now().Add(8 * 24 * time.Hour)
+	request.SendAt = &sendAt
+	requestBody, _ := util.Encode(request)
+	suite.context.Request, _ = http.NewRequest("POST", "/", bytes.NewBufferString(requestBody))
+
+	suite.controller.SendEmail(suite.context)
+
+	suite.Equal(http.StatusBadRequest, suite.recorder.Code)
+}
+
+func (suite emailControllerTestSuite) TestSendEmail_ShouldThrowBadRequestWhenSendAtHasNoTimezone() {
+	requestBody := `{"from":"gola@gola.xyz","to":["abc@gmail.com"],"subject":"Hi!","message_body":{"mime_type":"text/plain","base64_encoded_content":"SGk="},"send_at":"2030-01-02T09:00:00"}`
+	suite.context.Request, _ = http.NewRequest("POST", "/", bytes.NewBufferString(requestBody))
+
+	suite.controller.SendEmail(suite.context)
+
+	suite.Equal(http.StatusBadRequest, suite.recorder.Code)
+}
+
+func (suite emailControllerTestSuite) TestSendEmail_ShouldThrowBadRequestWhenCategoryIsUnknown() {
+	request := suite.validEmailRequest()
+	request.Category = "newsletter"
+	requestBody, _ := util.Encode(request)
+	suite.context.Request, _ = http.NewRequest("POST", "/", bytes.NewBufferString(requestBody))
+
+	suite.controller.SendEmail(suite.context)
+
+	suite.Equal(http.StatusBadRequest, suite.recorder.Code)
+}
+
+func (suite emailControllerTestSuite) TestCancelEmail_ShouldRespondWithNoContentWhenEmailIsCancelled() {
+	suite.context.Request, _ = http.NewRequest("DELETE", "/", nil)
+	suite.context.Params = gin.Params{{Key: "id", Value: "scheduled-message-id"}}
+	suite.emailService.EXPECT().Cancel(suite.context, "", "scheduled-message-id").Return(nil)
+
+	suite.controller.CancelEmail(suite.context)
+
+	suite.Equal(http.StatusNoContent, suite.context.Writer.Status())
+}
+
+func (suite emailControllerTestSuite) TestCancelEmail_ShouldRespondWithConflictWhenEmailIsNoLongerPending() {
+	suite.context.Request, _ = http.NewRequest("DELETE", "/", nil)
+	suite.context.Params = gin.Params{{Key: "id", Value: "sent-message-id"}}
+	suite.emailService.EXPECT().Cancel(suite.context, "", "sent-message-id").Return(&constants.MessageNotCancellableError)
+
+	suite.controller.CancelEmail(suite.context)
+
+	suite.Equal(http.StatusConflict, suite.recorder.Code)
+	response := golaerror.Error{}
+	_ = json.Unmarshal(suite.recorder.Body.Bytes(), &response)
+	suite.Equal(constants.MessageNotCancellableCode, response.ErrorCode)
+}
+
+func (suite emailControllerTestSuite) TestCancelEmail_ShouldRespondWithNotFoundWhenEmailIsUnknown() {
+	suite.context.Request, _ = http.NewRequest("DELETE", "/", nil)
+	suite.context.Params = gin.Params{{Key: "id", Value: "unknown-message-id"}}
+	suite.emailService.EXPECT().Cancel(suite.context, "", "unknown-message-id").Return(&constants.MessageNotFoundError)
+
+	suite.controller.CancelEmail(suite.context)
+
+	suite.Equal(http.StatusNotFound, suite.recorder.Code)
+}
+
+func (suite emailControllerTestSuite) TestSendEmail_ShouldRecordOutcomeForIdempotencyKey() {
+	request := suite.validEmailRequest()
+	requestBody, _ := util.Encode(request)
+	suite.context.Request, _ = http.NewRequest("POST", "/", bytes.NewBufferString(requestBody))
+	suite.context.Request.Header.Set(idempotency.KeyHeader, "some-key")
+
+	suite.guard.EXPECT().Begin(suite.context, "some-key", request).Return(nil, nil)
+	suite.emailService.EXPECT().Send(suite.context, gomock.Any()).Return(models.SendReceipt{MessageID: "some-message-id"}, nil)
+	suite.guard.EXPECT().Finish(suite.context, "some-key", idempotency.Outcome{
+		StatusCode: http.StatusOK,
+		Body:       []byte(`{"message_id":"some-message-id"}`),
+	})
+
+	suite.controller.SendEmail(suite.context)
+
+	suite.Equal(http.StatusOK, suite.recorder.Code)
+	suite.JSONEq(`{"message_id":"some-message-id"}`, suite.recorder.Body.String())
+}
+
+func (suite emailControllerTestSuite) TestSendEmail_ShouldReplayOriginalOutcomeWithoutSendingAgain() {
+	request := suite.validEmailRequest()
+	requestBody, _ := util.Encode(request)
+	suite.context.Request, _ = http.NewRequest("POST", "/", bytes.NewBufferString(requestBody))
+	suite.context.Request.Header.Set(idempotency.KeyHeader, "some-key")
+
+	suite.guard.EXPECT().Begin(suite.context, "some-key", request).Return(&idempotency.Outcome{
+		StatusCode: http.StatusOK,
+		Body:       []byte(`{"message_id":"original-message-id"}`),
+	}, nil)
+
+	suite.controller.SendEmail(suite.context)
+
+	suite.Equal(http.StatusOK, suite.recorder.Code)
+	suite.Equal("true", suite.recorder.Header().Get(idempotency.ReplayedHeader))
+	suite.JSONEq(`{"message_id":"original-message-id"}`, suite.recorder.Body.String())
+}
+
+func (suite emailControllerTestSuite) TestSendEmail_ShouldRespondWithConflictWhenIdempotencyKeyIsReusedWithDifferentPayload() {
+	request := suite.validEmailRequest()
+	requestBody, _ := util.Encode(request)
+	suite.context.Request, _ = http.NewRequest("POST", "/", bytes.NewBufferString(requestBody))
+	suite.context.Request.Header.Set(idempotency.KeyHeader, "some-key")
+
+	suite.guard.EXPECT().Begin(suite.context, "some-key", request).Return(nil, &constants.IdempotencyKeyReusedError)
+
+	suite.controller.SendEmail(suite.context)
+
+	suite.Equal(http.StatusConflict, suite.recorder.Code)
+	response := golaerror.Error{}
+	_ = json.Unmarshal(suite.recorder.Body.Bytes(), &response)
+	suite.Equal(constants.IdempotencyKeyReusedCode, response.ErrorCode)
+}
+
+func (suite emailControllerTestSuite) TestSendEmail_ShouldScopeIdempotencyKeyToAuthenticatedClient() {
+	request := suite.validEmailRequest()
+	requestBody, _ := util.Encode(request)
+	suite.context.Request, _ = http.NewRequest("POST", "/", bytes.NewBufferString(requestBody))
+	suite.context.Request.Header.Set(idempotency.KeyHeader, "some-key")
+	auth.SetClient(suite.context, auth.Client{ID: "gola-api"})
+
+	suite.guard.EXPECT().Begin(suite.context, "gola-api:some-key", request).Return(&idempotency.Outcome{
+		StatusCode: http.StatusOK,
+		Body:       []byte(`{"message_id":"original-message-id"}`),
+	}, nil)
+
+	suite.controller.SendEmail(suite.context)
+
+	suite.Equal(http.StatusOK, suite.recorder.Code)
+}
+
+func (suite emailControllerTestSuite) TestSendEmail_ShouldSendAsDefaultSenderOfPolicyWithItsDisplayName() {
+	requestBody, _ := util.Encode(suite.validEmailRequest())
+	suite.context.Request, _ = http.NewRequest("POST", "/", bytes.NewBufferString(requestBody))
+	auth.SetClient(suite.context, auth.Client{ID: "gola-api"})
+
+	suite.emailService.EXPECT().Send(suite.context, gomock.Any()).DoAndReturn(func(ctx *gin.Context, email models.Email) (models.SendReceipt, *golaerror.Error) {
+		suite.Equal("gola@gola.xyz", email.From)
+		suite.Equal("Gola", email.FromName)
+		return models.SendReceipt{MessageID: "some-message-id"}, nil
+	})
+
+	suite.controller.SendEmail(suite.context)
+
+	suite.Equal(http.StatusOK, suite.recorder.Code)
+}
+
+func (suite emailControllerTestSuite) TestSendEmail_ShouldRespondWithForbiddenWhenPolicyDoesNotAllowSender() {
+	request := suite.validEmailRequest()
+	request.From = "ceo@gola.xyz"
+	requestBody, _ := util.Encode(request)
+	suite.context.Request, _ = http.NewRequest("POST", "/", bytes.NewBufferString(requestBody))
+	auth.SetClient(suite.context, auth.Client{ID: "gola-api"})
+
+	suite.controller.SendEmail(suite.context)
+
+	suite.Equal(http.StatusForbidden, suite.recorder.Code)
+	response := golaerror.Error{}
+	_ = json.Unmarshal(suite.recorder.Body.Bytes(), &response)
+	suite.Equal(constants.SenderNotAllowedCode, response.ErrorCode)
+}
+
+func (suite emailControllerTestSuite) TestSendEmail_ShouldRespondWithForbiddenWhenPolicyDoesNotAllowCategory() {
+	request := suite.validEmailRequest()
+	request.Category = "marketing"
+	requestBody, _ := util.Encode(request)
+	suite.context.Request, _ = http.NewRequest("POST", "/", bytes.NewBufferString(requestBody))
+	auth.SetClient(suite.context, auth.Client{ID: "gola-api"})
+
+	suite.controller.SendEmail(suite.context)
+
+	suite.Equal(http.StatusForbidden, suite.recorder.Code)
+	response := golaerror.Error{}
+	_ = json.Unmarshal(suite.recorder.Body.Bytes(), &response)
+	suite.Equal(constants.CategoryNotAllowedCode, response.ErrorCode)
+}
+
+func (suite emailControllerTestSuite) TestSendEmail_ShouldRespondWithTooManyRequestsWhenRecipientIsOverRateLimit() {
+	request := suite.validEmailRequest()
+	request.Category = "marketing"
+	requestBody, _ := util.Encode(request)
+	suite.context.Request, _ = http.NewRequest("POST", "/", bytes.NewBufferString(requestBody))
+	suite.emailService.EXPECT().Send(gomock.Any(), gomock.Any()).Return(models.SendReceipt{MessageID: "some-message-id"}, nil)
+	suite.controller.SendEmail(suite.context)
+	suite.Equal(http.StatusOK, suite.recorder.Code)
+
+	recorder := httptest.NewRecorder()
+	context, _ := gin.CreateTestContext(recorder)
+	context.Request, _ = http.NewRequest("POST", "/", bytes.NewBufferString(requestBody))
+	suite.controller.SendEmail(context)
+
+	suite.Equal(http.StatusTooManyRequests, recorder.Code)
+	suite.Equal("3600", recorder.Header().Get("Retry-After"))
+	response := golaerror.Error{}
+	_ = json.Unmarshal(recorder.Body.Bytes(), &response)
+	suite.Equal(constants.RateLimitExceededCode, response.ErrorCode)
+	suite.Equal(map[string]interface{}{"retry_after_seconds": float64(3600)}, response.AdditionalData)
+}
+
+func (suite emailControllerTestSuite) TestSendEmail_ShouldSendEmailWithCcBccReplyToAndAllowedHeaders() {
+	request := suite.validEmailRequest()
+	request.Cc = []string{"cc@gmail.com"}
+	request.Bcc = []string{"bcc@gmail.com"}
+	request.ReplyTo = "support@gola.xyz"
+	request.Headers = map[string]string{"x-entity-ref-id": "order-42"}
+
+	suite.emailService.EXPECT().Send(suite.context, gomock.Any()).Do(func(ctx *gin.Context, email models.Email) {
+		suite.Equal([]string{"cc@gmail.com"}, email.Cc)
+		suite.Equal([]string{"bcc@gmail.com"}, email.Bcc)
+		suite.Equal("support@gola.xyz", email.ReplyTo)
+		suite.Equal(map[string]string{"x-entity-ref-id": "order-42"}, email.Headers)
+	}).Return(models.SendReceipt{MessageID: "some-message-id"}, nil)
+
+	requestBody, _ := util.Encode(request)
+	suite.context.Request, _ = http.NewRequest("POST", "/", bytes.NewBufferString(requestBody))
+
+	suite.controller.SendEmail(suite.context)
+
+	suite.Equal(http.StatusOK, suite.recorder.Code)
+}
+
+func (suite emailControllerTestSuite) TestSendEmail_ShouldSendInlineAttachmentWithContentId() {
+	request := suite.validEmailRequest()
+	request.Attachments = []http_request_response.Attachment{
+		{
+			FileName:    "logo.png",
+			Data:        "QXR0YWNobWVudCB3aXRoIHNvbWUgZGF0YSE=",
+			Disposition: "inline",
+			ContentID:   "logo@gola.xyz",
 		},
 	}
+
+	suite.emailService.EXPECT().Send(suite.context, gomock.Any()).Do(func(ctx *gin.Context, email models.Email) {
+		suite.True(email.Attachments[0].Inline)
+		suite.Equal("logo@gola.xyz", email.Attachments[0].ContentID)
+	}).Return(models.SendReceipt{MessageID: "some-message-id"}, nil)
+
+	requestBody, _ := util.Encode(request)
+	suite.context.Request, _ = http.NewRequest("POST", "/", bytes.NewBufferString(requestBody))
+
+	suite.controller.SendEmail(suite.context)
+
+	suite.Equal(http.StatusOK, suite.recorder.Code)
+}
+
+func (suite emailControllerTestSuite) TestSendEmail_ShouldThrowBadRequestWhenInlineAttachmentHasNoContentId() {
+	request := suite.validEmailRequest()
+	request.Attachments = []http_request_response.Attachment{
+		{
+			FileName:    "logo.png",
+			Data:        "QXR0YWNobWVudCB3aXRoIHNvbWUgZGF0YSE=",
+			Disposition: "inline",
+		},
+	}
+	requestBody, _ := util.Encode(request)
+	suite.context.Request, _ = http.NewRequest("POST", "/", bytes.NewBufferString(requestBody))
+
+	suite.controller.SendEmail(suite.context)
+
+	suite.Equal(http.StatusBadRequest, suite.recorder.Code)
+}
+
+func (suite emailControllerTestSuite) TestSendEmail_ShouldThrowBadRequestWhenContentIdIsGivenWithoutInlineDisposition() {
+	request := suite.validEmailRequest()
+	request.Attachments = []http_request_response.Attachment{
+		{
+			FileName:  "logo.png",
+			Data:      "QXR0YWNobWVudCB3aXRoIHNvbWUgZGF0YSE=",
+			ContentID: "logo@gola.xyz",
+		},
+	}
+	requestBody, _ := util.Encode(request)
+	suite.context.Request, _ = http.NewRequest("POST", "/", bytes.NewBufferString(requestBody))
+
+	suite.controller.SendEmail(suite.context)
+
+	suite.Equal(http.StatusBadRequest, suite.recorder.Code)
+}
+
+func (suite emailControllerTestSuite) TestSendEmail_ShouldThrowBadRequestWhenContentIdContainsAngleBrackets() {
+	request := suite.validEmailRequest()
+	request.Attachments = []http_request_response.Attachment{
+		{
+			FileName:    "logo.png",
+			Data:        "QXR0YWNobWVudCB3aXRoIHNvbWUgZGF0YSE=",
+			Disposition: "inline",
+			ContentID:   "<logo@gola.xyz>",
+		},
+	}
+	requestBody, _ := util.Encode(request)
+	suite.context.Request, _ = http.NewRequest("POST", "/", bytes.NewBufferString(requestBody))
+
+	suite.controller.SendEmail(suite.context)
+
+	suite.Equal(http.StatusBadRequest, suite.recorder.Code)
+}
+
+func (suite emailControllerTestSuite) TestSendEmail_ShouldThrowBadRequestForDuplicateContentIds() {
+	request := suite.validEmailRequest()
+	request.Attachments = []http_request_response.Attachment{
+		{
+			FileName:    "logo.png",
+			Data:        "QXR0YWNobWVudCB3aXRoIHNvbWUgZGF0YSE=",
+			Disposition: "inline",
+			ContentID:   "logo@gola.xyz",
+		},
+		{
+			FileName:    "banner.png",
+			Data:        "QXR0YWNobWVudCB3aXRoIHNvbWUgZGF0YSE=",
+			Disposition: "inline",
+			ContentID:   "logo@gola.xyz",
+		},
+	}
+	requestBody, _ := util.Encode(request)
+	suite.context.Request, _ = http.NewRequest("POST", "/", bytes.NewBufferString(requestBody))
+
+	suite.controller.SendEmail(suite.context)
+
+	suite.Equal(http.StatusBadRequest, suite.recorder.Code)
+}
+
+func (suite emailControllerTestSuite) TestSendEmail_ShouldThrowBadRequestWhenCcIsInvalid() {
+	request := suite.validEmailRequest()
+	request.Cc = []string{"not-an-email"}
+	requestBody, _ := util.Encode(request)
+	suite.context.Request, _ = http.NewRequest("POST", "/", bytes.NewBufferString(requestBody))
+
+	suite.controller.SendEmail(suite.context)
+
+	suite.Equal(http.StatusBadRequest, suite.recorder.Code)
+}
+
+func (suite emailControllerTestSuite) TestSendEmail_ShouldThrowBadRequestWhenBccIsInvalid() {
+	request := suite.validEmailRequest()
+	request.Bcc = []string{"not-an-email"}
+	requestBody, _ := util.Encode(request)
+	suite.context.Request, _ = http.NewRequest("POST", "/", bytes.NewBufferString(requestBody))
+
+	suite.controller.SendEmail(suite.context)
+
+	suite.Equal(http.StatusBadRequest, suite.recorder.Code)
+}
+
+func (suite emailControllerTestSuite) TestSendEmail_ShouldThrowBadRequestWhenReplyToIsInvalid() {
+	request := suite.validEmailRequest()
+	request.ReplyTo = "not-an-email"
+	requestBody, _ := util.Encode(request)
+	suite.context.Request, _ = http.NewRequest("POST", "/", bytes.NewBufferString(requestBody))
+
+	suite.controller.SendEmail(suite.context)
+
+	suite.Equal(http.StatusBadRequest, suite.recorder.Code)
+}
+
+func (suite emailControllerTestSuite) TestSendEmail_ShouldThrowBadRequestWhenRecipientsAcrossToCcAndBccExceedLimit() {
+	request := suite.validEmailRequest()
+	request.Cc = []string{"cc@gmail.com"}
+	request.Bcc = []string{"first-bcc@gmail.com", "second-bcc@gmail.com"}
+	requestBody, _ := util.Encode(request)
+	suite.context.Request, _ = http.NewRequest("POST", "/", bytes.NewBufferString(requestBody))
+
+	suite.controller.SendEmail(suite.context)
+
+	suite.Equal(http.StatusBadRequest, suite.recorder.Code)
+}
+
+func (suite emailControllerTestSuite) TestSendEmail_ShouldThrowBadRequestWhenHeaderIsNotAllowed() {
+	request := suite.validEmailRequest()
+	request.Headers = map[string]string{"From": "attacker@evil.com"}
+	requestBody, _ := util.Encode(request)
+	suite.context.Request, _ = http.NewRequest("POST", "/", bytes.NewBufferString(requestBody))
+
+	suite.controller.SendEmail(suite.context)
+
+	suite.Equal(http.StatusBadRequest, suite.recorder.Code)
+}
+
+func (suite emailControllerTestSuite) TestSendEmail_ShouldThrowBadRequestWhenHeaderValueContainsLineBreak() {
+	request := suite.validEmailRequest()
+	request.Headers = map[string]string{"List-Id": "news\r\nBcc: victim@gmail.com"}
+	requestBody, _ := util.Encode(request)
+	suite.context.Request, _ = http.NewRequest("POST", "/", bytes.NewBufferString(requestBody))
+
+	suite.controller.SendEmail(suite.context)
+
+	suite.Equal(http.StatusBadRequest, suite.recorder.Code)
+}
+
+func (suite emailControllerTestSuite) TestSendTemplateEmail_ShouldRenderTemplateAndSendEmail() {
+	request := suite.validTemplateEmailRequest()
+	requestBody, _ := util.Encode(request)
+	suite.context.Request, _ = http.NewRequest("POST", "/", bytes.NewBufferString(requestBody))
+
+	suite.registry.EXPECT().Render(suite.context, "password_reset", map[string]interface{}{"name": "Gola", "reset_url": "https://gola.xyz/reset"}).
+		Return(templates.Rendered{Subject: "Reset your password", HTML: "<p>Hi Gola</p>", Text: "Hi Gola", IncludeBaseTemplate: true}, nil)
+	suite.emailService.EXPECT().Send(suite.context, models.Email{
+		From:    "gola@gola.xyz",
+		To:      []string{"some@gmail.com"},
+		Subject: "Reset your password",
+		Body: models.MessageBody{
+			MimeType:  "text/html",
+			Content:   "<p>Hi Gola</p>",
+			PlainText: "Hi Gola",
+		},
+		IncludeBaseTemplate: true,
+	}).Return(models.SendReceipt{MessageID: "some-message-id"}, nil)
+
+	suite.controller.SendTemplateEmail(suite.context)
+
+	suite.Equal(http.StatusOK, suite.recorder.Code)
+	suite.JSONEq(`{"message_id":"some-message-id"}`, suite.recorder.Body.String())
+}
+
+func (suite emailControllerTestSuite) TestSendTemplateEmail_ShouldAcceptRenderedEmailIntoOutboxWhenAsyncIsRequested() {
+	request := suite.validTemplateEmailRequest()
+	request.Async = true
+	requestBody, _ := util.Encode(request)
+	suite.context.Request, _ = http.NewRequest("POST", "/", bytes.NewBufferString(requestBody))
+
+	suite.registry.EXPECT().Render(suite.context, "password_reset", gomock.Any()).Return(templates.Rendered{Subject: "Reset your password"}, nil)
+	suite.emailService.EXPECT().Enqueue(suite.context, gomock.Any()).Return(models.SendReceipt{MessageID: "some-message-id"}, nil)
+
+	suite.controller.SendTemplateEmail(suite.context)
+
+	suite.Equal(http.StatusAccepted, suite.recorder.Code)
+}
+
+func (suite emailControllerTestSuite) TestSendTemplateEmail_ShouldRespondWithRenderErrorWithoutSendingEmail() {
+	request := suite.validTemplateEmailRequest()
+	requestBody, _ := util.Encode(request)
+	suite.context.Request, _ = http.NewRequest("POST", "/", bytes.NewBufferString(requestBody))
+
+	suite.registry.EXPECT().Render(suite.context, "password_reset", gomock.Any()).Return(templates.Rendered{}, &constants.TemplateNotFoundError)
+
+	suite.controller.SendTemplateEmail(suite.context)
+
+	suite.Equal(http.StatusNotFound, suite.recorder.Code)
+	response := golaerror.Error{}
+	_ = json.Unmarshal(suite.recorder.Body.Bytes(), &response)
+	suite.Equal(constants.TemplateNotFoundCode, response.ErrorCode)
+}
+
+func (suite emailControllerTestSuite) TestSendTemplateEmail_ShouldThrowBadRequestWhenTemplateNameIsMissing() {
+	request := suite.validTemplateEmailRequest()
+	request.TemplateName = ""
+	requestBody, _ := util.Encode(request)
+	suite.context.Request, _ = http.NewRequest("POST", "/", bytes.NewBufferString(requestBody))
+
+	suite.controller.SendTemplateEmail(suite.context)
+
+	suite.Equal(http.StatusBadRequest, suite.recorder.Code)
+}
+
+func (suite emailControllerTestSuite) TestSendTemplateEmail_ShouldThrowBadRequestWhenFromEmailIsNotFromGolaDomain() {
+	request := suite.validTemplateEmailRequest()
+	request.From = "someone@gmail.com"
+	requestBody, _ := util.Encode(request)
+	suite.context.Request, _ = http.NewRequest("POST", "/", bytes.NewBufferString(requestBody))
+
+	suite.controller.SendTemplateEmail(suite.context)
+
+	suite.Equal(http.StatusBadRequest, suite.recorder.Code)
+}
+
+func (suite emailControllerTestSuite) validTemplateEmailRequest() http_request_response.TemplateEmailRequest {
+	return http_request_response.TemplateEmailRequest{
+		From:         "gola@gola.xyz",
+		To:           []string{"some@gmail.com"},
+		TemplateName: "password_reset",
+		Variables:    map[string]interface{}{"name": "Gola", "reset_url": "https://gola.xyz/reset"},
+	}
+}
+
+func (suite emailControllerTestSuite) TestSendEmail_ShouldThrowBadRequestWhenPlainTextContentIsNotBase64() {
+	request := suite.validEmailRequest()
+	request.Body.PlainTextContent = "not base64!"
+	requestBody, _ := util.Encode(request)
+	suite.context.Request, _ = http.NewRequest("POST", "/", bytes.NewBufferString(requestBody))
+
+	suite.controller.SendEmail(suite.context)
+
+	suite.Equal(http.StatusBadRequest, suite.recorder.Code)
+}
+
+func (suite emailControllerTestSuite) validEmailRequest() http_request_response.EmailRequest {
+	return http_request_response.EmailRequest{
+		From:    "gola@gola.xyz",
+		To:      []string{"some@gmail.com"},
+		Subject: "Hi",
+		Body: http_request_response.MessageBody{
+			Content:  "SGVsbG8h",
+			MimeType: "text/html",
+		},
+	}
+}
+
+func (suite emailControllerTestSuite) TestSendBatch_ShouldSendEveryValidMessageAndReportInvalidOnesAtTheirIndex() {
+	invalid := suite.validEmailRequest()
+	invalid.To = nil
+	async := suite.validEmailRequest()
+	async.Async = true
+	request := http_request_response.BatchEmailRequest{
+		Messages: []http_request_response.EmailRequest{suite.validEmailRequest(), invalid, async},
+	}
+
+	suite.emailService.EXPECT().Send(gomock.Any(), gomock.Any()).Return(models.SendReceipt{MessageID: "first-message-id"}, nil)
+	suite.emailService.EXPECT().Enqueue(gomock.Any(), gomock.Any()).Return(models.SendReceipt{MessageID: "third-message-id"}, nil)
+
+	requestBody, _ := util.Encode(request)
+	suite.context.Request, _ = http.NewRequest("POST", "/", bytes.NewBufferString(requestBody))
+
+	suite.controller.SendBatch(suite.context)
+
+	response := http_request_response.BatchEmailResponse{}
+	_ = json.Unmarshal(suite.recorder.Body.Bytes(), &response)
+	suite.Equal(http.StatusOK, suite.recorder.Code)
+	suite.Equal([]http_request_response.BatchItemResult{
+		{Index: 0, StatusCode: http.StatusOK, MessageID: "first-message-id"},
+		{Index: 1, StatusCode: http.StatusBadRequest, Error: &constants.PayloadValidationError},
+		{Index: 2, StatusCode: http.StatusAccepted, MessageID: "third-message-id"},
+	}, response.Results)
+}
+
+func (suite emailControllerTestSuite) TestSendBatch_ShouldRenderTemplateForEveryRecipientWithTheirOwnVariables() {
+	request := http_request_response.BatchEmailRequest{
+		Template: &http_request_response.BatchTemplateRequest{
+			From:         "gola@gola.xyz",
+			TemplateName: "welcome",
+			Recipients: []http_request_response.TemplateRecipient{
+				{To: []string{"first@gmail.com"}, Variables: map[string]interface{}{"name": "First"}},
+				{To: []string{"second@gmail.com"}, Variables: map[string]interface{}{}},
+				{To: []string{"not-an-email"}, Variables: map[string]interface{}{"name": "Third"}},
+			},
+		},
+	}
+	missingVariables := golaerror.New(constants.MissingTemplateVariablesCode, constants.MissingTemplateVariablesError.ErrorMessage,
+		map[string]interface{}{"missing_variables": []interface{}{"name"}})
+
+	suite.registry.EXPECT().Render(gomock.Any(), "welcome", map[string]interface{}{"name": "First"}).
+		Return(templates.Rendered{Subject: "Welcome First", HTML: "<p>Hi First</p>"}, nil)
+	suite.registry.EXPECT().Render(gomock.Any(), "welcome", map[string]interface{}{}).Return(templates.Rendered{}, &missingVariables)
+	suite.emailService.EXPECT().Send(gomock.Any(), gomock.Any()).Do(func(ctx *gin.Context, email models.Email) {
+		suite.Equal([]string{"first@gmail.com"}, email.To)
+		suite.Equal("Welcome First", email.Subject)
+	}).Return(models.SendReceipt{MessageID: "first-message-id"}, nil)
+
+	requestBody, _ := util.Encode(request)
+	suite.context.Request, _ = http.NewRequest("POST", "/", bytes.NewBufferString(requestBody))
+
+	suite.controller.SendBatch(suite.context)
+
+	response := http_request_response.BatchEmailResponse{}
+	_ = json.Unmarshal(suite.recorder.Body.Bytes(), &response)
+	suite.Equal(http.StatusOK, suite.recorder.Code)
+	suite.Equal([]http_request_response.BatchItemResult{
+		{Index: 0, StatusCode: http.StatusOK, MessageID: "first-message-id"},
+		{Index: 1, StatusCode: http.StatusBadRequest, Error: &missingVariables},
+		{Index: 2, StatusCode: http.StatusBadRequest, Error: &constants.PayloadValidationError},
+	}, response.Results)
+}
+
+func (suite emailControllerTestSuite) TestSendBatch_ShouldThrowBadRequestForEmptyBatch() {
+	requestBody, _ := util.Encode(http_request_response.BatchEmailRequest{})
+	suite.context.Request, _ = http.NewRequest("POST", "/", bytes.NewBufferString(requestBody))
+
+	suite.controller.SendBatch(suite.context)
+
+	suite.Equal(http.StatusBadRequest, suite.recorder.Code)
+}
+
+func (suite emailControllerTestSuite) TestSendBatch_ShouldThrowBadRequestIfBatchHasBothMessagesAndTemplate() {
+	request := http_request_response.BatchEmailRequest{
+		Messages: []http_request_response.EmailRequest{suite.validEmailRequest()},
+		Template: &http_request_response.BatchTemplateRequest{
+			From:         "gola@gola.xyz",
+			TemplateName: "welcome",
+			Recipients:   []http_request_response.TemplateRecipient{{To: []string{"first@gmail.com"}}},
+		},
+	}
+	requestBody, _ := util.Encode(request)
+	suite.context.Request, _ = http.NewRequest("POST", "/", bytes.NewBufferString(requestBody))
+
+	suite.controller.SendBatch(suite.context)
+
+	suite.Equal(http.StatusBadRequest, suite.recorder.Code)
+}
+
+func (suite emailControllerTestSuite) TestSendBatch_ShouldRejectBatchLargerThanAllowed() {
+	message := suite.validEmailRequest()
+	request := http_request_response.BatchEmailRequest{
+		Messages: []http_request_response.EmailRequest{message, message, message, message},
+	}
+	requestBody, _ := util.Encode(request)
+	suite.context.Request, _ = http.NewRequest("POST", "/", bytes.NewBufferString(requestBody))
+
+	suite.controller.SendBatch(suite.context)
+
+	response := golaerror.Error{}
+	_ = json.Unmarshal(suite.recorder.Body.Bytes(), &response)
+	suite.Equal(http.StatusRequestEntityTooLarge, suite.recorder.Code)
+	suite.Equal(constants.BatchSizeExceededCode, response.ErrorCode)
+}
+
+func (suite emailControllerTestSuite) TestSendEmail_ShouldSendForTenantOfRequestFromItsDefaultSenderAndDisplayName() {
+	request := suite.validEmailRequest()
+	request.From = ""
+	request.Tenant = "narratenet"
+	requestBody, _ := util.Encode(request)
+	suite.context.Request, _ = http.NewRequest("POST", "/", bytes.NewBufferString(requestBody))
+
+	suite.emailService.EXPECT().Send(suite.context, gomock.Any()).DoAndReturn(func(ctx *gin.Context, email models.Email) (models.SendReceipt, *golaerror.Error) {
+		suite.Equal("narratenet", email.Tenant)
+		suite.Equal("hello@narratenet.com", email.From)
+		suite.Equal("Narratenet", email.FromName)
+		return models.SendReceipt{MessageID: "some-message-id"}, nil
+	})
+
+	suite.controller.SendEmail(suite.context)
+
+	suite.Equal(http.StatusOK, suite.recorder.Code)
+}
+
+func (suite emailControllerTestSuite) TestSendEmail_ShouldSendForTenantOfAuthenticatedClient() {
+	request := suite.validEmailRequest()
+	request.From = "news@narratenet.com"
+	requestBody, _ := util.Encode(request)
+	suite.context.Request, _ = http.NewRequest("POST", "/", bytes.NewBufferString(requestBody))
+	auth.SetClient(suite.context, auth.Client{ID: "narratenet-app", Tenant: "narratenet"})
+
+	suite.emailService.EXPECT().Send(suite.context, gomock.Any()).DoAndReturn(func(ctx *gin.Context, email models.Email) (models.SendReceipt, *golaerror.Error) {
+		suite.Equal("narratenet", email.Tenant)
+		suite.Equal("news@narratenet.com", email.From)
+		return models.SendReceipt{MessageID: "some-message-id"}, nil
+	})
+
+	suite.controller.SendEmail(suite.context)
+
+	suite.Equal(http.StatusOK, suite.recorder.Code)
+}
+
+func (suite emailControllerTestSuite) TestSendEmail_ShouldRespondWithForbiddenWhenClientSendsForAnotherTenant() {
+	request := suite.validEmailRequest()
+	request.Tenant = "gola"
+	requestBody, _ := util.Encode(request)
+	suite.context.Request, _ = http.NewRequest("POST", "/", bytes.NewBufferString(requestBody))
+	auth.SetClient(suite.context, auth.Client{ID: "narratenet-app", Tenant: "narratenet"})
+
+	suite.controller.SendEmail(suite.context)
+
+	suite.Equal(http.StatusForbidden, suite.recorder.Code)
+	response := golaerror.Error{}
+	_ = json.Unmarshal(suite.recorder.Body.Bytes(), &response)
+	suite.Equal(constants.TenantNotAllowedCode, response.ErrorCode)
+}
+
+func (suite emailControllerTestSuite) TestSendEmail_ShouldRespondWithForbiddenWhenSenderIsNotOfDomainOfTenant() {
+	request := suite.validEmailRequest()
+	request.From = "gola@gola.xyz"
+	request.Tenant = "narratenet"
+	requestBody, _ := util.Encode(request)
+	suite.context.Request, _ = http.NewRequest("POST", "/", bytes.NewBufferString(requestBody))
+
+	suite.controller.SendEmail(suite.context)
+
+	suite.Equal(http.StatusForbidden, suite.recorder.Code)
+	response := golaerror.Error{}
+	_ = json.Unmarshal(suite.recorder.Body.Bytes(), &response)
+	suite.Equal(constants.SenderNotAllowedCode, response.ErrorCode)
+}
+
+func (suite emailControllerTestSuite) TestSendEmail_ShouldRespondWithBadRequestWhenTenantIsUnknown() {
+	request := suite.validEmailRequest()
+	request.Tenant = "unknown"
+	requestBody, _ := util.Encode(request)
+	suite.context.Request, _ = http.NewRequest("POST", "/", bytes.NewBufferString(requestBody))
+
+	suite.controller.SendEmail(suite.context)
+
+	suite.Equal(http.StatusBadRequest, suite.recorder.Code)
 }
@@ -1,6 +1,7 @@
 package controller
 
 import (
+	"ccg-api/email/models"
 	"github.com/go-playground/validator/v10"
 	"strings"
 )
@@ -13,8 +14,17 @@ func NewGolaDomainValidator(validGolaDomains []string) *GolaDomainValidator {
 	return &GolaDomainValidator{validGolaDomain: validGolaDomains}
 }
 
+// validate checks the address of the sender, which may come with a display name, is of one of our domains
 func (golaDomainValidator GolaDomainValidator) validate(fieldLevel validator.FieldLevel) bool {
-	domainInRequest := strings.ToLower(fieldLevel.Field().String())
+	address, _, err := models.ParseSender(fieldLevel.Field().String())
+	if err != nil {
+		return false
+	}
+	return golaDomainValidator.allows(address)
+}
+
+func (golaDomainValidator GolaDomainValidator) allows(address string) bool {
+	domainInRequest := strings.ToLower(address)
 	for _, domain := range golaDomainValidator.validGolaDomain {
 		domain = strings.ToLower(domain)
 		if strings.HasSuffix(domainInRequest, "@"+domain) {
@@ -0,0 +1,22 @@
+package controller
+
+import (
+	"github.com/go-playground/validator/v10"
+	"regexp"
+)
+
+var contentIDPattern = regexp.MustCompile(`^[A-Za-z0-9!#$%&'*+\-/=?^_{|}~.@]{1,255}$`)
+
+type inlineAttachment interface {
+	IsInline() bool
+}
+
+// InlineContentIDValidator requires a content id exactly for inline attachments, usable inside <> of a Content-ID header
+func InlineContentIDValidator(fieldLevel validator.FieldLevel) bool {
+	contentID := fieldLevel.Field().String()
+	attachment, ok := fieldLevel.Parent().Interface().(inlineAttachment)
+	if !ok || !attachment.IsInline() {
+		return len(contentID) == 0
+	}
+	return contentIDPattern.MatchString(contentID)
+}
@@ -0,0 +1,28 @@
+package controller
+
+import "github.com/go-playground/validator/v10"
+
+const defaultMaxRecipients = 50
+
+type recipientCounter interface {
+	RecipientCount() int
+}
+
+type MaxRecipientsValidator struct {
+	maxRecipients int
+}
+
+func NewMaxRecipientsValidator(maxRecipients int) *MaxRecipientsValidator {
+	if maxRecipients <= 0 {
+		maxRecipients = defaultMaxRecipients
+	}
+	return &MaxRecipientsValidator{maxRecipients: maxRecipients}
+}
+
+// validate is registered on To but counts Cc and Bcc of the enclosing request as well
+func (maxRecipientsValidator MaxRecipientsValidator) validate(fieldLevel validator.FieldLevel) bool {
+	if request, ok := fieldLevel.Parent().Interface().(recipientCounter); ok {
+		return request.RecipientCount() <= maxRecipientsValidator.maxRecipients
+	}
+	return fieldLevel.Field().Len() <= maxRecipientsValidator.maxRecipients
+}
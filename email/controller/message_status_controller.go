@@ -0,0 +1,87 @@
+package controller
+
+import (
+	"ccg-api/auth"
+	"ccg-api/constants"
+	"ccg-api/email/http_request_response"
+	"ccg-api/email/status"
+	http_util "ccg-api/http-util"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/inclusi-blog/gola-utils/golaerror"
+	"net/http"
+)
+
+type MessageStatusController interface {
+	GetStatus(ctx *gin.Context)
+	ListStatuses(ctx *gin.Context)
+}
+
+type messageStatusController struct {
+	tracker                 status.Tracker
+	httpRequestDeserializer http_util.HttpRequestDeserializer
+}
+
+func NewMessageStatusController(tracker status.Tracker) MessageStatusController {
+	return messageStatusController{
+		tracker:                 tracker,
+		httpRequestDeserializer: http_util.NewHttpRequestDeserializer(validator.New()),
+	}
+}
+
+// GetStatus godoc
+// @Tags Email
+// @Summary API to get the delivery status of an email
+// @Description API to get the lifecycle of an accepted email, with timestamps and the last error if any
+// @Description Only emails sent by the authenticated client are found
+// @Produce  json
+// @Param id path string true "Message Id"
+// @Success 200 {object} http_request_response.MessageStatusResponse
+// @Failure 404 {object} golaerror.Error "If no email was accepted with the given id"
+// @Failure 500 {object} golaerror.Error ""
+// @Router /api/ccg/v1/email/{id} [get]
+func (controller messageStatusController) GetStatus(ctx *gin.Context) {
+	// for swagger import
+	_ = golaerror.Error{}
+
+	client, _ := auth.ClientFrom(ctx)
+	messageStatus, err := controller.tracker.GetForClient(ctx, client.ID, ctx.Param("id"))
+	if err != nil {
+		constants.RespondWithGolaError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, http_request_response.NewMessageStatusResponse(messageStatus))
+}
+
+// ListStatuses godoc
+// @Tags Email
+// @Summary API to list the delivery status of emails
+// @Description API to list emails accepted from the authenticated client, most recent first
+// @Description If NextCursor is set in the response then, passing it back as cursor lists the next page
+// @Description Recipients are matched by the sha256 hex digest of the lower cased address
+// @Produce  json
+// @Param recipient_hash query string false "sha256 hex digest of a recipient address"
+// @Param from query string false "Sender address"
+// @Param since query string false "Accepted at or after (RFC3339)"
+// @Param until query string false "Accepted at or before (RFC3339)"
+// @Param limit query int false "Maximum number of emails, defaults to 100"
+// @Param cursor query string false "NextCursor of the previous page"
+// @Success 200 {object} http_request_response.MessageStatusListResponse
+// @Failure 400 {object} golaerror.Error "If any filter is invalid"
+// @Failure 500 {object} golaerror.Error ""
+// @Router /api/ccg/v1/email [get]
+func (controller messageStatusController) ListStatuses(ctx *gin.Context) {
+	var request http_request_response.MessageStatusListRequest
+	if bindError := controller.httpRequestDeserializer.ShouldBindQueryIfValid(&request, ctx); bindError != nil {
+		constants.RespondWithGolaError(ctx, &constants.PayloadValidationError)
+		return
+	}
+
+	client, _ := auth.ClientFrom(ctx)
+	page, err := controller.tracker.List(ctx, request.ToFilter(client.ID))
+	if err != nil {
+		constants.RespondWithGolaError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, http_request_response.NewMessageStatusListResponse(page))
+}
@@ -0,0 +1,153 @@
+package controller
+
+import (
+	"ccg-api/auth"
+	"ccg-api/constants"
+	"ccg-api/email/http_request_response"
+	"ccg-api/email/models"
+	"ccg-api/email/status/mocks"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/inclusi-blog/gola-utils/golaerror"
+	"github.com/stretchr/testify/suite"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+type messageStatusControllerTestSuite struct {
+	suite.Suite
+	mockCtrl   *gomock.Controller
+	recorder   *httptest.ResponseRecorder
+	context    *gin.Context
+	tracker    *mocks.MockTracker
+	controller MessageStatusController
+}
+
+func TestMessageStatusControllerTestSuite(t *testing.T) {
+	suite.Run(t, new(messageStatusControllerTestSuite))
+}
+
+func (suite *messageStatusControllerTestSuite) SetupTest() {
+	suite.mockCtrl = gomock.NewController(suite.T())
+	suite.recorder = httptest.NewRecorder()
+	suite.context, _ = gin.CreateTestContext(suite.recorder)
+	suite.tracker = mocks.NewMockTracker(suite.mockCtrl)
+	suite.controller = NewMessageStatusController(suite.tracker)
+}
+
+func (suite messageStatusControllerTestSuite) TestGetStatus_ShouldRespondWithStatusOfMessage() {
+	createdAt := time.Date(2020, 9, 20, 10, 0, 0, 0, time.UTC)
+	suite.context.Request, _ = http.NewRequest("GET", "/", nil)
+	suite.context.Params = gin.Params{{Key: "id", Value: "message-1"}}
+	auth.SetClient(suite.context, auth.Client{ID: "gola-api"})
+	suite.tracker.EXPECT().GetForClient(suite.context, "gola-api", "message-1").Return(models.MessageStatus{
+		ID:        "message-1",
+		ClientID:  "gola-api",
+		From:      "gola@gola.xyz",
+		State:     models.Retrying,
+		Attempts:  1,
+		LastError: "421 try again later",
+		CreatedAt: createdAt,
+		UpdatedAt: createdAt,
+		History: []models.StateTransition{
+			{State: models.Accepted, At: createdAt},
+			{State: models.Sending, At: createdAt},
+			{State: models.Retrying, At: createdAt, Error: "421 try again later"},
+		},
+	}, nil)
+
+	suite.controller.GetStatus(suite.context)
+
+	suite.Equal(http.StatusOK, suite.recorder.Code)
+	response := http_request_response.MessageStatusResponse{}
+	_ = json.Unmarshal(suite.recorder.Body.Bytes(), &response)
+	suite.Equal("message-1", response.MessageID)
+	suite.Equal("retrying", response.Status)
+	suite.Equal("421 try again later", response.LastError)
+	suite.Len(response.History, 3)
+}
+
+func (suite messageStatusControllerTestSuite) TestGetStatus_ShouldRespondWithNotFoundForUnknownMessage() {
+	suite.context.Request, _ = http.NewRequest("GET", "/", nil)
+	suite.context.Params = gin.Params{{Key: "id", Value: "unknown-message"}}
+	suite.tracker.EXPECT().GetForClient(suite.context, "", "unknown-message").Return(models.MessageStatus{}, &constants.MessageNotFoundError)
+
+	suite.controller.GetStatus(suite.context)
+
+	suite.Equal(http.StatusNotFound, suite.recorder.Code)
+	response := golaerror.Error{}
+	_ = json.Unmarshal(suite.recorder.Body.Bytes(), &response)
+	suite.Equal(constants.MessageNotFoundCode, response.ErrorCode)
+}
+
+func (suite messageStatusControllerTestSuite) TestListStatuses_ShouldListStatusesMatchingQuery() {
+	recipientHash := "4f3c2b5e0e1f7c3d8a9b6e5f4d3c2b1a0f9e8d7c6b5a4f3e2d1c0b9a8f7e6d5c"
+	suite.context.Request, _ = http.NewRequest("GET",
+		"/?recipient_hash="+recipientHash+"&from=gola@gola.xyz&since=2020-09-20T00:00:00Z&limit=10&cursor=1600560000000000000_message-0", nil)
+	auth.SetClient(suite.context, auth.Client{ID: "gola-api"})
+	suite.tracker.EXPECT().List(suite.context, models.MessageStatusFilter{
+		ClientID:      "gola-api",
+		RecipientHash: recipientHash,
+		From:          "gola@gola.xyz",
+		Since:         time.Date(2020, 9, 20, 0, 0, 0, 0, time.UTC),
+		Limit:         10,
+		Cursor:        "1600560000000000000_message-0",
+	}).Return(models.MessageStatusPage{
+		Statuses:   []models.MessageStatus{{ID: "message-1", ClientID: "gola-api", State: models.Sent}},
+		NextCursor: "1600560000000000000_message-1",
+	}, nil)
+
+	suite.controller.ListStatuses(suite.context)
+
+	suite.Equal(http.StatusOK, suite.recorder.Code)
+	response := http_request_response.MessageStatusListResponse{}
+	_ = json.Unmarshal(suite.recorder.Body.Bytes(), &response)
+	suite.Len(response.Messages, 1)
+	suite.Equal("message-1", response.Messages[0].MessageID)
+	suite.Equal("1600560000000000000_message-1", response.NextCursor)
+}
+
+func (suite messageStatusControllerTestSuite) TestListStatuses_ShouldMatchRecipientHashRegardlessOfCase() {
+	recipientHash := "4f3c2b5e0e1f7c3d8a9b6e5f4d3c2b1a0f9e8d7c6b5a4f3e2d1c0b9a8f7e6d5c"
+	suite.context.Request, _ = http.NewRequest("GET", "/?recipient_hash="+strings.ToUpper(recipientHash), nil)
+	suite.tracker.EXPECT().List(suite.context, models.MessageStatusFilter{
+		RecipientHash: recipientHash,
+		Limit:         http_request_response.DefaultMessageStatusListLimit,
+	}).Return(models.MessageStatusPage{Statuses: []models.MessageStatus{}}, nil)
+
+	suite.controller.ListStatuses(suite.context)
+
+	suite.Equal(http.StatusOK, suite.recorder.Code)
+}
+
+func (suite messageStatusControllerTestSuite) TestListStatuses_ShouldApplyDefaultLimit() {
+	suite.context.Request, _ = http.NewRequest("GET", "/", nil)
+	suite.tracker.EXPECT().List(suite.context, models.MessageStatusFilter{
+		Limit: http_request_response.DefaultMessageStatusListLimit,
+	}).Return(models.MessageStatusPage{Statuses: []models.MessageStatus{}}, nil)
+
+	suite.controller.ListStatuses(suite.context)
+
+	suite.Equal(http.StatusOK, suite.recorder.Code)
+	suite.JSONEq(`{"messages":[]}`, suite.recorder.Body.String())
+}
+
+func (suite messageStatusControllerTestSuite) TestListStatuses_ShouldRespondWithBadRequestForInvalidFilter() {
+	suite.context.Request, _ = http.NewRequest("GET", "/?recipient_hash=not-a-hash", nil)
+
+	suite.controller.ListStatuses(suite.context)
+
+	suite.Equal(http.StatusBadRequest, suite.recorder.Code)
+}
+
+func (suite messageStatusControllerTestSuite) TestListStatuses_ShouldRespondWithBadRequestForInvalidTimeRange() {
+	suite.context.Request, _ = http.NewRequest("GET", "/?since=yesterday", nil)
+
+	suite.controller.ListStatuses(suite.context)
+
+	suite.Equal(http.StatusBadRequest, suite.recorder.Code)
+}
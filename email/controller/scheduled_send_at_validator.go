@@ -0,0 +1,33 @@
+package controller
+
+import (
+	"github.com/go-playground/validator/v10"
+	"time"
+)
+
+const defaultMaxScheduleAheadInDays = 30
+
+type ScheduledSendAtValidator struct {
+	maxScheduleAhead time.Duration
+}
+
+func NewScheduledSendAtValidator(maxScheduleAheadInDays int) *ScheduledSendAtValidator {
+	if maxScheduleAheadInDays <= 0 {
+		maxScheduleAheadInDays = defaultMaxScheduleAheadInDays
+	}
+	return &ScheduledSendAtValidator{maxScheduleAhead: time.Duration(maxScheduleAheadInDays) * 24 * time.Hour}
+}
+
+func (scheduledSendAtValidator ScheduledSendAtValidator) MaxScheduleAhead() time.Duration {
+	return scheduledSendAtValidator.maxScheduleAhead
+}
+
+// validate accepts a send time in the future, but no further ahead than messages are worth keeping in the outbox
+func (scheduledSendAtValidator ScheduledSendAtValidator) validate(fieldLevel validator.FieldLevel) bool {
+	sendAt, ok := fieldLevel.Field().Interface().(time.Time)
+	if !ok {
+		return false
+	}
+	now := time.Now()
+	return sendAt.After(now) && !sendAt.After(now.Add(scheduledSendAtValidator.maxScheduleAhead))
+}
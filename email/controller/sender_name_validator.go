@@ -0,0 +1,21 @@
+package controller
+
+import (
+	"ccg-api/email/models"
+	"github.com/go-playground/validator/v10"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const maxSenderNameLength = 64
+
+// SenderNameValidator checks the display name of the sender on its own, the address is left to GolaDomainValidator.
+// Names are limited in length and may not carry control characters, which could break the From header.
+func SenderNameValidator(fieldLevel validator.FieldLevel) bool {
+	_, name, err := models.ParseSender(fieldLevel.Field().String())
+	if err != nil {
+		return false
+	}
+	return utf8.RuneCountInString(name) <= maxSenderNameLength && strings.IndexFunc(name, unicode.IsControl) < 0
+}
@@ -0,0 +1,157 @@
+package controller
+
+import (
+	"ccg-api/constants"
+	"ccg-api/email/http_request_response"
+	"ccg-api/email/suppression"
+	http_util "ccg-api/http-util"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/inclusi-blog/gola-utils/golaerror"
+	"github.com/inclusi-blog/gola-utils/logging"
+	"net/http"
+)
+
+type SuppressionController interface {
+	ListSuppressions(ctx *gin.Context)
+	GetSuppression(ctx *gin.Context)
+	AddSuppression(ctx *gin.Context)
+	RemoveSuppression(ctx *gin.Context)
+	ImportSuppressions(ctx *gin.Context)
+}
+
+type suppressionController struct {
+	suppressions            suppression.List
+	validate                *validator.Validate
+	httpRequestDeserializer http_util.HttpRequestDeserializer
+}
+
+func NewSuppressionController(suppressions suppression.List) SuppressionController {
+	validate := validator.New()
+	return suppressionController{
+		suppressions:            suppressions,
+		validate:                validate,
+		httpRequestDeserializer: http_util.NewHttpRequestDeserializer(validate),
+	}
+}
+
+// ListSuppressions godoc
+// @Tags Suppression
+// @Summary API to list suppressed addresses and domains
+// @Description API to list every suppressed address or domain, most recent first
+// @Produce  json
+// @Success 200 {object} http_request_response.SuppressionListResponse
+// @Failure 500 {object} golaerror.Error ""
+// @Router /api/ccg/v1/suppressions [get]
+func (controller suppressionController) ListSuppressions(ctx *gin.Context) {
+	// for swagger import
+	_ = golaerror.Error{}
+
+	suppressions, err := controller.suppressions.All(ctx)
+	if err != nil {
+		constants.RespondWithGolaError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, http_request_response.NewSuppressionListResponse(suppressions))
+}
+
+// GetSuppression godoc
+// @Tags Suppression
+// @Summary API to get the suppression of an address or domain
+// @Produce  json
+// @Param address path string true "Email address or domain"
+// @Success 200 {object} http_request_response.SuppressionResponse
+// @Failure 404 {object} golaerror.Error "If the address or domain is not suppressed"
+// @Failure 500 {object} golaerror.Error ""
+// @Router /api/ccg/v1/suppressions/{address} [get]
+func (controller suppressionController) GetSuppression(ctx *gin.Context) {
+	suppressionEntry, err := controller.suppressions.Get(ctx, ctx.Param("address"))
+	if err != nil {
+		constants.RespondWithGolaError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, http_request_response.NewSuppressionResponse(suppressionEntry))
+}
+
+// AddSuppression godoc
+// @Tags Suppression
+// @Summary API to suppress an address or domain
+// @Description API to stop every future email to an address, or to every address of a domain,
+// @Description Suppressing an address that is already suppressed keeps and returns the original entry
+// @Accept  json
+// @Produce  json
+// @Param suppressionRequest body http_request_response.SuppressionRequest true "Suppression Request"
+// @Success 201 {object} http_request_response.SuppressionResponse
+// @Failure 400 {object} golaerror.Error "If the address is neither an email address nor a domain, or the reason is unknown"
+// @Failure 500 {object} golaerror.Error ""
+// @Router /api/ccg/v1/suppressions [post]
+func (controller suppressionController) AddSuppression(ctx *gin.Context) {
+	var request http_request_response.SuppressionRequest
+	if bindError := controller.httpRequestDeserializer.ShouldBindJsonBodyIfValid(&request, ctx); bindError != nil {
+		constants.RespondWithGolaError(ctx, &constants.PayloadValidationError)
+		return
+	}
+
+	suppressionEntry, err := controller.suppressions.Add(ctx, request.ToSuppressionModel(suppression.ApiSource))
+	if err != nil {
+		constants.RespondWithGolaError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusCreated, http_request_response.NewSuppressionResponse(suppressionEntry))
+}
+
+// RemoveSuppression godoc
+// @Tags Suppression
+// @Summary API to lift the suppression of an address or domain
+// @Param address path string true "Email address or domain"
+// @Success 204 "If the suppression was lifted"
+// @Failure 404 {object} golaerror.Error "If the address or domain is not suppressed"
+// @Failure 500 {object} golaerror.Error ""
+// @Router /api/ccg/v1/suppressions/{address} [delete]
+func (controller suppressionController) RemoveSuppression(ctx *gin.Context) {
+	if err := controller.suppressions.Remove(ctx, ctx.Param("address")); err != nil {
+		constants.RespondWithGolaError(ctx, err)
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
+
+// ImportSuppressions godoc
+// @Tags Suppression
+// @Summary API to suppress addresses and domains in bulk from a csv
+// @Description API to import a csv of address,reason lines, the header row is optional and a missing reason is taken as manual,
+// @Description Invalid lines are skipped and reported with their line number, every valid line is imported
+// @Accept  text/csv
+// @Produce  json
+// @Param suppressions body string true "address,reason lines"
+// @Success 200 {object} http_request_response.SuppressionImportResponse
+// @Failure 400 {object} golaerror.Error "If the body is not a valid csv"
+// @Failure 500 {object} golaerror.Error ""
+// @Router /api/ccg/v1/suppressions/import [post]
+func (controller suppressionController) ImportSuppressions(ctx *gin.Context) {
+	logger := logging.GetLogger(ctx).WithField("class", "SuppressionController").WithField("method", "ImportSuppressions")
+	rows, readError := http_request_response.ReadSuppressionCsv(ctx.Request.Body)
+	if readError != nil {
+		logger.Error("Failed to read suppression csv ", readError)
+		constants.RespondWithGolaError(ctx, &constants.PayloadValidationError)
+		return
+	}
+
+	response := http_request_response.SuppressionImportResponse{Rejected: []http_request_response.SuppressionImportRejection{}}
+	for _, row := range rows {
+		rejection := http_request_response.SuppressionImportRejection{Line: row.Line, Address: row.Request.Address}
+		if validationError := controller.validate.Struct(row.Request); validationError != nil {
+			rejection.Error = constants.PayloadValidationError.ErrorMessage
+			response.Rejected = append(response.Rejected, rejection)
+			continue
+		}
+		if _, err := controller.suppressions.Add(ctx, row.Request.ToSuppressionModel(suppression.CsvImportSource)); err != nil {
+			rejection.Error = err.ErrorMessage
+			response.Rejected = append(response.Rejected, rejection)
+			continue
+		}
+		response.Imported++
+	}
+	logger.Infof("Imported %d suppression(s), rejected %d", response.Imported, len(response.Rejected))
+	ctx.JSON(http.StatusOK, response)
+}
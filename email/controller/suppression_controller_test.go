@@ -0,0 +1,159 @@
+package controller
+
+import (
+	"bytes"
+	"ccg-api/constants"
+	"ccg-api/email/http_request_response"
+	"ccg-api/email/models"
+	"ccg-api/email/suppression"
+	"ccg-api/email/suppression/mocks"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/inclusi-blog/gola-utils/golaerror"
+	"github.com/stretchr/testify/suite"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type suppressionControllerTestSuite struct {
+	suite.Suite
+	mockCtrl     *gomock.Controller
+	recorder     *httptest.ResponseRecorder
+	context      *gin.Context
+	suppressions *mocks.MockList
+	controller   SuppressionController
+}
+
+func TestSuppressionControllerTestSuite(t *testing.T) {
+	suite.Run(t, new(suppressionControllerTestSuite))
+}
+
+func (suite *suppressionControllerTestSuite) SetupTest() {
+	suite.mockCtrl = gomock.NewController(suite.T())
+	suite.recorder = httptest.NewRecorder()
+	suite.context, _ = gin.CreateTestContext(suite.recorder)
+	suite.suppressions = mocks.NewMockList(suite.mockCtrl)
+	suite.controller = NewSuppressionController(suite.suppressions)
+}
+
+func (suite *suppressionControllerTestSuite) TearDownTest() {
+	suite.mockCtrl.Finish()
+}
+
+func (suite suppressionControllerTestSuite) TestListSuppressions_ShouldRespondWithEverySuppression() {
+	createdAt := time.Date(2020, 9, 20, 10, 0, 0, 0, time.UTC)
+	suite.context.Request, _ = http.NewRequest("GET", "/", nil)
+	suite.suppressions.EXPECT().All(suite.context).Return([]models.Suppression{
+		{Address: "bounced@gmail.com", Reason: models.HardBounce, Source: "api", CreatedAt: createdAt},
+		{Address: "blocked.com", Reason: models.Manual, Source: "csv_import", CreatedAt: createdAt},
+	}, nil)
+
+	suite.controller.ListSuppressions(suite.context)
+
+	suite.Equal(http.StatusOK, suite.recorder.Code)
+	response := http_request_response.SuppressionListResponse{}
+	_ = json.Unmarshal(suite.recorder.Body.Bytes(), &response)
+	suite.Len(response.Suppressions, 2)
+	suite.Equal(http_request_response.SuppressionResponse{
+		Address:   "blocked.com",
+		Reason:    "manual",
+		Source:    "csv_import",
+		CreatedAt: createdAt,
+	}, response.Suppressions[1])
+}
+
+func (suite suppressionControllerTestSuite) TestGetSuppression_ShouldRespondWithNotFoundWhenAddressIsNotSuppressed() {
+	suite.context.Request, _ = http.NewRequest("GET", "/", nil)
+	suite.context.Params = gin.Params{{Key: "address", Value: "someone@gmail.com"}}
+	suite.suppressions.EXPECT().Get(suite.context, "someone@gmail.com").Return(models.Suppression{}, &constants.SuppressionNotFoundError)
+
+	suite.controller.GetSuppression(suite.context)
+
+	suite.Equal(http.StatusNotFound, suite.recorder.Code)
+	response := golaerror.Error{}
+	_ = json.Unmarshal(suite.recorder.Body.Bytes(), &response)
+	suite.Equal(constants.SuppressionNotFoundCode, response.ErrorCode)
+}
+
+func (suite suppressionControllerTestSuite) TestAddSuppression_ShouldSuppressAddressFromApi() {
+	suite.context.Request, _ = http.NewRequest("POST", "/", bytes.NewBufferString(`{"address":"bounced@gmail.com","reason":"hard_bounce"}`))
+	expected := models.Suppression{Address: "bounced@gmail.com", Reason: models.HardBounce, Source: suppression.ApiSource}
+	suite.suppressions.EXPECT().Add(suite.context, expected).Return(expected, nil)
+
+	suite.controller.AddSuppression(suite.context)
+
+	suite.Equal(http.StatusCreated, suite.recorder.Code)
+	response := http_request_response.SuppressionResponse{}
+	_ = json.Unmarshal(suite.recorder.Body.Bytes(), &response)
+	suite.Equal("bounced@gmail.com", response.Address)
+	suite.Equal("hard_bounce", response.Reason)
+}
+
+func (suite suppressionControllerTestSuite) TestAddSuppression_ShouldSuppressWholeDomain() {
+	suite.context.Request, _ = http.NewRequest("POST", "/", bytes.NewBufferString(`{"address":"blocked.com","reason":"manual"}`))
+	expected := models.Suppression{Address: "blocked.com", Reason: models.Manual, Source: suppression.ApiSource}
+	suite.suppressions.EXPECT().Add(suite.context, expected).Return(expected, nil)
+
+	suite.controller.AddSuppression(suite.context)
+
+	suite.Equal(http.StatusCreated, suite.recorder.Code)
+}
+
+func (suite suppressionControllerTestSuite) TestAddSuppression_ShouldThrowBadRequestWhenReasonIsUnknown() {
+	suite.context.Request, _ = http.NewRequest("POST", "/", bytes.NewBufferString(`{"address":"bounced@gmail.com","reason":"annoying"}`))
+
+	suite.controller.AddSuppression(suite.context)
+
+	suite.Equal(http.StatusBadRequest, suite.recorder.Code)
+}
+
+func (suite suppressionControllerTestSuite) TestAddSuppression_ShouldThrowBadRequestWhenAddressIsInvalid() {
+	suite.context.Request, _ = http.NewRequest("POST", "/", bytes.NewBufferString(`{"address":"not an address","reason":"manual"}`))
+
+	suite.controller.AddSuppression(suite.context)
+
+	suite.Equal(http.StatusBadRequest, suite.recorder.Code)
+}
+
+func (suite suppressionControllerTestSuite) TestRemoveSuppression_ShouldRespondWithNoContent() {
+	suite.context.Request, _ = http.NewRequest("DELETE", "/", nil)
+	suite.context.Params = gin.Params{{Key: "address", Value: "bounced@gmail.com"}}
+	suite.suppressions.EXPECT().Remove(suite.context, "bounced@gmail.com").Return(nil)
+
+	suite.controller.RemoveSuppression(suite.context)
+
+	suite.Equal(http.StatusNoContent, suite.context.Writer.Status())
+}
+
+func (suite suppressionControllerTestSuite) TestImportSuppressions_ShouldImportValidLinesAndReportInvalidOnes() {
+	csv := "address,reason\nbounced@gmail.com,hard_bounce\nnot an address,manual\nblocked.com\nangry@gmail.com,annoying\n"
+	suite.context.Request, _ = http.NewRequest("POST", "/", bytes.NewBufferString(csv))
+	gomock.InOrder(
+		suite.suppressions.EXPECT().Add(suite.context, models.Suppression{Address: "bounced@gmail.com", Reason: models.HardBounce, Source: suppression.CsvImportSource}).
+			Return(models.Suppression{}, nil),
+		suite.suppressions.EXPECT().Add(suite.context, models.Suppression{Address: "blocked.com", Reason: models.Manual, Source: suppression.CsvImportSource}).
+			Return(models.Suppression{}, nil),
+	)
+
+	suite.controller.ImportSuppressions(suite.context)
+
+	suite.Equal(http.StatusOK, suite.recorder.Code)
+	response := http_request_response.SuppressionImportResponse{}
+	_ = json.Unmarshal(suite.recorder.Body.Bytes(), &response)
+	suite.Equal(2, response.Imported)
+	suite.Len(response.Rejected, 2)
+	suite.Equal(3, response.Rejected[0].Line)
+	suite.Equal("not an address", response.Rejected[0].Address)
+	suite.Equal(5, response.Rejected[1].Line)
+}
+
+func (suite suppressionControllerTestSuite) TestImportSuppressions_ShouldThrowBadRequestWhenBodyIsNotCsv() {
+	suite.context.Request, _ = http.NewRequest("POST", "/", bytes.NewBufferString("\"unterminated,hard_bounce\n"))
+
+	suite.controller.ImportSuppressions(suite.context)
+
+	suite.Equal(http.StatusBadRequest, suite.recorder.Code)
+}
@@ -0,0 +1,26 @@
+package controller
+
+import (
+	"ccg-api/configuration"
+	"github.com/go-playground/validator/v10"
+)
+
+type TenantValidator struct {
+	tenants map[string]bool
+}
+
+func NewTenantValidator(tenants []configuration.Tenant) *TenantValidator {
+	knownTenants := map[string]bool{}
+	for _, tenant := range tenants {
+		knownTenants[tenant.Name] = true
+	}
+	return &TenantValidator{tenants: knownTenants}
+}
+
+func (tenantValidator TenantValidator) validate(fieldLevel validator.FieldLevel) bool {
+	tenant, ok := fieldLevel.Field().Interface().(string)
+	if !ok {
+		return false
+	}
+	return tenantValidator.tenants[tenant]
+}
@@ -0,0 +1,96 @@
+package controller
+
+import (
+	"ccg-api/constants"
+	"ccg-api/email/event"
+	"ccg-api/email/models"
+	"ccg-api/email/tracking"
+	"github.com/gin-gonic/gin"
+	"github.com/inclusi-blog/gola-utils/golaerror"
+	"github.com/inclusi-blog/gola-utils/logging"
+	"net/http"
+	"time"
+)
+
+// transparentPixel is a 1x1 transparent GIF
+var transparentPixel = []byte{
+	0x47, 0x49, 0x46, 0x38, 0x39, 0x61, 0x01, 0x00, 0x01, 0x00, 0x80, 0x00, 0x00, 0x00, 0x00, 0x00,
+	0xff, 0xff, 0xff, 0x21, 0xf9, 0x04, 0x01, 0x00, 0x00, 0x00, 0x00, 0x2c, 0x00, 0x00, 0x00, 0x00,
+	0x01, 0x00, 0x01, 0x00, 0x00, 0x02, 0x02, 0x44, 0x01, 0x00, 0x3b,
+}
+
+type TrackingController interface {
+	TrackOpen(ctx *gin.Context)
+	TrackClick(ctx *gin.Context)
+}
+
+type trackingController struct {
+	tokens   tracking.Tokens
+	recorder event.Recorder
+}
+
+// NewTrackingController accepts nil tokens when no tracking secret is set, every token is rejected then
+func NewTrackingController(tokens tracking.Tokens, recorder event.Recorder) TrackingController {
+	return trackingController{tokens: tokens, recorder: recorder}
+}
+
+// TrackOpen godoc
+// @Tags Tracking
+// @Summary API behind the open pixel of a tracked email
+// @Description API loaded by the mail client when it shows the images of a tracked email, counts an open of the message,
+// @Description The pixel is served even for invalid tokens so that the email renders the same
+// @Produce  image/gif
+// @Param token path string true "Tracking token from the pixel url"
+// @Success 200 {string} string "1x1 transparent GIF"
+// @Router /api/ccg/t/o/{token} [get]
+func (controller trackingController) TrackOpen(ctx *gin.Context) {
+	if claim, valid := controller.verify(ctx); valid {
+		controller.record(ctx, claim, models.OpenedEvent)
+	}
+	ctx.Header("Cache-Control", "no-store, no-cache, must-revalidate, private")
+	ctx.Data(http.StatusOK, "image/gif", transparentPixel)
+}
+
+// TrackClick godoc
+// @Tags Tracking
+// @Summary API behind the links of a tracked email
+// @Description API opened when a link of a tracked email is clicked, counts a click of the message and redirects to the original link
+// @Param token path string true "Tracking token from the link"
+// @Success 302 {string} string "Redirect to the original link"
+// @Failure 404 {object} golaerror.Error "If the token is invalid"
+// @Router /api/ccg/t/c/{token} [get]
+func (controller trackingController) TrackClick(ctx *gin.Context) {
+	// for swagger import
+	_ = golaerror.Error{}
+
+	claim, valid := controller.verify(ctx)
+	if !valid || claim.Url == "" {
+		constants.RespondWithGolaError(ctx, &constants.InvalidTrackingLinkError)
+		return
+	}
+	controller.record(ctx, claim, models.ClickedEvent)
+	ctx.Redirect(http.StatusFound, claim.Url)
+}
+
+func (controller trackingController) verify(ctx *gin.Context) (tracking.Claim, bool) {
+	logger := logging.GetLogger(ctx).WithField("class", "TrackingController").WithField("method", "verify")
+	if controller.tokens == nil {
+		logger.Warn("Tracking link followed but no tracking secret is configured")
+		return tracking.Claim{}, false
+	}
+	claim, err := controller.tokens.Verify(ctx.Param("token"))
+	if err != nil {
+		logger.Warn("Rejected tracking token ", err)
+		return tracking.Claim{}, false
+	}
+	return claim, true
+}
+
+func (controller trackingController) record(ctx *gin.Context, claim tracking.Claim, eventType models.DeliveryEventType) {
+	controller.recorder.Record(ctx, models.DeliveryEvent{
+		Source:     tracking.EventSource,
+		Type:       eventType,
+		MessageID:  claim.MessageID,
+		OccurredAt: time.Now(),
+	})
+}
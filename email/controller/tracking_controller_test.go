@@ -0,0 +1,94 @@
+package controller
+
+import (
+	"ccg-api/email/event/mocks"
+	"ccg-api/email/models"
+	"ccg-api/email/tracking"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/suite"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type trackingControllerTestSuite struct {
+	suite.Suite
+	mockCtrl      *gomock.Controller
+	recorder      *httptest.ResponseRecorder
+	context       *gin.Context
+	tokens        tracking.Tokens
+	eventRecorder *mocks.MockRecorder
+	controller    TrackingController
+}
+
+func TestTrackingControllerTestSuite(t *testing.T) {
+	suite.Run(t, new(trackingControllerTestSuite))
+}
+
+func (suite *trackingControllerTestSuite) SetupTest() {
+	suite.mockCtrl = gomock.NewController(suite.T())
+	suite.recorder = httptest.NewRecorder()
+	suite.context, _ = gin.CreateTestContext(suite.recorder)
+	suite.context.Request, _ = http.NewRequest("GET", "/", nil)
+	suite.tokens = tracking.NewTokens("unit-test-secret")
+	suite.eventRecorder = mocks.NewMockRecorder(suite.mockCtrl)
+	suite.controller = NewTrackingController(suite.tokens, suite.eventRecorder)
+}
+
+func (suite *trackingControllerTestSuite) TearDownTest() {
+	suite.mockCtrl.Finish()
+}
+
+func (suite trackingControllerTestSuite) TestTrackOpen_ShouldRecordOpenAndServePixel() {
+	suite.context.Params = gin.Params{{Key: "token", Value: suite.tokens.Issue(tracking.Claim{MessageID: "message-id"})}}
+	suite.eventRecorder.EXPECT().Record(suite.context, gomock.Any()).Do(func(ctx *gin.Context, event models.DeliveryEvent) {
+		suite.Equal(models.OpenedEvent, event.Type)
+		suite.Equal("message-id", event.MessageID)
+		suite.Equal(tracking.EventSource, event.Source)
+	})
+
+	suite.controller.TrackOpen(suite.context)
+
+	suite.Equal(http.StatusOK, suite.recorder.Code)
+	suite.Equal("image/gif", suite.recorder.Header().Get("Content-Type"))
+	suite.Equal(transparentPixel, suite.recorder.Body.Bytes())
+}
+
+func (suite trackingControllerTestSuite) TestTrackOpen_ShouldServePixelWithoutRecordingForInvalidToken() {
+	suite.context.Params = gin.Params{{Key: "token", Value: "forged.token"}}
+
+	suite.controller.TrackOpen(suite.context)
+
+	suite.Equal(http.StatusOK, suite.recorder.Code)
+	suite.Equal(transparentPixel, suite.recorder.Body.Bytes())
+}
+
+func (suite trackingControllerTestSuite) TestTrackClick_ShouldRecordClickAndRedirectToOriginalLink() {
+	suite.context.Params = gin.Params{{Key: "token", Value: suite.tokens.Issue(tracking.Claim{MessageID: "message-id", Url: "https://gola.xyz/posts/1"})}}
+	suite.eventRecorder.EXPECT().Record(suite.context, gomock.Any()).Do(func(ctx *gin.Context, event models.DeliveryEvent) {
+		suite.Equal(models.ClickedEvent, event.Type)
+		suite.Equal("message-id", event.MessageID)
+	})
+
+	suite.controller.TrackClick(suite.context)
+
+	suite.Equal(http.StatusFound, suite.recorder.Code)
+	suite.Equal("https://gola.xyz/posts/1", suite.recorder.Header().Get("Location"))
+}
+
+func (suite trackingControllerTestSuite) TestTrackClick_ShouldRespondNotFoundForTokenWithoutLink() {
+	suite.context.Params = gin.Params{{Key: "token", Value: suite.tokens.Issue(tracking.Claim{MessageID: "message-id"})}}
+
+	suite.controller.TrackClick(suite.context)
+
+	suite.Equal(http.StatusNotFound, suite.recorder.Code)
+}
+
+func (suite trackingControllerTestSuite) TestTrackClick_ShouldRespondNotFoundWhenTrackingIsDisabled() {
+	suite.context.Params = gin.Params{{Key: "token", Value: suite.tokens.Issue(tracking.Claim{MessageID: "message-id", Url: "https://gola.xyz"})}}
+
+	NewTrackingController(nil, suite.eventRecorder).TrackClick(suite.context)
+
+	suite.Equal(http.StatusNotFound, suite.recorder.Code)
+}
@@ -8,11 +8,18 @@ import (
 func UniqueAttachmentValidator(fieldLevel validator.FieldLevel) bool {
 	attachments := fieldLevel.Field().Interface().([]httprequestresponse.Attachment)
 	uniqueFiles := map[string]bool{}
+	uniqueContentIDs := map[string]bool{}
 	for _, attachment := range attachments {
 		if _, duplicateFileName := uniqueFiles[attachment.FileName]; duplicateFileName {
 			return false
 		}
 		uniqueFiles[attachment.FileName] = true
+		if attachment.IsInline() {
+			if _, duplicateContentID := uniqueContentIDs[attachment.ContentID]; duplicateContentID {
+				return false
+			}
+			uniqueContentIDs[attachment.ContentID] = true
+		}
 	}
 	return true
 }
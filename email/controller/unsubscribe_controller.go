@@ -0,0 +1,94 @@
+package controller
+
+import (
+	"bytes"
+	"ccg-api/constants"
+	"ccg-api/email/http_request_response"
+	"ccg-api/email/preference"
+	"ccg-api/email/unsubscribe"
+	"github.com/gin-gonic/gin"
+	"github.com/inclusi-blog/gola-utils/golaerror"
+	"github.com/inclusi-blog/gola-utils/logging"
+	"html/template"
+	"net/http"
+)
+
+const htmlContentType = "text/html; charset=utf-8"
+
+var unsubscribedPage = template.Must(template.New("unsubscribed").Parse(
+	`<!DOCTYPE html><html><head><meta charset="utf-8"><title>Unsubscribed</title></head>` +
+		`<body><p>You will no longer receive {{.}} emails from us.</p></body></html>`))
+
+type UnsubscribeController interface {
+	UnsubscribeFromLink(ctx *gin.Context)
+	UnsubscribeOneClick(ctx *gin.Context)
+}
+
+type unsubscribeController struct {
+	tokens      unsubscribe.Tokens
+	preferences preference.Preferences
+}
+
+func NewUnsubscribeController(tokens unsubscribe.Tokens, preferences preference.Preferences) UnsubscribeController {
+	return unsubscribeController{tokens: tokens, preferences: preferences}
+}
+
+// UnsubscribeFromLink godoc
+// @Tags Unsubscribe
+// @Summary API behind the unsubscribe link of an email
+// @Description API opened from the unsubscribe link in the email body, opts the recipient out of the category of the email and shows a confirmation page
+// @Produce  html
+// @Param token path string true "Unsubscribe token from the link"
+// @Success 200 {string} string "Confirmation page"
+// @Failure 400 {object} golaerror.Error "If the token is invalid"
+// @Failure 500 {object} golaerror.Error ""
+// @Router /api/ccg/v1/unsubscribe/{token} [get]
+func (controller unsubscribeController) UnsubscribeFromLink(ctx *gin.Context) {
+	// for swagger import
+	_ = golaerror.Error{}
+
+	claim, err := controller.optOut(ctx)
+	if err != nil {
+		constants.RespondWithGolaError(ctx, err)
+		return
+	}
+	var page bytes.Buffer
+	_ = unsubscribedPage.Execute(&page, claim.Category)
+	ctx.Data(http.StatusOK, htmlContentType, page.Bytes())
+}
+
+// UnsubscribeOneClick godoc
+// @Tags Unsubscribe
+// @Summary API for one-click unsubscribe (RFC 8058)
+// @Description API posted to by mail clients from the List-Unsubscribe header, opts the recipient out of the category of the email
+// @Produce  json
+// @Param token path string true "Unsubscribe token from the List-Unsubscribe header"
+// @Success 200 {object} http_request_response.UnsubscribeResponse
+// @Failure 400 {object} golaerror.Error "If the token is invalid"
+// @Failure 500 {object} golaerror.Error ""
+// @Router /api/ccg/v1/unsubscribe/{token} [post]
+func (controller unsubscribeController) UnsubscribeOneClick(ctx *gin.Context) {
+	claim, err := controller.optOut(ctx)
+	if err != nil {
+		constants.RespondWithGolaError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, http_request_response.UnsubscribeResponse{Category: claim.Category})
+}
+
+func (controller unsubscribeController) optOut(ctx *gin.Context) (unsubscribe.Claim, *golaerror.Error) {
+	logger := logging.GetLogger(ctx).WithField("class", "UnsubscribeController").WithField("method", "optOut")
+	if controller.tokens == nil {
+		logger.Error("Unsubscribe requested but no unsubscribe secret is configured")
+		return unsubscribe.Claim{}, &constants.InvalidUnsubscribeTokenError
+	}
+	claim, verifyError := controller.tokens.Verify(ctx.Param("token"))
+	if verifyError != nil {
+		logger.Warn("Rejected unsubscribe request ", verifyError)
+		return unsubscribe.Claim{}, &constants.InvalidUnsubscribeTokenError
+	}
+	if err := controller.preferences.OptOut(ctx, claim.Recipient, claim.Category); err != nil {
+		return unsubscribe.Claim{}, err
+	}
+	return claim, nil
+}
@@ -0,0 +1,89 @@
+package controller
+
+import (
+	"ccg-api/constants"
+	"ccg-api/email/http_request_response"
+	"ccg-api/email/preference/mocks"
+	"ccg-api/email/unsubscribe"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/inclusi-blog/gola-utils/golaerror"
+	"github.com/stretchr/testify/suite"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type unsubscribeControllerTestSuite struct {
+	suite.Suite
+	mockCtrl    *gomock.Controller
+	recorder    *httptest.ResponseRecorder
+	context     *gin.Context
+	tokens      unsubscribe.Tokens
+	preferences *mocks.MockPreferences
+	controller  UnsubscribeController
+}
+
+func TestUnsubscribeControllerTestSuite(t *testing.T) {
+	suite.Run(t, new(unsubscribeControllerTestSuite))
+}
+
+func (suite *unsubscribeControllerTestSuite) SetupTest() {
+	suite.mockCtrl = gomock.NewController(suite.T())
+	suite.recorder = httptest.NewRecorder()
+	suite.context, _ = gin.CreateTestContext(suite.recorder)
+	suite.tokens = unsubscribe.NewTokens("unit-test-secret")
+	suite.preferences = mocks.NewMockPreferences(suite.mockCtrl)
+	suite.controller = NewUnsubscribeController(suite.tokens, suite.preferences)
+}
+
+func (suite *unsubscribeControllerTestSuite) TearDownTest() {
+	suite.mockCtrl.Finish()
+}
+
+func (suite unsubscribeControllerTestSuite) TestUnsubscribeOneClick_ShouldOptRecipientOutOfCategory() {
+	suite.context.Request, _ = http.NewRequest("POST", "/", nil)
+	suite.context.Params = gin.Params{{Key: "token", Value: suite.tokens.Issue("someone@gmail.com", "marketing")}}
+	suite.preferences.EXPECT().OptOut(suite.context, "someone@gmail.com", "marketing").Return(nil)
+
+	suite.controller.UnsubscribeOneClick(suite.context)
+
+	suite.Equal(http.StatusOK, suite.recorder.Code)
+	response := http_request_response.UnsubscribeResponse{}
+	_ = json.Unmarshal(suite.recorder.Body.Bytes(), &response)
+	suite.Equal("marketing", response.Category)
+}
+
+func (suite unsubscribeControllerTestSuite) TestUnsubscribeFromLink_ShouldOptRecipientOutAndShowConfirmationPage() {
+	suite.context.Request, _ = http.NewRequest("GET", "/", nil)
+	suite.context.Params = gin.Params{{Key: "token", Value: suite.tokens.Issue("someone@gmail.com", "digest")}}
+	suite.preferences.EXPECT().OptOut(suite.context, "someone@gmail.com", "digest").Return(nil)
+
+	suite.controller.UnsubscribeFromLink(suite.context)
+
+	suite.Equal(http.StatusOK, suite.recorder.Code)
+	suite.Equal(htmlContentType, suite.recorder.Header().Get("Content-Type"))
+	suite.Contains(suite.recorder.Body.String(), "You will no longer receive digest emails")
+}
+
+func (suite unsubscribeControllerTestSuite) TestUnsubscribeOneClick_ShouldRejectForgedToken() {
+	suite.context.Request, _ = http.NewRequest("POST", "/", nil)
+	suite.context.Params = gin.Params{{Key: "token", Value: unsubscribe.NewTokens("guessed-secret").Issue("someone@gmail.com", "marketing")}}
+
+	suite.controller.UnsubscribeOneClick(suite.context)
+
+	suite.Equal(http.StatusBadRequest, suite.recorder.Code)
+	response := golaerror.Error{}
+	_ = json.Unmarshal(suite.recorder.Body.Bytes(), &response)
+	suite.Equal(constants.InvalidUnsubscribeTokenCode, response.ErrorCode)
+}
+
+func (suite unsubscribeControllerTestSuite) TestUnsubscribeOneClick_ShouldRejectEveryTokenWhenNoSecretIsConfigured() {
+	suite.context.Request, _ = http.NewRequest("POST", "/", nil)
+	suite.context.Params = gin.Params{{Key: "token", Value: suite.tokens.Issue("someone@gmail.com", "marketing")}}
+
+	NewUnsubscribeController(nil, suite.preferences).UnsubscribeOneClick(suite.context)
+
+	suite.Equal(http.StatusBadRequest, suite.recorder.Code)
+}
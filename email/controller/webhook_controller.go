@@ -0,0 +1,80 @@
+package controller
+
+import (
+	"ccg-api/constants"
+	"ccg-api/email/event"
+	"ccg-api/email/http_request_response"
+	"ccg-api/email/webhook"
+	"github.com/gin-gonic/gin"
+	"github.com/inclusi-blog/gola-utils/golaerror"
+	"github.com/inclusi-blog/gola-utils/logging"
+	"io/ioutil"
+	"net/http"
+)
+
+const maxWebhookBodySize = 1 << 20
+
+type WebhookController interface {
+	ReceiveEvents(ctx *gin.Context)
+}
+
+type webhookController struct {
+	providers map[string]webhook.Provider
+	recorder  event.Recorder
+}
+
+func NewWebhookController(providers map[string]webhook.Provider, recorder event.Recorder) WebhookController {
+	return webhookController{providers: providers, recorder: recorder}
+}
+
+// ReceiveEvents godoc
+// @Tags Webhook
+// @Summary API for email providers to post delivery events
+// @Description API called by the email provider with delivered, bounced, complaint, open and click events of our messages,
+// @Description Hard bounces and complaints suppress the recipient, delivered, bounced and complaint events update the message status
+// @Accept  json
+// @Produce  json
+// @Param provider path string true "Provider name e.g. brevo"
+// @Param Authorization header string true "Bearer secret configured for the provider's webhook"
+// @Success 200 {object} http_request_response.WebhookResponse
+// @Failure 400 {object} golaerror.Error "If the payload cannot be parsed"
+// @Failure 401 {object} golaerror.Error "If the call cannot be authenticated"
+// @Failure 404 {object} golaerror.Error "If no webhook is configured for the provider"
+// @Router /api/ccg/v1/webhooks/{provider} [post]
+func (controller webhookController) ReceiveEvents(ctx *gin.Context) {
+	// for swagger import
+	_ = golaerror.Error{}
+
+	logger := logging.GetLogger(ctx).WithField("class", "WebhookController").WithField("method", "ReceiveEvents")
+	providerName := ctx.Param("provider")
+	provider, found := controller.providers[providerName]
+	if !found {
+		logger.Warnf("Webhook called for unknown provider %s", providerName)
+		constants.RespondWithGolaError(ctx, &constants.UnknownWebhookProviderError)
+		return
+	}
+
+	body, err := ioutil.ReadAll(http.MaxBytesReader(ctx.Writer, ctx.Request.Body, maxWebhookBodySize))
+	if err != nil {
+		logger.Error("Unable to read webhook payload ", err)
+		constants.RespondWithGolaError(ctx, &constants.PayloadValidationError)
+		return
+	}
+	if !provider.Verify(ctx.Request, body) {
+		logger.Warnf("Rejected webhook call from %s", providerName)
+		constants.RespondWithGolaError(ctx, &constants.InvalidWebhookCredentialsError)
+		return
+	}
+	events, err := provider.Parse(body)
+	if err != nil {
+		logger.Error("Unable to parse webhook payload ", err)
+		constants.RespondWithGolaError(ctx, &constants.PayloadValidationError)
+		return
+	}
+
+	for _, deliveryEvent := range events {
+		controller.recorder.Record(ctx, deliveryEvent)
+	}
+	logger.Infof("Recorded %d event(s) from %s", len(events), providerName)
+	ctx.JSON(http.StatusOK, http_request_response.WebhookResponse{Accepted: len(events)})
+}
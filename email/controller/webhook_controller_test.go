@@ -0,0 +1,95 @@
+package controller
+
+import (
+	"bytes"
+	"ccg-api/constants"
+	"ccg-api/email/event/mocks"
+	"ccg-api/email/http_request_response"
+	"ccg-api/email/models"
+	"ccg-api/email/webhook"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/inclusi-blog/gola-utils/golaerror"
+	"github.com/stretchr/testify/suite"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type webhookControllerTestSuite struct {
+	suite.Suite
+	mockCtrl      *gomock.Controller
+	recorder      *httptest.ResponseRecorder
+	context       *gin.Context
+	eventRecorder *mocks.MockRecorder
+	controller    WebhookController
+}
+
+func TestWebhookControllerTestSuite(t *testing.T) {
+	suite.Run(t, new(webhookControllerTestSuite))
+}
+
+func (suite *webhookControllerTestSuite) SetupTest() {
+	suite.mockCtrl = gomock.NewController(suite.T())
+	suite.recorder = httptest.NewRecorder()
+	suite.context, _ = gin.CreateTestContext(suite.recorder)
+	suite.eventRecorder = mocks.NewMockRecorder(suite.mockCtrl)
+	providers := map[string]webhook.Provider{webhook.BrevoProvider: webhook.NewBrevo("webhook-secret")}
+	suite.controller = NewWebhookController(providers, suite.eventRecorder)
+}
+
+func (suite *webhookControllerTestSuite) TearDownTest() {
+	suite.mockCtrl.Finish()
+}
+
+func (suite webhookControllerTestSuite) TestReceiveEvents_ShouldRecordEveryNormalisedEvent() {
+	body := `[{"event":"delivered","email":"some@gmail.com","message-id":"<first@gola.xyz>"},{"event":"hard_bounce","email":"missing@gmail.com","message-id":"<second@gola.xyz>"}]`
+	suite.context.Request, _ = http.NewRequest("POST", "/", bytes.NewBufferString(body))
+	suite.context.Request.Header.Set("Authorization", "Bearer webhook-secret")
+	suite.context.Params = gin.Params{{Key: "provider", Value: "brevo"}}
+	var recorded []models.DeliveryEventType
+	suite.eventRecorder.EXPECT().Record(suite.context, gomock.Any()).Do(func(ctx *gin.Context, event models.DeliveryEvent) {
+		recorded = append(recorded, event.Type)
+	}).Times(2)
+
+	suite.controller.ReceiveEvents(suite.context)
+
+	suite.Equal(http.StatusOK, suite.recorder.Code)
+	suite.Equal([]models.DeliveryEventType{models.DeliveredEvent, models.HardBounceEvent}, recorded)
+	response := http_request_response.WebhookResponse{}
+	_ = json.Unmarshal(suite.recorder.Body.Bytes(), &response)
+	suite.Equal(2, response.Accepted)
+}
+
+func (suite webhookControllerTestSuite) TestReceiveEvents_ShouldRejectUnauthenticatedCall() {
+	suite.context.Request, _ = http.NewRequest("POST", "/", bytes.NewBufferString(`{"event":"spam","email":"some@gmail.com"}`))
+	suite.context.Request.Header.Set("Authorization", "Bearer guessed")
+	suite.context.Params = gin.Params{{Key: "provider", Value: "brevo"}}
+
+	suite.controller.ReceiveEvents(suite.context)
+
+	suite.Equal(http.StatusUnauthorized, suite.recorder.Code)
+	response := golaerror.Error{}
+	_ = json.Unmarshal(suite.recorder.Body.Bytes(), &response)
+	suite.Equal(constants.InvalidWebhookCredentialsCode, response.ErrorCode)
+}
+
+func (suite webhookControllerTestSuite) TestReceiveEvents_ShouldRespondNotFoundForUnknownProvider() {
+	suite.context.Request, _ = http.NewRequest("POST", "/", bytes.NewBufferString(`{}`))
+	suite.context.Params = gin.Params{{Key: "provider", Value: "mailgun"}}
+
+	suite.controller.ReceiveEvents(suite.context)
+
+	suite.Equal(http.StatusNotFound, suite.recorder.Code)
+}
+
+func (suite webhookControllerTestSuite) TestReceiveEvents_ShouldThrowBadRequestForMalformedPayload() {
+	suite.context.Request, _ = http.NewRequest("POST", "/", bytes.NewBufferString(`{"event":`))
+	suite.context.Request.Header.Set("Authorization", "Bearer webhook-secret")
+	suite.context.Params = gin.Params{{Key: "provider", Value: "brevo"}}
+
+	suite.controller.ReceiveEvents(suite.context)
+
+	suite.Equal(http.StatusBadRequest, suite.recorder.Code)
+}
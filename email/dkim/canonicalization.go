@@ -0,0 +1,83 @@
+package dkim
+
+import (
+	"bytes"
+	"strings"
+)
+
+const crlf = "\r\n"
+
+// splitMessage separates the header block, including its final CRLF, from the body of a CRLF terminated message
+func splitMessage(message []byte) ([]byte, []byte) {
+	separator := []byte(crlf + crlf)
+	index := bytes.Index(message, separator)
+	if index < 0 {
+		return message, nil
+	}
+	return message[:index+len(crlf)], message[index+len(separator):]
+}
+
+// headerFields splits a header block into its (possibly folded) fields, each keeping its trailing CRLF
+func headerFields(header []byte) []string {
+	var fields []string
+	for _, line := range strings.SplitAfter(string(header), crlf) {
+		if len(line) == 0 {
+			continue
+		}
+		if (line[0] == ' ' || line[0] == '\t') && len(fields) > 0 {
+			fields[len(fields)-1] += line
+			continue
+		}
+		fields = append(fields, line)
+	}
+	return fields
+}
+
+func fieldName(field string) string {
+	return strings.TrimSpace(strings.SplitN(field, ":", 2)[0])
+}
+
+// relaxedHeader canonicalizes a header field as per RFC 6376 section 3.4.2
+func relaxedHeader(field string) string {
+	parts := strings.SplitN(field, ":", 2)
+	name := strings.ToLower(strings.TrimSpace(parts[0]))
+	value := ""
+	if len(parts) == 2 {
+		value = parts[1]
+	}
+	value = strings.NewReplacer("\r\n", "", "\n", "").Replace(value)
+	value = strings.TrimSpace(collapseWhitespace(value))
+	return name + ":" + value + crlf
+}
+
+// relaxedBody canonicalizes a body as per RFC 6376 section 3.4.4
+func relaxedBody(body []byte) []byte {
+	lines := strings.Split(string(body), crlf)
+	for index, line := range lines {
+		lines[index] = strings.TrimRight(collapseWhitespace(line), " ")
+	}
+	for len(lines) > 0 && len(lines[len(lines)-1]) == 0 {
+		lines = lines[:len(lines)-1]
+	}
+	if len(lines) == 0 {
+		return nil
+	}
+	return []byte(strings.Join(lines, crlf) + crlf)
+}
+
+func collapseWhitespace(value string) string {
+	var builder strings.Builder
+	inWhitespace := false
+	for _, character := range value {
+		if character == ' ' || character == '\t' {
+			if !inWhitespace {
+				builder.WriteByte(' ')
+			}
+			inWhitespace = true
+			continue
+		}
+		inWhitespace = false
+		builder.WriteRune(character)
+	}
+	return builder.String()
+}
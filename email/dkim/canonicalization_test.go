@@ -0,0 +1,43 @@
+package dkim
+
+import (
+	"github.com/stretchr/testify/suite"
+	"testing"
+)
+
+type canonicalizationTestSuite struct {
+	suite.Suite
+}
+
+func TestCanonicalizationTestSuite(t *testing.T) {
+	suite.Run(t, new(canonicalizationTestSuite))
+}
+
+// examples from RFC 6376 section 3.4.6
+func (suite *canonicalizationTestSuite) TestRelaxedHeader_ShouldLowercaseNameUnfoldAndCompressWhitespace() {
+	fields := headerFields([]byte("A: X\r\nB : Y\t\r\n\tZ  \r\n"))
+
+	suite.Equal([]string{"A: X\r\n", "B : Y\t\r\n\tZ  \r\n"}, fields)
+	suite.Equal("a:X\r\n", relaxedHeader(fields[0]))
+	suite.Equal("b:Y Z\r\n", relaxedHeader(fields[1]))
+}
+
+func (suite *canonicalizationTestSuite) TestRelaxedBody_ShouldCompressWhitespaceAndDropTrailingEmptyLines() {
+	suite.Equal(" C\r\nD E\r\n", string(relaxedBody([]byte(" C \r\nD \t E\r\n\r\n\r\n"))))
+}
+
+func (suite *canonicalizationTestSuite) TestRelaxedBody_ShouldBeEmptyForEmptyBody() {
+	suite.Empty(relaxedBody([]byte("\r\n\r\n")))
+	suite.Empty(relaxedBody(nil))
+}
+
+func (suite *canonicalizationTestSuite) TestRelaxedBody_ShouldAddMissingFinalLineBreak() {
+	suite.Equal("Hello\r\n", string(relaxedBody([]byte("Hello"))))
+}
+
+func (suite *canonicalizationTestSuite) TestSplitMessage_ShouldSeparateHeaderFromBody() {
+	header, body := splitMessage([]byte("From: a@gola.xyz\r\nTo: b@gola.xyz\r\n\r\nHello\r\n"))
+
+	suite.Equal("From: a@gola.xyz\r\nTo: b@gola.xyz\r\n", string(header))
+	suite.Equal("Hello\r\n", string(body))
+}
@@ -0,0 +1,180 @@
+package dkim
+
+import (
+	"ccg-api/configuration"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"time"
+)
+
+// mockgen -source=email/dkim/signer.go -destination=email/dkim/mocks/mock_signer.go -package=mocks
+
+const (
+	SignatureHeader = "DKIM-Signature"
+
+	rsaSha256     = "rsa-sha256"
+	ed25519Sha256 = "ed25519-sha256"
+
+	foldWidth = 72
+)
+
+// signedHeaders are signed whenever the message has them, From is mandatory
+var signedHeaders = []string{
+	"From", "Sender", "Reply-To", "To", "Cc", "Subject", "Date", "Message-ID",
+	"MIME-Version", "Content-Type", "Content-Transfer-Encoding", "List-Id", "List-Unsubscribe", "List-Unsubscribe-Post",
+}
+
+type Signer interface {
+	// Sign prepends a DKIM-Signature to a rendered message, messages from a domain without a key are returned as is
+	Sign(senderDomain string, message []byte) ([]byte, error)
+}
+
+type domainKey struct {
+	selector   string
+	algorithm  string
+	privateKey crypto.Signer
+}
+
+type signer struct {
+	keys map[string]domainKey
+	now  func() time.Time
+}
+
+// NewSigner loads the private key of every configured domain, each of which must be one of the valid sender domains
+func NewSigner(config configuration.Dkim, validDomains []string) (Signer, error) {
+	keys := map[string]domainKey{}
+	for _, key := range config.Keys {
+		domain := strings.ToLower(key.Domain)
+		if !contains(validDomains, domain) {
+			return nil, fmt.Errorf("dkim key configured for %s which is not a valid sender domain", key.Domain)
+		}
+		if len(key.Selector) == 0 {
+			return nil, fmt.Errorf("dkim selector is missing for %s", key.Domain)
+		}
+		privateKey, algorithm, err := loadPrivateKey(key.PrivateKeyPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load dkim private key of %s: %w", key.Domain, err)
+		}
+		keys[domain] = domainKey{selector: key.Selector, algorithm: algorithm, privateKey: privateKey}
+	}
+	return signer{keys: keys, now: time.Now}, nil
+}
+
+func (signer signer) Sign(senderDomain string, message []byte) ([]byte, error) {
+	domain := strings.ToLower(senderDomain)
+	key, ok := signer.keys[domain]
+	if !ok {
+		return message, nil
+	}
+
+	header, body := splitMessage(message)
+	fields := headerFields(header)
+	bodyHash := sha256.Sum256(relaxedBody(body))
+
+	var names []string
+	var canonicalHeaders strings.Builder
+	for _, name := range signedHeaders {
+		if field, found := lastField(fields, name); found {
+			names = append(names, strings.ToLower(name))
+			canonicalHeaders.WriteString(relaxedHeader(field))
+		}
+	}
+	if len(names) == 0 || names[0] != "from" {
+		return nil, errors.New("dkim: message has no From header")
+	}
+
+	signatureField := fmt.Sprintf("%s: v=1; a=%s; c=relaxed/relaxed; d=%s; s=%s; t=%d;\r\n\th=%s;\r\n\tbh=%s;\r\n\tb=",
+		SignatureHeader, key.algorithm, domain, key.selector, signer.now().Unix(),
+		strings.Join(names, ":"), base64.StdEncoding.EncodeToString(bodyHash[:]))
+	canonicalHeaders.WriteString(strings.TrimSuffix(relaxedHeader(signatureField), crlf))
+
+	signature, err := sign(key, []byte(canonicalHeaders.String()))
+	if err != nil {
+		return nil, err
+	}
+
+	signed := make([]byte, 0, len(signatureField)+len(signature)*2+len(message))
+	signed = append(signed, signatureField...)
+	signed = append(signed, fold(base64.StdEncoding.EncodeToString(signature))...)
+	signed = append(signed, crlf...)
+	return append(signed, message...), nil
+}
+
+func sign(key domainKey, data []byte) ([]byte, error) {
+	hash := sha256.Sum256(data)
+	if key.algorithm == ed25519Sha256 {
+		// RFC 8463 signs the SHA-256 digest with PureEdDSA
+		return key.privateKey.Sign(rand.Reader, hash[:], crypto.Hash(0))
+	}
+	return key.privateKey.Sign(rand.Reader, hash[:], crypto.SHA256)
+}
+
+func loadPrivateKey(path string) (crypto.Signer, string, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, "", err
+	}
+	block, _ := pem.Decode(content)
+	if block == nil {
+		return nil, "", errors.New("no PEM block found")
+	}
+
+	var parsedKey interface{}
+	if block.Type == "RSA PRIVATE KEY" {
+		parsedKey, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	} else {
+		parsedKey, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	}
+	if err != nil {
+		return nil, "", err
+	}
+
+	switch privateKey := parsedKey.(type) {
+	case *rsa.PrivateKey:
+		return privateKey, rsaSha256, nil
+	case ed25519.PrivateKey:
+		return privateKey, ed25519Sha256, nil
+	default:
+		return nil, "", fmt.Errorf("unsupported key type %T", parsedKey)
+	}
+}
+
+// lastField picks the bottom most instance of a header, as verifiers do
+func lastField(fields []string, name string) (string, bool) {
+	for index := len(fields) - 1; index >= 0; index-- {
+		if strings.EqualFold(fieldName(fields[index]), name) {
+			return fields[index], true
+		}
+	}
+	return "", false
+}
+
+func fold(value string) string {
+	var builder strings.Builder
+	for len(value) > foldWidth {
+		builder.WriteString(value[:foldWidth])
+		builder.WriteString("\r\n\t")
+		value = value[foldWidth:]
+	}
+	builder.WriteString(value)
+	return builder.String()
+}
+
+func contains(values []string, value string) bool {
+	for _, candidate := range values {
+		if strings.EqualFold(candidate, value) {
+			return true
+		}
+	}
+	return false
+}
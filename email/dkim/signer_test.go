@@ -0,0 +1,178 @@
+package dkim
+
+import (
+	"bytes"
+	"ccg-api/configuration"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"github.com/stretchr/testify/suite"
+	"gopkg.in/gomail.v2"
+	"io/ioutil"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+type signerTestSuite struct {
+	suite.Suite
+	directory string
+}
+
+func TestSignerTestSuite(t *testing.T) {
+	suite.Run(t, new(signerTestSuite))
+}
+
+func (suite *signerTestSuite) SetupTest() {
+	suite.directory, _ = ioutil.TempDir("", "ccg-dkim-unit-test-dir")
+}
+
+func (suite *signerTestSuite) TearDownTest() {
+	_ = os.RemoveAll(suite.directory)
+}
+
+func (suite *signerTestSuite) TestSign_ShouldProduceRsaSha256SignatureVerifiableWithPublicKey() {
+	privateKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	keyPath := suite.writeKey("rsa.pem", "RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(privateKey))
+	signer, err := NewSigner(suite.dkimConfig(keyPath), []string{"gola.xyz"})
+	suite.Nil(err)
+
+	signed, err := signer.Sign("gola.xyz", suite.renderedMessage())
+
+	suite.Nil(err)
+	suite.Nil(verify(signed, &privateKey.PublicKey))
+	suite.Contains(string(signed), "a=rsa-sha256; c=relaxed/relaxed; d=gola.xyz; s=mail2024;")
+}
+
+func (suite *signerTestSuite) TestSign_ShouldProduceEd25519SignatureVerifiableWithPublicKey() {
+	publicKey, privateKey, _ := ed25519.GenerateKey(rand.Reader)
+	pkcs8, _ := x509.MarshalPKCS8PrivateKey(privateKey)
+	keyPath := suite.writeKey("ed25519.pem", "PRIVATE KEY", pkcs8)
+	signer, err := NewSigner(suite.dkimConfig(keyPath), []string{"gola.xyz"})
+	suite.Nil(err)
+
+	signed, err := signer.Sign("GOLA.xyz", suite.renderedMessage())
+
+	suite.Nil(err)
+	suite.Nil(verify(signed, publicKey))
+	suite.Contains(string(signed), "a=ed25519-sha256;")
+}
+
+func (suite *signerTestSuite) TestSign_ShouldFailVerificationIfMessageIsTamperedAfterSigning() {
+	publicKey, privateKey, _ := ed25519.GenerateKey(rand.Reader)
+	pkcs8, _ := x509.MarshalPKCS8PrivateKey(privateKey)
+	signer, _ := NewSigner(suite.dkimConfig(suite.writeKey("ed25519.pem", "PRIVATE KEY", pkcs8)), []string{"gola.xyz"})
+
+	signed, _ := signer.Sign("gola.xyz", suite.renderedMessage())
+
+	suite.NotNil(verify(bytes.Replace(signed, []byte("Hello User!"), []byte("Hello Hacker"), 1), publicKey))
+	suite.NotNil(verify(bytes.Replace(signed, []byte("Subject: Hi!"), []byte("Subject: Hey"), 1), publicKey))
+}
+
+func (suite *signerTestSuite) TestSign_ShouldReturnMessageUnchangedForDomainWithoutKey() {
+	signer, _ := NewSigner(configuration.Dkim{}, []string{"gola.xyz"})
+	message := suite.renderedMessage()
+
+	signed, err := signer.Sign("gola.xyz", message)
+
+	suite.Nil(err)
+	suite.Equal(message, signed)
+}
+
+func (suite *signerTestSuite) TestNewSigner_ShouldReturnErrorForDomainThatIsNotAValidSenderDomain() {
+	_, privateKey, _ := ed25519.GenerateKey(rand.Reader)
+	pkcs8, _ := x509.MarshalPKCS8PrivateKey(privateKey)
+
+	_, err := NewSigner(suite.dkimConfig(suite.writeKey("ed25519.pem", "PRIVATE KEY", pkcs8)), []string{"mensuvadi.com"})
+
+	suite.NotNil(err)
+}
+
+func (suite *signerTestSuite) TestNewSigner_ShouldReturnErrorIfPrivateKeyCannotBeLoaded() {
+	_, err := NewSigner(suite.dkimConfig(path.Join(suite.directory, "missing.pem")), []string{"gola.xyz"})
+	suite.NotNil(err)
+
+	_, err = NewSigner(suite.dkimConfig(suite.writeKey("garbage.pem", "PRIVATE KEY", []byte("garbage"))), []string{"gola.xyz"})
+	suite.NotNil(err)
+}
+
+func (suite *signerTestSuite) dkimConfig(keyPath string) configuration.Dkim {
+	return configuration.Dkim{Keys: []configuration.DkimKey{
+		{Domain: "gola.xyz", Selector: "mail2024", PrivateKeyPath: keyPath},
+	}}
+}
+
+func (suite *signerTestSuite) writeKey(name string, blockType string, der []byte) string {
+	keyPath := path.Join(suite.directory, name)
+	_ = ioutil.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0600)
+	return keyPath
+}
+
+func (suite *signerTestSuite) renderedMessage() []byte {
+	message := gomail.NewMessage()
+	message.SetHeader("From", "gola@gola.xyz")
+	message.SetHeader("To", "first@gmail.com", "second@gmail.com")
+	message.SetHeader("Subject", "Hi!")
+	message.SetBody("text/plain", "Hello User!  \r\n\r\n")
+	var rendered bytes.Buffer
+	_, _ = message.WriteTo(&rendered)
+	return rendered.Bytes()
+}
+
+var signatureValuePattern = regexp.MustCompile(`b=[^;]*$`)
+
+// verify checks the first DKIM-Signature of a message the way a receiving server would
+func verify(message []byte, publicKey crypto.PublicKey) error {
+	header, body := splitMessage(message)
+	fields := headerFields(header)
+	if fieldName(fields[0]) != SignatureHeader {
+		return errors.New("message is not signed")
+	}
+	tags := map[string]string{}
+	for _, tag := range strings.Split(strings.SplitN(fields[0], ":", 2)[1], ";") {
+		parts := strings.SplitN(tag, "=", 2)
+		tags[strings.TrimSpace(parts[0])] = strings.Join(strings.Fields(parts[1]), "")
+	}
+
+	bodyHash := sha256.Sum256(relaxedBody(body))
+	if base64.StdEncoding.EncodeToString(bodyHash[:]) != tags["bh"] {
+		return errors.New("body hash mismatch")
+	}
+
+	var data strings.Builder
+	remaining := fields[1:]
+	for _, name := range strings.Split(tags["h"], ":") {
+		for index := len(remaining) - 1; index >= 0; index-- {
+			if strings.EqualFold(fieldName(remaining[index]), name) {
+				data.WriteString(relaxedHeader(remaining[index]))
+				remaining = append(remaining[:index:index], remaining[index+1:]...)
+				break
+			}
+		}
+	}
+	data.WriteString(strings.TrimSuffix(signatureValuePattern.ReplaceAllString(relaxedHeader(fields[0]), "b="), crlf))
+	hash := sha256.Sum256([]byte(data.String()))
+
+	signature, err := base64.StdEncoding.DecodeString(tags["b"])
+	if err != nil {
+		return err
+	}
+	switch key := publicKey.(type) {
+	case *rsa.PublicKey:
+		return rsa.VerifyPKCS1v15(key, crypto.SHA256, hash[:], signature)
+	case ed25519.PublicKey:
+		if !ed25519.Verify(key, hash[:], signature) {
+			return errors.New("ed25519 signature mismatch")
+		}
+		return nil
+	}
+	return errors.New("unsupported public key")
+}
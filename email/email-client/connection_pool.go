@@ -0,0 +1,252 @@
+package email_client
+
+import (
+	"bytes"
+	"ccg-api/configuration"
+	"errors"
+	"gopkg.in/gomail.v2"
+	"io"
+	"net"
+	"net/textproto"
+	"sync"
+	"time"
+)
+
+const (
+	defaultIdleTimeout       = time.Minute
+	defaultKeepaliveInterval = 20 * time.Second
+	serviceNotAvailableCode  = 421
+)
+
+// ConnectionPool keeps authenticated SMTP sessions open between messages instead of dialing for every one.
+// It is both a GomailDialer and, for wrappers such as the DKIM signing dialer, an SmtpDialer.
+type ConnectionPool interface {
+	GomailDialer
+	SmtpDialer
+	Close()
+}
+
+type idleSession struct {
+	session   SmtpSession
+	idleSince time.Time
+	lastUsed  time.Time
+}
+
+type connectionPool struct {
+	dialer            SmtpSessionDialer
+	maxIdle           int
+	idleTimeout       time.Duration
+	keepaliveInterval time.Duration
+	now               func() time.Time
+
+	mutex sync.Mutex
+	idle  []idleSession
+	stop  chan struct{}
+	once  sync.Once
+}
+
+// NewConnectionPool starts a keepalive loop that NOOPs idle sessions and closes those idle beyond the idle timeout
+func NewConnectionPool(dialer SmtpSessionDialer, config configuration.ConnectionPool) ConnectionPool {
+	pool := newConnectionPool(dialer, config)
+	go pool.keepAlive()
+	return pool
+}
+
+func newConnectionPool(dialer SmtpSessionDialer, config configuration.ConnectionPool) *connectionPool {
+	pool := &connectionPool{
+		dialer:            dialer,
+		maxIdle:           config.MaxIdleConnections,
+		idleTimeout:       time.Duration(config.IdleTimeoutInSeconds) * time.Second,
+		keepaliveInterval: time.Duration(config.KeepaliveIntervalInSeconds) * time.Second,
+		now:               time.Now,
+		stop:              make(chan struct{}),
+	}
+	if pool.idleTimeout <= 0 {
+		pool.idleTimeout = defaultIdleTimeout
+	}
+	if pool.keepaliveInterval <= 0 {
+		pool.keepaliveInterval = defaultKeepaliveInterval
+	}
+	return pool
+}
+
+func (pool *connectionPool) DialAndSend(messages ...*gomail.Message) error {
+	sendCloser, err := pool.Dial()
+	if err != nil {
+		return err
+	}
+	defer sendCloser.Close()
+	return gomail.Send(sendCloser, messages...)
+}
+
+// Dial hands out the most recently used idle session, or a new one when none is idle
+func (pool *connectionPool) Dial() (gomail.SendCloser, error) {
+	if session := pool.takeIdle(); session != nil {
+		return &pooledSendCloser{pool: pool, session: session, reused: true}, nil
+	}
+	session, err := pool.dialer.DialSession()
+	if err != nil {
+		return nil, err
+	}
+	return &pooledSendCloser{pool: pool, session: session}, nil
+}
+
+func (pool *connectionPool) Close() {
+	pool.once.Do(func() {
+		close(pool.stop)
+		pool.mutex.Lock()
+		idle := pool.idle
+		pool.idle = nil
+		pool.mutex.Unlock()
+		for _, idleSession := range idle {
+			_ = idleSession.session.Close()
+		}
+	})
+}
+
+func (pool *connectionPool) takeIdle() SmtpSession {
+	pool.mutex.Lock()
+	defer pool.mutex.Unlock()
+	for len(pool.idle) > 0 {
+		last := pool.idle[len(pool.idle)-1]
+		pool.idle = pool.idle[:len(pool.idle)-1]
+		if pool.now().Sub(last.idleSince) < pool.idleTimeout {
+			return last.session
+		}
+		go last.session.Close()
+	}
+	return nil
+}
+
+func (pool *connectionPool) release(session SmtpSession) {
+	pool.mutex.Lock()
+	if len(pool.idle) < pool.maxIdle {
+		now := pool.now()
+		pool.idle = append(pool.idle, idleSession{session: session, idleSince: now, lastUsed: now})
+		pool.mutex.Unlock()
+		return
+	}
+	pool.mutex.Unlock()
+	_ = session.Close()
+}
+
+func (pool *connectionPool) keepAlive() {
+	ticker := time.NewTicker(pool.keepaliveInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-pool.stop:
+			return
+		case <-ticker.C:
+			pool.probeIdleSessions()
+		}
+	}
+}
+
+// probeIdleSessions closes sessions idle for longer than the idle timeout and NOOPs the rest that have been
+// quiet for a keepalive interval, dropping any the relay has already hung up on
+func (pool *connectionPool) probeIdleSessions() {
+	pool.mutex.Lock()
+	idle := pool.idle
+	pool.idle = nil
+	pool.mutex.Unlock()
+
+	var alive []idleSession
+	for _, idleSession := range idle {
+		now := pool.now()
+		if now.Sub(idleSession.idleSince) >= pool.idleTimeout {
+			_ = idleSession.session.Close()
+			continue
+		}
+		if now.Sub(idleSession.lastUsed) >= pool.keepaliveInterval {
+			if err := idleSession.session.Noop(); err != nil {
+				_ = idleSession.session.Close()
+				continue
+			}
+			idleSession.lastUsed = now
+		}
+		alive = append(alive, idleSession)
+	}
+
+	pool.mutex.Lock()
+	pool.idle = append(alive, pool.idle...)
+	var excess []idleSession
+	if len(pool.idle) > pool.maxIdle {
+		excess = pool.idle[:len(pool.idle)-pool.maxIdle]
+		pool.idle = pool.idle[len(pool.idle)-pool.maxIdle:]
+	}
+	pool.mutex.Unlock()
+	for _, idleSession := range excess {
+		_ = idleSession.session.Close()
+	}
+}
+
+type pooledSendCloser struct {
+	pool         *connectionPool
+	session      SmtpSession
+	reused       bool
+	broken       bool
+	inFailedMail bool
+}
+
+// Send redials once when a reused session turns out to have been dropped by the relay while it sat idle. That is only
+// known for sure when the envelope fails, once DATA has started the relay may have accepted the message and a redial
+// could deliver it twice. The message is rendered up front so that it can be written out again.
+func (sendCloser *pooledSendCloser) Send(from string, to []string, message io.WriterTo) error {
+	if sendCloser.reused {
+		if _, replayable := message.(renderedMessage); !replayable {
+			var rendered bytes.Buffer
+			if _, err := message.WriteTo(&rendered); err != nil {
+				return err
+			}
+			message = renderedMessage(rendered.Bytes())
+		}
+	}
+	err := sendCloser.session.Send(from, to, message)
+	if err != nil && sendCloser.reused && isDeadIdleSession(err) {
+		_ = sendCloser.session.Close()
+		session, dialError := sendCloser.pool.dialer.DialSession()
+		if dialError != nil {
+			sendCloser.broken = true
+			return dialError
+		}
+		sendCloser.session, sendCloser.reused = session, false
+		err = sendCloser.session.Send(from, to, message)
+	}
+	if err != nil {
+		sendCloser.broken = sendCloser.broken || isBrokenSession(err)
+		sendCloser.inFailedMail = true
+	}
+	return err
+}
+
+// Close returns a healthy session to the pool, resetting it first if a transaction was left half done
+func (sendCloser *pooledSendCloser) Close() error {
+	if sendCloser.broken {
+		_ = sendCloser.session.Close()
+		return nil
+	}
+	if sendCloser.inFailedMail {
+		if err := sendCloser.session.Reset(); err != nil {
+			_ = sendCloser.session.Close()
+			return nil
+		}
+	}
+	sendCloser.pool.release(sendCloser.session)
+	return nil
+}
+
+func isDeadIdleSession(err error) bool {
+	var failedEnvelope *envelopeError
+	return errors.As(err, &failedEnvelope) && isBrokenSession(err)
+}
+
+func isBrokenSession(err error) bool {
+	var smtpError *textproto.Error
+	if errors.As(err, &smtpError) {
+		return smtpError.Code == serviceNotAvailableCode
+	}
+	var netError net.Error
+	return errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) || errors.As(err, &netError) ||
+		errors.Is(err, net.ErrClosed)
+}
@@ -0,0 +1,255 @@
+package email_client
+
+import (
+	"bytes"
+	"ccg-api/configuration"
+	mocksigner "ccg-api/email/dkim/mocks"
+	"errors"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/suite"
+	"gopkg.in/gomail.v2"
+	"io"
+	"net/textproto"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type fakeSmtpSession struct {
+	mutex     sync.Mutex
+	sendError error
+	noopError error
+	sent      []string
+	messages  [][]byte
+	noops     int
+	resets    int
+	closed    bool
+}
+
+func (session *fakeSmtpSession) Send(from string, to []string, message io.WriterTo) error {
+	session.mutex.Lock()
+	defer session.mutex.Unlock()
+	if session.sendError != nil {
+		return session.sendError
+	}
+	var rendered bytes.Buffer
+	_, _ = message.WriteTo(&rendered)
+	session.sent = append(session.sent, from)
+	session.messages = append(session.messages, rendered.Bytes())
+	return nil
+}
+
+func (session *fakeSmtpSession) Noop() error {
+	session.noops++
+	return session.noopError
+}
+
+func (session *fakeSmtpSession) Reset() error {
+	session.resets++
+	return nil
+}
+
+func (session *fakeSmtpSession) Close() error {
+	session.mutex.Lock()
+	defer session.mutex.Unlock()
+	session.closed = true
+	return nil
+}
+
+func (session *fakeSmtpSession) isClosed() bool {
+	session.mutex.Lock()
+	defer session.mutex.Unlock()
+	return session.closed
+}
+
+type fakeSmtpSessionDialer struct {
+	sessions []*fakeSmtpSession
+	dialed   int
+}
+
+func (dialer *fakeSmtpSessionDialer) DialSession() (SmtpSession, error) {
+	if dialer.dialed >= len(dialer.sessions) {
+		return nil, errors.New("dial tcp: connection refused")
+	}
+	session := dialer.sessions[dialer.dialed]
+	dialer.dialed++
+	return session, nil
+}
+
+type connectionPoolTestSuite struct {
+	suite.Suite
+	dialer *fakeSmtpSessionDialer
+	now    time.Time
+}
+
+func TestConnectionPoolTestSuite(t *testing.T) {
+	suite.Run(t, new(connectionPoolTestSuite))
+}
+
+func (suite *connectionPoolTestSuite) SetupTest() {
+	suite.dialer = &fakeSmtpSessionDialer{sessions: []*fakeSmtpSession{{}, {}}}
+	suite.now = time.Date(2020, 9, 20, 10, 0, 0, 0, time.UTC)
+}
+
+func (suite *connectionPoolTestSuite) newPool(maxIdle int) *connectionPool {
+	pool := newConnectionPool(suite.dialer, configuration.ConnectionPool{
+		MaxIdleConnections:         maxIdle,
+		IdleTimeoutInSeconds:       60,
+		KeepaliveIntervalInSeconds: 20,
+	})
+	pool.now = func() time.Time { return suite.now }
+	return pool
+}
+
+func (suite *connectionPoolTestSuite) message() *gomail.Message {
+	message := gomail.NewMessage()
+	message.SetHeader("From", "gola@gola.xyz")
+	message.SetHeader("To", "someone@gmail.com")
+	message.SetBody("text/plain", "Hello User!")
+	return message
+}
+
+func (suite *connectionPoolTestSuite) TestDialAndSend_ShouldReuseIdleSessionForNextMessage() {
+	pool := suite.newPool(2)
+
+	suite.Nil(pool.DialAndSend(suite.message()))
+	suite.Nil(pool.DialAndSend(suite.message()))
+
+	suite.Equal(1, suite.dialer.dialed)
+	suite.Equal([]string{"gola@gola.xyz", "gola@gola.xyz"}, suite.dialer.sessions[0].sent)
+	suite.False(suite.dialer.sessions[0].isClosed())
+}
+
+func (suite *connectionPoolTestSuite) TestDial_ShouldCloseSessionsBeyondMaxIdleConnections() {
+	pool := suite.newPool(1)
+
+	first, _ := pool.Dial()
+	second, _ := pool.Dial()
+	_ = first.Close()
+	_ = second.Close()
+
+	suite.Equal(2, suite.dialer.dialed)
+	suite.False(suite.dialer.sessions[0].isClosed())
+	suite.True(suite.dialer.sessions[1].isClosed())
+}
+
+func (suite *connectionPoolTestSuite) TestDial_ShouldNotHandOutSessionIdleBeyondIdleTimeout() {
+	pool := suite.newPool(2)
+	suite.Nil(pool.DialAndSend(suite.message()))
+
+	suite.now = suite.now.Add(time.Minute)
+	suite.Nil(pool.DialAndSend(suite.message()))
+
+	suite.Equal(2, suite.dialer.dialed)
+	suite.Eventually(suite.dialer.sessions[0].isClosed, time.Second, 10*time.Millisecond)
+	suite.Equal([]string{"gola@gola.xyz"}, suite.dialer.sessions[1].sent)
+}
+
+func (suite *connectionPoolTestSuite) TestDialAndSend_ShouldReconnectTransparentlyIfRelayDroppedIdleSession() {
+	pool := suite.newPool(2)
+	suite.Nil(pool.DialAndSend(suite.message()))
+	suite.dialer.sessions[0].sendError = &envelopeError{err: io.EOF}
+
+	suite.Nil(pool.DialAndSend(suite.message()))
+
+	suite.True(suite.dialer.sessions[0].isClosed())
+	suite.Equal([]string{"gola@gola.xyz"}, suite.dialer.sessions[1].sent)
+	suite.Equal([]SmtpSession{suite.dialer.sessions[1]}, idleSessions(pool))
+}
+
+func (suite *connectionPoolTestSuite) TestDialAndSend_ShouldNotRedialIfSessionBreaksAfterEnvelopeWasAccepted() {
+	pool := suite.newPool(2)
+	suite.Nil(pool.DialAndSend(suite.message()))
+	suite.dialer.sessions[0].sendError = io.EOF
+
+	err := pool.DialAndSend(suite.message())
+
+	suite.EqualError(err, "gomail: could not send email 1: EOF")
+	suite.Equal(1, suite.dialer.dialed)
+	suite.True(suite.dialer.sessions[0].isClosed())
+	suite.Empty(idleSessions(pool))
+}
+
+func (suite *connectionPoolTestSuite) TestDialAndSend_ShouldResubmitWholeSignedMessageAfterRedialingDroppedSession() {
+	mockCtrl := gomock.NewController(suite.T())
+	defer mockCtrl.Finish()
+	signer := mocksigner.NewMockSigner(mockCtrl)
+	signer.EXPECT().Sign("gola.xyz", gomock.Any()).DoAndReturn(func(domain string, message []byte) ([]byte, error) {
+		return append([]byte("DKIM-Signature: v=1; d=gola.xyz\r\n"), message...), nil
+	}).Times(2)
+	pool := suite.newPool(2)
+	signingDialer := NewDkimSigningDialer(pool, signer)
+	suite.Nil(signingDialer.DialAndSend(suite.message()))
+	suite.dialer.sessions[0].sendError = &envelopeError{err: io.EOF}
+
+	suite.Nil(signingDialer.DialAndSend(suite.message()))
+
+	suite.Equal(2, suite.dialer.dialed)
+	resubmitted := string(suite.dialer.sessions[1].messages[0])
+	suite.True(strings.HasPrefix(resubmitted, "DKIM-Signature: v=1; d=gola.xyz\r\n"))
+	suite.True(strings.HasSuffix(resubmitted, "Hello User!"))
+}
+
+func (suite *connectionPoolTestSuite) TestDialAndSend_ShouldNotRetryFreshSessionThatBreaks() {
+	pool := suite.newPool(2)
+	suite.dialer.sessions[0].sendError = io.EOF
+
+	err := pool.DialAndSend(suite.message())
+
+	suite.NotNil(err)
+	suite.Equal(1, suite.dialer.dialed)
+	suite.True(suite.dialer.sessions[0].isClosed())
+	suite.Empty(idleSessions(pool))
+}
+
+func (suite *connectionPoolTestSuite) TestDialAndSend_ShouldResetSessionRejectedMidTransactionBeforePoolingIt() {
+	pool := suite.newPool(2)
+	suite.dialer.sessions[0].sendError = &textproto.Error{Code: 550, Msg: "5.1.1 mailbox unavailable"}
+
+	err := pool.DialAndSend(suite.message())
+
+	suite.NotNil(err)
+	suite.Equal(1, suite.dialer.sessions[0].resets)
+	suite.Equal([]SmtpSession{suite.dialer.sessions[0]}, idleSessions(pool))
+}
+
+func (suite *connectionPoolTestSuite) TestProbeIdleSessions_ShouldNoopQuietSessionsAndDropDeadOrExpiredOnes() {
+	pool := suite.newPool(3)
+	suite.dialer.sessions = []*fakeSmtpSession{{}, {noopError: io.EOF}, {}}
+	expired, dead, alive := suite.dialer.sessions[0], suite.dialer.sessions[1], suite.dialer.sessions[2]
+	pool.idle = []idleSession{
+		{session: expired, idleSince: suite.now.Add(-time.Minute), lastUsed: suite.now.Add(-time.Minute)},
+		{session: dead, idleSince: suite.now.Add(-30 * time.Second), lastUsed: suite.now.Add(-30 * time.Second)},
+		{session: alive, idleSince: suite.now.Add(-30 * time.Second), lastUsed: suite.now.Add(-30 * time.Second)},
+	}
+
+	pool.probeIdleSessions()
+
+	suite.True(expired.isClosed())
+	suite.Equal(0, expired.noops)
+	suite.True(dead.isClosed())
+	suite.Equal(1, alive.noops)
+	suite.False(alive.isClosed())
+	suite.Equal([]SmtpSession{alive}, idleSessions(pool))
+	suite.Equal(suite.now, pool.idle[0].lastUsed)
+}
+
+func (suite *connectionPoolTestSuite) TestClose_ShouldCloseIdleSessions() {
+	pool := suite.newPool(2)
+	suite.Nil(pool.DialAndSend(suite.message()))
+
+	pool.Close()
+	pool.Close()
+
+	suite.True(suite.dialer.sessions[0].isClosed())
+	suite.Empty(idleSessions(pool))
+}
+
+func idleSessions(pool *connectionPool) []SmtpSession {
+	var sessions []SmtpSession
+	for _, idleSession := range pool.idle {
+		sessions = append(sessions, idleSession.session)
+	}
+	return sessions
+}
@@ -0,0 +1,67 @@
+package email_client
+
+import (
+	"bytes"
+	"ccg-api/email/dkim"
+	"gopkg.in/gomail.v2"
+	"io"
+	"strings"
+)
+
+// mockgen -source=email/email-client/dkim_signing_dialer.go -destination=email/email-client/mocks/mock_smtp_dialer.go -package=mocks
+type SmtpDialer interface {
+	Dial() (gomail.SendCloser, error)
+}
+
+type dkimSigningDialer struct {
+	dialer SmtpDialer
+	signer dkim.Signer
+}
+
+// NewDkimSigningDialer signs every fully rendered message with the key of its sender domain before submitting it
+func NewDkimSigningDialer(dialer SmtpDialer, signer dkim.Signer) GomailDialer {
+	return dkimSigningDialer{dialer: dialer, signer: signer}
+}
+
+func (signingDialer dkimSigningDialer) DialAndSend(messages ...*gomail.Message) error {
+	sendCloser, err := signingDialer.dialer.Dial()
+	if err != nil {
+		return err
+	}
+	defer sendCloser.Close()
+	return gomail.Send(dkimSigningSender{sender: sendCloser, signer: signingDialer.signer}, messages...)
+}
+
+type dkimSigningSender struct {
+	sender gomail.Sender
+	signer dkim.Signer
+}
+
+func (signingSender dkimSigningSender) Send(from string, to []string, message io.WriterTo) error {
+	signed, err := render(message, from, signingSender.signer)
+	if err != nil {
+		return err
+	}
+	return signingSender.sender.Send(from, to, renderedMessage(signed))
+}
+
+// renderedMessage can be written out any number of times, unlike a bytes.Buffer it is not drained by WriteTo,
+// so a sender that redials can submit it again
+type renderedMessage []byte
+
+func (message renderedMessage) WriteTo(writer io.Writer) (int64, error) {
+	written, err := writer.Write(message)
+	return int64(written), err
+}
+
+// render writes out the message, signed with the key of the sender domain when there is a signer
+func render(message io.WriterTo, from string, signer dkim.Signer) ([]byte, error) {
+	var rendered bytes.Buffer
+	if _, err := message.WriteTo(&rendered); err != nil {
+		return nil, err
+	}
+	if signer == nil {
+		return rendered.Bytes(), nil
+	}
+	return signer.Sign(from[strings.LastIndex(from, "@")+1:], rendered.Bytes())
+}
@@ -0,0 +1,101 @@
+package email_client
+
+import (
+	"bytes"
+	mocksigner "ccg-api/email/dkim/mocks"
+	mockemailclient "ccg-api/email/email-client/mocks"
+	"errors"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/suite"
+	"gopkg.in/gomail.v2"
+	"io"
+	"testing"
+)
+
+type recordingSendCloser struct {
+	from    string
+	to      []string
+	message []byte
+	closed  bool
+}
+
+func (sendCloser *recordingSendCloser) Send(from string, to []string, message io.WriterTo) error {
+	var rendered bytes.Buffer
+	_, _ = message.WriteTo(&rendered)
+	sendCloser.from, sendCloser.to, sendCloser.message = from, to, rendered.Bytes()
+	return nil
+}
+
+func (sendCloser *recordingSendCloser) Close() error {
+	sendCloser.closed = true
+	return nil
+}
+
+type dkimSigningDialerTestSuite struct {
+	suite.Suite
+	mockCtrl   *gomock.Controller
+	smtpDialer *mockemailclient.MockSmtpDialer
+	signer     *mocksigner.MockSigner
+}
+
+func TestDkimSigningDialerTestSuite(t *testing.T) {
+	suite.Run(t, new(dkimSigningDialerTestSuite))
+}
+
+func (suite *dkimSigningDialerTestSuite) SetupTest() {
+	suite.mockCtrl = gomock.NewController(suite.T())
+	suite.smtpDialer = mockemailclient.NewMockSmtpDialer(suite.mockCtrl)
+	suite.signer = mocksigner.NewMockSigner(suite.mockCtrl)
+}
+
+func (suite *dkimSigningDialerTestSuite) TearDownTest() {
+	suite.mockCtrl.Finish()
+}
+
+func (suite *dkimSigningDialerTestSuite) TestDialAndSend_ShouldSubmitRenderedMessageSignedWithSenderDomainKey() {
+	message := gomail.NewMessage()
+	message.SetHeader("From", "gola@gola.xyz")
+	message.SetHeader("To", "someone@gmail.com")
+	message.SetHeader("Bcc", "hidden@gmail.com")
+	message.SetBody("text/plain", "Hello User!")
+	sendCloser := &recordingSendCloser{}
+
+	suite.smtpDialer.EXPECT().Dial().Return(sendCloser, nil)
+	suite.signer.EXPECT().Sign("gola.xyz", gomock.Any()).DoAndReturn(func(domain string, rendered []byte) ([]byte, error) {
+		suite.Contains(string(rendered), "Hello User!")
+		suite.NotContains(string(rendered), "hidden@gmail.com")
+		return append([]byte("DKIM-Signature: v=1\r\n"), rendered...), nil
+	})
+
+	err := NewDkimSigningDialer(suite.smtpDialer, suite.signer).DialAndSend(message)
+
+	suite.Nil(err)
+	suite.Equal("gola@gola.xyz", sendCloser.from)
+	suite.Equal([]string{"someone@gmail.com", "hidden@gmail.com"}, sendCloser.to)
+	suite.True(bytes.HasPrefix(sendCloser.message, []byte("DKIM-Signature: v=1\r\n")))
+	suite.True(sendCloser.closed)
+}
+
+func (suite *dkimSigningDialerTestSuite) TestDialAndSend_ShouldNotSubmitMessageIfSigningFails() {
+	message := gomail.NewMessage()
+	message.SetHeader("From", "gola@gola.xyz")
+	message.SetHeader("To", "someone@gmail.com")
+	sendCloser := &recordingSendCloser{}
+
+	suite.smtpDialer.EXPECT().Dial().Return(sendCloser, nil)
+	suite.signer.EXPECT().Sign("gola.xyz", gomock.Any()).Return(nil, errors.New("dkim: message has no From header"))
+
+	err := NewDkimSigningDialer(suite.smtpDialer, suite.signer).DialAndSend(message)
+
+	suite.NotNil(err)
+	suite.Nil(sendCloser.message)
+	suite.True(sendCloser.closed)
+}
+
+func (suite *dkimSigningDialerTestSuite) TestDialAndSend_ShouldReturnErrorIfUnableToConnect() {
+	suite.smtpDialer.EXPECT().Dial().Return(nil, errors.New("failed to connect SMTP server"))
+
+	err := NewDkimSigningDialer(suite.smtpDialer, suite.signer).DialAndSend(gomail.NewMessage())
+
+	suite.NotNil(err)
+}
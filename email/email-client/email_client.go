@@ -15,16 +15,16 @@ type EmailClient interface {
 
 type emailClient struct {
 	tempProcessingDir string
-	gomailDialer      GomailDialer
+	transport         Transport
 }
 
-func NewEmailClient(tempDir string, dialer GomailDialer) EmailClient {
+func NewEmailClient(tempDir string, transport Transport) EmailClient {
 	logger := logging.NewLoggerEntry()
 	dirCreationError := os.MkdirAll(tempDir, 0755)
 	if dirCreationError != nil {
 		logger.Warn("Failed to create directory", dirCreationError)
 	}
-	client := emailClient{tempProcessingDir: tempDir, gomailDialer: dialer}
+	client := emailClient{tempProcessingDir: tempDir, transport: transport}
 	return &client
 }
 
@@ -43,5 +43,5 @@ func (emailClient *emailClient) Send(ctx *gin.Context, emailRequest *email_clien
 
 	message, _ := emailRequest.ToMessage(nil, tmpDirToUseForCurrentRequest)
 
-	return emailClient.gomailDialer.DialAndSend(message)
+	return emailClient.transport.Deliver(ctx, emailRequest, message)
 }
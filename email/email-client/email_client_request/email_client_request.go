@@ -7,23 +7,70 @@ import (
 	"gopkg.in/gomail.v2"
 	"io/ioutil"
 	"path"
+	"strings"
 )
 
+// CategoryHeader lets mailbox providers and downstream filters tell marketing mail from transactional mail
+const CategoryHeader = "X-Category"
+
 type EmailClientRequest struct {
+	MessageID   string
 	From        string
+	FromName    string
 	To          []string
+	Cc          []string
+	Bcc         []string
+	ReplyTo     string
+	Headers     map[string]string
 	Subject     string
 	Body        models.MessageBody
 	Attachments []models.Attachment
+	Category    string
+	// UnsubscribeUrl is advertised for one-click unsubscribe as per RFC 8058 when set
+	UnsubscribeUrl string
+	// Tenant picks the SMTP relays of the tenant, when it has its own
+	Tenant string
 }
 
 func (request EmailClientRequest) ToMessage(ctx *gin.Context, tempAttachmentDir string) (*gomail.Message, error) {
 	gomailMessage := gomail.NewMessage()
-	gomailMessage.SetHeader("From", request.From)
+	if request.FromName != "" {
+		gomailMessage.SetAddressHeader("From", request.From, request.FromName)
+	} else {
+		gomailMessage.SetHeader("From", request.From)
+	}
+	if request.MessageID != "" {
+		gomailMessage.SetHeader("Message-ID", request.messageIDHeader())
+	}
 	gomailMessage.SetHeaders(map[string][]string{"To": request.To})
+	if len(request.Cc) > 0 {
+		gomailMessage.SetHeader("Cc", request.Cc...)
+	}
+	// gomail uses Bcc only for the envelope and never writes it into the message
+	if len(request.Bcc) > 0 {
+		gomailMessage.SetHeader("Bcc", request.Bcc...)
+	}
+	if request.ReplyTo != "" {
+		gomailMessage.SetHeader("Reply-To", request.ReplyTo)
+	}
+	for name, value := range request.Headers {
+		gomailMessage.SetHeader(name, value)
+	}
+	if request.Category != "" {
+		gomailMessage.SetHeader(CategoryHeader, request.Category)
+	}
+	if request.UnsubscribeUrl != "" {
+		gomailMessage.SetHeader("List-Unsubscribe", "<"+request.UnsubscribeUrl+">")
+		gomailMessage.SetHeader("List-Unsubscribe-Post", "List-Unsubscribe=One-Click")
+	}
 
 	gomailMessage.SetHeader("Subject", request.Subject)
-	gomailMessage.SetBody(request.Body.MimeType, request.Body.Content)
+	if request.Body.PlainText != "" && request.Body.MimeType == "text/html" {
+		gomailMessage.SetBody("text/plain", request.Body.PlainText)
+		gomailMessage.AddAlternative(request.Body.MimeType, request.Body.Content)
+	} else {
+		gomailMessage.SetBody(request.Body.MimeType, request.Body.Content)
+	}
 
 	for _, attachment := range request.Attachments {
 		if err := request.addAttachment(ctx, tempAttachmentDir, attachment, gomailMessage); err != nil {
@@ -33,6 +80,21 @@ func (request EmailClientRequest) ToMessage(ctx *gin.Context, tempAttachmentDir
 	return gomailMessage, nil
 }
 
+// messageIDHeader scopes our message id to the sender domain so that bounces and provider events can be correlated back to it
+func (request EmailClientRequest) messageIDHeader() string {
+	domain := request.From[strings.LastIndex(request.From, "@")+1:]
+	return "<" + request.MessageID + "@" + domain + ">"
+}
+
+// MessageIDFromHeader reverts messageIDHeader, giving back our message id from the Message-ID quoted in bounces and provider events
+func MessageIDFromHeader(header string) string {
+	messageID := strings.Trim(strings.TrimSpace(header), "<>")
+	if at := strings.LastIndex(messageID, "@"); at >= 0 {
+		messageID = messageID[:at]
+	}
+	return messageID
+}
+
 func (request EmailClientRequest) addAttachment(
 	ctx *gin.Context,
 	tempAttachmentDir string,
@@ -44,6 +106,10 @@ func (request EmailClientRequest) addAttachment(
 		logging.GetLogger(ctx).Error("Failed to create file to send attachment:", fileCreationError)
 		return fileCreationError
 	}
+	if attachment.Inline {
+		gomailMessage.Embed(filePath, gomail.SetHeader(map[string][]string{"Content-ID": {"<" + attachment.ContentID + ">"}}))
+		return nil
+	}
 	gomailMessage.Attach(filePath)
 	return nil
 }
@@ -1,6 +1,7 @@
 package email_client_request
 
 import (
+	"bytes"
 	"ccg-api/email/email-client/test_helper"
 	"ccg-api/email/models"
 	"github.com/gin-gonic/gin"
@@ -9,6 +10,7 @@ import (
 	"net/http/httptest"
 	"os"
 	"path"
+	"strings"
 	"testing"
 )
 
@@ -89,6 +91,199 @@ func (suite *emailClientRequestTestSuite) TestToMessage_ShouldReturnErrorIfUnabl
 	suite.NotNil(err)
 }
 
+func (suite *emailClientRequestTestSuite) TestToMessage_ShouldSetMessageIdScopedToSenderDomain() {
+	emailClientRequest := EmailClientRequest{
+		MessageID: "9b2f6c1e-3f0a-4a8e-8f5e-2d7c1b0a9e11",
+		From:      "gola@gola.xyz",
+		To:        []string{"first@gmail.com"},
+		Subject:   "Hi!",
+		Body: models.MessageBody{
+			MimeType: "text/plain",
+			Content:  "Hello User!",
+		},
+	}
+
+	actualMessage, err := emailClientRequest.ToMessage(suite.ctx, os.TempDir())
+	suite.Nil(err)
+
+	suite.Equal([]string{"<9b2f6c1e-3f0a-4a8e-8f5e-2d7c1b0a9e11@gola.xyz>"}, actualMessage.GetHeader("Message-ID"))
+}
+
+func (suite *emailClientRequestTestSuite) TestToMessage_ShouldAdvertiseOneClickUnsubscribe() {
+	emailClientRequest := EmailClientRequest{
+		From:           "gola@gola.xyz",
+		To:             []string{"first@gmail.com"},
+		Subject:        "This week on gola",
+		UnsubscribeUrl: "https://ccg.gola.xyz/api/ccg/v1/unsubscribe/some-token",
+		Body: models.MessageBody{
+			MimeType: "text/plain",
+			Content:  "Hello User!",
+		},
+	}
+
+	actualMessage, err := emailClientRequest.ToMessage(suite.ctx, os.TempDir())
+	suite.Nil(err)
+
+	suite.Equal([]string{"<https://ccg.gola.xyz/api/ccg/v1/unsubscribe/some-token>"}, actualMessage.GetHeader("List-Unsubscribe"))
+	suite.Equal([]string{"List-Unsubscribe=One-Click"}, actualMessage.GetHeader("List-Unsubscribe-Post"))
+}
+
+func (suite *emailClientRequestTestSuite) TestToMessage_ShouldSetCategoryHeader() {
+	emailClientRequest := EmailClientRequest{
+		From:     "gola@gola.xyz",
+		To:       []string{"first@gmail.com"},
+		Subject:  "Your password was changed",
+		Category: "security",
+		Body: models.MessageBody{
+			MimeType: "text/plain",
+			Content:  "Hello User!",
+		},
+	}
+
+	actualMessage, err := emailClientRequest.ToMessage(suite.ctx, os.TempDir())
+	suite.Nil(err)
+
+	suite.Equal([]string{"security"}, actualMessage.GetHeader("X-Category"))
+}
+
+func (suite *emailClientRequestTestSuite) TestToMessage_ShouldEncodeDisplayNameOfSender() {
+	emailClientRequest := EmailClientRequest{
+		From:     "gola@gola.xyz",
+		FromName: "Gola Support",
+		To:       []string{"first@gmail.com"},
+		Subject:  "Hi",
+		Body: models.MessageBody{
+			MimeType: "text/plain",
+			Content:  "Hello User!",
+		},
+	}
+
+	actualMessage, err := emailClientRequest.ToMessage(suite.ctx, os.TempDir())
+	suite.Nil(err)
+
+	suite.Equal([]string{`"Gola Support" <gola@gola.xyz>`}, actualMessage.GetHeader("From"))
+}
+
+func (suite *emailClientRequestTestSuite) TestToMessage_ShouldEncodeNonAsciiDisplayNameOfSender() {
+	emailClientRequest := EmailClientRequest{
+		From:     "gola@gola.xyz",
+		FromName: "Zoë from Gola",
+		To:       []string{"first@gmail.com"},
+		Subject:  "Hi",
+		Body: models.MessageBody{
+			MimeType: "text/plain",
+			Content:  "Hello User!",
+		},
+	}
+
+	actualMessage, err := emailClientRequest.ToMessage(suite.ctx, os.TempDir())
+	suite.Nil(err)
+
+	suite.Equal([]string{"=?UTF-8?q?Zo=C3=AB_from_Gola?= <gola@gola.xyz>"}, actualMessage.GetHeader("From"))
+}
+
+func (suite *emailClientRequestTestSuite) TestToMessage_ShouldNotAdvertiseUnsubscribeWithoutUrl() {
+	emailClientRequest := EmailClientRequest{
+		From:    "gola@gola.xyz",
+		To:      []string{"first@gmail.com"},
+		Subject: "Your password was changed",
+		Body: models.MessageBody{
+			MimeType: "text/plain",
+			Content:  "Hello User!",
+		},
+	}
+
+	actualMessage, err := emailClientRequest.ToMessage(suite.ctx, os.TempDir())
+	suite.Nil(err)
+
+	suite.Empty(actualMessage.GetHeader("List-Unsubscribe"))
+	suite.Empty(actualMessage.GetHeader("List-Unsubscribe-Post"))
+}
+
+func (suite *emailClientRequestTestSuite) TestToMessage_ShouldSetCcReplyToAndCustomHeadersButNeverRenderBcc() {
+	emailClientRequest := EmailClientRequest{
+		From:    "gola@gola.xyz",
+		To:      []string{"first@gmail.com"},
+		Cc:      []string{"cc@gmail.com"},
+		Bcc:     []string{"hidden@gmail.com"},
+		ReplyTo: "support@gola.xyz",
+		Headers: map[string]string{"X-Entity-Ref-ID": "order-42"},
+		Subject: "Hi!",
+		Body: models.MessageBody{
+			MimeType: "text/plain",
+			Content:  "Hello User!",
+		},
+	}
+
+	actualMessage, err := emailClientRequest.ToMessage(suite.ctx, os.TempDir())
+	suite.Nil(err)
+
+	suite.Equal([]string{"cc@gmail.com"}, actualMessage.GetHeader("Cc"))
+	suite.Equal([]string{"hidden@gmail.com"}, actualMessage.GetHeader("Bcc"))
+	suite.Equal([]string{"support@gola.xyz"}, actualMessage.GetHeader("Reply-To"))
+	suite.Equal([]string{"order-42"}, actualMessage.GetHeader("X-Entity-Ref-ID"))
+
+	var renderedMessage bytes.Buffer
+	_, _ = actualMessage.WriteTo(&renderedMessage)
+	suite.Contains(renderedMessage.String(), "Cc: cc@gmail.com")
+	suite.NotContains(renderedMessage.String(), "hidden@gmail.com")
+}
+
+func (suite *emailClientRequestTestSuite) TestToMessage_ShouldEmbedInlineAttachmentsWithContentId() {
+	emailClientRequest := EmailClientRequest{
+		From:    "gola@gola.xyz",
+		To:      []string{"first@gmail.com"},
+		Subject: "Hi!",
+		Body: models.MessageBody{
+			MimeType: "text/html",
+			Content:  `<img src="cid:logo@gola.xyz">`,
+		},
+		Attachments: []models.Attachment{
+			{
+				FileName:  "logo.png",
+				Data:      []byte("Logo Data"),
+				Inline:    true,
+				ContentID: "logo@gola.xyz",
+			},
+		},
+	}
+
+	tempAttachmentDir := path.Join(os.TempDir(), "email-send-unit-test-read-write-dir-for-inline-attachments")
+	_ = os.Mkdir(tempAttachmentDir, 0777)
+	defer os.RemoveAll(tempAttachmentDir)
+
+	actualMessage, err := emailClientRequest.ToMessage(suite.ctx, tempAttachmentDir)
+	suite.Nil(err)
+
+	var renderedMessage bytes.Buffer
+	_, _ = actualMessage.WriteTo(&renderedMessage)
+	suite.Contains(renderedMessage.String(), "multipart/related")
+	suite.Contains(renderedMessage.String(), "Content-ID: <logo@gola.xyz>")
+	suite.Contains(renderedMessage.String(), `Content-Disposition: inline; filename="logo.png"`)
+	suite.NotContains(renderedMessage.String(), "multipart/mixed")
+}
+
+func (suite *emailClientRequestTestSuite) TestToMessage_ShouldSendPlainTextAsAlternativeOfHtml() {
+	emailClientRequest := EmailClientRequest{
+		From:    "gola@gola.xyz",
+		To:      []string{"first@gmail.com"},
+		Subject: "Hi!",
+		Body: models.MessageBody{
+			MimeType:  "text/html",
+			Content:   "<p>Hello User!</p>",
+			PlainText: "Hello User!",
+		},
+	}
+
+	actualMessage, err := emailClientRequest.ToMessage(suite.ctx, os.TempDir())
+	suite.Nil(err)
+
+	var renderedMessage bytes.Buffer
+	_, _ = actualMessage.WriteTo(&renderedMessage)
+	suite.Contains(renderedMessage.String(), "multipart/alternative")
+	suite.Less(strings.Index(renderedMessage.String(), "text/plain"), strings.Index(renderedMessage.String(), "text/html"))
+}
+
 func expectedMessageContentForEmailClientRequestTest() string {
 	return `Mime-Version: 1.0
 Date: Sun, 23 Feb 2020 00:34:14 +0530
@@ -51,7 +51,7 @@ func (suite *emailClientTestSuite) TestSend_ShouldReturnErrorIfUnableToSendEmail
 	_ = os.MkdirAll(tempDirForAttachingFiles, 0755)
 	defer os.RemoveAll(tempDirForAttachingFiles)
 
-	emailClient := NewEmailClient(tempDirForAttachingFiles, suite.gomailDialer)
+	emailClient := NewEmailClient(tempDirForAttachingFiles, NewSmtpTransport(suite.gomailDialer))
 	logging.NewLoggerEntry().Debug("Temp dir for attachments: ", tempDirForAttachingFiles)
 
 	suite.gomailDialer.EXPECT().DialAndSend(gomock.Any()).Return(errors.New("failed to connect SMTP server"))
@@ -87,7 +87,7 @@ func (suite *emailClientTestSuite) TestSend_ShouldSuccessfullySendEmailUsingGoma
 	_ = os.MkdirAll(tempDirForAttachingFiles, 0755)
 	defer os.RemoveAll(tempDirForAttachingFiles)
 
-	emailClient := NewEmailClient(tempDirForAttachingFiles, suite.gomailDialer)
+	emailClient := NewEmailClient(tempDirForAttachingFiles, NewSmtpTransport(suite.gomailDialer))
 	logging.NewLoggerEntry().Debug("Temp dir for attachments: ", tempDirForAttachingFiles)
 
 	var actualFromEmails []string
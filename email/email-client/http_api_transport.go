@@ -0,0 +1,162 @@
+package email_client
+
+import (
+	"bytes"
+	"ccg-api/configuration"
+	"ccg-api/email/email-client/email_client_request"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"gopkg.in/gomail.v2"
+	"io/ioutil"
+	"net/http"
+	"time"
+)
+
+const apiKeyHeader = "api-key"
+
+var renderedHeaders = []string{"Message-ID", email_client_request.CategoryHeader, "List-Unsubscribe", "List-Unsubscribe-Post"}
+
+type ApiError struct {
+	StatusCode int
+	Body       string
+}
+
+func (err ApiError) Error() string {
+	return fmt.Sprintf("email api responded with status %d: %s", err.StatusCode, err.Body)
+}
+
+// Permanent treats client errors as final, except for timeouts and throttling
+func (err ApiError) Permanent() bool {
+	return err.StatusCode >= 400 && err.StatusCode < 500 &&
+		err.StatusCode != http.StatusRequestTimeout && err.StatusCode != http.StatusTooManyRequests
+}
+
+// InlineAttachmentsError is permanent, the API has no field for the Content-ID that the html body refers to inline parts by
+type InlineAttachmentsError struct{}
+
+func (err InlineAttachmentsError) Error() string {
+	return "inline attachments cannot be sent through the http_api transport"
+}
+
+func (err InlineAttachmentsError) Permanent() bool {
+	return true
+}
+
+type apiAddress struct {
+	Email string `json:"email"`
+	Name  string `json:"name,omitempty"`
+}
+
+type apiAttachment struct {
+	Name    string `json:"name"`
+	Content string `json:"content"`
+}
+
+// apiEmail is the transactional email payload of Sendinblue/Brevo style APIs
+type apiEmail struct {
+	Sender      apiAddress        `json:"sender"`
+	To          []apiAddress      `json:"to"`
+	Cc          []apiAddress      `json:"cc,omitempty"`
+	Bcc         []apiAddress      `json:"bcc,omitempty"`
+	ReplyTo     *apiAddress       `json:"replyTo,omitempty"`
+	Subject     string            `json:"subject"`
+	HtmlContent string            `json:"htmlContent,omitempty"`
+	TextContent string            `json:"textContent,omitempty"`
+	Attachments []apiAttachment   `json:"attachment,omitempty"`
+	Headers     map[string]string `json:"headers,omitempty"`
+	Tags        []string          `json:"tags,omitempty"`
+}
+
+type httpApiTransport struct {
+	url        string
+	apiKey     string
+	httpClient *http.Client
+}
+
+func NewHttpApiTransport(config configuration.HttpApiTransport, apiKey string) Transport {
+	return httpApiTransport{
+		url:        config.Url,
+		apiKey:     apiKey,
+		httpClient: &http.Client{Timeout: time.Duration(config.TimeoutInSeconds) * time.Second},
+	}
+}
+
+// Deliver refuses messages with inline parts rather than sending them as plain attachments that the body cannot show
+func (transport httpApiTransport) Deliver(ctx *gin.Context, request *email_client_request.EmailClientRequest, message *gomail.Message) error {
+	for _, attachment := range request.Attachments {
+		if attachment.Inline {
+			return InlineAttachmentsError{}
+		}
+	}
+	payload, err := json.Marshal(buildApiEmail(request, message))
+	if err != nil {
+		return err
+	}
+	httpRequest, err := http.NewRequestWithContext(ctx, http.MethodPost, transport.url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	httpRequest.Header.Set("Content-Type", "application/json")
+	httpRequest.Header.Set("Accept", "application/json")
+	httpRequest.Header.Set(apiKeyHeader, transport.apiKey)
+
+	response, err := transport.httpClient.Do(httpRequest)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode >= 200 && response.StatusCode < 300 {
+		return nil
+	}
+	body, _ := ioutil.ReadAll(response.Body)
+	return ApiError{StatusCode: response.StatusCode, Body: string(body)}
+}
+
+func buildApiEmail(request *email_client_request.EmailClientRequest, message *gomail.Message) apiEmail {
+	email := apiEmail{
+		Sender:  apiAddress{Email: request.From, Name: request.FromName},
+		To:      apiAddresses(request.To),
+		Cc:      apiAddresses(request.Cc),
+		Bcc:     apiAddresses(request.Bcc),
+		Subject: request.Subject,
+		Headers: map[string]string{},
+	}
+	if request.ReplyTo != "" {
+		email.ReplyTo = &apiAddress{Email: request.ReplyTo}
+	}
+	if request.Category != "" {
+		email.Tags = []string{request.Category}
+	}
+	if request.Body.MimeType == "text/html" {
+		email.HtmlContent = request.Body.Content
+		email.TextContent = request.Body.PlainText
+	} else {
+		email.TextContent = request.Body.Content
+	}
+	for _, attachment := range request.Attachments {
+		email.Attachments = append(email.Attachments, apiAttachment{
+			Name:    attachment.FileName,
+			Content: base64.StdEncoding.EncodeToString(attachment.Data),
+		})
+	}
+	for name, value := range request.Headers {
+		email.Headers[name] = value
+	}
+	// keep the headers rendered for the message, the Message-ID so that provider events still correlate
+	for _, name := range renderedHeaders {
+		if value := message.GetHeader(name); len(value) > 0 {
+			email.Headers[name] = value[0]
+		}
+	}
+	return email
+}
+
+func apiAddresses(emails []string) []apiAddress {
+	var addresses []apiAddress
+	for _, email := range emails {
+		addresses = append(addresses, apiAddress{Email: email})
+	}
+	return addresses
+}
@@ -0,0 +1,176 @@
+package email_client
+
+import (
+	"ccg-api/configuration"
+	"ccg-api/email/email-client/email_client_request"
+	"ccg-api/email/models"
+	"ccg-api/email/retry"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/suite"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+)
+
+type httpApiTransportTestSuite struct {
+	suite.Suite
+	context *gin.Context
+	request email_client_request.EmailClientRequest
+}
+
+func TestHttpApiTransportTestSuite(t *testing.T) {
+	suite.Run(t, new(httpApiTransportTestSuite))
+}
+
+func (suite *httpApiTransportTestSuite) SetupTest() {
+	suite.context, _ = gin.CreateTestContext(httptest.NewRecorder())
+	suite.context.Request, _ = http.NewRequest("POST", "/", nil)
+	suite.request = email_client_request.EmailClientRequest{
+		MessageID: "some-message-id",
+		From:      "gola@gola.xyz",
+		To:        []string{"someone@gmail.com"},
+		Bcc:       []string{"hidden@gmail.com"},
+		ReplyTo:   "support@gola.xyz",
+		Headers:   map[string]string{"X-Entity-Ref-ID": "order-42"},
+		Subject:   "Hi!",
+		Body: models.MessageBody{
+			MimeType:  "text/html",
+			Content:   "<p>Hello User!</p>",
+			PlainText: "Hello User!",
+		},
+		Attachments: []models.Attachment{
+			{FileName: "invoice.pdf", Data: []byte("Invoice Data")},
+		},
+	}
+}
+
+func (suite *httpApiTransportTestSuite) TestDeliver_ShouldPostEmailAsJsonWithApiKey() {
+	var received map[string]interface{}
+	var apiKey string
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		apiKey = request.Header.Get("api-key")
+		body, _ := ioutil.ReadAll(request.Body)
+		_ = json.Unmarshal(body, &received)
+		writer.WriteHeader(http.StatusCreated)
+		_, _ = writer.Write([]byte(`{"messageId":"<provider-id@smtp-relay.mailin.fr>"}`))
+	}))
+	defer server.Close()
+
+	transport := NewHttpApiTransport(configuration.HttpApiTransport{Url: server.URL, TimeoutInSeconds: 5}, "secret-key")
+	message, _ := suite.request.ToMessage(suite.context, os.TempDir())
+
+	err := transport.Deliver(suite.context, &suite.request, message)
+
+	suite.Nil(err)
+	suite.Equal("secret-key", apiKey)
+	suite.Equal(map[string]interface{}{"email": "gola@gola.xyz"}, received["sender"])
+	suite.Equal([]interface{}{map[string]interface{}{"email": "someone@gmail.com"}}, received["to"])
+	suite.Equal([]interface{}{map[string]interface{}{"email": "hidden@gmail.com"}}, received["bcc"])
+	suite.Nil(received["cc"])
+	suite.Equal(map[string]interface{}{"email": "support@gola.xyz"}, received["replyTo"])
+	suite.Equal("Hi!", received["subject"])
+	suite.Equal("<p>Hello User!</p>", received["htmlContent"])
+	suite.Equal("Hello User!", received["textContent"])
+	suite.Equal([]interface{}{map[string]interface{}{"name": "invoice.pdf", "content": "SW52b2ljZSBEYXRh"}}, received["attachment"])
+	suite.Equal(map[string]interface{}{
+		"X-Entity-Ref-ID": "order-42",
+		"Message-ID":      "<some-message-id@gola.xyz>",
+	}, received["headers"])
+}
+
+func (suite *httpApiTransportTestSuite) TestDeliver_ShouldTagCategoryAndKeepUnsubscribeHeaders() {
+	var received map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		body, _ := ioutil.ReadAll(request.Body)
+		_ = json.Unmarshal(body, &received)
+		writer.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+	suite.request.Category = "marketing"
+	suite.request.UnsubscribeUrl = "https://ccg.gola.xyz/api/ccg/v1/unsubscribe/token"
+
+	transport := NewHttpApiTransport(configuration.HttpApiTransport{Url: server.URL}, "secret-key")
+	message, _ := suite.request.ToMessage(suite.context, os.TempDir())
+
+	suite.Nil(transport.Deliver(suite.context, &suite.request, message))
+	suite.Equal([]interface{}{"marketing"}, received["tags"])
+	suite.Equal(map[string]interface{}{
+		"X-Entity-Ref-ID":       "order-42",
+		"Message-ID":            "<some-message-id@gola.xyz>",
+		"X-Category":            "marketing",
+		"List-Unsubscribe":      "<https://ccg.gola.xyz/api/ccg/v1/unsubscribe/token>",
+		"List-Unsubscribe-Post": "List-Unsubscribe=One-Click",
+	}, received["headers"])
+}
+
+func (suite *httpApiTransportTestSuite) TestDeliver_ShouldSendPlainTextBodyAsTextContent() {
+	var received map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		body, _ := ioutil.ReadAll(request.Body)
+		_ = json.Unmarshal(body, &received)
+		writer.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+	suite.request.Body = models.MessageBody{MimeType: "text/plain", Content: "Hello User!"}
+
+	transport := NewHttpApiTransport(configuration.HttpApiTransport{Url: server.URL}, "secret-key")
+	message, _ := suite.request.ToMessage(suite.context, os.TempDir())
+
+	suite.Nil(transport.Deliver(suite.context, &suite.request, message))
+	suite.Equal("Hello User!", received["textContent"])
+	suite.Nil(received["htmlContent"])
+}
+
+func (suite *httpApiTransportTestSuite) TestDeliver_ShouldReturnPermanentErrorIfApiRejectsEmail() {
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		writer.WriteHeader(http.StatusBadRequest)
+		_, _ = writer.Write([]byte(`{"code":"invalid_parameter","message":"sender is not valid"}`))
+	}))
+	defer server.Close()
+
+	transport := NewHttpApiTransport(configuration.HttpApiTransport{Url: server.URL}, "secret-key")
+	message, _ := suite.request.ToMessage(suite.context, os.TempDir())
+
+	err := transport.Deliver(suite.context, &suite.request, message)
+
+	suite.Equal(ApiError{StatusCode: 400, Body: `{"code":"invalid_parameter","message":"sender is not valid"}`}, err)
+	suite.Equal(retry.Permanent, retry.Classify(err))
+}
+
+func (suite *httpApiTransportTestSuite) TestDeliver_ShouldReturnTransientErrorIfApiIsThrottlingOrUnavailable() {
+	statusCode := http.StatusTooManyRequests
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		writer.WriteHeader(statusCode)
+	}))
+	defer server.Close()
+
+	transport := NewHttpApiTransport(configuration.HttpApiTransport{Url: server.URL}, "secret-key")
+	message, _ := suite.request.ToMessage(suite.context, os.TempDir())
+
+	suite.Equal(retry.Transient, retry.Classify(transport.Deliver(suite.context, &suite.request, message)))
+	statusCode = http.StatusServiceUnavailable
+	suite.Equal(retry.Transient, retry.Classify(transport.Deliver(suite.context, &suite.request, message)))
+}
+
+func (suite *httpApiTransportTestSuite) TestDeliver_ShouldRefuseInlineAttachmentsAsPermanentError() {
+	posted := false
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		posted = true
+		writer.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+	suite.request.Attachments = append(suite.request.Attachments,
+		models.Attachment{FileName: "logo.png", Data: []byte("Logo Data"), Inline: true, ContentID: "logo@gola.xyz"})
+
+	transport := NewHttpApiTransport(configuration.HttpApiTransport{Url: server.URL}, "secret-key")
+	message, _ := suite.request.ToMessage(suite.context, os.TempDir())
+
+	err := transport.Deliver(suite.context, &suite.request, message)
+
+	suite.Equal(InlineAttachmentsError{}, err)
+	suite.Equal(retry.Permanent, retry.Classify(err))
+	suite.False(posted)
+}
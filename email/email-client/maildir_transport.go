@@ -0,0 +1,58 @@
+package email_client
+
+import (
+	"ccg-api/email/dkim"
+	"ccg-api/email/email-client/email_client_request"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gopkg.in/gomail.v2"
+	"os"
+	"path"
+	"time"
+)
+
+type maildirTransport struct {
+	directory string
+	hostname  string
+	signer    dkim.Signer
+}
+
+// NewMaildirTransport drops every message into a local maildir instead of sending it, meant for development.
+// Messages are DKIM signed when there is a signer, as they would be over SMTP.
+func NewMaildirTransport(directory string, signer dkim.Signer) (Transport, error) {
+	for _, subDirectory := range []string{"tmp", "new", "cur"} {
+		if err := os.MkdirAll(path.Join(directory, subDirectory), 0755); err != nil {
+			return nil, err
+		}
+	}
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "localhost"
+	}
+	return maildirTransport{directory: directory, hostname: hostname, signer: signer}, nil
+}
+
+// Deliver writes into tmp and then moves the file into new, so that readers never see a partial message
+func (transport maildirTransport) Deliver(ctx *gin.Context, request *email_client_request.EmailClientRequest, message *gomail.Message) error {
+	content, err := render(message, request.From, transport.signer)
+	if err != nil {
+		return err
+	}
+	fileName := fmt.Sprintf("%d.%s.%s", time.Now().UnixNano(), uuid.New().String(), transport.hostname)
+	tempPath := path.Join(transport.directory, "tmp", fileName)
+	file, err := os.OpenFile(tempPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err = file.Write(content); err != nil {
+		_ = file.Close()
+		_ = os.Remove(tempPath)
+		return err
+	}
+	if err = file.Close(); err != nil {
+		_ = os.Remove(tempPath)
+		return err
+	}
+	return os.Rename(tempPath, path.Join(transport.directory, "new", fileName))
+}
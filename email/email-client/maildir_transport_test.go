@@ -0,0 +1,95 @@
+package email_client
+
+import (
+	mocksigner "ccg-api/email/dkim/mocks"
+	"ccg-api/email/email-client/email_client_request"
+	"ccg-api/email/models"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/suite"
+	"io/ioutil"
+	"net/http/httptest"
+	"os"
+	"path"
+	"strings"
+	"testing"
+)
+
+type maildirTransportTestSuite struct {
+	suite.Suite
+	context   *gin.Context
+	directory string
+}
+
+func TestMaildirTransportTestSuite(t *testing.T) {
+	suite.Run(t, new(maildirTransportTestSuite))
+}
+
+func (suite *maildirTransportTestSuite) SetupTest() {
+	suite.context, _ = gin.CreateTestContext(httptest.NewRecorder())
+	suite.directory = path.Join(os.TempDir(), "ccg-maildir-unit-test-dir")
+	_ = os.RemoveAll(suite.directory)
+}
+
+func (suite *maildirTransportTestSuite) TearDownTest() {
+	_ = os.RemoveAll(suite.directory)
+}
+
+func (suite *maildirTransportTestSuite) TestDeliver_ShouldWriteRenderedMessageIntoNewOfMaildir() {
+	request := email_client_request.EmailClientRequest{
+		From:    "gola@gola.xyz",
+		To:      []string{"someone@gmail.com"},
+		Subject: "Hi!",
+		Body: models.MessageBody{
+			MimeType: "text/plain",
+			Content:  "Hello User!",
+		},
+	}
+	message, _ := request.ToMessage(suite.context, os.TempDir())
+	transport, err := NewMaildirTransport(suite.directory, nil)
+	suite.Nil(err)
+
+	suite.Nil(transport.Deliver(suite.context, &request, message))
+	suite.Nil(transport.Deliver(suite.context, &request, message))
+
+	newMessages, _ := ioutil.ReadDir(path.Join(suite.directory, "new"))
+	suite.Len(newMessages, 2)
+	tempMessages, _ := ioutil.ReadDir(path.Join(suite.directory, "tmp"))
+	suite.Empty(tempMessages)
+	content, _ := ioutil.ReadFile(path.Join(suite.directory, "new", newMessages[0].Name()))
+	suite.Contains(string(content), "Subject: Hi!")
+	suite.Contains(string(content), "Hello User!")
+}
+
+func (suite *maildirTransportTestSuite) TestDeliver_ShouldWriteMessageSignedWithSenderDomainKey() {
+	mockCtrl := gomock.NewController(suite.T())
+	defer mockCtrl.Finish()
+	signer := mocksigner.NewMockSigner(mockCtrl)
+	request := email_client_request.EmailClientRequest{
+		From:    "gola@gola.xyz",
+		To:      []string{"someone@gmail.com"},
+		Subject: "Hi!",
+		Body:    models.MessageBody{MimeType: "text/plain", Content: "Hello User!"},
+	}
+	message, _ := request.ToMessage(suite.context, os.TempDir())
+	signer.EXPECT().Sign("gola.xyz", gomock.Any()).DoAndReturn(func(domain string, rendered []byte) ([]byte, error) {
+		return append([]byte("DKIM-Signature: v=1; d=gola.xyz\r\n"), rendered...), nil
+	})
+	transport, _ := NewMaildirTransport(suite.directory, signer)
+
+	suite.Nil(transport.Deliver(suite.context, &request, message))
+
+	newMessages, _ := ioutil.ReadDir(path.Join(suite.directory, "new"))
+	content, _ := ioutil.ReadFile(path.Join(suite.directory, "new", newMessages[0].Name()))
+	suite.True(strings.HasPrefix(string(content), "DKIM-Signature: v=1; d=gola.xyz"))
+	suite.Contains(string(content), "Hello User!")
+}
+
+func (suite *maildirTransportTestSuite) TestNewMaildirTransport_ShouldReturnErrorIfDirectoryCannotBeCreated() {
+	_ = os.MkdirAll(path.Dir(suite.directory), 0755)
+	_ = ioutil.WriteFile(suite.directory, []byte("not a directory"), 0644)
+
+	_, err := NewMaildirTransport(suite.directory, nil)
+
+	suite.NotNil(err)
+}
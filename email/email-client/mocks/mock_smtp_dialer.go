@@ -0,0 +1,49 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: email/email-client/dkim_signing_dialer.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	gomock "github.com/golang/mock/gomock"
+	gomail "gopkg.in/gomail.v2"
+	reflect "reflect"
+)
+
+// MockSmtpDialer is a mock of SmtpDialer interface
+type MockSmtpDialer struct {
+	ctrl     *gomock.Controller
+	recorder *MockSmtpDialerMockRecorder
+}
+
+// MockSmtpDialerMockRecorder is the mock recorder for MockSmtpDialer
+type MockSmtpDialerMockRecorder struct {
+	mock *MockSmtpDialer
+}
+
+// NewMockSmtpDialer creates a new mock instance
+func NewMockSmtpDialer(ctrl *gomock.Controller) *MockSmtpDialer {
+	mock := &MockSmtpDialer{ctrl: ctrl}
+	mock.recorder = &MockSmtpDialerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockSmtpDialer) EXPECT() *MockSmtpDialerMockRecorder {
+	return m.recorder
+}
+
+// Dial mocks base method
+func (m *MockSmtpDialer) Dial() (gomail.SendCloser, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Dial")
+	ret0, _ := ret[0].(gomail.SendCloser)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Dial indicates an expected call of Dial
+func (mr *MockSmtpDialerMockRecorder) Dial() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Dial", reflect.TypeOf((*MockSmtpDialer)(nil).Dial))
+}
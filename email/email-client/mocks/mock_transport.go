@@ -0,0 +1,50 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: email/email-client/transport.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	email_client_request "ccg-api/email/email-client/email_client_request"
+	gin "github.com/gin-gonic/gin"
+	gomock "github.com/golang/mock/gomock"
+	gomail "gopkg.in/gomail.v2"
+	reflect "reflect"
+)
+
+// MockTransport is a mock of Transport interface
+type MockTransport struct {
+	ctrl     *gomock.Controller
+	recorder *MockTransportMockRecorder
+}
+
+// MockTransportMockRecorder is the mock recorder for MockTransport
+type MockTransportMockRecorder struct {
+	mock *MockTransport
+}
+
+// NewMockTransport creates a new mock instance
+func NewMockTransport(ctrl *gomock.Controller) *MockTransport {
+	mock := &MockTransport{ctrl: ctrl}
+	mock.recorder = &MockTransportMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockTransport) EXPECT() *MockTransportMockRecorder {
+	return m.recorder
+}
+
+// Deliver mocks base method
+func (m *MockTransport) Deliver(ctx *gin.Context, request *email_client_request.EmailClientRequest, message *gomail.Message) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Deliver", ctx, request, message)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Deliver indicates an expected call of Deliver
+func (mr *MockTransportMockRecorder) Deliver(ctx, request, message interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Deliver", reflect.TypeOf((*MockTransport)(nil).Deliver), ctx, request, message)
+}
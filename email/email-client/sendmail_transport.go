@@ -0,0 +1,66 @@
+package email_client
+
+import (
+	"bytes"
+	"ccg-api/email/dkim"
+	"ccg-api/email/email-client/email_client_request"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"gopkg.in/gomail.v2"
+	"os/exec"
+	"strings"
+)
+
+// sysexits codes for which sendmail will never accept the message
+var permanentSendmailExitCodes = map[int]bool{
+	65: true, // EX_DATAERR
+	67: true, // EX_NOUSER
+	68: true, // EX_NOHOST
+}
+
+type SendmailError struct {
+	ExitCode int
+	Output   string
+}
+
+func (err SendmailError) Error() string {
+	return fmt.Sprintf("sendmail exited with status %d: %s", err.ExitCode, err.Output)
+}
+
+func (err SendmailError) Permanent() bool {
+	return permanentSendmailExitCodes[err.ExitCode]
+}
+
+type sendmailTransport struct {
+	path   string
+	args   []string
+	signer dkim.Signer
+}
+
+// NewSendmailTransport pipes messages into a local sendmail compatible binary, DKIM signed when there is a signer
+func NewSendmailTransport(path string, args []string, signer dkim.Signer) Transport {
+	return sendmailTransport{path: path, args: args, signer: signer}
+}
+
+func (transport sendmailTransport) Deliver(ctx *gin.Context, request *email_client_request.EmailClientRequest, message *gomail.Message) error {
+	args := append(append([]string{}, transport.args...), "-i", "-f", request.From, "--")
+	args = append(append(append(args, request.To...), request.Cc...), request.Bcc...)
+
+	input, err := render(message, request.From, transport.signer)
+	if err != nil {
+		return err
+	}
+	var output bytes.Buffer
+	command := exec.CommandContext(ctx, transport.path, args...)
+	command.Stdin = bytes.NewReader(input)
+	command.Stdout = &output
+	command.Stderr = &output
+
+	err = command.Run()
+	var exitError *exec.ExitError
+	if errors.As(err, &exitError) {
+		return SendmailError{ExitCode: exitError.ExitCode(), Output: strings.TrimSpace(output.String())}
+	}
+	return err
+}
@@ -0,0 +1,104 @@
+package email_client
+
+import (
+	mocksigner "ccg-api/email/dkim/mocks"
+	"ccg-api/email/email-client/email_client_request"
+	"ccg-api/email/models"
+	"ccg-api/email/retry"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/suite"
+	"io/ioutil"
+	"net/http/httptest"
+	"os"
+	"path"
+	"strings"
+	"testing"
+)
+
+type sendmailTransportTestSuite struct {
+	suite.Suite
+	context   *gin.Context
+	directory string
+	request   email_client_request.EmailClientRequest
+}
+
+func TestSendmailTransportTestSuite(t *testing.T) {
+	suite.Run(t, new(sendmailTransportTestSuite))
+}
+
+func (suite *sendmailTransportTestSuite) SetupTest() {
+	suite.context, _ = gin.CreateTestContext(httptest.NewRecorder())
+	suite.directory, _ = ioutil.TempDir("", "ccg-sendmail-unit-test-dir")
+	suite.request = email_client_request.EmailClientRequest{
+		From:    "gola@gola.xyz",
+		To:      []string{"someone@gmail.com"},
+		Cc:      []string{"cc@gmail.com"},
+		Bcc:     []string{"hidden@gmail.com"},
+		Subject: "Hi!",
+		Body: models.MessageBody{
+			MimeType: "text/plain",
+			Content:  "Hello User!",
+		},
+	}
+}
+
+func (suite *sendmailTransportTestSuite) TearDownTest() {
+	_ = os.RemoveAll(suite.directory)
+}
+
+func (suite *sendmailTransportTestSuite) TestDeliver_ShouldPipeMessageToSendmailWithEnvelope() {
+	sendmail := suite.fakeSendmail(`echo "$@" > "$(dirname "$0")/args"; cat > "$(dirname "$0")/message"`)
+	message, _ := suite.request.ToMessage(suite.context, os.TempDir())
+
+	err := NewSendmailTransport(sendmail, []string{"-oi"}, nil).Deliver(suite.context, &suite.request, message)
+
+	suite.Nil(err)
+	args, _ := ioutil.ReadFile(path.Join(suite.directory, "args"))
+	suite.Equal("-oi -i -f gola@gola.xyz -- someone@gmail.com cc@gmail.com hidden@gmail.com", strings.TrimSpace(string(args)))
+	content, _ := ioutil.ReadFile(path.Join(suite.directory, "message"))
+	suite.Contains(string(content), "Subject: Hi!")
+	suite.NotContains(string(content), "hidden@gmail.com")
+}
+
+func (suite *sendmailTransportTestSuite) TestDeliver_ShouldNotRunSendmailIfSigningFails() {
+	mockCtrl := gomock.NewController(suite.T())
+	defer mockCtrl.Finish()
+	signer := mocksigner.NewMockSigner(mockCtrl)
+	signer.EXPECT().Sign("gola.xyz", gomock.Any()).Return(nil, errors.New("no dkim key for domain gola.xyz"))
+	sendmail := suite.fakeSendmail(`cat > "$(dirname "$0")/message"`)
+	message, _ := suite.request.ToMessage(suite.context, os.TempDir())
+
+	err := NewSendmailTransport(sendmail, nil, signer).Deliver(suite.context, &suite.request, message)
+
+	suite.EqualError(err, "no dkim key for domain gola.xyz")
+	_, statError := os.Stat(path.Join(suite.directory, "message"))
+	suite.True(os.IsNotExist(statError))
+}
+
+func (suite *sendmailTransportTestSuite) TestDeliver_ShouldReturnPermanentErrorIfSendmailRejectsRecipient() {
+	sendmail := suite.fakeSendmail(`cat > /dev/null; echo "someone@gmail.com... User unknown" >&2; exit 67`)
+	message, _ := suite.request.ToMessage(suite.context, os.TempDir())
+
+	err := NewSendmailTransport(sendmail, nil, nil).Deliver(suite.context, &suite.request, message)
+
+	suite.Equal(SendmailError{ExitCode: 67, Output: "someone@gmail.com... User unknown"}, err)
+	suite.Equal(retry.Permanent, retry.Classify(err))
+}
+
+func (suite *sendmailTransportTestSuite) TestDeliver_ShouldReturnTransientErrorIfSendmailAsksToTryLater() {
+	sendmail := suite.fakeSendmail(`cat > /dev/null; exit 75`)
+	message, _ := suite.request.ToMessage(suite.context, os.TempDir())
+
+	err := NewSendmailTransport(sendmail, nil, nil).Deliver(suite.context, &suite.request, message)
+
+	suite.NotNil(err)
+	suite.Equal(retry.Transient, retry.Classify(err))
+}
+
+func (suite *sendmailTransportTestSuite) fakeSendmail(script string) string {
+	sendmail := path.Join(suite.directory, "sendmail")
+	_ = ioutil.WriteFile(sendmail, []byte("#!/bin/sh\n"+script+"\n"), 0755)
+	return sendmail
+}
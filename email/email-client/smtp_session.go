@@ -0,0 +1,180 @@
+package email_client
+
+import (
+	"bytes"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/smtp"
+	"strings"
+	"time"
+)
+
+// SmtpSession is an authenticated SMTP session that can carry several messages, unlike the gomail.SendCloser
+// it can be probed with NOOP and brought back out of a failed transaction with RSET
+type SmtpSession interface {
+	Send(from string, to []string, message io.WriterTo) error
+	Noop() error
+	Reset() error
+	Close() error
+}
+
+type SmtpSessionDialer interface {
+	DialSession() (SmtpSession, error)
+}
+
+const (
+	smtpsPort         = 465
+	smtpDialTimeout   = 10 * time.Second
+	smtpCommandWindow = 5 * time.Minute
+)
+
+type smtpSessionDialer struct {
+	host      string
+	port      int
+	username  string
+	password  string
+	tlsConfig *tls.Config
+}
+
+// NewSmtpSessionDialer opens sessions the way gomail.Dialer does: implicit TLS on 465, STARTTLS when offered
+// and CRAM-MD5, LOGIN or PLAIN authentication when a username is set
+func NewSmtpSessionDialer(host string, port int, username string, password string, tlsConfig *tls.Config) SmtpSessionDialer {
+	return smtpSessionDialer{host: host, port: port, username: username, password: password, tlsConfig: tlsConfig}
+}
+
+func (dialer smtpSessionDialer) DialSession() (SmtpSession, error) {
+	conn, err := net.DialTimeout("tcp", fmt.Sprintf("%s:%d", dialer.host, dialer.port), smtpDialTimeout)
+	if err != nil {
+		return nil, err
+	}
+	if dialer.port == smtpsPort {
+		conn = tls.Client(conn, dialer.tlsConfigForHost())
+	}
+
+	client, err := smtp.NewClient(conn, dialer.host)
+	if err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	if dialer.port != smtpsPort {
+		if ok, _ := client.Extension("STARTTLS"); ok {
+			if err := client.StartTLS(dialer.tlsConfigForHost()); err != nil {
+				_ = client.Close()
+				return nil, err
+			}
+		}
+	}
+	if dialer.username != "" {
+		if ok, mechanisms := client.Extension("AUTH"); ok {
+			if err := client.Auth(dialer.auth(mechanisms)); err != nil {
+				_ = client.Close()
+				return nil, err
+			}
+		}
+	}
+	return &smtpSession{client: client, conn: conn}, nil
+}
+
+func (dialer smtpSessionDialer) tlsConfigForHost() *tls.Config {
+	if dialer.tlsConfig == nil {
+		return &tls.Config{ServerName: dialer.host}
+	}
+	return dialer.tlsConfig
+}
+
+func (dialer smtpSessionDialer) auth(mechanisms string) smtp.Auth {
+	if strings.Contains(mechanisms, "CRAM-MD5") {
+		return smtp.CRAMMD5Auth(dialer.username, dialer.password)
+	}
+	if strings.Contains(mechanisms, "LOGIN") && !strings.Contains(mechanisms, "PLAIN") {
+		return loginAuth{username: dialer.username, password: dialer.password}
+	}
+	return smtp.PlainAuth("", dialer.username, dialer.password, dialer.host)
+}
+
+type smtpSession struct {
+	client *smtp.Client
+	conn   net.Conn
+}
+
+func (session *smtpSession) Send(from string, to []string, message io.WriterTo) error {
+	// a deadline per message keeps a half dead relay from blocking the caller forever
+	_ = session.conn.SetDeadline(time.Now().Add(smtpCommandWindow))
+	if err := session.client.Mail(from); err != nil {
+		return &envelopeError{err: err}
+	}
+	for _, address := range to {
+		if err := session.client.Rcpt(address); err != nil {
+			return &envelopeError{err: err}
+		}
+	}
+	writer, err := session.client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err = message.WriteTo(writer); err != nil {
+		_ = writer.Close()
+		return err
+	}
+	return writer.Close()
+}
+
+func (session *smtpSession) Noop() error {
+	_ = session.conn.SetDeadline(time.Now().Add(smtpDialTimeout))
+	return session.client.Noop()
+}
+
+func (session *smtpSession) Reset() error {
+	_ = session.conn.SetDeadline(time.Now().Add(smtpDialTimeout))
+	return session.client.Reset()
+}
+
+func (session *smtpSession) Close() error {
+	_ = session.conn.SetDeadline(time.Now().Add(smtpDialTimeout))
+	if err := session.client.Quit(); err != nil {
+		return session.client.Close()
+	}
+	return nil
+}
+
+// envelopeError is a failure of MAIL FROM or RCPT, when none of the message has been handed to the relay yet
+type envelopeError struct {
+	err error
+}
+
+func (envelopeError *envelopeError) Error() string {
+	return envelopeError.err.Error()
+}
+
+func (envelopeError *envelopeError) Unwrap() error {
+	return envelopeError.err
+}
+
+// loginAuth implements the LOGIN mechanism, which net/smtp lacks
+type loginAuth struct {
+	username string
+	password string
+}
+
+func (auth loginAuth) Start(server *smtp.ServerInfo) (string, []byte, error) {
+	if !server.TLS {
+		return "", nil, errors.New("smtp: refusing LOGIN authentication over an unencrypted connection")
+	}
+	return "LOGIN", nil, nil
+}
+
+func (auth loginAuth) Next(fromServer []byte, more bool) ([]byte, error) {
+	if !more {
+		return nil, nil
+	}
+	switch {
+	case bytes.EqualFold(fromServer, []byte("Username:")):
+		return []byte(auth.username), nil
+	case bytes.EqualFold(fromServer, []byte("Password:")):
+		return []byte(auth.password), nil
+	}
+	return nil, fmt.Errorf("smtp: unexpected server challenge: %s", fromServer)
+}
@@ -0,0 +1,128 @@
+package email_client
+
+import (
+	"bufio"
+	"bytes"
+	"github.com/stretchr/testify/suite"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeSmtpServer speaks just enough plain SMTP to record the commands a session sends
+type fakeSmtpServer struct {
+	listener net.Listener
+	mutex    sync.Mutex
+	commands []string
+	data     string
+	done     chan struct{}
+}
+
+func newFakeSmtpServer() *fakeSmtpServer {
+	listener, _ := net.Listen("tcp", "127.0.0.1:0")
+	server := &fakeSmtpServer{listener: listener, done: make(chan struct{})}
+	go server.serve()
+	return server
+}
+
+func (server *fakeSmtpServer) serve() {
+	defer close(server.done)
+	conn, err := server.listener.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	write := func(reply string) { _, _ = conn.Write([]byte(reply + "\r\n")) }
+	write("220 localhost ESMTP")
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		command := strings.TrimSpace(line)
+		server.mutex.Lock()
+		server.commands = append(server.commands, command)
+		server.mutex.Unlock()
+		switch verb := strings.ToUpper(strings.SplitN(command, " ", 2)[0]); verb {
+		case "EHLO":
+			write("250-localhost\r\n250 8BITMIME")
+		case "DATA":
+			write("354 go ahead")
+			var data bytes.Buffer
+			for {
+				dataLine, _ := reader.ReadString('\n')
+				if dataLine == ".\r\n" || len(dataLine) == 0 {
+					break
+				}
+				data.WriteString(dataLine)
+			}
+			server.mutex.Lock()
+			server.data = data.String()
+			server.mutex.Unlock()
+			write("250 queued")
+		case "QUIT":
+			write("221 bye")
+			return
+		default:
+			write("250 ok")
+		}
+	}
+}
+
+func (server *fakeSmtpServer) port() int {
+	return server.listener.Addr().(*net.TCPAddr).Port
+}
+
+type smtpSessionTestSuite struct {
+	suite.Suite
+	server *fakeSmtpServer
+}
+
+func TestSmtpSessionTestSuite(t *testing.T) {
+	suite.Run(t, new(smtpSessionTestSuite))
+}
+
+func (suite *smtpSessionTestSuite) SetupTest() {
+	suite.server = newFakeSmtpServer()
+}
+
+func (suite *smtpSessionTestSuite) TearDownTest() {
+	_ = suite.server.listener.Close()
+}
+
+func (suite *smtpSessionTestSuite) TestSession_ShouldSendSeveralMessagesKeepAliveAndResetOnOneConnection() {
+	session, err := NewSmtpSessionDialer("127.0.0.1", suite.server.port(), "", "", nil).DialSession()
+	suite.Nil(err)
+
+	suite.Nil(session.Send("gola@gola.xyz", []string{"first@gmail.com", "second@gmail.com"}, bytes.NewBufferString("Subject: Hi!\r\n\r\nHello User!\r\n")))
+	suite.Nil(session.Noop())
+	suite.Nil(session.Reset())
+	suite.Nil(session.Send("gola@gola.xyz", []string{"third@gmail.com"}, bytes.NewBufferString("Subject: Bye!\r\n\r\nBye User!\r\n")))
+	suite.Nil(session.Close())
+	<-suite.server.done
+
+	suite.Equal([]string{
+		"EHLO localhost",
+		"MAIL FROM:<gola@gola.xyz> BODY=8BITMIME",
+		"RCPT TO:<first@gmail.com>",
+		"RCPT TO:<second@gmail.com>",
+		"DATA",
+		"NOOP",
+		"RSET",
+		"MAIL FROM:<gola@gola.xyz> BODY=8BITMIME",
+		"RCPT TO:<third@gmail.com>",
+		"DATA",
+		"QUIT",
+	}, suite.server.commands)
+	suite.Equal("Subject: Bye!\r\n\r\nBye User!\r\n", suite.server.data)
+}
+
+func (suite *smtpSessionTestSuite) TestDialSession_ShouldReturnErrorIfRelayIsUnreachable() {
+	_ = suite.server.listener.Close()
+
+	_, err := NewSmtpSessionDialer("127.0.0.1", suite.server.port(), "", "", nil).DialSession()
+
+	suite.NotNil(err)
+}
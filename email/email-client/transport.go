@@ -0,0 +1,34 @@
+package email_client
+
+import (
+	"ccg-api/email/email-client/email_client_request"
+	"github.com/gin-gonic/gin"
+	"gopkg.in/gomail.v2"
+)
+
+const (
+	SmtpTransportType     = "smtp"
+	HttpApiTransportType  = "http_api"
+	MaildirTransportType  = "maildir"
+	SendmailTransportType = "sendmail"
+)
+
+// mockgen -source=email/email-client/transport.go -destination=email/email-client/mocks/mock_transport.go -package=mocks
+
+// Transport hands a message over for delivery, transports that submit MIME use the rendered message
+// while API based ones build their payload from the request
+type Transport interface {
+	Deliver(ctx *gin.Context, request *email_client_request.EmailClientRequest, message *gomail.Message) error
+}
+
+type smtpTransport struct {
+	gomailDialer GomailDialer
+}
+
+func NewSmtpTransport(dialer GomailDialer) Transport {
+	return smtpTransport{gomailDialer: dialer}
+}
+
+func (transport smtpTransport) Deliver(ctx *gin.Context, request *email_client_request.EmailClientRequest, message *gomail.Message) error {
+	return transport.gomailDialer.DialAndSend(message)
+}
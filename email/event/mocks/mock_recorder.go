@@ -0,0 +1,47 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: email/event/recorder.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	models "ccg-api/email/models"
+	gin "github.com/gin-gonic/gin"
+	gomock "github.com/golang/mock/gomock"
+	reflect "reflect"
+)
+
+// MockRecorder is a mock of Recorder interface
+type MockRecorder struct {
+	ctrl     *gomock.Controller
+	recorder *MockRecorderMockRecorder
+}
+
+// MockRecorderMockRecorder is the mock recorder for MockRecorder
+type MockRecorderMockRecorder struct {
+	mock *MockRecorder
+}
+
+// NewMockRecorder creates a new mock instance
+func NewMockRecorder(ctrl *gomock.Controller) *MockRecorder {
+	mock := &MockRecorder{ctrl: ctrl}
+	mock.recorder = &MockRecorderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockRecorder) EXPECT() *MockRecorderMockRecorder {
+	return m.recorder
+}
+
+// Record mocks base method
+func (m *MockRecorder) Record(ctx *gin.Context, event models.DeliveryEvent) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Record", ctx, event)
+}
+
+// Record indicates an expected call of Record
+func (mr *MockRecorderMockRecorder) Record(ctx, event interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Record", reflect.TypeOf((*MockRecorder)(nil).Record), ctx, event)
+}
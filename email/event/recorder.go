@@ -0,0 +1,89 @@
+package event
+
+// mockgen -source=email/event/recorder.go -destination=email/event/mocks/mock_recorder.go -package=mocks
+import (
+	"ccg-api/email/models"
+	"ccg-api/email/status"
+	"ccg-api/email/suppression"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/inclusi-blog/gola-utils/golaerror"
+	"github.com/inclusi-blog/gola-utils/logging"
+	"time"
+)
+
+// Recorder applies delivery events, from provider webhooks or bounce notifications, to message statuses and the suppression list
+type Recorder interface {
+	Record(ctx *gin.Context, event models.DeliveryEvent)
+}
+
+type recorder struct {
+	tracker      status.Tracker
+	suppressions suppression.List
+}
+
+func NewRecorder(tracker status.Tracker, suppressions suppression.List) Recorder {
+	return recorder{tracker: tracker, suppressions: suppressions}
+}
+
+// Record suppresses recipients that hard bounced, complained or unsubscribed; opens and clicks are counted as engagement and
+// deferrals leave the status as it is
+func (recorder recorder) Record(ctx *gin.Context, event models.DeliveryEvent) {
+	logger := logging.GetLogger(ctx).WithField("class", "EventRecorder").WithField("method", "Record")
+	switch event.Type {
+	case models.DeliveredEvent:
+		recorder.updateStatus(ctx, event, models.Delivered)
+	case models.HardBounceEvent:
+		recorder.suppress(ctx, event, models.HardBounce)
+		recorder.updateStatus(ctx, event, models.Bounced)
+	case models.ComplaintEvent:
+		recorder.suppress(ctx, event, models.Complaint)
+		recorder.updateStatus(ctx, event, models.Complained)
+	case models.UnsubscribedEvent:
+		recorder.suppress(ctx, event, models.Unsubscribed)
+	case models.OpenedEvent, models.ClickedEvent:
+		if event.MessageID != "" {
+			recorder.tracker.RecordEngagement(ctx, event.MessageID, event.Type, event.OccurredAt)
+		}
+	default:
+		logger.Infof("Message %s was %s according to %s", event.MessageID, event.Type, event.Source)
+	}
+}
+
+func (recorder recorder) suppress(ctx *gin.Context, event models.DeliveryEvent, reason models.SuppressionReason) {
+	if event.Recipient == "" {
+		return
+	}
+	_, _ = recorder.suppressions.Add(ctx, models.Suppression{
+		Address: event.Recipient,
+		Reason:  reason,
+		Source:  event.Source,
+	})
+}
+
+// updateStatus records the state for the recipient of the event, or for the whole message when the event names no recipient.
+// Events of messages that we do not track, e.g. ones sent by other systems over the same relay, are ignored by the tracker
+func (recorder recorder) updateStatus(ctx *gin.Context, event models.DeliveryEvent, state models.MessageState) {
+	logger := logging.GetLogger(ctx).WithField("class", "EventRecorder").WithField("method", "updateStatus")
+	if event.MessageID == "" {
+		return
+	}
+	var cause error
+	if event.Reason != "" {
+		cause = errors.New(event.Reason)
+	}
+	var statusError *golaerror.Error
+	if event.Recipient == "" {
+		statusError = recorder.tracker.Update(ctx, event.MessageID, state, cause)
+	} else {
+		occurredAt := event.OccurredAt
+		if occurredAt.IsZero() {
+			occurredAt = time.Now()
+		}
+		statusError = recorder.tracker.RecordOutcome(ctx, event.MessageID, event.Recipient, state, cause, occurredAt)
+	}
+	if statusError != nil {
+		return
+	}
+	logger.Infof("Message %s is %s according to %s", event.MessageID, state, event.Source)
+}
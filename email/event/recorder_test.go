@@ -0,0 +1,98 @@
+package event
+
+import (
+	"ccg-api/constants"
+	"ccg-api/email/models"
+	mockStatus "ccg-api/email/status/mocks"
+	mockSuppression "ccg-api/email/suppression/mocks"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/suite"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type recorderTestSuite struct {
+	suite.Suite
+	context      *gin.Context
+	mockCtrl     *gomock.Controller
+	tracker      *mockStatus.MockTracker
+	suppressions *mockSuppression.MockList
+	recorder     Recorder
+}
+
+func TestRecorderTestSuite(t *testing.T) {
+	suite.Run(t, new(recorderTestSuite))
+}
+
+func (suite *recorderTestSuite) SetupTest() {
+	suite.context, _ = gin.CreateTestContext(httptest.NewRecorder())
+	suite.context.Request, _ = http.NewRequest("POST", "/", nil)
+	suite.mockCtrl = gomock.NewController(suite.T())
+	suite.tracker = mockStatus.NewMockTracker(suite.mockCtrl)
+	suite.suppressions = mockSuppression.NewMockList(suite.mockCtrl)
+	suite.recorder = NewRecorder(suite.tracker, suite.suppressions)
+}
+
+func (suite *recorderTestSuite) TearDownTest() {
+	suite.mockCtrl.Finish()
+}
+
+func (suite *recorderTestSuite) TestRecord_ShouldMarkMessageDelivered() {
+	deliveredAt := time.Now()
+	suite.tracker.EXPECT().RecordOutcome(suite.context, "message-id", "some@gmail.com", models.Delivered, nil, deliveredAt)
+
+	suite.recorder.Record(suite.context, models.DeliveryEvent{Source: "brevo", Type: models.DeliveredEvent, MessageID: "message-id", Recipient: "some@gmail.com", OccurredAt: deliveredAt})
+}
+
+func (suite *recorderTestSuite) TestRecord_ShouldMarkWholeMessageWhenEventNamesNoRecipient() {
+	suite.tracker.EXPECT().Update(suite.context, "message-id", models.Delivered, nil)
+
+	suite.recorder.Record(suite.context, models.DeliveryEvent{Source: "brevo", Type: models.DeliveredEvent, MessageID: "message-id"})
+}
+
+func (suite *recorderTestSuite) TestRecord_ShouldSuppressRecipientAndMarkMessageBouncedOnHardBounce() {
+	suite.suppressions.EXPECT().Add(suite.context, models.Suppression{Address: "missing@gmail.com", Reason: models.HardBounce, Source: "brevo"}).
+		Return(models.Suppression{}, nil)
+	suite.tracker.EXPECT().RecordOutcome(suite.context, "message-id", "missing@gmail.com", models.Bounced, gomock.Any(), gomock.Any()).
+		Do(func(ctx *gin.Context, id string, recipient string, state models.MessageState, cause error, at time.Time) {
+			suite.EqualError(cause, "550 user unknown")
+		})
+
+	suite.recorder.Record(suite.context, models.DeliveryEvent{
+		Source:    "brevo",
+		Type:      models.HardBounceEvent,
+		MessageID: "message-id",
+		Recipient: "missing@gmail.com",
+		Reason:    "550 user unknown",
+	})
+}
+
+func (suite *recorderTestSuite) TestRecord_ShouldSuppressRecipientAndMarkMessageComplainedOnComplaint() {
+	suite.suppressions.EXPECT().Add(suite.context, models.Suppression{Address: "annoyed@gmail.com", Reason: models.Complaint, Source: "brevo"}).
+		Return(models.Suppression{}, nil)
+	suite.tracker.EXPECT().RecordOutcome(suite.context, "message-id", "annoyed@gmail.com", models.Complained, nil, gomock.Any())
+
+	suite.recorder.Record(suite.context, models.DeliveryEvent{Source: "brevo", Type: models.ComplaintEvent, MessageID: "message-id", Recipient: "annoyed@gmail.com"})
+}
+
+func (suite *recorderTestSuite) TestRecord_ShouldNotUpdateStatusOfMessageThatIsNotTracked() {
+	suite.suppressions.EXPECT().Add(suite.context, gomock.Any()).Return(models.Suppression{}, nil)
+	suite.tracker.EXPECT().RecordOutcome(suite.context, "foreign-message-id", "missing@gmail.com", models.Bounced, nil, gomock.Any()).
+		Return(&constants.MessageNotFoundError)
+
+	suite.recorder.Record(suite.context, models.DeliveryEvent{Source: "brevo", Type: models.HardBounceEvent, MessageID: "foreign-message-id", Recipient: "missing@gmail.com"})
+}
+
+func (suite *recorderTestSuite) TestRecord_ShouldCountOpensAsEngagement() {
+	openedAt := time.Now()
+	suite.tracker.EXPECT().RecordEngagement(suite.context, "message-id", models.OpenedEvent, openedAt)
+
+	suite.recorder.Record(suite.context, models.DeliveryEvent{Source: "brevo", Type: models.OpenedEvent, MessageID: "message-id", Recipient: "some@gmail.com", OccurredAt: openedAt})
+}
+
+func (suite *recorderTestSuite) TestRecord_ShouldOnlyLogDeferrals() {
+	suite.recorder.Record(suite.context, models.DeliveryEvent{Source: "brevo", Type: models.DeferredEvent, MessageID: "message-id", Recipient: "full@gmail.com"})
+}
@@ -2,11 +2,19 @@ package http_request_response
 
 import "encoding/base64"
 
+const InlineDisposition = "inline"
+
 type Attachment struct {
-	FileName string `json:"file_name" validate:"required,validFileExtension" example:"fileName.pdf"`
-	Data     string `json:"base64_encoded_data" validate:"required,base64" example:"base64 encoded value"`
+	FileName    string `json:"file_name" validate:"required,validFileExtension" example:"fileName.pdf"`
+	Data        string `json:"base64_encoded_data" validate:"required,base64" example:"base64 encoded value"`
+	Disposition string `json:"disposition" validate:"omitempty,oneof=attachment inline" example:"inline"` //Use attachment as default
+	ContentID   string `json:"content_id" validate:"inlineContentId" example:"logo@gola.xyz"`
 }
 
 func (attachment Attachment) GetDecodedData() ([]byte, error) {
 	return base64.StdEncoding.DecodeString(attachment.Data)
 }
+
+func (attachment Attachment) IsInline() bool {
+	return attachment.Disposition == InlineDisposition
+}
@@ -0,0 +1,64 @@
+package http_request_response
+
+import "time"
+
+// BatchEmailRequest carries either a list of messages or one template with per recipient variables, never both.
+// Items are validated one by one, so that an invalid item fails alone instead of the whole batch.
+type BatchEmailRequest struct {
+	Messages []EmailRequest        `json:"messages"`
+	Template *BatchTemplateRequest `json:"template"`
+}
+
+type BatchTemplateRequest struct {
+	From         string              `json:"from" example:"Gola Support <abc@gola.xyz>"`
+	Cc           []string            `json:"cc" example:"def@gmail.com"`
+	Bcc          []string            `json:"bcc" example:"ghi@gmail.com"`
+	ReplyTo      string              `json:"reply_to" example:"support@gola.xyz"`
+	Headers      map[string]string   `json:"headers"`
+	TemplateName string              `json:"template_name" example:"welcome"`
+	Recipients   []TemplateRecipient `json:"recipients"`
+	Async        bool                `json:"async" example:"false"`
+	Category     string              `json:"category" example:"marketing"`
+	Track        bool                `json:"track" example:"false"`
+	SendAt       *time.Time          `json:"send_at" example:"2022-01-02T09:00:00+05:30"`
+	Tenant       string              `json:"tenant" example:"narratenet"`
+}
+
+type TemplateRecipient struct {
+	To        []string               `json:"to" example:"abc@gmail.com"`
+	Variables map[string]interface{} `json:"variables"`
+}
+
+func (batchEmailRequest BatchEmailRequest) Size() int {
+	if batchEmailRequest.Template != nil {
+		return len(batchEmailRequest.Template.Recipients)
+	}
+	return len(batchEmailRequest.Messages)
+}
+
+func (batchEmailRequest BatchEmailRequest) IsTemplateBatch() bool {
+	return batchEmailRequest.Template != nil
+}
+
+// ToTemplateEmailRequests expands the template batch into one request per recipient
+func (batchTemplateRequest BatchTemplateRequest) ToTemplateEmailRequests() []TemplateEmailRequest {
+	var requests []TemplateEmailRequest
+	for _, recipient := range batchTemplateRequest.Recipients {
+		requests = append(requests, TemplateEmailRequest{
+			From:         batchTemplateRequest.From,
+			To:           recipient.To,
+			Cc:           batchTemplateRequest.Cc,
+			Bcc:          batchTemplateRequest.Bcc,
+			ReplyTo:      batchTemplateRequest.ReplyTo,
+			Headers:      batchTemplateRequest.Headers,
+			TemplateName: batchTemplateRequest.TemplateName,
+			Variables:    recipient.Variables,
+			Async:        batchTemplateRequest.Async,
+			Category:     batchTemplateRequest.Category,
+			Track:        batchTemplateRequest.Track,
+			SendAt:       batchTemplateRequest.SendAt,
+			Tenant:       batchTemplateRequest.Tenant,
+		})
+	}
+	return requests
+}
@@ -0,0 +1,19 @@
+package http_request_response
+
+import (
+	"ccg-api/email/models"
+	"github.com/inclusi-blog/gola-utils/golaerror"
+)
+
+type BatchEmailResponse struct {
+	Results []BatchItemResult `json:"results"`
+}
+
+// BatchItemResult reports the item at Index of the batch with the status it would have got as a single send
+type BatchItemResult struct {
+	Index      int                          `json:"index" example:"0"`
+	StatusCode int                          `json:"status_code" example:"200"`
+	MessageID  string                       `json:"message_id,omitempty" example:"9b2f6c1e-3f0a-4a8e-8f5e-2d7c1b0a9e11"`
+	Suppressed []models.SuppressedRecipient `json:"suppressed,omitempty"`
+	Error      *golaerror.Error             `json:"error,omitempty"`
+}
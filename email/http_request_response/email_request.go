@@ -4,15 +4,30 @@ import (
 	"ccg-api/email/models"
 	"github.com/gin-gonic/gin"
 	"github.com/inclusi-blog/gola-utils/logging"
+	"time"
 )
 
 type EmailRequest struct {
-	From                string       `json:"from" binding:"required" validate:"email,validGolaEmailDomain" example:"abc@gola.xyz"`
-	To                  []string     `json:"to" binding:"required" validate:"gt=0,dive,email" example:"abc@gmail.com"`
-	Subject             string       `json:"subject" binding:"required" validate:"notblank" example:"base64 encoded value"`
-	Body                MessageBody  `json:"message_body" binding:"required"`
-	Attachments         []Attachment `json:"attachments" validate:"uniqueAttachments,totalAttachmentSizeWithinPermissibleLimit,dive"`
-	IncludeBaseTemplate bool         `json:"include_base_template" example:"true"`
+	From                string            `json:"from" validate:"omitempty,validGolaEmailDomain,senderName" example:"Gola Support <abc@gola.xyz>"`
+	To                  []string          `json:"to" binding:"required" validate:"gt=0,recipientsWithinLimit,dive,email" example:"abc@gmail.com"`
+	Cc                  []string          `json:"cc" validate:"dive,email" example:"def@gmail.com"`
+	Bcc                 []string          `json:"bcc" validate:"dive,email" example:"ghi@gmail.com"`
+	ReplyTo             string            `json:"reply_to" validate:"omitempty,email" example:"support@gola.xyz"`
+	Headers             map[string]string `json:"headers" validate:"allowedHeaders"`
+	Subject             string            `json:"subject" binding:"required" validate:"notblank" example:"base64 encoded value"`
+	Body                MessageBody       `json:"message_body" binding:"required"`
+	Attachments         []Attachment      `json:"attachments" validate:"uniqueAttachments,totalAttachmentSizeWithinPermissibleLimit,dive"`
+	IncludeBaseTemplate bool              `json:"include_base_template" example:"true"`
+	Async               bool              `json:"async" example:"false"`
+	Category            string            `json:"category" validate:"omitempty,knownCategory" example:"marketing"`
+	Track               bool              `json:"track" example:"false"`
+	SendAt              *time.Time        `json:"send_at" validate:"omitempty,scheduledSendAt" example:"2022-01-02T09:00:00+05:30"`
+	Tenant              string            `json:"tenant" validate:"omitempty,knownTenant" example:"narratenet"`
+}
+
+// RecipientCount counts To, Cc and Bcc together since each of them is a delivery
+func (emailRequest EmailRequest) RecipientCount() int {
+	return len(emailRequest.To) + len(emailRequest.Cc) + len(emailRequest.Bcc)
 }
 
 func (emailRequest EmailRequest) ToEmailModel(ctx *gin.Context) (models.Email, error) {
@@ -28,13 +43,25 @@ func (emailRequest EmailRequest) ToEmailModel(ctx *gin.Context) (models.Email, e
 		return models.Email{}, attachmentError
 	}
 
+	from, fromName := sender(emailRequest.From)
 	email := models.Email{
-		From:                emailRequest.From,
+		From:                from,
+		FromName:            fromName,
 		To:                  emailRequest.To,
+		Cc:                  emailRequest.Cc,
+		Bcc:                 emailRequest.Bcc,
+		ReplyTo:             emailRequest.ReplyTo,
+		Headers:             emailRequest.Headers,
 		Subject:             emailRequest.Subject,
 		Body:                messageBody,
 		Attachments:         attachments,
 		IncludeBaseTemplate: emailRequest.IncludeBaseTemplate,
+		Category:            emailRequest.Category,
+		Track:               emailRequest.Track,
+		Tenant:              emailRequest.Tenant,
+	}
+	if emailRequest.SendAt != nil {
+		email.SendAt = *emailRequest.SendAt
 	}
 	return email, nil
 }
@@ -57,9 +84,15 @@ func (emailRequest EmailRequest) buildMessageBody(messageBodyInRequest MessageBo
 		return models.MessageBody{}, err
 	}
 
+	decodedPlainTextContent, err := messageBodyInRequest.GetDecodedPlainTextContent()
+	if err != nil {
+		return models.MessageBody{}, err
+	}
+
 	messageBody := models.MessageBody{
-		MimeType: messageBodyInRequest.MimeType,
-		Content:  decodedContent,
+		MimeType:  messageBodyInRequest.MimeType,
+		Content:   decodedContent,
+		PlainText: decodedPlainTextContent,
 	}
 
 	return messageBody, nil
@@ -71,8 +104,22 @@ func (emailRequest EmailRequest) buildAttachment(attachmentInRequest Attachment)
 		return models.Attachment{}, err
 	}
 	attachment := models.Attachment{
-		FileName: attachmentInRequest.FileName,
-		Data:     decodedBytes,
+		FileName:  attachmentInRequest.FileName,
+		Data:      decodedBytes,
+		Inline:    attachmentInRequest.IsInline(),
+		ContentID: attachmentInRequest.ContentID,
 	}
 	return attachment, nil
 }
+
+// sender splits the validated from of a request into address and display name, an empty from is left to the default sender
+func sender(from string) (string, string) {
+	if from == "" {
+		return "", ""
+	}
+	address, name, err := models.ParseSender(from)
+	if err != nil {
+		return from, ""
+	}
+	return address, name
+}
@@ -108,3 +108,63 @@ func (suite *emailRequestTestSuite) TestGetDecodedData_ShouldReturnErrorIfUnable
 	_, err := emailRequest.ToEmailModel(suite.context)
 	suite.NotNil(err)
 }
+
+func (suite *emailRequestTestSuite) TestToEmail_ShouldCarryCcBccReplyToAndHeaders() {
+	emailRequest := EmailRequest{
+		From:    "gola@gola.xyz",
+		To:      []string{"some@gmail.com"},
+		Cc:      []string{"cc@gmail.com"},
+		Bcc:     []string{"bcc@gmail.com"},
+		ReplyTo: "support@gola.xyz",
+		Headers: map[string]string{"List-Id": "<news.gola.xyz>"},
+		Subject: "Hi!",
+		Body: MessageBody{
+			Content: "TWVzc2FnZSBCb2R5IQ==",
+		},
+	}
+
+	actualEmailModel, err := emailRequest.ToEmailModel(suite.context)
+
+	suite.Nil(err)
+	suite.Equal([]string{"cc@gmail.com"}, actualEmailModel.Cc)
+	suite.Equal([]string{"bcc@gmail.com"}, actualEmailModel.Bcc)
+	suite.Equal("support@gola.xyz", actualEmailModel.ReplyTo)
+	suite.Equal(map[string]string{"List-Id": "<news.gola.xyz>"}, actualEmailModel.Headers)
+	suite.Equal(3, emailRequest.RecipientCount())
+	suite.Equal([]string{"some@gmail.com", "cc@gmail.com", "bcc@gmail.com"}, actualEmailModel.Recipients())
+}
+
+func (suite *emailRequestTestSuite) TestToEmail_ShouldDecodePlainTextAlternative() {
+	emailRequest := EmailRequest{
+		From:    "gola@gola.xyz",
+		To:      []string{"some@gmail.com"},
+		Subject: "Hi!",
+		Body: MessageBody{
+			MimeType:         "text/html",
+			Content:          "PHA+SGVsbG8hPC9wPg==",
+			PlainTextContent: "SGVsbG8h",
+		},
+	}
+
+	actualEmailModel, err := emailRequest.ToEmailModel(suite.context)
+
+	suite.Nil(err)
+	suite.Equal(models.MessageBody{MimeType: "text/html", Content: "<p>Hello!</p>", PlainText: "Hello!"}, actualEmailModel.Body)
+}
+
+func (suite *emailRequestTestSuite) TestToEmail_ShouldSplitFromIntoAddressAndDecodedDisplayName() {
+	emailRequest := EmailRequest{
+		From:    "=?UTF-8?q?Zo=C3=AB_from_Gola?= <gola@gola.xyz>",
+		To:      []string{"some@gmail.com"},
+		Subject: "Hi!",
+		Body: MessageBody{
+			Content: "SGVsbG8h",
+		},
+	}
+
+	actualEmailModel, err := emailRequest.ToEmailModel(suite.context)
+
+	suite.Nil(err)
+	suite.Equal("gola@gola.xyz", actualEmailModel.From)
+	suite.Equal("Zoë from Gola", actualEmailModel.FromName)
+}
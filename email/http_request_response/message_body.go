@@ -5,6 +5,8 @@ import "encoding/base64"
 type MessageBody struct {
 	MimeType string `json:"mime_type" example:"text/html"` //Use text/plain as default
 	Content  string `json:"base64_encoded_content" validate:"required,base64,notblankbase64" example:"base64 encoded value"`
+	// PlainTextContent is the text alternative of an html Content, generated from the html when not given
+	PlainTextContent string `json:"base64_encoded_plain_text_content" validate:"omitempty,base64" example:"base64 encoded value"`
 }
 
 func (message MessageBody) GetDecodedContent() (string, error) {
@@ -14,3 +16,11 @@ func (message MessageBody) GetDecodedContent() (string, error) {
 	}
 	return string(decodedBytes), nil
 }
+
+func (message MessageBody) GetDecodedPlainTextContent() (string, error) {
+	decodedBytes, err := base64.StdEncoding.DecodeString(message.PlainTextContent)
+	if err != nil {
+		return "", err
+	}
+	return string(decodedBytes), nil
+}
@@ -0,0 +1,35 @@
+package http_request_response
+
+import (
+	"ccg-api/email/models"
+	"strings"
+	"time"
+)
+
+const DefaultMessageStatusListLimit = 100
+
+type MessageStatusListRequest struct {
+	RecipientHash string    `form:"recipient_hash" validate:"omitempty,hexadecimal,len=64"`
+	From          string    `form:"from" validate:"omitempty,email"`
+	Since         time.Time `form:"since" time_format:"2006-01-02T15:04:05Z07:00"`
+	Until         time.Time `form:"until" time_format:"2006-01-02T15:04:05Z07:00"`
+	Limit         int       `form:"limit" validate:"omitempty,min=1,max=1000"`
+	Cursor        string    `form:"cursor" validate:"omitempty,printascii,max=128"`
+}
+
+// ToFilter lowercases the recipient hash, which is kept in the lowercase hex of status.HashRecipient
+func (request MessageStatusListRequest) ToFilter(clientID string) models.MessageStatusFilter {
+	limit := request.Limit
+	if limit == 0 {
+		limit = DefaultMessageStatusListLimit
+	}
+	return models.MessageStatusFilter{
+		ClientID:      clientID,
+		RecipientHash: strings.ToLower(request.RecipientHash),
+		From:          request.From,
+		Since:         request.Since,
+		Until:         request.Until,
+		Limit:         limit,
+		Cursor:        request.Cursor,
+	}
+}
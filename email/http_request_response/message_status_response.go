@@ -0,0 +1,77 @@
+package http_request_response
+
+import (
+	"ccg-api/email/models"
+	"time"
+)
+
+type MessageStatusResponse struct {
+	MessageID       string                     `json:"message_id" example:"9b2f6c1e-3f0a-4a8e-8f5e-2d7c1b0a9e11"`
+	Status          string                     `json:"status" example:"sent"`
+	From            string                     `json:"from" example:"abc@gola.xyz"`
+	RecipientHashes []string                   `json:"recipient_hashes"`
+	Attempts        int                        `json:"attempts" example:"1"`
+	LastError       string                     `json:"last_error,omitempty"`
+	CreatedAt       time.Time                  `json:"created_at"`
+	UpdatedAt       time.Time                  `json:"updated_at"`
+	History         []StateTransitionResponse  `json:"history"`
+	Outcomes        []RecipientOutcomeResponse `json:"outcomes,omitempty"`
+}
+
+type RecipientOutcomeResponse struct {
+	RecipientHash string    `json:"recipient_hash" example:"6f2a3d1c0e9b8a7f6e5d4c3b2a1f0e9d8c7b6a5f4e3d2c1b0a9f8e7d6c5b4a39"`
+	Status        string    `json:"status" example:"delivered"`
+	At            time.Time `json:"at"`
+	Error         string    `json:"error,omitempty"`
+}
+
+type StateTransitionResponse struct {
+	Status string    `json:"status" example:"sending"`
+	At     time.Time `json:"at"`
+	Error  string    `json:"error,omitempty"`
+}
+
+type MessageStatusListResponse struct {
+	Messages   []MessageStatusResponse `json:"messages"`
+	NextCursor string                  `json:"next_cursor,omitempty" example:"1582418054000000000_9b2f6c1e-3f0a-4a8e-8f5e-2d7c1b0a9e11"`
+}
+
+func NewMessageStatusResponse(status models.MessageStatus) MessageStatusResponse {
+	history := make([]StateTransitionResponse, 0, len(status.History))
+	for _, transition := range status.History {
+		history = append(history, StateTransitionResponse{
+			Status: string(transition.State),
+			At:     transition.At,
+			Error:  transition.Error,
+		})
+	}
+	var outcomes []RecipientOutcomeResponse
+	for _, outcome := range status.Outcomes {
+		outcomes = append(outcomes, RecipientOutcomeResponse{
+			RecipientHash: outcome.RecipientHash,
+			Status:        string(outcome.State),
+			At:            outcome.At,
+			Error:         outcome.Error,
+		})
+	}
+	return MessageStatusResponse{
+		MessageID:       status.ID,
+		Status:          string(status.State),
+		From:            status.From,
+		RecipientHashes: status.RecipientHashes,
+		Attempts:        status.Attempts,
+		LastError:       status.LastError,
+		CreatedAt:       status.CreatedAt,
+		UpdatedAt:       status.UpdatedAt,
+		History:         history,
+		Outcomes:        outcomes,
+	}
+}
+
+func NewMessageStatusListResponse(page models.MessageStatusPage) MessageStatusListResponse {
+	messages := make([]MessageStatusResponse, 0, len(page.Statuses))
+	for _, status := range page.Statuses {
+		messages = append(messages, NewMessageStatusResponse(status))
+	}
+	return MessageStatusListResponse{Messages: messages, NextCursor: page.NextCursor}
+}
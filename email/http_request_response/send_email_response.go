@@ -0,0 +1,13 @@
+package http_request_response
+
+import "ccg-api/email/models"
+
+type SendEmailResponse struct {
+	MessageID string `json:"message_id" example:"9b2f6c1e-3f0a-4a8e-8f5e-2d7c1b0a9e11"`
+	// Suppressed lists the recipients that were dropped, the email still goes to everyone else
+	Suppressed []models.SuppressedRecipient `json:"suppressed,omitempty"`
+}
+
+func NewSendEmailResponse(receipt models.SendReceipt) SendEmailResponse {
+	return SendEmailResponse{MessageID: receipt.MessageID, Suppressed: receipt.Suppressed}
+}
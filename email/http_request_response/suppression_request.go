@@ -0,0 +1,58 @@
+package http_request_response
+
+import (
+	"ccg-api/email/models"
+	"encoding/csv"
+	"io"
+	"strings"
+)
+
+type SuppressionRequest struct {
+	Address string                   `json:"address" binding:"required" validate:"email|fqdn" example:"abc@gmail.com"`
+	Reason  models.SuppressionReason `json:"reason" binding:"required" validate:"oneof=hard_bounce complaint unsubscribed manual" example:"manual"`
+}
+
+func (request SuppressionRequest) ToSuppressionModel(source string) models.Suppression {
+	return models.Suppression{
+		Address: request.Address,
+		Reason:  request.Reason,
+		Source:  source,
+	}
+}
+
+// SuppressionCsvRow is a line of an imported csv, numbered from 1 with the header counted
+type SuppressionCsvRow struct {
+	Line    int
+	Request SuppressionRequest
+}
+
+// ReadSuppressionCsv reads address,reason lines with an optional header row, a missing reason is taken as manual
+func ReadSuppressionCsv(reader io.Reader) ([]SuppressionCsvRow, error) {
+	csvReader := csv.NewReader(reader)
+	csvReader.FieldsPerRecord = -1
+	csvReader.TrimLeadingSpace = true
+
+	var rows []SuppressionCsvRow
+	for recordIndex := 0; ; recordIndex++ {
+		record, err := csvReader.Read()
+		if err == io.EOF {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := csvReader.FieldPos(0)
+		address := strings.TrimSpace(record[0])
+		if recordIndex == 0 && strings.EqualFold(address, "address") {
+			continue
+		}
+		if len(record) == 1 && address == "" {
+			continue
+		}
+		reason := models.Manual
+		if len(record) > 1 && strings.TrimSpace(record[1]) != "" {
+			reason = models.SuppressionReason(strings.ToLower(strings.TrimSpace(record[1])))
+		}
+		rows = append(rows, SuppressionCsvRow{Line: line, Request: SuppressionRequest{Address: address, Reason: reason}})
+	}
+}
@@ -0,0 +1,35 @@
+package http_request_response
+
+import (
+	"ccg-api/email/models"
+	"github.com/stretchr/testify/suite"
+	"strings"
+	"testing"
+)
+
+type suppressionRequestTestSuite struct {
+	suite.Suite
+}
+
+func TestSuppressionRequestTestSuite(t *testing.T) {
+	suite.Run(t, new(suppressionRequestTestSuite))
+}
+
+func (suite *suppressionRequestTestSuite) TestReadSuppressionCsv_ShouldReadLinesWithoutHeaderAndSkipBlankOnes() {
+	rows, err := ReadSuppressionCsv(strings.NewReader("bounced@gmail.com, Hard_Bounce\n\n\"blocked.com\"\n"))
+
+	suite.Nil(err)
+	suite.Equal([]SuppressionCsvRow{
+		{Line: 1, Request: SuppressionRequest{Address: "bounced@gmail.com", Reason: models.HardBounce}},
+		{Line: 3, Request: SuppressionRequest{Address: "blocked.com", Reason: models.Manual}},
+	}, rows)
+}
+
+func (suite *suppressionRequestTestSuite) TestReadSuppressionCsv_ShouldSkipHeaderRow() {
+	rows, err := ReadSuppressionCsv(strings.NewReader("Address,Reason\ncomplained@gmail.com,complaint\n"))
+
+	suite.Nil(err)
+	suite.Equal([]SuppressionCsvRow{
+		{Line: 2, Request: SuppressionRequest{Address: "complained@gmail.com", Reason: models.Complaint}},
+	}, rows)
+}
@@ -0,0 +1,45 @@
+package http_request_response
+
+import (
+	"ccg-api/email/models"
+	"time"
+)
+
+type SuppressionResponse struct {
+	Address   string    `json:"address" example:"abc@gmail.com"`
+	Reason    string    `json:"reason" example:"hard_bounce"`
+	Source    string    `json:"source" example:"api"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type SuppressionListResponse struct {
+	Suppressions []SuppressionResponse `json:"suppressions"`
+}
+
+type SuppressionImportResponse struct {
+	Imported int                          `json:"imported" example:"2"`
+	Rejected []SuppressionImportRejection `json:"rejected"`
+}
+
+type SuppressionImportRejection struct {
+	Line    int    `json:"line" example:"3"`
+	Address string `json:"address" example:"not-an-address"`
+	Error   string `json:"error" example:"One or more of the request parameters are missing or invalid"`
+}
+
+func NewSuppressionResponse(suppression models.Suppression) SuppressionResponse {
+	return SuppressionResponse{
+		Address:   suppression.Address,
+		Reason:    string(suppression.Reason),
+		Source:    suppression.Source,
+		CreatedAt: suppression.CreatedAt,
+	}
+}
+
+func NewSuppressionListResponse(suppressions []models.Suppression) SuppressionListResponse {
+	responses := make([]SuppressionResponse, 0, len(suppressions))
+	for _, suppression := range suppressions {
+		responses = append(responses, NewSuppressionResponse(suppression))
+	}
+	return SuppressionListResponse{Suppressions: responses}
+}
@@ -0,0 +1,54 @@
+package http_request_response
+
+import (
+	"ccg-api/email/models"
+	"ccg-api/email/templates"
+	"time"
+)
+
+type TemplateEmailRequest struct {
+	From         string                 `json:"from" validate:"omitempty,validGolaEmailDomain,senderName" example:"Gola Support <abc@gola.xyz>"`
+	To           []string               `json:"to" binding:"required" validate:"gt=0,recipientsWithinLimit,dive,email" example:"abc@gmail.com"`
+	Cc           []string               `json:"cc" validate:"dive,email" example:"def@gmail.com"`
+	Bcc          []string               `json:"bcc" validate:"dive,email" example:"ghi@gmail.com"`
+	ReplyTo      string                 `json:"reply_to" validate:"omitempty,email" example:"support@gola.xyz"`
+	Headers      map[string]string      `json:"headers" validate:"allowedHeaders"`
+	TemplateName string                 `json:"template_name" binding:"required" validate:"notblank" example:"password_reset"`
+	Variables    map[string]interface{} `json:"variables"`
+	Async        bool                   `json:"async" example:"false"`
+	Category     string                 `json:"category" validate:"omitempty,knownCategory" example:"marketing"`
+	Track        bool                   `json:"track" example:"false"`
+	SendAt       *time.Time             `json:"send_at" validate:"omitempty,scheduledSendAt" example:"2022-01-02T09:00:00+05:30"`
+	Tenant       string                 `json:"tenant" validate:"omitempty,knownTenant" example:"narratenet"`
+}
+
+func (templateEmailRequest TemplateEmailRequest) RecipientCount() int {
+	return len(templateEmailRequest.To) + len(templateEmailRequest.Cc) + len(templateEmailRequest.Bcc)
+}
+
+func (templateEmailRequest TemplateEmailRequest) ToEmailModel(rendered templates.Rendered) models.Email {
+	from, fromName := sender(templateEmailRequest.From)
+	email := models.Email{
+		From:     from,
+		FromName: fromName,
+		To:       templateEmailRequest.To,
+		Cc:       templateEmailRequest.Cc,
+		Bcc:      templateEmailRequest.Bcc,
+		ReplyTo:  templateEmailRequest.ReplyTo,
+		Headers:  templateEmailRequest.Headers,
+		Subject:  rendered.Subject,
+		Body: models.MessageBody{
+			MimeType:  "text/html",
+			Content:   rendered.HTML,
+			PlainText: rendered.Text,
+		},
+		IncludeBaseTemplate: rendered.IncludeBaseTemplate,
+		Category:            templateEmailRequest.Category,
+		Track:               templateEmailRequest.Track,
+		Tenant:              templateEmailRequest.Tenant,
+	}
+	if templateEmailRequest.SendAt != nil {
+		email.SendAt = *templateEmailRequest.SendAt
+	}
+	return email
+}
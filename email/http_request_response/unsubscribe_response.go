@@ -0,0 +1,5 @@
+package http_request_response
+
+type UnsubscribeResponse struct {
+	Category string `json:"category" example:"marketing"`
+}
@@ -0,0 +1,5 @@
+package http_request_response
+
+type WebhookResponse struct {
+	Accepted int `json:"accepted" example:"1"`
+}
@@ -0,0 +1,96 @@
+package idempotency
+
+// mockgen -source=email/idempotency/guard.go -destination=email/idempotency/mocks/mock_guard.go -package=mocks
+import (
+	"ccg-api/constants"
+	"ccg-api/util"
+	"crypto/sha256"
+	"encoding/hex"
+	"github.com/gin-gonic/gin"
+	"github.com/inclusi-blog/gola-utils/golaerror"
+	"github.com/inclusi-blog/gola-utils/logging"
+	"net/http"
+	"time"
+)
+
+const (
+	KeyHeader              = "Idempotency-Key"
+	ReplayedHeader         = "Idempotent-Replayed"
+	MaxKeyLength           = 255
+	defaultWindow          = 24 * time.Hour
+	minimumWindowInSeconds = 1
+)
+
+// Guard makes a request safe to retry under the same key.
+// Begin returns the original outcome for a replay, or nil when the request should be processed and then passed to Finish
+type Guard interface {
+	Begin(ctx *gin.Context, key string, request interface{}) (*Outcome, *golaerror.Error)
+	Finish(ctx *gin.Context, key string, outcome Outcome)
+}
+
+type guard struct {
+	store  Store
+	window time.Duration
+}
+
+func NewGuard(store Store, windowInSeconds int) Guard {
+	window := defaultWindow
+	if windowInSeconds >= minimumWindowInSeconds {
+		window = time.Duration(windowInSeconds) * time.Second
+	}
+	return guard{store: store, window: window}
+}
+
+func (guard guard) Begin(ctx *gin.Context, key string, request interface{}) (*Outcome, *golaerror.Error) {
+	logger := logging.GetLogger(ctx).WithField("class", "IdempotencyGuard").WithField("method", "Begin")
+	if len(key) > MaxKeyLength {
+		return nil, &constants.PayloadValidationError
+	}
+	requestHash, err := hashRequest(request)
+	if err != nil {
+		logger.Error("Failed to hash request ", err)
+		return nil, &constants.InternalServerError
+	}
+
+	record, found, err := guard.store.Reserve(key, requestHash, guard.window)
+	if err != nil {
+		logger.Error("Failed to reserve idempotency key ", err)
+		return nil, &constants.InternalServerError
+	}
+	if !found {
+		return nil, nil
+	}
+	if record.RequestHash != requestHash {
+		logger.Warn("Idempotency key reused with a different payload")
+		return nil, &constants.IdempotencyKeyReusedError
+	}
+	if record.Outcome == nil {
+		logger.Warn("Idempotency key replayed while the original request is still being processed")
+		return nil, &constants.IdempotentRequestInProgressError
+	}
+	logger.Info("Replaying outcome of request for idempotency key")
+	return record.Outcome, nil
+}
+
+// Finish remembers the outcome for replays; server errors and rate limited requests release the key so that the caller can retry
+func (guard guard) Finish(ctx *gin.Context, key string, outcome Outcome) {
+	logger := logging.GetLogger(ctx).WithField("class", "IdempotencyGuard").WithField("method", "Finish")
+	if outcome.StatusCode >= http.StatusInternalServerError || outcome.StatusCode == http.StatusTooManyRequests {
+		if err := guard.store.Release(key); err != nil {
+			logger.Error("Failed to release idempotency key ", err)
+		}
+		return
+	}
+	if err := guard.store.Complete(key, outcome); err != nil {
+		logger.Error("Failed to record outcome for idempotency key ", err)
+	}
+}
+
+func hashRequest(request interface{}) (string, error) {
+	encodedRequest, err := util.Encode(request)
+	if err != nil {
+		return "", err
+	}
+	hash := sha256.Sum256([]byte(encodedRequest))
+	return hex.EncodeToString(hash[:]), nil
+}
@@ -0,0 +1,118 @@
+package idempotency
+
+import (
+	"ccg-api/constants"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/suite"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testRequest struct {
+	To      string `json:"to"`
+	Subject string `json:"subject"`
+}
+
+type failingStore struct{}
+
+func (failingStore) Reserve(string, string, time.Duration) (Record, bool, error) {
+	return Record{}, false, errors.New("store unavailable")
+}
+
+func (failingStore) Complete(string, Outcome) error {
+	return errors.New("store unavailable")
+}
+
+func (failingStore) Release(string) error {
+	return errors.New("store unavailable")
+}
+
+type guardTestSuite struct {
+	suite.Suite
+	context *gin.Context
+	guard   Guard
+}
+
+func TestGuardTestSuite(t *testing.T) {
+	suite.Run(t, new(guardTestSuite))
+}
+
+func (suite *guardTestSuite) SetupTest() {
+	suite.context, _ = gin.CreateTestContext(httptest.NewRecorder())
+	suite.context.Request, _ = http.NewRequest("POST", "/", nil)
+	suite.guard = NewGuard(NewMemoryStore(), 60)
+}
+
+func (suite *guardTestSuite) TestBegin_ShouldLetFirstRequestThrough() {
+	outcome, err := suite.guard.Begin(suite.context, "some-key", testRequest{To: "someone@gmail.com"})
+
+	suite.Nil(err)
+	suite.Nil(outcome)
+}
+
+func (suite *guardTestSuite) TestBegin_ShouldReplayOutcomeForSameKeyAndPayload() {
+	request := testRequest{To: "someone@gmail.com", Subject: "Reset your password"}
+	_, _ = suite.guard.Begin(suite.context, "some-key", request)
+	suite.guard.Finish(suite.context, "some-key", Outcome{StatusCode: http.StatusOK, Body: []byte(`{"message_id":"1"}`)})
+
+	outcome, err := suite.guard.Begin(suite.context, "some-key", request)
+
+	suite.Nil(err)
+	suite.Equal(&Outcome{StatusCode: http.StatusOK, Body: []byte(`{"message_id":"1"}`)}, outcome)
+}
+
+func (suite *guardTestSuite) TestBegin_ShouldRejectReusedKeyWithDifferentPayload() {
+	_, _ = suite.guard.Begin(suite.context, "some-key", testRequest{To: "someone@gmail.com"})
+	suite.guard.Finish(suite.context, "some-key", Outcome{StatusCode: http.StatusOK})
+
+	_, err := suite.guard.Begin(suite.context, "some-key", testRequest{To: "other@gmail.com"})
+
+	suite.Equal(&constants.IdempotencyKeyReusedError, err)
+}
+
+func (suite *guardTestSuite) TestBegin_ShouldRejectReplayWhileOriginalRequestIsInProgress() {
+	request := testRequest{To: "someone@gmail.com"}
+	_, _ = suite.guard.Begin(suite.context, "some-key", request)
+
+	_, err := suite.guard.Begin(suite.context, "some-key", request)
+
+	suite.Equal(&constants.IdempotentRequestInProgressError, err)
+}
+
+func (suite *guardTestSuite) TestBegin_ShouldRejectOverlyLongKey() {
+	_, err := suite.guard.Begin(suite.context, strings.Repeat("k", MaxKeyLength+1), testRequest{})
+
+	suite.Equal(&constants.PayloadValidationError, err)
+}
+
+func (suite *guardTestSuite) TestBegin_ShouldReturnInternalServerErrorIfStoreFails() {
+	_, err := NewGuard(failingStore{}, 60).Begin(suite.context, "some-key", testRequest{})
+
+	suite.Equal(&constants.InternalServerError, err)
+}
+
+func (suite *guardTestSuite) TestFinish_ShouldReleaseKeyOnServerErrorSoThatRequestCanBeRetried() {
+	request := testRequest{To: "someone@gmail.com"}
+	_, _ = suite.guard.Begin(suite.context, "some-key", request)
+	suite.guard.Finish(suite.context, "some-key", Outcome{StatusCode: http.StatusInternalServerError})
+
+	outcome, err := suite.guard.Begin(suite.context, "some-key", request)
+
+	suite.Nil(err)
+	suite.Nil(outcome)
+}
+
+func (suite *guardTestSuite) TestFinish_ShouldReleaseKeyWhenRequestWasRateLimited() {
+	request := testRequest{To: "someone@gmail.com"}
+	_, _ = suite.guard.Begin(suite.context, "some-key", request)
+	suite.guard.Finish(suite.context, "some-key", Outcome{StatusCode: http.StatusTooManyRequests})
+
+	outcome, err := suite.guard.Begin(suite.context, "some-key", request)
+
+	suite.Nil(err)
+	suite.Nil(outcome)
+}
@@ -0,0 +1,63 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: email/idempotency/guard.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	idempotency "ccg-api/email/idempotency"
+	gin "github.com/gin-gonic/gin"
+	gomock "github.com/golang/mock/gomock"
+	golaerror "github.com/inclusi-blog/gola-utils/golaerror"
+	reflect "reflect"
+)
+
+// MockGuard is a mock of Guard interface
+type MockGuard struct {
+	ctrl     *gomock.Controller
+	recorder *MockGuardMockRecorder
+}
+
+// MockGuardMockRecorder is the mock recorder for MockGuard
+type MockGuardMockRecorder struct {
+	mock *MockGuard
+}
+
+// NewMockGuard creates a new mock instance
+func NewMockGuard(ctrl *gomock.Controller) *MockGuard {
+	mock := &MockGuard{ctrl: ctrl}
+	mock.recorder = &MockGuardMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockGuard) EXPECT() *MockGuardMockRecorder {
+	return m.recorder
+}
+
+// Begin mocks base method
+func (m *MockGuard) Begin(ctx *gin.Context, key string, request interface{}) (*idempotency.Outcome, *golaerror.Error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Begin", ctx, key, request)
+	ret0, _ := ret[0].(*idempotency.Outcome)
+	ret1, _ := ret[1].(*golaerror.Error)
+	return ret0, ret1
+}
+
+// Begin indicates an expected call of Begin
+func (mr *MockGuardMockRecorder) Begin(ctx, key, request interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Begin", reflect.TypeOf((*MockGuard)(nil).Begin), ctx, key, request)
+}
+
+// Finish mocks base method
+func (m *MockGuard) Finish(ctx *gin.Context, key string, outcome idempotency.Outcome) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Finish", ctx, key, outcome)
+}
+
+// Finish indicates an expected call of Finish
+func (mr *MockGuardMockRecorder) Finish(ctx, key, outcome interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Finish", reflect.TypeOf((*MockGuard)(nil).Finish), ctx, key, outcome)
+}
@@ -0,0 +1,83 @@
+package idempotency
+
+import (
+	"ccg-api/email/redis"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+const redisKeyPrefix = "ccg:idempotency:"
+
+type redisRecord struct {
+	RequestHash string   `json:"request_hash"`
+	Outcome     *Outcome `json:"outcome,omitempty"`
+}
+
+type redisStore struct {
+	client redis.Client
+}
+
+// NewRedisStore shares records between instances, so a retry is recognised whichever instance it reaches.
+// Redis expires the records once the window has passed.
+func NewRedisStore(client redis.Client) Store {
+	return &redisStore{client: client}
+}
+
+func (store *redisStore) Reserve(key string, requestHash string, window time.Duration) (Record, bool, error) {
+	value, err := json.Marshal(redisRecord{RequestHash: requestHash})
+	if err != nil {
+		return Record{}, false, err
+	}
+	// the record may expire between SET and GET, the key is then free to claim again
+	for attempt := 0; attempt < 2; attempt++ {
+		reply, err := store.client.Do("SET", redisKeyPrefix+key, string(value), "NX", "PX", strconv.FormatInt(window.Milliseconds(), 10))
+		if err != nil {
+			return Record{}, false, err
+		}
+		if reply == "OK" {
+			return Record{}, false, nil
+		}
+		record, found, err := store.get(key)
+		if err != nil || found {
+			return record, found, err
+		}
+	}
+	return Record{}, false, fmt.Errorf("idempotency key %s could neither be claimed nor read", key)
+}
+
+// Complete keeps the remaining time to live of the record, a record that already expired is not brought back
+func (store *redisStore) Complete(key string, outcome Outcome) error {
+	record, found, err := store.get(key)
+	if err != nil || !found {
+		return err
+	}
+	value, err := json.Marshal(redisRecord{RequestHash: record.RequestHash, Outcome: &outcome})
+	if err != nil {
+		return err
+	}
+	_, err = store.client.Do("SET", redisKeyPrefix+key, string(value), "XX", "KEEPTTL")
+	return err
+}
+
+func (store *redisStore) Release(key string) error {
+	_, err := store.client.Do("DEL", redisKeyPrefix+key)
+	return err
+}
+
+func (store *redisStore) get(key string) (Record, bool, error) {
+	reply, err := store.client.Do("GET", redisKeyPrefix+key)
+	if err != nil || reply == nil {
+		return Record{}, false, err
+	}
+	value, ok := reply.(string)
+	if !ok {
+		return Record{}, false, fmt.Errorf("unexpected redis reply %v", reply)
+	}
+	var record redisRecord
+	if err := json.Unmarshal([]byte(value), &record); err != nil {
+		return Record{}, false, err
+	}
+	return Record{Key: key, RequestHash: record.RequestHash, Outcome: record.Outcome}, true, nil
+}
@@ -0,0 +1,80 @@
+package idempotency
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	MemoryStore = "memory"
+	RedisStore  = "redis"
+)
+
+type Outcome struct {
+	StatusCode int
+	Body       []byte
+}
+
+type Record struct {
+	Key         string
+	RequestHash string
+	Outcome     *Outcome
+	ExpiresAt   time.Time
+}
+
+// Store keeps idempotency records; a record without an outcome is still being processed
+type Store interface {
+	// Reserve claims the key for the request hash, or returns the live record if the key is already claimed
+	Reserve(key string, requestHash string, window time.Duration) (Record, bool, error)
+	Complete(key string, outcome Outcome) error
+	Release(key string) error
+}
+
+type memoryStore struct {
+	mutex   sync.Mutex
+	records map[string]Record
+	now     func() time.Time
+}
+
+// NewMemoryStore keeps records per instance, behind a load balancer a retry reaching another instance is processed again
+func NewMemoryStore() Store {
+	return &memoryStore{records: map[string]Record{}, now: time.Now}
+}
+
+func (store *memoryStore) Reserve(key string, requestHash string, window time.Duration) (Record, bool, error) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+	now := store.now()
+	store.removeExpired(now)
+
+	if record, found := store.records[key]; found {
+		return record, true, nil
+	}
+	store.records[key] = Record{Key: key, RequestHash: requestHash, ExpiresAt: now.Add(window)}
+	return Record{}, false, nil
+}
+
+func (store *memoryStore) Complete(key string, outcome Outcome) error {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+	if record, found := store.records[key]; found {
+		record.Outcome = &outcome
+		store.records[key] = record
+	}
+	return nil
+}
+
+func (store *memoryStore) Release(key string) error {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+	delete(store.records, key)
+	return nil
+}
+
+func (store *memoryStore) removeExpired(now time.Time) {
+	for key, record := range store.records {
+		if !record.ExpiresAt.After(now) {
+			delete(store.records, key)
+		}
+	}
+}
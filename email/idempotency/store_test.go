@@ -0,0 +1,132 @@
+package idempotency
+
+import (
+	"ccg-api/email/redis/mocks"
+	"errors"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/suite"
+	"testing"
+	"time"
+)
+
+type memoryStoreTestSuite struct {
+	suite.Suite
+	now   time.Time
+	store *memoryStore
+}
+
+func TestMemoryStoreTestSuite(t *testing.T) {
+	suite.Run(t, new(memoryStoreTestSuite))
+}
+
+func (suite *memoryStoreTestSuite) SetupTest() {
+	suite.now = time.Date(2020, 9, 20, 10, 0, 0, 0, time.UTC)
+	suite.store = NewMemoryStore().(*memoryStore)
+	suite.store.now = func() time.Time { return suite.now }
+}
+
+func (suite *memoryStoreTestSuite) TestReserve_ShouldReturnExistingRecordWithinWindow() {
+	_, found, _ := suite.store.Reserve("some-key", "hash", time.Hour)
+	suite.False(found)
+	_ = suite.store.Complete("some-key", Outcome{StatusCode: 200})
+
+	suite.now = suite.now.Add(59 * time.Minute)
+	record, found, err := suite.store.Reserve("some-key", "other-hash", time.Hour)
+
+	suite.Nil(err)
+	suite.True(found)
+	suite.Equal("hash", record.RequestHash)
+	suite.Equal(&Outcome{StatusCode: 200}, record.Outcome)
+}
+
+func (suite *memoryStoreTestSuite) TestReserve_ShouldForgetKeyOnceWindowHasPassed() {
+	_, _, _ = suite.store.Reserve("some-key", "hash", time.Hour)
+	_ = suite.store.Complete("some-key", Outcome{StatusCode: 200})
+
+	suite.now = suite.now.Add(time.Hour)
+	_, found, err := suite.store.Reserve("some-key", "other-hash", time.Hour)
+
+	suite.Nil(err)
+	suite.False(found)
+	suite.Len(suite.store.records, 1)
+}
+
+type redisStoreTestSuite struct {
+	suite.Suite
+	mockCtrl *gomock.Controller
+	client   *mocks.MockClient
+	store    Store
+}
+
+func TestRedisStoreTestSuite(t *testing.T) {
+	suite.Run(t, new(redisStoreTestSuite))
+}
+
+func (suite *redisStoreTestSuite) SetupTest() {
+	suite.mockCtrl = gomock.NewController(suite.T())
+	suite.client = mocks.NewMockClient(suite.mockCtrl)
+	suite.store = NewRedisStore(suite.client)
+}
+
+func (suite *redisStoreTestSuite) TearDownTest() {
+	suite.mockCtrl.Finish()
+}
+
+func (suite *redisStoreTestSuite) TestReserve_ShouldClaimFreeKeyForTheWindow() {
+	suite.client.EXPECT().Do("SET", "ccg:idempotency:some-key", `{"request_hash":"hash"}`, "NX", "PX", "3600000").Return("OK", nil)
+
+	_, found, err := suite.store.Reserve("some-key", "hash", time.Hour)
+
+	suite.Nil(err)
+	suite.False(found)
+}
+
+func (suite *redisStoreTestSuite) TestReserve_ShouldReturnRecordOfClaimedKey() {
+	gomock.InOrder(
+		suite.client.EXPECT().Do("SET", "ccg:idempotency:some-key", `{"request_hash":"other-hash"}`, "NX", "PX", "3600000").Return(nil, nil),
+		suite.client.EXPECT().Do("GET", "ccg:idempotency:some-key").Return(`{"request_hash":"hash","outcome":{"StatusCode":200,"Body":"e30="}}`, nil),
+	)
+
+	record, found, err := suite.store.Reserve("some-key", "other-hash", time.Hour)
+
+	suite.Nil(err)
+	suite.True(found)
+	suite.Equal("hash", record.RequestHash)
+	suite.Equal(&Outcome{StatusCode: 200, Body: []byte("{}")}, record.Outcome)
+}
+
+func (suite *redisStoreTestSuite) TestReserve_ShouldClaimKeyThatExpiredBeforeItCouldBeRead() {
+	gomock.InOrder(
+		suite.client.EXPECT().Do("SET", gomock.Any(), gomock.Any(), "NX", "PX", "3600000").Return(nil, nil),
+		suite.client.EXPECT().Do("GET", "ccg:idempotency:some-key").Return(nil, nil),
+		suite.client.EXPECT().Do("SET", gomock.Any(), gomock.Any(), "NX", "PX", "3600000").Return("OK", nil),
+	)
+
+	_, found, err := suite.store.Reserve("some-key", "hash", time.Hour)
+
+	suite.Nil(err)
+	suite.False(found)
+}
+
+func (suite *redisStoreTestSuite) TestReserve_ShouldReturnErrorOfClient() {
+	suite.client.EXPECT().Do(gomock.Any()).Return(nil, errors.New("connection refused"))
+
+	_, _, err := suite.store.Reserve("some-key", "hash", time.Hour)
+
+	suite.EqualError(err, "connection refused")
+}
+
+func (suite *redisStoreTestSuite) TestComplete_ShouldStoreOutcomeKeepingTimeToLive() {
+	gomock.InOrder(
+		suite.client.EXPECT().Do("GET", "ccg:idempotency:some-key").Return(`{"request_hash":"hash"}`, nil),
+		suite.client.EXPECT().Do("SET", "ccg:idempotency:some-key", `{"request_hash":"hash","outcome":{"StatusCode":200,"Body":"e30="}}`, "XX", "KEEPTTL").Return("OK", nil),
+	)
+
+	suite.Nil(suite.store.Complete("some-key", Outcome{StatusCode: 200, Body: []byte("{}")}))
+}
+
+func (suite *redisStoreTestSuite) TestRelease_ShouldDeleteKey() {
+	suite.client.EXPECT().Do("DEL", "ccg:idempotency:some-key").Return(int64(1), nil)
+
+	suite.Nil(suite.store.Release("some-key"))
+}
@@ -6,6 +6,7 @@ package mocks
 
 import (
 	configuration "ccg-api/configuration"
+	configuration0 "ccg-api/email/configuration"
 	gomock "github.com/golang/mock/gomock"
 	reflect "reflect"
 )
@@ -131,18 +132,116 @@ func (mr *MockEmailClientConfigMockRecorder) ValidGolaEmailDomain() *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ValidGolaEmailDomain", reflect.TypeOf((*MockEmailClientConfig)(nil).ValidGolaEmailDomain))
 }
 
-// DefaultGolaEmailSender mocks base method
-func (m *MockEmailClientConfig) DefaultGolaEmailSender() string {
+// AllValidGolaEmailDomains mocks base method
+func (m *MockEmailClientConfig) AllValidGolaEmailDomains() []string {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DefaultGolaEmailSender")
+	ret := m.ctrl.Call(m, "AllValidGolaEmailDomains")
+	ret0, _ := ret[0].([]string)
+	return ret0
+}
+
+// AllValidGolaEmailDomains indicates an expected call of AllValidGolaEmailDomains
+func (mr *MockEmailClientConfigMockRecorder) AllValidGolaEmailDomains() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AllValidGolaEmailDomains", reflect.TypeOf((*MockEmailClientConfig)(nil).AllValidGolaEmailDomains))
+}
+
+// Tenants mocks base method
+func (m *MockEmailClientConfig) Tenants() []configuration.Tenant {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Tenants")
+	ret0, _ := ret[0].([]configuration.Tenant)
+	return ret0
+}
+
+// Tenants indicates an expected call of Tenants
+func (mr *MockEmailClientConfigMockRecorder) Tenants() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Tenants", reflect.TypeOf((*MockEmailClientConfig)(nil).Tenants))
+}
+
+// DefaultTenant mocks base method
+func (m *MockEmailClientConfig) DefaultTenant() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DefaultTenant")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// DefaultTenant indicates an expected call of DefaultTenant
+func (mr *MockEmailClientConfigMockRecorder) DefaultTenant() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DefaultTenant", reflect.TypeOf((*MockEmailClientConfig)(nil).DefaultTenant))
+}
+
+// ForTenant mocks base method
+func (m *MockEmailClientConfig) ForTenant(name string) configuration0.EmailClientConfig {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ForTenant", name)
+	ret0, _ := ret[0].(configuration0.EmailClientConfig)
+	return ret0
+}
+
+// ForTenant indicates an expected call of ForTenant
+func (mr *MockEmailClientConfigMockRecorder) ForTenant(name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ForTenant", reflect.TypeOf((*MockEmailClientConfig)(nil).ForTenant), name)
+}
+
+// DefaultSender mocks base method
+func (m *MockEmailClientConfig) DefaultSender() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DefaultSender")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// DefaultSender indicates an expected call of DefaultSender
+func (mr *MockEmailClientConfigMockRecorder) DefaultSender() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DefaultSender", reflect.TypeOf((*MockEmailClientConfig)(nil).DefaultSender))
+}
+
+// DisplayName mocks base method
+func (m *MockEmailClientConfig) DisplayName() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DisplayName")
 	ret0, _ := ret[0].(string)
 	return ret0
 }
 
-// DefaultGolaEmailSender indicates an expected call of DefaultGolaEmailSender
-func (mr *MockEmailClientConfigMockRecorder) DefaultGolaEmailSender() *gomock.Call {
+// DisplayName indicates an expected call of DisplayName
+func (mr *MockEmailClientConfigMockRecorder) DisplayName() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DefaultGolaEmailSender", reflect.TypeOf((*MockEmailClientConfig)(nil).DefaultGolaEmailSender))
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DisplayName", reflect.TypeOf((*MockEmailClientConfig)(nil).DisplayName))
+}
+
+// FooterText mocks base method
+func (m *MockEmailClientConfig) FooterText() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FooterText")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// FooterText indicates an expected call of FooterText
+func (mr *MockEmailClientConfigMockRecorder) FooterText() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FooterText", reflect.TypeOf((*MockEmailClientConfig)(nil).FooterText))
+}
+
+// SenderPolicies mocks base method
+func (m *MockEmailClientConfig) SenderPolicies() []configuration.SenderPolicy {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SenderPolicies")
+	ret0, _ := ret[0].([]configuration.SenderPolicy)
+	return ret0
+}
+
+// SenderPolicies indicates an expected call of SenderPolicies
+func (mr *MockEmailClientConfigMockRecorder) SenderPolicies() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SenderPolicies", reflect.TypeOf((*MockEmailClientConfig)(nil).SenderPolicies))
 }
 
 // UnsupportedAttachmentExtensions mocks base method
@@ -173,6 +272,76 @@ func (mr *MockEmailClientConfigMockRecorder) PermissibleTotalSizeOfAttachments()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PermissibleTotalSizeOfAttachments", reflect.TypeOf((*MockEmailClientConfig)(nil).PermissibleTotalSizeOfAttachments))
 }
 
+// MaxRecipients mocks base method
+func (m *MockEmailClientConfig) MaxRecipients() int {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MaxRecipients")
+	ret0, _ := ret[0].(int)
+	return ret0
+}
+
+// MaxRecipients indicates an expected call of MaxRecipients
+func (mr *MockEmailClientConfigMockRecorder) MaxRecipients() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MaxRecipients", reflect.TypeOf((*MockEmailClientConfig)(nil).MaxRecipients))
+}
+
+// MaxBatchSize mocks base method
+func (m *MockEmailClientConfig) MaxBatchSize() int {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MaxBatchSize")
+	ret0, _ := ret[0].(int)
+	return ret0
+}
+
+// MaxBatchSize indicates an expected call of MaxBatchSize
+func (mr *MockEmailClientConfigMockRecorder) MaxBatchSize() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MaxBatchSize", reflect.TypeOf((*MockEmailClientConfig)(nil).MaxBatchSize))
+}
+
+// BatchWorkers mocks base method
+func (m *MockEmailClientConfig) BatchWorkers() int {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BatchWorkers")
+	ret0, _ := ret[0].(int)
+	return ret0
+}
+
+// BatchWorkers indicates an expected call of BatchWorkers
+func (mr *MockEmailClientConfigMockRecorder) BatchWorkers() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BatchWorkers", reflect.TypeOf((*MockEmailClientConfig)(nil).BatchWorkers))
+}
+
+// MaxScheduleAheadInDays mocks base method
+func (m *MockEmailClientConfig) MaxScheduleAheadInDays() int {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MaxScheduleAheadInDays")
+	ret0, _ := ret[0].(int)
+	return ret0
+}
+
+// MaxScheduleAheadInDays indicates an expected call of MaxScheduleAheadInDays
+func (mr *MockEmailClientConfigMockRecorder) MaxScheduleAheadInDays() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MaxScheduleAheadInDays", reflect.TypeOf((*MockEmailClientConfig)(nil).MaxScheduleAheadInDays))
+}
+
+// AllowedCustomHeaders mocks base method
+func (m *MockEmailClientConfig) AllowedCustomHeaders() []string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AllowedCustomHeaders")
+	ret0, _ := ret[0].([]string)
+	return ret0
+}
+
+// AllowedCustomHeaders indicates an expected call of AllowedCustomHeaders
+func (mr *MockEmailClientConfigMockRecorder) AllowedCustomHeaders() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AllowedCustomHeaders", reflect.TypeOf((*MockEmailClientConfig)(nil).AllowedCustomHeaders))
+}
+
 // BaseTemplateFilePath mocks base method
 func (m *MockEmailClientConfig) BaseTemplateFilePath() string {
 	m.ctrl.T.Helper()
@@ -187,6 +356,20 @@ func (mr *MockEmailClientConfigMockRecorder) BaseTemplateFilePath() *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BaseTemplateFilePath", reflect.TypeOf((*MockEmailClientConfig)(nil).BaseTemplateFilePath))
 }
 
+// TemplateDirectory mocks base method
+func (m *MockEmailClientConfig) TemplateDirectory() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TemplateDirectory")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// TemplateDirectory indicates an expected call of TemplateDirectory
+func (mr *MockEmailClientConfigMockRecorder) TemplateDirectory() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TemplateDirectory", reflect.TypeOf((*MockEmailClientConfig)(nil).TemplateDirectory))
+}
+
 // LogoUrls mocks base method
 func (m *MockEmailClientConfig) LogoUrls() configuration.LogoUrls {
 	m.ctrl.T.Helper()
@@ -201,6 +384,34 @@ func (mr *MockEmailClientConfigMockRecorder) LogoUrls() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LogoUrls", reflect.TypeOf((*MockEmailClientConfig)(nil).LogoUrls))
 }
 
+// EmbedLogos mocks base method
+func (m *MockEmailClientConfig) EmbedLogos() bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EmbedLogos")
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// EmbedLogos indicates an expected call of EmbedLogos
+func (mr *MockEmailClientConfigMockRecorder) EmbedLogos() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EmbedLogos", reflect.TypeOf((*MockEmailClientConfig)(nil).EmbedLogos))
+}
+
+// LogoFiles mocks base method
+func (m *MockEmailClientConfig) LogoFiles() configuration.LogoFiles {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LogoFiles")
+	ret0, _ := ret[0].(configuration.LogoFiles)
+	return ret0
+}
+
+// LogoFiles indicates an expected call of LogoFiles
+func (mr *MockEmailClientConfigMockRecorder) LogoFiles() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LogoFiles", reflect.TypeOf((*MockEmailClientConfig)(nil).LogoFiles))
+}
+
 // OtherUrls mocks base method
 func (m *MockEmailClientConfig) OtherUrls() configuration.Urls {
 	m.ctrl.T.Helper()
@@ -214,3 +425,353 @@ func (mr *MockEmailClientConfigMockRecorder) OtherUrls() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OtherUrls", reflect.TypeOf((*MockEmailClientConfig)(nil).OtherUrls))
 }
+
+// Outbox mocks base method
+func (m *MockEmailClientConfig) Outbox() configuration.Outbox {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Outbox")
+	ret0, _ := ret[0].(configuration.Outbox)
+	return ret0
+}
+
+// Outbox indicates an expected call of Outbox
+func (mr *MockEmailClientConfigMockRecorder) Outbox() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Outbox", reflect.TypeOf((*MockEmailClientConfig)(nil).Outbox))
+}
+
+// SendRetryPolicy mocks base method
+func (m *MockEmailClientConfig) SendRetryPolicy() configuration.RetryPolicy {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendRetryPolicy")
+	ret0, _ := ret[0].(configuration.RetryPolicy)
+	return ret0
+}
+
+// SendRetryPolicy indicates an expected call of SendRetryPolicy
+func (mr *MockEmailClientConfigMockRecorder) SendRetryPolicy() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendRetryPolicy", reflect.TypeOf((*MockEmailClientConfig)(nil).SendRetryPolicy))
+}
+
+// MessageStatus mocks base method
+func (m *MockEmailClientConfig) MessageStatus() configuration.MessageStatus {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MessageStatus")
+	ret0, _ := ret[0].(configuration.MessageStatus)
+	return ret0
+}
+
+// MessageStatus indicates an expected call of MessageStatus
+func (mr *MockEmailClientConfigMockRecorder) MessageStatus() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MessageStatus", reflect.TypeOf((*MockEmailClientConfig)(nil).MessageStatus))
+}
+
+// Suppression mocks base method
+func (m *MockEmailClientConfig) Suppression() configuration.Suppression {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Suppression")
+	ret0, _ := ret[0].(configuration.Suppression)
+	return ret0
+}
+
+// Suppression indicates an expected call of Suppression
+func (mr *MockEmailClientConfigMockRecorder) Suppression() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Suppression", reflect.TypeOf((*MockEmailClientConfig)(nil).Suppression))
+}
+
+// Bounce mocks base method
+func (m *MockEmailClientConfig) Bounce() configuration.Bounce {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Bounce")
+	ret0, _ := ret[0].(configuration.Bounce)
+	return ret0
+}
+
+// Bounce indicates an expected call of Bounce
+func (mr *MockEmailClientConfigMockRecorder) Bounce() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Bounce", reflect.TypeOf((*MockEmailClientConfig)(nil).Bounce))
+}
+
+// BounceMailboxPassword mocks base method
+func (m *MockEmailClientConfig) BounceMailboxPassword() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BounceMailboxPassword")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// BounceMailboxPassword indicates an expected call of BounceMailboxPassword
+func (mr *MockEmailClientConfigMockRecorder) BounceMailboxPassword() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BounceMailboxPassword", reflect.TypeOf((*MockEmailClientConfig)(nil).BounceMailboxPassword))
+}
+
+// Webhooks mocks base method
+func (m *MockEmailClientConfig) Webhooks() []configuration.Webhook {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Webhooks")
+	ret0, _ := ret[0].([]configuration.Webhook)
+	return ret0
+}
+
+// Webhooks indicates an expected call of Webhooks
+func (mr *MockEmailClientConfigMockRecorder) Webhooks() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Webhooks", reflect.TypeOf((*MockEmailClientConfig)(nil).Webhooks))
+}
+
+// WebhookSecret mocks base method
+func (m *MockEmailClientConfig) WebhookSecret(webhook configuration.Webhook) string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WebhookSecret", webhook)
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// WebhookSecret indicates an expected call of WebhookSecret
+func (mr *MockEmailClientConfigMockRecorder) WebhookSecret(webhook interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WebhookSecret", reflect.TypeOf((*MockEmailClientConfig)(nil).WebhookSecret), webhook)
+}
+
+// Unsubscribe mocks base method
+func (m *MockEmailClientConfig) Unsubscribe() configuration.Unsubscribe {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Unsubscribe")
+	ret0, _ := ret[0].(configuration.Unsubscribe)
+	return ret0
+}
+
+// Unsubscribe indicates an expected call of Unsubscribe
+func (mr *MockEmailClientConfigMockRecorder) Unsubscribe() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Unsubscribe", reflect.TypeOf((*MockEmailClientConfig)(nil).Unsubscribe))
+}
+
+// Categories mocks base method
+func (m *MockEmailClientConfig) Categories() []configuration.Category {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Categories")
+	ret0, _ := ret[0].([]configuration.Category)
+	return ret0
+}
+
+// Categories indicates an expected call of Categories
+func (mr *MockEmailClientConfigMockRecorder) Categories() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Categories", reflect.TypeOf((*MockEmailClientConfig)(nil).Categories))
+}
+
+// DefaultCategory mocks base method
+func (m *MockEmailClientConfig) DefaultCategory() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DefaultCategory")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// DefaultCategory indicates an expected call of DefaultCategory
+func (mr *MockEmailClientConfigMockRecorder) DefaultCategory() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DefaultCategory", reflect.TypeOf((*MockEmailClientConfig)(nil).DefaultCategory))
+}
+
+// RateLimiting mocks base method
+func (m *MockEmailClientConfig) RateLimiting() configuration.RateLimiting {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RateLimiting")
+	ret0, _ := ret[0].(configuration.RateLimiting)
+	return ret0
+}
+
+// RateLimiting indicates an expected call of RateLimiting
+func (mr *MockEmailClientConfigMockRecorder) RateLimiting() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RateLimiting", reflect.TypeOf((*MockEmailClientConfig)(nil).RateLimiting))
+}
+
+// Redis mocks base method
+func (m *MockEmailClientConfig) Redis() configuration.Redis {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Redis")
+	ret0, _ := ret[0].(configuration.Redis)
+	return ret0
+}
+
+// Redis indicates an expected call of Redis
+func (mr *MockEmailClientConfigMockRecorder) Redis() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Redis", reflect.TypeOf((*MockEmailClientConfig)(nil).Redis))
+}
+
+// RedisPassword mocks base method
+func (m *MockEmailClientConfig) RedisPassword() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RedisPassword")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// RedisPassword indicates an expected call of RedisPassword
+func (mr *MockEmailClientConfigMockRecorder) RedisPassword() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RedisPassword", reflect.TypeOf((*MockEmailClientConfig)(nil).RedisPassword))
+}
+
+// Idempotency mocks base method
+func (m *MockEmailClientConfig) Idempotency() configuration.Idempotency {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Idempotency")
+	ret0, _ := ret[0].(configuration.Idempotency)
+	return ret0
+}
+
+// Idempotency indicates an expected call of Idempotency
+func (mr *MockEmailClientConfigMockRecorder) Idempotency() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Idempotency", reflect.TypeOf((*MockEmailClientConfig)(nil).Idempotency))
+}
+
+// Dkim mocks base method
+func (m *MockEmailClientConfig) Dkim() configuration.Dkim {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Dkim")
+	ret0, _ := ret[0].(configuration.Dkim)
+	return ret0
+}
+
+// Dkim indicates an expected call of Dkim
+func (mr *MockEmailClientConfigMockRecorder) Dkim() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Dkim", reflect.TypeOf((*MockEmailClientConfig)(nil).Dkim))
+}
+
+// Transport mocks base method
+func (m *MockEmailClientConfig) Transport() configuration.Transport {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Transport")
+	ret0, _ := ret[0].(configuration.Transport)
+	return ret0
+}
+
+// Transport indicates an expected call of Transport
+func (mr *MockEmailClientConfigMockRecorder) Transport() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Transport", reflect.TypeOf((*MockEmailClientConfig)(nil).Transport))
+}
+
+// Relays mocks base method
+func (m *MockEmailClientConfig) Relays() []configuration.Relay {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Relays")
+	ret0, _ := ret[0].([]configuration.Relay)
+	return ret0
+}
+
+// Relays indicates an expected call of Relays
+func (mr *MockEmailClientConfigMockRecorder) Relays() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Relays", reflect.TypeOf((*MockEmailClientConfig)(nil).Relays))
+}
+
+// RelayPassword mocks base method
+func (m *MockEmailClientConfig) RelayPassword(relay configuration.Relay) string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RelayPassword", relay)
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// RelayPassword indicates an expected call of RelayPassword
+func (mr *MockEmailClientConfigMockRecorder) RelayPassword(relay interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RelayPassword", reflect.TypeOf((*MockEmailClientConfig)(nil).RelayPassword), relay)
+}
+
+// RelayCircuitBreaker mocks base method
+func (m *MockEmailClientConfig) RelayCircuitBreaker() configuration.CircuitBreaker {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RelayCircuitBreaker")
+	ret0, _ := ret[0].(configuration.CircuitBreaker)
+	return ret0
+}
+
+// RelayCircuitBreaker indicates an expected call of RelayCircuitBreaker
+func (mr *MockEmailClientConfigMockRecorder) RelayCircuitBreaker() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RelayCircuitBreaker", reflect.TypeOf((*MockEmailClientConfig)(nil).RelayCircuitBreaker))
+}
+
+// ConnectionPool mocks base method
+func (m *MockEmailClientConfig) ConnectionPool() configuration.ConnectionPool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConnectionPool")
+	ret0, _ := ret[0].(configuration.ConnectionPool)
+	return ret0
+}
+
+// ConnectionPool indicates an expected call of ConnectionPool
+func (mr *MockEmailClientConfigMockRecorder) ConnectionPool() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConnectionPool", reflect.TypeOf((*MockEmailClientConfig)(nil).ConnectionPool))
+}
+
+// ApiKey mocks base method
+func (m *MockEmailClientConfig) ApiKey() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ApiKey")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// ApiKey indicates an expected call of ApiKey
+func (mr *MockEmailClientConfigMockRecorder) ApiKey() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ApiKey", reflect.TypeOf((*MockEmailClientConfig)(nil).ApiKey))
+}
+
+// UnsubscribeSecret mocks base method
+func (m *MockEmailClientConfig) UnsubscribeSecret() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UnsubscribeSecret")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// UnsubscribeSecret indicates an expected call of UnsubscribeSecret
+func (mr *MockEmailClientConfigMockRecorder) UnsubscribeSecret() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnsubscribeSecret", reflect.TypeOf((*MockEmailClientConfig)(nil).UnsubscribeSecret))
+}
+
+// Tracking mocks base method
+func (m *MockEmailClientConfig) Tracking() configuration.Tracking {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Tracking")
+	ret0, _ := ret[0].(configuration.Tracking)
+	return ret0
+}
+
+// Tracking indicates an expected call of Tracking
+func (mr *MockEmailClientConfigMockRecorder) Tracking() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Tracking", reflect.TypeOf((*MockEmailClientConfig)(nil).Tracking))
+}
+
+// TrackingSecret mocks base method
+func (m *MockEmailClientConfig) TrackingSecret() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TrackingSecret")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// TrackingSecret indicates an expected call of TrackingSecret
+func (mr *MockEmailClientConfigMockRecorder) TrackingSecret() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TrackingSecret", reflect.TypeOf((*MockEmailClientConfig)(nil).TrackingSecret))
+}
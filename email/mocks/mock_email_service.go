@@ -36,11 +36,12 @@ func (m *MockEmailService) EXPECT() *MockEmailServiceMockRecorder {
 }
 
 // Send mocks base method
-func (m *MockEmailService) Send(ctx *gin.Context, email models.Email) *golaerror.Error {
+func (m *MockEmailService) Send(ctx *gin.Context, email models.Email) (models.SendReceipt, *golaerror.Error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "Send", ctx, email)
-	ret0, _ := ret[0].(*golaerror.Error)
-	return ret0
+	ret0, _ := ret[0].(models.SendReceipt)
+	ret1, _ := ret[1].(*golaerror.Error)
+	return ret0, ret1
 }
 
 // Send indicates an expected call of Send
@@ -48,3 +49,32 @@ func (mr *MockEmailServiceMockRecorder) Send(ctx, email interface{}) *gomock.Cal
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Send", reflect.TypeOf((*MockEmailService)(nil).Send), ctx, email)
 }
+
+// Enqueue mocks base method
+func (m *MockEmailService) Enqueue(ctx *gin.Context, email models.Email) (models.SendReceipt, *golaerror.Error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Enqueue", ctx, email)
+	ret0, _ := ret[0].(models.SendReceipt)
+	ret1, _ := ret[1].(*golaerror.Error)
+	return ret0, ret1
+}
+
+// Enqueue indicates an expected call of Enqueue
+func (mr *MockEmailServiceMockRecorder) Enqueue(ctx, email interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Enqueue", reflect.TypeOf((*MockEmailService)(nil).Enqueue), ctx, email)
+}
+
+// Cancel mocks base method
+func (m *MockEmailService) Cancel(ctx *gin.Context, clientID, messageID string) *golaerror.Error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Cancel", ctx, clientID, messageID)
+	ret0, _ := ret[0].(*golaerror.Error)
+	return ret0
+}
+
+// Cancel indicates an expected call of Cancel
+func (mr *MockEmailServiceMockRecorder) Cancel(ctx, clientID, messageID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Cancel", reflect.TypeOf((*MockEmailService)(nil).Cancel), ctx, clientID, messageID)
+}
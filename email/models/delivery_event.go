@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+type DeliveryEventType string
+
+const (
+	DeliveredEvent    DeliveryEventType = "delivered"
+	DeferredEvent     DeliveryEventType = "deferred"
+	HardBounceEvent   DeliveryEventType = "hard_bounce"
+	ComplaintEvent    DeliveryEventType = "complaint"
+	UnsubscribedEvent DeliveryEventType = "unsubscribed"
+	OpenedEvent       DeliveryEventType = "opened"
+	ClickedEvent      DeliveryEventType = "clicked"
+)
+
+// DeliveryEvent is what a provider webhook or a bounce notification tells about one recipient of one of our messages.
+// Source names where it came from, e.g. brevo or bounce.
+type DeliveryEvent struct {
+	Source     string
+	Type       DeliveryEventType
+	MessageID  string
+	Recipient  string
+	Reason     string
+	OccurredAt time.Time
+}
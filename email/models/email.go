@@ -1,20 +1,50 @@
 package models
 
+import "time"
+
 type Email struct {
-	From                string
+	From string
+	// FromName is the display name of the sender
+	FromName            string
 	To                  []string
+	Cc                  []string
+	Bcc                 []string
+	ReplyTo             string
+	Headers             map[string]string
 	Subject             string
 	Body                MessageBody
 	Attachments         []Attachment
 	IncludeBaseTemplate bool
+	Category            string
+	// Track rewrites the links of an HTML body and adds an open pixel, to count opens and clicks
+	Track bool
+	// SendAt defers delivery until the given time, zero sends as soon as possible
+	SendAt time.Time
+	// Tenant is the product the email is sent for, it decides the brand and the SMTP relays
+	Tenant string
+	// ClientID is the authenticated client that sent the email, only it can look up or cancel the message
+	ClientID string
+}
+
+// Recipients lists every address the email is delivered to, including Bcc
+func (email Email) Recipients() []string {
+	recipients := make([]string, 0, len(email.To)+len(email.Cc)+len(email.Bcc))
+	recipients = append(recipients, email.To...)
+	recipients = append(recipients, email.Cc...)
+	return append(recipients, email.Bcc...)
 }
 
 type Attachment struct {
 	FileName string
 	Data     []byte
+	// Inline attachments are embedded in the message and referenced from the html body as cid:ContentID
+	Inline    bool
+	ContentID string
 }
 
 type MessageBody struct {
 	MimeType string
 	Content  string
+	// PlainText is sent as the text/plain alternative of an HTML Content when present
+	PlainText string
 }
@@ -0,0 +1,118 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+type MessageState string
+
+const (
+	Accepted   MessageState = "accepted"
+	Scheduled  MessageState = "scheduled"
+	Cancelled  MessageState = "cancelled"
+	Sending    MessageState = "sending"
+	Sent       MessageState = "sent"
+	Retrying   MessageState = "retrying"
+	Failed     MessageState = "failed"
+	Delivered  MessageState = "delivered"
+	Bounced    MessageState = "bounced"
+	Complained MessageState = "complained"
+)
+
+type MessageStatus struct {
+	ID              string             `json:"id"`
+	ClientID        string             `json:"client_id,omitempty"`
+	From            string             `json:"from"`
+	RecipientHashes []string           `json:"recipient_hashes"`
+	State           MessageState       `json:"state"`
+	Attempts        int                `json:"attempts"`
+	LastError       string             `json:"last_error,omitempty"`
+	CreatedAt       time.Time          `json:"created_at"`
+	UpdatedAt       time.Time          `json:"updated_at"`
+	History         []StateTransition  `json:"history"`
+	Outcomes        []RecipientOutcome `json:"outcomes,omitempty"`
+	Engagement      *Engagement        `json:"engagement,omitempty"`
+}
+
+// RecipientOutcome is what delivery events reported for one recipient of a message
+type RecipientOutcome struct {
+	RecipientHash string       `json:"recipient_hash"`
+	State         MessageState `json:"state"`
+	At            time.Time    `json:"at"`
+	Error         string       `json:"error,omitempty"`
+}
+
+// Engagement of a tracked message; opens are approximate, since mail clients block or prefetch images
+type Engagement struct {
+	Opens          int        `json:"opens"`
+	Clicks         int        `json:"clicks"`
+	FirstOpenedAt  *time.Time `json:"first_opened_at,omitempty"`
+	FirstClickedAt *time.Time `json:"first_clicked_at,omitempty"`
+}
+
+// stateRanks orders states by how far a message got, delivery events can arrive before the outbox records the message
+// as sent, and a lower ranked state never replaces a higher ranked one
+var stateRanks = map[MessageState]int{
+	Accepted:   0,
+	Scheduled:  1,
+	Sending:    2,
+	Retrying:   2,
+	Sent:       3,
+	Failed:     4,
+	Cancelled:  4,
+	Delivered:  5,
+	Bounced:    6,
+	Complained: 7,
+}
+
+// Supersedes is false for a state that would take the message back to an earlier stage
+func (state MessageState) Supersedes(other MessageState) bool {
+	return stateRanks[state] >= stateRanks[other]
+}
+
+type StateTransition struct {
+	State MessageState `json:"state"`
+	At    time.Time    `json:"at"`
+	Error string       `json:"error,omitempty"`
+}
+
+// MessageStatusFilter always scopes to one client, an empty ClientID being the one of unauthenticated requests
+type MessageStatusFilter struct {
+	ClientID      string
+	RecipientHash string
+	From          string
+	Since         time.Time
+	Until         time.Time
+	Limit         int
+	Cursor        string
+}
+
+type MessageStatusPage struct {
+	Statuses   []MessageStatus
+	NextCursor string
+}
+
+func (filter MessageStatusFilter) Matches(status MessageStatus) bool {
+	if filter.ClientID != status.ClientID {
+		return false
+	}
+	if filter.From != "" && !strings.EqualFold(filter.From, status.From) {
+		return false
+	}
+	if !filter.Since.IsZero() && status.CreatedAt.Before(filter.Since) {
+		return false
+	}
+	if !filter.Until.IsZero() && status.CreatedAt.After(filter.Until) {
+		return false
+	}
+	if filter.RecipientHash == "" {
+		return true
+	}
+	for _, recipientHash := range status.RecipientHashes {
+		if recipientHash == filter.RecipientHash {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// Preference holds the categories a recipient opted out of, keyed by the hash of the address only
+type Preference struct {
+	RecipientHash string               `json:"recipient_hash"`
+	OptOuts       map[string]time.Time `json:"opt_outs"`
+}
+
+func (preference Preference) IsOptedOut(category string) bool {
+	_, optedOut := preference.OptOuts[category]
+	return optedOut
+}
@@ -0,0 +1,16 @@
+package models
+
+import (
+	"net/mail"
+	"strings"
+)
+
+// ParseSender splits an RFC 5322 mailbox like "Narratenet Support" <support@narratenet.com> into its address and
+// display name. Encoded-words in the name are decoded, a bare address has no name.
+func ParseSender(from string) (string, string, error) {
+	sender, err := mail.ParseAddress(strings.TrimSpace(from))
+	if err != nil {
+		return "", "", err
+	}
+	return sender.Address, sender.Name, nil
+}
@@ -0,0 +1,43 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+type SuppressionReason string
+
+const (
+	HardBounce   SuppressionReason = "hard_bounce"
+	Complaint    SuppressionReason = "complaint"
+	Unsubscribed SuppressionReason = "unsubscribed"
+	Manual       SuppressionReason = "manual"
+)
+
+// Suppression stops delivery to Address, which is either a full email address or a whole domain
+type Suppression struct {
+	Address   string            `json:"address"`
+	Reason    SuppressionReason `json:"reason"`
+	Source    string            `json:"source"`
+	CreatedAt time.Time         `json:"created_at"`
+}
+
+func (suppression Suppression) IsDomain() bool {
+	return !strings.Contains(suppression.Address, "@")
+}
+
+// NormalizeSuppressionAddress is the form in which addresses and domains are stored and looked up
+func NormalizeSuppressionAddress(address string) string {
+	return strings.ToLower(strings.TrimSpace(address))
+}
+
+type SuppressedRecipient struct {
+	Address string            `json:"address"`
+	Reason  SuppressionReason `json:"reason"`
+}
+
+// SendReceipt is what the caller learns about an accepted email, including recipients that were dropped
+type SendReceipt struct {
+	MessageID  string
+	Suppressed []SuppressedRecipient
+}
@@ -0,0 +1,20 @@
+package outbox
+
+import (
+	"ccg-api/email/email-client/email_client_request"
+	"time"
+)
+
+type Message struct {
+	ID            string                                  `json:"id"`
+	Request       email_client_request.EmailClientRequest `json:"request"`
+	Attempts      int                                     `json:"attempts"`
+	AcceptedAt    time.Time                               `json:"accepted_at"`
+	NextAttemptAt time.Time                               `json:"next_attempt_at"`
+	LastError     string                                  `json:"last_error,omitempty"`
+	ClaimedAt     time.Time                               `json:"-"`
+}
+
+func (message Message) isDue(now time.Time) bool {
+	return !message.NextAttemptAt.After(now)
+}
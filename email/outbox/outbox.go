@@ -0,0 +1,236 @@
+package outbox
+
+// mockgen -source=email/outbox/outbox.go -destination=email/outbox/mocks/mock_outbox.go -package=mocks
+import (
+	"ccg-api/configuration"
+	"ccg-api/email/email-client"
+	"ccg-api/email/email-client/email_client_request"
+	"ccg-api/email/models"
+	"ccg-api/email/retry"
+	"ccg-api/email/status"
+	"ccg-api/util"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/inclusi-blog/gola-utils/logging"
+	"sync"
+	"time"
+)
+
+const (
+	defaultWorkers      = 1
+	defaultPollInterval = 5 * time.Second
+	defaultClaimTimeout = 10 * time.Minute
+	queueSizePerWorker  = 16
+)
+
+var (
+	ErrMessageNotPending = errors.New("message is not pending in outbox")
+	ErrMessageInFlight   = errors.New("message is already being delivered")
+)
+
+type Outbox interface {
+	Enqueue(ctx *gin.Context, id string, request email_client_request.EmailClientRequest, sendAt time.Time) error
+	Cancel(ctx *gin.Context, id string) error
+	Start() error
+}
+
+// outbox keeps no messages of its own, every instance reads the shared store and claims a message before delivering it
+type outbox struct {
+	store        Store
+	emailClient  email_client.EmailClient
+	tracker      status.Tracker
+	workers      int
+	pollInterval time.Duration
+	claimTimeout time.Duration
+	retryPolicy  retry.Policy
+	queue        chan Message
+
+	mutex sync.Mutex
+}
+
+func NewOutbox(store Store, emailClient email_client.EmailClient, tracker status.Tracker, config configuration.Outbox) Outbox {
+	workers := config.Workers
+	if workers <= 0 {
+		workers = defaultWorkers
+	}
+	claimTimeout := defaultClaimTimeout
+	if config.ClaimTimeoutInSeconds > 0 {
+		claimTimeout = time.Duration(config.ClaimTimeoutInSeconds) * time.Second
+	}
+	return &outbox{
+		store:        store,
+		emailClient:  emailClient,
+		tracker:      tracker,
+		workers:      workers,
+		pollInterval: pollInterval(config.PollIntervalInSeconds),
+		claimTimeout: claimTimeout,
+		retryPolicy:  retry.NewPolicy(config.RetryPolicy),
+		queue:        make(chan Message, workers*queueSizePerWorker),
+	}
+}
+
+// Start begins draining the outbox, messages left in the store by a previous run are picked up by the first poll
+func (outbox *outbox) Start() error {
+	logger := logging.NewLoggerEntry().WithField("class", "Outbox").WithField("method", "Start")
+	dueMessages, err := outbox.store.ListDue(time.Now())
+	if err != nil {
+		logger.Error("Failed to list due messages in outbox store ", err)
+		return err
+	}
+	logger.Infof("Found %d due message(s) in outbox", len(dueMessages))
+
+	for worker := 0; worker < outbox.workers; worker++ {
+		go outbox.work()
+	}
+	go outbox.poll()
+	return nil
+}
+
+// Enqueue holds the message back until sendAt, a zero sendAt delivers it right away
+func (outbox *outbox) Enqueue(ctx *gin.Context, id string, request email_client_request.EmailClientRequest, sendAt time.Time) error {
+	logger := logging.GetLogger(ctx).WithField("class", "Outbox").WithField("method", "Enqueue")
+	now := time.Now()
+	message := Message{
+		ID:            id,
+		Request:       request,
+		AcceptedAt:    now,
+		NextAttemptAt: now,
+	}
+	if sendAt.After(now) {
+		message.NextAttemptAt = sendAt
+	}
+	if err := outbox.store.Save(message); err != nil {
+		logger.Error("Failed to persist message in outbox ", err)
+		return err
+	}
+
+	if message.isDue(now) {
+		outbox.dispatch(message)
+	}
+	logger.Infof("Message %s accepted into outbox for delivery at %s", message.ID, message.NextAttemptAt.Format(time.RFC3339))
+	return nil
+}
+
+// Cancel drops a message that is still waiting in the outbox, one already claimed for delivery can no longer be stopped
+func (outbox *outbox) Cancel(ctx *gin.Context, id string) error {
+	logger := logging.GetLogger(ctx).WithField("class", "Outbox").WithField("method", "Cancel")
+	removed, err := outbox.store.Delete(id)
+	if err != nil {
+		logger.Errorf("Failed to remove cancelled message %s from outbox store, error: %s", id, err)
+		return err
+	}
+	if removed {
+		logger.Infof("Message %s cancelled", id)
+		return nil
+	}
+
+	claimed, err := outbox.store.IsClaimed(id)
+	if err != nil {
+		logger.Errorf("Failed to look up claim on message %s, error: %s", id, err)
+		return err
+	}
+	if claimed {
+		return ErrMessageInFlight
+	}
+	return ErrMessageNotPending
+}
+
+func (outbox *outbox) poll() {
+	ticker := time.NewTicker(outbox.pollInterval)
+	defer ticker.Stop()
+	for {
+		outbox.dispatchDueMessages()
+		<-ticker.C
+	}
+}
+
+func (outbox *outbox) dispatchDueMessages() {
+	logger := logging.NewLoggerEntry().WithField("class", "Outbox").WithField("method", "dispatchDueMessages")
+	released, err := outbox.store.ReleaseExpiredClaims(outbox.claimTimeout)
+	if err != nil {
+		logger.Error("Failed to release expired claims in outbox store ", err)
+	}
+	if released > 0 {
+		logger.Warnf("Released %d message(s) claimed longer than %s ago", released, outbox.claimTimeout)
+	}
+
+	messages, err := outbox.store.ListDue(time.Now())
+	if err != nil {
+		logger.Error("Failed to list due messages in outbox store ", err)
+		return
+	}
+	for _, message := range messages {
+		outbox.dispatch(message)
+	}
+}
+
+// dispatch claims the message and hands it to a worker. A full queue leaves the message unclaimed for the next poll,
+// and a message claimed by another instance is left to it
+func (outbox *outbox) dispatch(pending Message) {
+	outbox.mutex.Lock()
+	defer outbox.mutex.Unlock()
+	if len(outbox.queue) == cap(outbox.queue) {
+		return
+	}
+
+	message, claimed, err := outbox.store.Claim(pending)
+	if err != nil {
+		logging.NewLoggerEntry().WithField("class", "Outbox").WithField("method", "dispatch").
+			Errorf("Failed to claim message %s, error: %s", pending.ID, err)
+		return
+	}
+	if claimed {
+		outbox.queue <- message
+	}
+}
+
+func (outbox *outbox) work() {
+	for message := range outbox.queue {
+		outbox.deliver(message)
+	}
+}
+
+func (outbox *outbox) deliver(message Message) {
+	ctx := util.NewBackgroundContext()
+	logger := logging.GetLogger(ctx).WithField("class", "Outbox").WithField("method", "deliver")
+	id := message.ID
+
+	outbox.tracker.Update(ctx, id, models.Sending, nil)
+	sendError := outbox.emailClient.Send(ctx, &message.Request)
+
+	if sendError == nil {
+		if err := outbox.store.Complete(message); err != nil {
+			logger.Errorf("Message %s was sent but could not be removed from outbox store, error: %s", id, err)
+		}
+		outbox.tracker.Update(ctx, id, models.Sent, nil)
+		logger.Infof("Message %s sent from outbox", id)
+		return
+	}
+
+	message.Attempts++
+	message.LastError = sendError.Error()
+	if !outbox.retryPolicy.ShouldRetry(message.Attempts, sendError) {
+		if err := outbox.store.Complete(message); err != nil {
+			logger.Errorf("Failed to remove undeliverable message %s from outbox store, error: %s", id, err)
+		}
+		outbox.tracker.Update(ctx, id, models.Failed, sendError)
+		logger.Errorf("Giving up on message %s after %d attempt(s), %s error: %s",
+			id, message.Attempts, retry.Classify(sendError), sendError)
+		return
+	}
+
+	outbox.tracker.Update(ctx, id, models.Retrying, sendError)
+	message.NextAttemptAt = time.Now().Add(outbox.retryPolicy.Backoff(message.Attempts))
+	logger.Warnf("Attempt %d to send message %s failed, retrying at %s, error: %s",
+		message.Attempts, id, message.NextAttemptAt.Format(time.RFC3339), sendError)
+	if err := outbox.store.Release(message); err != nil {
+		logger.Errorf("Failed to persist attempt for message %s, error: %s", id, err)
+	}
+}
+
+func pollInterval(seconds int) time.Duration {
+	if seconds <= 0 {
+		return defaultPollInterval
+	}
+	return time.Duration(seconds) * time.Second
+}
@@ -0,0 +1,273 @@
+package outbox
+
+import (
+	"ccg-api/configuration"
+	"ccg-api/email/email-client/email_client_request"
+	mockemailclient "ccg-api/email/email-client/mocks"
+	"ccg-api/email/models"
+	mockstatus "ccg-api/email/status/mocks"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/suite"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"os"
+	"path"
+	"testing"
+	"time"
+)
+
+type outboxTestSuite struct {
+	suite.Suite
+	context     *gin.Context
+	mockCtrl    *gomock.Controller
+	emailClient *mockemailclient.MockEmailClient
+	tracker     *mockstatus.MockTracker
+	directory   string
+	store       Store
+	config      configuration.Outbox
+}
+
+func TestOutboxTestSuite(t *testing.T) {
+	suite.Run(t, new(outboxTestSuite))
+}
+
+func (suite *outboxTestSuite) SetupTest() {
+	suite.context, _ = gin.CreateTestContext(httptest.NewRecorder())
+	suite.context.Request, _ = http.NewRequest("POST", "/", nil)
+	suite.mockCtrl = gomock.NewController(suite.T())
+	suite.emailClient = mockemailclient.NewMockEmailClient(suite.mockCtrl)
+	suite.tracker = mockstatus.NewMockTracker(suite.mockCtrl)
+	suite.tracker.EXPECT().Update(gomock.Any(), gomock.Any(), models.Sending, nil).AnyTimes()
+	suite.directory = path.Join(os.TempDir(), "ccg-outbox-unit-test-dir")
+	_ = os.RemoveAll(suite.directory)
+	suite.store, _ = NewFileStore(suite.directory)
+	suite.config = configuration.Outbox{
+		Enabled:               true,
+		Workers:               2,
+		PollIntervalInSeconds: 1,
+		RetryPolicy: configuration.RetryPolicy{
+			MaxAttempts:            3,
+			InitialBackoffInMillis: 60000,
+		},
+	}
+}
+
+func (suite *outboxTestSuite) TearDownTest() {
+	suite.mockCtrl.Finish()
+	_ = os.RemoveAll(suite.directory)
+}
+
+func (suite *outboxTestSuite) TestEnqueue_ShouldPersistMessageAndDeliverItInTheBackground() {
+	request := email_client_request.EmailClientRequest{
+		From:    "gola@gola.xyz",
+		To:      []string{"someone@gmail.com"},
+		Subject: "Hi!",
+	}
+	delivered := make(chan bool, 1)
+	suite.emailClient.EXPECT().Send(gomock.Any(), &request).Return(nil).Do(func(ctx *gin.Context, request *email_client_request.EmailClientRequest) {
+		delivered <- true
+	})
+
+	suite.tracker.EXPECT().Update(gomock.Any(), "some-message-id", models.Sent, nil)
+
+	outbox := NewOutbox(suite.store, suite.emailClient, suite.tracker, suite.config)
+	suite.Nil(outbox.Enqueue(suite.context, "some-message-id", request, time.Time{}))
+
+	suite.Nil(outbox.Start())
+	suite.waitFor(delivered)
+	suite.Eventually(func() bool {
+		messages, _ := loadAll(suite.directory)
+		return len(messages) == 0
+	}, time.Second, 10*time.Millisecond)
+}
+
+func (suite *outboxTestSuite) TestEnqueue_ShouldReturnErrorIfMessageCannotBePersisted() {
+	_ = os.RemoveAll(suite.directory)
+	outbox := NewOutbox(suite.store, suite.emailClient, suite.tracker, suite.config)
+
+	err := outbox.Enqueue(suite.context, "some-message-id", email_client_request.EmailClientRequest{}, time.Time{})
+
+	suite.NotNil(err)
+}
+
+func (suite *outboxTestSuite) TestStart_ShouldResumePendingMessagesLeftByPreviousRun() {
+	request := email_client_request.EmailClientRequest{
+		From:    "gola@gola.xyz",
+		To:      []string{"someone@gmail.com"},
+		Subject: "Pending from last run",
+	}
+	_ = suite.store.Save(Message{ID: "pending-message", Request: request, AcceptedAt: time.Now(), NextAttemptAt: time.Now()})
+	delivered := make(chan bool, 1)
+	suite.emailClient.EXPECT().Send(gomock.Any(), &request).Return(nil).Do(func(ctx *gin.Context, request *email_client_request.EmailClientRequest) {
+		delivered <- true
+	})
+	suite.tracker.EXPECT().Update(gomock.Any(), "pending-message", models.Sent, nil)
+
+	suite.Nil(NewOutbox(suite.store, suite.emailClient, suite.tracker, suite.config).Start())
+
+	suite.waitFor(delivered)
+	suite.Eventually(func() bool {
+		messages, _ := loadAll(suite.directory)
+		return len(messages) == 0
+	}, time.Second, 10*time.Millisecond)
+}
+
+func (suite *outboxTestSuite) TestStart_ShouldKeepMessageInOutboxForRetryIfDeliveryFails() {
+	request := email_client_request.EmailClientRequest{
+		From:    "gola@gola.xyz",
+		To:      []string{"someone@gmail.com"},
+		Subject: "Hi!",
+	}
+	_ = suite.store.Save(Message{ID: "failing-message", Request: request, AcceptedAt: time.Now(), NextAttemptAt: time.Now()})
+	suite.emailClient.EXPECT().Send(gomock.Any(), &request).Return(errors.New("failed to connect SMTP server"))
+	suite.tracker.EXPECT().Update(gomock.Any(), "failing-message", models.Retrying, gomock.Any())
+
+	suite.Nil(NewOutbox(suite.store, suite.emailClient, suite.tracker, suite.config).Start())
+
+	suite.Eventually(func() bool {
+		messages, _ := loadAll(suite.directory)
+		return len(messages) == 1 && messages[0].Attempts == 1
+	}, time.Second, 10*time.Millisecond)
+	messages, _ := loadAll(suite.directory)
+	suite.Equal("failed to connect SMTP server", messages[0].LastError)
+	suite.True(messages[0].NextAttemptAt.After(time.Now()))
+}
+
+func (suite *outboxTestSuite) TestStart_ShouldDropMessageFromOutboxIfDeliveryFailsPermanently() {
+	request := email_client_request.EmailClientRequest{
+		From:    "gola@gola.xyz",
+		To:      []string{"unknown@gmail.com"},
+		Subject: "Hi!",
+	}
+	_ = suite.store.Save(Message{ID: "rejected-message", Request: request, AcceptedAt: time.Now(), NextAttemptAt: time.Now()})
+	suite.emailClient.EXPECT().Send(gomock.Any(), &request).Return(&textproto.Error{Code: 550, Msg: "5.1.1 mailbox unavailable"})
+	suite.tracker.EXPECT().Update(gomock.Any(), "rejected-message", models.Failed, gomock.Any())
+
+	suite.Nil(NewOutbox(suite.store, suite.emailClient, suite.tracker, suite.config).Start())
+
+	suite.Eventually(func() bool {
+		messages, _ := loadAll(suite.directory)
+		return len(messages) == 0
+	}, time.Second, 10*time.Millisecond)
+}
+
+func (suite *outboxTestSuite) TestStart_ShouldDropMessageFromOutboxOnceRetryAttemptsAreExhausted() {
+	request := email_client_request.EmailClientRequest{
+		From:    "gola@gola.xyz",
+		To:      []string{"someone@gmail.com"},
+		Subject: "Hi!",
+	}
+	_ = suite.store.Save(Message{ID: "exhausted-message", Request: request, Attempts: 2, AcceptedAt: time.Now(), NextAttemptAt: time.Now()})
+	suite.emailClient.EXPECT().Send(gomock.Any(), &request).Return(errors.New("failed to connect SMTP server"))
+	suite.tracker.EXPECT().Update(gomock.Any(), "exhausted-message", models.Failed, gomock.Any())
+
+	suite.Nil(NewOutbox(suite.store, suite.emailClient, suite.tracker, suite.config).Start())
+
+	suite.Eventually(func() bool {
+		messages, _ := loadAll(suite.directory)
+		return len(messages) == 0
+	}, time.Second, 10*time.Millisecond)
+}
+
+func (suite *outboxTestSuite) TestEnqueue_ShouldHoldScheduledMessageUntilSendAt() {
+	request := email_client_request.EmailClientRequest{
+		From:    "gola@gola.xyz",
+		To:      []string{"someone@gmail.com"},
+		Subject: "Weekly digest",
+	}
+	sendAt := time.Now().Add(time.Hour).Truncate(time.Second)
+	outbox := NewOutbox(suite.store, suite.emailClient, suite.tracker, suite.config)
+
+	suite.Nil(outbox.Enqueue(suite.context, "scheduled-message", request, sendAt))
+	suite.Nil(outbox.Start())
+
+	time.Sleep(100 * time.Millisecond)
+	messages, _ := loadAll(suite.directory)
+	suite.Len(messages, 1)
+	suite.True(sendAt.Equal(messages[0].NextAttemptAt))
+}
+
+func (suite *outboxTestSuite) TestCancel_ShouldRemoveScheduledMessageFromOutbox() {
+	outbox := NewOutbox(suite.store, suite.emailClient, suite.tracker, suite.config)
+	suite.Nil(outbox.Enqueue(suite.context, "scheduled-message", email_client_request.EmailClientRequest{}, time.Now().Add(time.Hour)))
+	suite.Nil(outbox.Start())
+
+	suite.Nil(outbox.Cancel(suite.context, "scheduled-message"))
+
+	messages, _ := loadAll(suite.directory)
+	suite.Empty(messages)
+	suite.Equal(ErrMessageNotPending, outbox.Cancel(suite.context, "scheduled-message"))
+}
+
+func (suite *outboxTestSuite) TestCancel_ShouldReturnErrorForUnknownMessage() {
+	outbox := NewOutbox(suite.store, suite.emailClient, suite.tracker, suite.config)
+
+	suite.Equal(ErrMessageNotPending, outbox.Cancel(suite.context, "unknown-message"))
+}
+
+func (suite *outboxTestSuite) TestCancel_ShouldNotCancelMessageAlreadyHandedToWorker() {
+	outbox := NewOutbox(suite.store, suite.emailClient, suite.tracker, suite.config)
+	suite.Nil(outbox.Enqueue(suite.context, "due-message", email_client_request.EmailClientRequest{}, time.Time{}))
+
+	suite.Equal(ErrMessageInFlight, outbox.Cancel(suite.context, "due-message"))
+	claimed, _ := suite.store.IsClaimed("due-message")
+	suite.True(claimed)
+}
+
+func (suite *outboxTestSuite) TestCancel_ShouldRemoveMessageEnqueuedByAnotherInstance() {
+	otherInstance := NewOutbox(suite.store, suite.emailClient, suite.tracker, suite.config)
+	suite.Nil(otherInstance.Enqueue(suite.context, "scheduled-message", email_client_request.EmailClientRequest{}, time.Now().Add(time.Hour)))
+
+	suite.Nil(NewOutbox(suite.store, suite.emailClient, suite.tracker, suite.config).Cancel(suite.context, "scheduled-message"))
+
+	messages, _ := loadAll(suite.directory)
+	suite.Empty(messages)
+}
+
+func (suite *outboxTestSuite) TestStart_ShouldLeaveMessageClaimedByAnotherInstance() {
+	pending := Message{ID: "claimed-message", AcceptedAt: time.Now(), NextAttemptAt: time.Now()}
+	_ = suite.store.Save(pending)
+	_, _, _ = suite.store.Claim(pending)
+
+	suite.Nil(NewOutbox(suite.store, suite.emailClient, suite.tracker, suite.config).Start())
+
+	time.Sleep(100 * time.Millisecond)
+	claimed, _ := suite.store.IsClaimed("claimed-message")
+	suite.True(claimed)
+}
+
+func (suite *outboxTestSuite) TestStart_ShouldRedeliverMessageWhoseClaimExpired() {
+	request := email_client_request.EmailClientRequest{
+		From:    "gola@gola.xyz",
+		To:      []string{"someone@gmail.com"},
+		Subject: "Abandoned by a crashed instance",
+	}
+	pending := Message{ID: "abandoned-message", Request: request, AcceptedAt: time.Now(), NextAttemptAt: time.Now()}
+	_ = suite.store.Save(pending)
+	abandoned, _, _ := suite.store.Claim(pending)
+	expireClaim(suite.directory, abandoned, time.Now().Add(-time.Hour))
+	delivered := make(chan bool, 1)
+	suite.emailClient.EXPECT().Send(gomock.Any(), &request).Return(nil).Do(func(ctx *gin.Context, request *email_client_request.EmailClientRequest) {
+		delivered <- true
+	})
+	suite.tracker.EXPECT().Update(gomock.Any(), "abandoned-message", models.Sent, nil)
+
+	suite.Nil(NewOutbox(suite.store, suite.emailClient, suite.tracker, suite.config).Start())
+
+	suite.waitFor(delivered)
+	suite.Eventually(func() bool {
+		claimed, _ := suite.store.IsClaimed("abandoned-message")
+		return !claimed
+	}, time.Second, 10*time.Millisecond)
+}
+
+func (suite *outboxTestSuite) waitFor(delivered chan bool) {
+	select {
+	case <-delivered:
+	case <-time.After(time.Second):
+		suite.Fail("message was not delivered from outbox")
+	}
+}
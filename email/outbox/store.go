@@ -0,0 +1,255 @@
+package outbox
+
+// mockgen -source=email/outbox/store.go -destination=email/outbox/mocks/mock_store.go -package=mocks
+import (
+	"ccg-api/util"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	messageFileExtension = ".json"
+	claimFileExtension   = ".claimed"
+	entryTimeSeparator   = "_"
+)
+
+var ErrClaimLost = errors.New("claim on message was released before delivery finished")
+
+// Store is shared by every instance of the service. A message is either pending, and visible to ListDue once it is due,
+// or claimed by the one instance delivering it
+type Store interface {
+	Save(message Message) error
+	Delete(id string) (bool, error)
+	ListDue(now time.Time) ([]Message, error)
+	Claim(pending Message) (Message, bool, error)
+	IsClaimed(id string) (bool, error)
+	Release(message Message) error
+	Complete(message Message) error
+	ReleaseExpiredClaims(timeout time.Duration) (int, error)
+}
+
+type fileStore struct {
+	directory string
+}
+
+// NewFileStore keeps one file per message. Pointing several instances at the same directory on a shared volume
+// lets them drain one outbox, claims rely on rename being atomic.
+// Pending files are named after the time the message is due and claimed files after the time they were claimed,
+// so both can be told apart from a directory listing without reading any file
+func NewFileStore(directory string) (Store, error) {
+	if err := os.MkdirAll(directory, 0755); err != nil {
+		return nil, err
+	}
+	return fileStore{directory: directory}, nil
+}
+
+func (store fileStore) Save(message Message) error {
+	data, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+	return util.WriteFileAtomically(store.messagePath(message.ID, message.NextAttemptAt), data)
+}
+
+// Delete removes a pending message, it is false when the message is not pending
+func (store fileStore) Delete(id string) (bool, error) {
+	pending, err := store.entries(messageFileExtension)
+	if err != nil {
+		return false, err
+	}
+	for _, entry := range pending {
+		if entry.id != id {
+			continue
+		}
+		err := os.Remove(store.messagePath(entry.id, entry.at))
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return err == nil, err
+	}
+	return false, nil
+}
+
+// ListDue returns the pending messages due by now, earliest first. Only ID and NextAttemptAt are filled in,
+// the rest of a message is read when it is claimed
+func (store fileStore) ListDue(now time.Time) ([]Message, error) {
+	pending, err := store.entries(messageFileExtension)
+	if err != nil {
+		return nil, err
+	}
+
+	var messages []Message
+	for _, entry := range pending {
+		if entry.at.After(now) {
+			break
+		}
+		messages = append(messages, Message{ID: entry.id, NextAttemptAt: entry.at})
+	}
+	return messages, nil
+}
+
+// Claim takes a pending message for delivery, it is false when another instance claimed, completed or cancelled it first.
+// The claim time goes into the name of the claimed file, so a claim is never mistaken for an older one
+func (store fileStore) Claim(pending Message) (Message, bool, error) {
+	claimedAt := time.Unix(0, time.Now().UnixNano())
+	claimPath := store.claimPath(pending.ID, claimedAt)
+	err := os.Rename(store.messagePath(pending.ID, pending.NextAttemptAt), claimPath)
+	if os.IsNotExist(err) {
+		return Message{}, false, nil
+	}
+	if err != nil {
+		return Message{}, false, err
+	}
+	message, err := readMessage(claimPath)
+	if err != nil {
+		return Message{}, false, err
+	}
+	message.ClaimedAt = claimedAt
+	return message, true, nil
+}
+
+func (store fileStore) IsClaimed(id string) (bool, error) {
+	claims, err := store.entries(claimFileExtension)
+	if err != nil {
+		return false, err
+	}
+	for _, claim := range claims {
+		if claim.id == id {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Release hands a claimed message back as pending, with its attempt recorded. The claim is first renamed to a fresh
+// claim time, which fails with ErrClaimLost when the claim expired and was released meanwhile
+func (store fileStore) Release(message Message) error {
+	data, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+	renewedAt := time.Unix(0, time.Now().UnixNano())
+	renewedPath := store.claimPath(message.ID, renewedAt)
+	if err := store.renameClaim(store.claimPath(message.ID, message.ClaimedAt), renewedPath); err != nil {
+		return err
+	}
+	if err := util.WriteFileAtomically(renewedPath, data); err != nil {
+		return err
+	}
+	return os.Rename(renewedPath, store.messagePath(message.ID, message.NextAttemptAt))
+}
+
+// Complete drops a claimed message once it needs no further delivery
+func (store fileStore) Complete(message Message) error {
+	err := os.Remove(store.claimPath(message.ID, message.ClaimedAt))
+	if os.IsNotExist(err) {
+		return ErrClaimLost
+	}
+	return err
+}
+
+// ReleaseExpiredClaims hands back messages claimed longer than timeout ago, their instance is assumed to have died mid delivery.
+// A released message was due when it was claimed, so it is pending again as due at its claim time
+func (store fileStore) ReleaseExpiredClaims(timeout time.Duration) (int, error) {
+	claims, err := store.entries(claimFileExtension)
+	if err != nil {
+		return 0, err
+	}
+
+	released := 0
+	expiredBefore := time.Now().Add(-timeout)
+	for _, claim := range claims {
+		if claim.at.After(expiredBefore) {
+			continue
+		}
+		err := store.renameClaim(store.claimPath(claim.id, claim.at), store.messagePath(claim.id, claim.at))
+		if err == ErrClaimLost {
+			continue
+		}
+		if err != nil {
+			return released, err
+		}
+		released++
+	}
+	return released, nil
+}
+
+type entry struct {
+	id string
+	at time.Time
+}
+
+// entries lists the files with extension by name alone, sorted by the time in their name. A file is never renamed
+// in place, so its name stays accurate
+func (store fileStore) entries(extension string) ([]entry, error) {
+	files, err := os.ReadDir(store.directory)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []entry
+	for _, file := range files {
+		name := file.Name()
+		if file.IsDir() || !strings.HasSuffix(name, extension) {
+			continue
+		}
+		separator := strings.Index(name, entryTimeSeparator)
+		if separator < 0 {
+			continue
+		}
+		nanos, err := strconv.ParseInt(name[:separator], 10, 64)
+		if err != nil {
+			continue
+		}
+		entries = append(entries, entry{
+			id: strings.TrimSuffix(name[separator+1:], extension),
+			at: time.Unix(0, nanos),
+		})
+	}
+	return entries, nil
+}
+
+// renameClaim moves exactly one claim, it fails with ErrClaimLost once another instance released or completed it
+func (store fileStore) renameClaim(claimPath string, target string) error {
+	err := os.Rename(claimPath, target)
+	if os.IsNotExist(err) {
+		return ErrClaimLost
+	}
+	return err
+}
+
+func (store fileStore) messagePath(id string, dueAt time.Time) string {
+	return store.entryPath(id, dueAt, messageFileExtension)
+}
+
+func (store fileStore) claimPath(id string, claimedAt time.Time) string {
+	return store.entryPath(id, claimedAt, claimFileExtension)
+}
+
+// entryPath zero pads the time so that names sort in time order, times before 1970 sort as 1970
+func (store fileStore) entryPath(id string, at time.Time, extension string) string {
+	nanos := int64(0)
+	if at.After(time.Unix(0, 0)) {
+		nanos = at.UnixNano()
+	}
+	return path.Join(store.directory, fmt.Sprintf("%019d%s%s%s", nanos, entryTimeSeparator, id, extension))
+}
+
+func readMessage(filePath string) (Message, error) {
+	data, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		return Message{}, err
+	}
+	var message Message
+	if err := json.Unmarshal(data, &message); err != nil {
+		return Message{}, err
+	}
+	return message, nil
+}
@@ -0,0 +1,275 @@
+package outbox
+
+import (
+	"ccg-api/email/email-client/email_client_request"
+	"ccg-api/email/models"
+	"fmt"
+	"github.com/stretchr/testify/suite"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+	"time"
+)
+
+type fileStoreTestSuite struct {
+	suite.Suite
+	directory string
+	store     Store
+}
+
+func TestFileStoreTestSuite(t *testing.T) {
+	suite.Run(t, new(fileStoreTestSuite))
+}
+
+func (suite *fileStoreTestSuite) SetupTest() {
+	suite.directory = path.Join(os.TempDir(), "ccg-outbox-file-store-unit-test-dir")
+	_ = os.RemoveAll(suite.directory)
+	suite.store, _ = NewFileStore(suite.directory)
+}
+
+func (suite *fileStoreTestSuite) TearDownTest() {
+	_ = os.RemoveAll(suite.directory)
+}
+
+func (suite *fileStoreTestSuite) TestSave_ShouldPersistMessageThatCanBeLoadedBack() {
+	message := Message{
+		ID: "message-1",
+		Request: email_client_request.EmailClientRequest{
+			From:    "gola@gola.xyz",
+			To:      []string{"someone@gmail.com"},
+			Subject: "Hi!",
+			Body: models.MessageBody{
+				MimeType: "text/plain",
+				Content:  "Hello User!",
+			},
+			Attachments: []models.Attachment{{FileName: "attachment.txt", Data: []byte("Attachment Data")}},
+		},
+		Attempts:      2,
+		AcceptedAt:    time.Date(2020, 2, 23, 0, 34, 14, 0, time.UTC),
+		NextAttemptAt: time.Date(2020, 2, 23, 0, 35, 14, 0, time.UTC),
+		LastError:     "failed to connect SMTP server",
+	}
+
+	err := suite.store.Save(message)
+	suite.Nil(err)
+
+	messages, err := loadAll(suite.directory)
+	suite.Nil(err)
+	suite.Equal([]Message{message}, messages)
+
+	files, _ := ioutil.ReadDir(suite.directory)
+	suite.Len(files, 1)
+	suite.Equal(fmt.Sprintf("%019d_message-1.json", message.NextAttemptAt.UnixNano()), files[0].Name())
+}
+
+func (suite *fileStoreTestSuite) TestDelete_ShouldRemoveMessageFromStore() {
+	_ = suite.store.Save(Message{ID: "message-1"})
+	_ = suite.store.Save(Message{ID: "message-2"})
+
+	removed, err := suite.store.Delete("message-1")
+	suite.Nil(err)
+	suite.True(removed)
+
+	messages, _ := loadAll(suite.directory)
+	suite.Len(messages, 1)
+	suite.Equal("message-2", messages[0].ID)
+}
+
+func (suite *fileStoreTestSuite) TestDelete_ShouldNotRemoveUnknownOrClaimedMessage() {
+	_ = suite.store.Save(Message{ID: "message-1"})
+	_, _, _ = suite.store.Claim(Message{ID: "message-1"})
+
+	removed, err := suite.store.Delete("unknown-message")
+	suite.Nil(err)
+	suite.False(removed)
+
+	removed, err = suite.store.Delete("message-1")
+	suite.Nil(err)
+	suite.False(removed)
+	claimed, _ := suite.store.IsClaimed("message-1")
+	suite.True(claimed)
+}
+
+func (suite *fileStoreTestSuite) TestClaim_ShouldHandMessageToOnlyOneClaimant() {
+	_ = suite.store.Save(Message{ID: "message-1", Attempts: 1})
+	otherInstance, _ := NewFileStore(suite.directory)
+
+	message, claimed, err := suite.store.Claim(Message{ID: "message-1"})
+	suite.Nil(err)
+	suite.True(claimed)
+	suite.Equal("message-1", message.ID)
+	suite.Equal(1, message.Attempts)
+	suite.False(message.ClaimedAt.IsZero())
+
+	_, claimed, err = otherInstance.Claim(Message{ID: "message-1"})
+	suite.Nil(err)
+	suite.False(claimed)
+	messages, _ := loadAll(suite.directory)
+	suite.Empty(messages)
+}
+
+func (suite *fileStoreTestSuite) TestRelease_ShouldMakeClaimedMessagePendingAgain() {
+	_ = suite.store.Save(Message{ID: "message-1"})
+	message, _, _ := suite.store.Claim(Message{ID: "message-1"})
+	message.Attempts = 1
+
+	suite.Nil(suite.store.Release(message))
+
+	messages, _ := loadAll(suite.directory)
+	suite.Equal([]Message{{ID: "message-1", Attempts: 1}}, messages)
+	claimed, _ := suite.store.IsClaimed("message-1")
+	suite.False(claimed)
+}
+
+func (suite *fileStoreTestSuite) TestRelease_ShouldFailWhenClaimWasReleasedAsExpired() {
+	_ = suite.store.Save(Message{ID: "message-1"})
+	message, _, _ := suite.store.Claim(Message{ID: "message-1"})
+	message = expireClaim(suite.directory, message, time.Now().Add(-time.Hour))
+	_, _ = suite.store.ReleaseExpiredClaims(10 * time.Minute)
+	released, _ := suite.store.ListDue(time.Now())
+	_, _, _ = suite.store.Claim(released[0])
+
+	suite.Equal(ErrClaimLost, suite.store.Release(message))
+	suite.Equal(ErrClaimLost, suite.store.Complete(message))
+	claimed, _ := suite.store.IsClaimed("message-1")
+	suite.True(claimed)
+}
+
+func (suite *fileStoreTestSuite) TestComplete_ShouldRemoveClaimedMessage() {
+	_ = suite.store.Save(Message{ID: "message-1"})
+	message, _, _ := suite.store.Claim(Message{ID: "message-1"})
+
+	suite.Nil(suite.store.Complete(message))
+
+	files, _ := ioutil.ReadDir(suite.directory)
+	suite.Empty(files)
+}
+
+func (suite *fileStoreTestSuite) TestReleaseExpiredClaims_ShouldReleaseOnlyClaimsOlderThanTimeout() {
+	_ = suite.store.Save(Message{ID: "abandoned-message"})
+	_ = suite.store.Save(Message{ID: "in-flight-message"})
+	abandoned, _, _ := suite.store.Claim(Message{ID: "abandoned-message"})
+	_, _, _ = suite.store.Claim(Message{ID: "in-flight-message"})
+	expireClaim(suite.directory, abandoned, time.Now().Add(-time.Hour))
+
+	released, err := suite.store.ReleaseExpiredClaims(10 * time.Minute)
+
+	suite.Nil(err)
+	suite.Equal(1, released)
+	messages, _ := loadAll(suite.directory)
+	suite.Equal([]Message{{ID: "abandoned-message"}}, messages)
+}
+
+func (suite *fileStoreTestSuite) TestReleaseExpiredClaims_ShouldNotReleaseMessagesClaimedWhileItRuns() {
+	longAgo := time.Unix(0, time.Now().Add(-time.Hour).UnixNano())
+	for i := 0; i < 50; i++ {
+		id := fmt.Sprintf("message-%d", i)
+		_ = suite.store.Save(Message{ID: id, NextAttemptAt: longAgo})
+	}
+	otherInstance, _ := NewFileStore(suite.directory)
+
+	claiming := make(chan bool)
+	go func() {
+		for i := 0; i < 50; i++ {
+			_, _, _ = suite.store.Claim(Message{ID: fmt.Sprintf("message-%d", i), NextAttemptAt: longAgo})
+		}
+		close(claiming)
+	}()
+	released := 0
+	for running := true; running; {
+		select {
+		case <-claiming:
+			running = false
+		default:
+		}
+		count, err := otherInstance.ReleaseExpiredClaims(10 * time.Minute)
+		suite.Nil(err)
+		released += count
+	}
+
+	suite.Equal(0, released)
+	messages, _ := loadAll(suite.directory)
+	suite.Empty(messages)
+}
+
+func (suite *fileStoreTestSuite) TestRelease_ShouldLeaveOneCopyWhenRacingExpiredClaimRelease() {
+	_ = suite.store.Save(Message{ID: "message-1"})
+	message, _, _ := suite.store.Claim(Message{ID: "message-1"})
+	message = expireClaim(suite.directory, message, time.Now().Add(-time.Hour))
+	message.Attempts = 1
+	otherInstance, _ := NewFileStore(suite.directory)
+
+	done := make(chan bool)
+	go func() {
+		_, _ = otherInstance.ReleaseExpiredClaims(10 * time.Minute)
+		close(done)
+	}()
+	_ = suite.store.Release(message)
+	<-done
+
+	messages, _ := loadAll(suite.directory)
+	suite.Len(messages, 1)
+	claimed, _ := suite.store.IsClaimed("message-1")
+	suite.False(claimed)
+}
+
+func (suite *fileStoreTestSuite) TestListDue_ShouldListOnlyDueMessagesEarliestFirst() {
+	now := time.Unix(0, time.Now().UnixNano())
+	_ = suite.store.Save(Message{ID: "later-message", NextAttemptAt: now.Add(time.Hour)})
+	_ = suite.store.Save(Message{ID: "due-message", NextAttemptAt: now})
+	_ = suite.store.Save(Message{ID: "overdue-message", NextAttemptAt: now.Add(-time.Hour)})
+
+	messages, err := suite.store.ListDue(now)
+
+	suite.Nil(err)
+	suite.Equal([]Message{
+		{ID: "overdue-message", NextAttemptAt: now.Add(-time.Hour)},
+		{ID: "due-message", NextAttemptAt: now},
+	}, messages)
+}
+
+func (suite *fileStoreTestSuite) TestListDue_ShouldNotReadMessageFiles() {
+	_ = ioutil.WriteFile(path.Join(suite.directory, "0000000000000000001_message-1.json"), []byte("{\"id\":"), 0644)
+
+	messages, err := suite.store.ListDue(time.Now())
+
+	suite.Nil(err)
+	suite.Equal([]Message{{ID: "message-1", NextAttemptAt: time.Unix(0, 1)}}, messages)
+}
+
+func (suite *fileStoreTestSuite) TestListDue_ShouldIgnoreIncompleteTempFiles() {
+	_ = suite.store.Save(Message{ID: "message-1"})
+	_ = ioutil.WriteFile(path.Join(suite.directory, "0000000000000000000_message-2.json.123.tmp"), []byte("{\"id\":"), 0644)
+
+	messages, err := suite.store.ListDue(time.Now())
+	suite.Nil(err)
+	suite.Len(messages, 1)
+}
+
+// loadAll reads every pending message, earliest due first
+func loadAll(directory string) ([]Message, error) {
+	store := fileStore{directory: directory}
+	pending, err := store.entries(messageFileExtension)
+	if err != nil {
+		return nil, err
+	}
+	var messages []Message
+	for _, entry := range pending {
+		message, err := readMessage(store.messagePath(entry.id, entry.at))
+		if err != nil {
+			return nil, err
+		}
+		messages = append(messages, message)
+	}
+	return messages, nil
+}
+
+// expireClaim moves a claim back to claimedAt the way a claim left behind by a crashed instance would look
+func expireClaim(directory string, message Message, claimedAt time.Time) Message {
+	store := fileStore{directory: directory}
+	claimedAt = time.Unix(0, claimedAt.UnixNano())
+	_ = os.Rename(store.claimPath(message.ID, message.ClaimedAt), store.claimPath(message.ID, claimedAt))
+	message.ClaimedAt = claimedAt
+	return message
+}
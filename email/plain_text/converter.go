@@ -0,0 +1,159 @@
+package plain_text
+
+import (
+	"fmt"
+	"golang.org/x/net/html"
+	"regexp"
+	"strings"
+)
+
+var (
+	skippedElements = map[string]bool{"head": true, "script": true, "style": true, "title": true}
+	blockElements   = map[string]bool{
+		"address": true, "article": true, "blockquote": true, "div": true, "footer": true, "header": true,
+		"section": true, "table": true, "tr": true, "ul": true, "ol": true,
+	}
+	paragraphElements = map[string]bool{"p": true, "h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true}
+
+	whitespace       = regexp.MustCompile(`[ \t\r\n\f]+`)
+	spaceAroundBreak = regexp.MustCompile(` *\n *`)
+	extraBreaks      = regexp.MustCompile(`\n{3,}`)
+)
+
+type converter struct {
+	text      strings.Builder
+	links     []string
+	linkIndex map[string]int
+
+	skipDepth  int
+	anchorHref string
+	anchorText strings.Builder
+	inAnchor   bool
+}
+
+// FromHTML renders HTML as readable plain text. Link targets are listed as numbered footnotes after the text
+func FromHTML(content string) string {
+	converter := converter{linkIndex: map[string]int{}}
+	tokenizer := html.NewTokenizer(strings.NewReader(content))
+	for {
+		tokenType := tokenizer.Next()
+		if tokenType == html.ErrorToken {
+			break
+		}
+		converter.handle(tokenType, tokenizer.Token())
+	}
+	return converter.result()
+}
+
+func (converter *converter) handle(tokenType html.TokenType, token html.Token) {
+	switch tokenType {
+	case html.StartTagToken, html.SelfClosingTagToken:
+		if skippedElements[token.Data] && tokenType == html.StartTagToken {
+			converter.skipDepth++
+			return
+		}
+		if converter.skipDepth > 0 {
+			return
+		}
+		converter.openElement(token)
+	case html.EndTagToken:
+		if skippedElements[token.Data] {
+			if converter.skipDepth > 0 {
+				converter.skipDepth--
+			}
+			return
+		}
+		if converter.skipDepth > 0 {
+			return
+		}
+		converter.closeElement(token)
+	case html.TextToken:
+		if converter.skipDepth > 0 {
+			return
+		}
+		converter.write(whitespace.ReplaceAllString(token.Data, " "))
+	}
+}
+
+func (converter *converter) openElement(token html.Token) {
+	switch {
+	case token.Data == "br":
+		converter.write("\n")
+	case token.Data == "hr":
+		converter.write("\n\n")
+	case token.Data == "li":
+		converter.write("\n- ")
+	case token.Data == "td" || token.Data == "th":
+		converter.write(" ")
+	case token.Data == "a":
+		converter.inAnchor = true
+		converter.anchorHref = strings.TrimSpace(attribute(token, "href"))
+		converter.anchorText.Reset()
+	case paragraphElements[token.Data]:
+		converter.write("\n\n")
+	case blockElements[token.Data]:
+		converter.write("\n")
+	}
+}
+
+func (converter *converter) closeElement(token html.Token) {
+	switch {
+	case token.Data == "a" && converter.inAnchor:
+		converter.inAnchor = false
+		linkText := strings.TrimSpace(converter.anchorText.String())
+		converter.text.WriteString(converter.anchorText.String())
+		if footnote := converter.footnote(converter.anchorHref, linkText); footnote != "" {
+			converter.text.WriteString(footnote)
+		}
+	case paragraphElements[token.Data]:
+		converter.write("\n\n")
+	case blockElements[token.Data]:
+		converter.write("\n")
+	}
+}
+
+func (converter *converter) write(text string) {
+	if converter.inAnchor {
+		converter.anchorText.WriteString(text)
+		return
+	}
+	converter.text.WriteString(text)
+}
+
+// footnote numbers each distinct link once; fragment links and links already spelled out in the text need none
+func (converter *converter) footnote(href string, linkText string) string {
+	if href == "" || strings.HasPrefix(href, "#") || href == linkText {
+		return ""
+	}
+	index, found := converter.linkIndex[href]
+	if !found {
+		converter.links = append(converter.links, href)
+		index = len(converter.links)
+		converter.linkIndex[href] = index
+	}
+	return fmt.Sprintf(" [%d]", index)
+}
+
+func (converter *converter) result() string {
+	text := spaceAroundBreak.ReplaceAllString(converter.text.String(), "\n")
+	text = extraBreaks.ReplaceAllString(text, "\n\n")
+	text = strings.TrimSpace(text)
+	if len(converter.links) == 0 {
+		return text
+	}
+
+	footnotes := make([]string, 0, len(converter.links))
+	for index, link := range converter.links {
+		footnotes = append(footnotes, fmt.Sprintf("[%d] %s", index+1, link))
+	}
+	return text + "\n\n" + strings.Join(footnotes, "\n")
+}
+
+func attribute(token html.Token, name string) string {
+	for _, attr := range token.Attr {
+		if attr.Key == name {
+			return attr.Val
+		}
+	}
+	return ""
+}
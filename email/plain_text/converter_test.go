@@ -0,0 +1,62 @@
+package plain_text
+
+import (
+	"github.com/stretchr/testify/suite"
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+type converterTestSuite struct {
+	suite.Suite
+}
+
+func TestConverterTestSuite(t *testing.T) {
+	suite.Run(t, new(converterTestSuite))
+}
+
+func (suite *converterTestSuite) TestFromHTML_ShouldRenderParagraphsAndLineBreaks() {
+	text := FromHTML(`<h1>Welcome</h1><p>Hi   Gola,<br>thanks for
+		joining.</p><p>Cheers</p>`)
+
+	suite.Equal("Welcome\n\nHi Gola,\nthanks for joining.\n\nCheers", text)
+}
+
+func (suite *converterTestSuite) TestFromHTML_ShouldListLinksAsFootnotes() {
+	text := FromHTML(`<p>Please <a href="https://gola.xyz/reset?a=1&amp;b=2">reset your password</a> or
+		read the <a href="https://gola.xyz/faq">FAQ</a>. Again: <a href="https://gola.xyz/reset?a=1&amp;b=2">reset</a></p>`)
+
+	suite.Equal("Please reset your password [1] or read the FAQ [2]. Again: reset [1]\n\n"+
+		"[1] https://gola.xyz/reset?a=1&b=2\n"+
+		"[2] https://gola.xyz/faq", text)
+}
+
+func (suite *converterTestSuite) TestFromHTML_ShouldNotFootnoteLinksSpelledOutOrPointingWithinDocument() {
+	text := FromHTML(`<p><a href="https://gola.xyz">https://gola.xyz</a> <a href="#top">Top</a></p>`)
+
+	suite.Equal("https://gola.xyz Top", text)
+}
+
+func (suite *converterTestSuite) TestFromHTML_ShouldSkipHeadStylesScriptsAndComments() {
+	text := FromHTML(`<html><head><title>Frame</title><style>p { color: red; }</style></head>
+		<body><!--[if mso]><p>Outlook only</p><![endif]--><script>alert(1)</script><p>Body</p></body></html>`)
+
+	suite.Equal("Body", text)
+}
+
+func (suite *converterTestSuite) TestFromHTML_ShouldRenderListItemsAndTableCells() {
+	text := FromHTML(`<ul><li>First</li><li>Second</li></ul><table><tr><td>Name</td><td>Gola</td></tr></table>`)
+
+	suite.Equal("- First\n- Second\n\nName Gola", text)
+}
+
+func (suite *converterTestSuite) TestFromHTML_ShouldProduceReadableTextForBaseTemplate() {
+	baseTemplate, err := ioutil.ReadFile("../../email_templates/base_email_template.html")
+	suite.Nil(err)
+
+	text := FromHTML(string(baseTemplate))
+
+	suite.NotContains(text, "<")
+	suite.NotContains(text, "font-family")
+	suite.False(strings.Contains(text, "\n\n\n"))
+}
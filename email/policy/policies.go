@@ -0,0 +1,74 @@
+package policy
+
+import (
+	"ccg-api/configuration"
+	"path"
+	"strings"
+)
+
+// AnyClient is the client of the policy for clients without their own, including anonymous ones
+const AnyClient = "*"
+
+// Policy decides which senders and categories a client may use
+type Policy struct {
+	Client            string
+	AllowedSenders    []string
+	DefaultSender     string
+	DisplayName       string
+	AllowedCategories []string
+}
+
+// MaySendAs accepts the default sender and any address matching an allowed sender, patterns use * as a wildcard
+func (policy Policy) MaySendAs(sender string) bool {
+	sender = strings.ToLower(strings.TrimSpace(sender))
+	if sender == strings.ToLower(policy.DefaultSender) {
+		return true
+	}
+	for _, allowed := range policy.AllowedSenders {
+		if matched, err := path.Match(strings.ToLower(allowed), sender); err == nil && matched {
+			return true
+		}
+	}
+	return false
+}
+
+func (policy Policy) MaySendCategory(category string) bool {
+	if len(policy.AllowedCategories) == 0 {
+		return true
+	}
+	for _, allowed := range policy.AllowedCategories {
+		if allowed == category {
+			return true
+		}
+	}
+	return false
+}
+
+type Policies interface {
+	// For returns the policy of the client, falling back to the policy of AnyClient. Without any policies
+	// configured every client is unrestricted.
+	For(clientID string) (Policy, bool)
+}
+
+type policies struct {
+	byClient map[string]Policy
+}
+
+func NewPolicies(senderPolicies []configuration.SenderPolicy) Policies {
+	byClient := map[string]Policy{}
+	for _, senderPolicy := range senderPolicies {
+		byClient[senderPolicy.Client] = Policy(senderPolicy)
+	}
+	return policies{byClient: byClient}
+}
+
+func (policies policies) For(clientID string) (Policy, bool) {
+	if len(policies.byClient) == 0 {
+		return Policy{Client: clientID, AllowedSenders: []string{"*"}}, true
+	}
+	if policy, found := policies.byClient[clientID]; found && clientID != "" {
+		return policy, true
+	}
+	policy, found := policies.byClient[AnyClient]
+	return policy, found
+}
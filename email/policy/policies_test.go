@@ -0,0 +1,74 @@
+package policy
+
+import (
+	"ccg-api/configuration"
+	"github.com/stretchr/testify/suite"
+	"testing"
+)
+
+type policiesTestSuite struct {
+	suite.Suite
+	policies Policies
+}
+
+func TestPoliciesTestSuite(t *testing.T) {
+	suite.Run(t, new(policiesTestSuite))
+}
+
+func (suite *policiesTestSuite) SetupTest() {
+	suite.policies = NewPolicies([]configuration.SenderPolicy{
+		{Client: AnyClient, AllowedSenders: []string{"*@gola.xyz"}, DefaultSender: "support@gola.xyz"},
+		{Client: "gola-api", AllowedSenders: []string{"noreply@gola.xyz", "alerts+*@gola.xyz"}, DefaultSender: "Support@gola.xyz",
+			DisplayName: "Gola", AllowedCategories: []string{"transactional", "security"}},
+	})
+}
+
+func (suite *policiesTestSuite) TestFor_ShouldReturnPolicyOfClient() {
+	policy, found := suite.policies.For("gola-api")
+
+	suite.True(found)
+	suite.Equal("Gola", policy.DisplayName)
+}
+
+func (suite *policiesTestSuite) TestFor_ShouldFallBackToPolicyOfAnyClientForUnknownAndAnonymousClients() {
+	policy, found := suite.policies.For("other-api")
+	suite.True(found)
+	suite.Equal(AnyClient, policy.Client)
+
+	policy, found = suite.policies.For("")
+	suite.True(found)
+	suite.Equal(AnyClient, policy.Client)
+}
+
+func (suite *policiesTestSuite) TestFor_ShouldFindNoPolicyWithoutOneForAnyClient() {
+	policies := NewPolicies([]configuration.SenderPolicy{{Client: "gola-api", AllowedSenders: []string{"*"}}})
+
+	_, found := policies.For("other-api")
+
+	suite.False(found)
+}
+
+func (suite *policiesTestSuite) TestFor_ShouldLeaveEveryClientUnrestrictedWithoutPolicies() {
+	policy, found := NewPolicies(nil).For("gola-api")
+
+	suite.True(found)
+	suite.True(policy.MaySendAs("anyone@anywhere.com"))
+	suite.True(policy.MaySendCategory("marketing"))
+}
+
+func (suite *policiesTestSuite) TestMaySendAs_ShouldMatchExactAddressesPatternsAndDefaultSender() {
+	policy, _ := suite.policies.For("gola-api")
+
+	suite.True(policy.MaySendAs("NoReply@gola.xyz"))
+	suite.True(policy.MaySendAs("alerts+billing@gola.xyz"))
+	suite.True(policy.MaySendAs("support@gola.xyz"))
+	suite.False(policy.MaySendAs("ceo@gola.xyz"))
+	suite.False(policy.MaySendAs("noreply@gola.xyz.evil"))
+}
+
+func (suite *policiesTestSuite) TestMaySendCategory_ShouldOnlyAllowListedCategories() {
+	policy, _ := suite.policies.For("gola-api")
+
+	suite.True(policy.MaySendCategory("security"))
+	suite.False(policy.MaySendCategory("marketing"))
+}
@@ -0,0 +1,63 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: email/preference/preferences.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	gin "github.com/gin-gonic/gin"
+	gomock "github.com/golang/mock/gomock"
+	golaerror "github.com/inclusi-blog/gola-utils/golaerror"
+	reflect "reflect"
+)
+
+// MockPreferences is a mock of Preferences interface
+type MockPreferences struct {
+	ctrl     *gomock.Controller
+	recorder *MockPreferencesMockRecorder
+}
+
+// MockPreferencesMockRecorder is the mock recorder for MockPreferences
+type MockPreferencesMockRecorder struct {
+	mock *MockPreferences
+}
+
+// NewMockPreferences creates a new mock instance
+func NewMockPreferences(ctrl *gomock.Controller) *MockPreferences {
+	mock := &MockPreferences{ctrl: ctrl}
+	mock.recorder = &MockPreferencesMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockPreferences) EXPECT() *MockPreferencesMockRecorder {
+	return m.recorder
+}
+
+// OptOut mocks base method
+func (m *MockPreferences) OptOut(ctx *gin.Context, recipient, category string) *golaerror.Error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "OptOut", ctx, recipient, category)
+	ret0, _ := ret[0].(*golaerror.Error)
+	return ret0
+}
+
+// OptOut indicates an expected call of OptOut
+func (mr *MockPreferencesMockRecorder) OptOut(ctx, recipient, category interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OptOut", reflect.TypeOf((*MockPreferences)(nil).OptOut), ctx, recipient, category)
+}
+
+// OptedOut mocks base method
+func (m *MockPreferences) OptedOut(ctx *gin.Context, recipients []string, category string) []string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "OptedOut", ctx, recipients, category)
+	ret0, _ := ret[0].([]string)
+	return ret0
+}
+
+// OptedOut indicates an expected call of OptedOut
+func (mr *MockPreferencesMockRecorder) OptedOut(ctx, recipients, category interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OptedOut", reflect.TypeOf((*MockPreferences)(nil).OptedOut), ctx, recipients, category)
+}
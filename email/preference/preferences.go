@@ -0,0 +1,75 @@
+package preference
+
+// mockgen -source=email/preference/preferences.go -destination=email/preference/mocks/mock_preferences.go -package=mocks
+import (
+	"ccg-api/constants"
+	"ccg-api/email/models"
+	"ccg-api/email/status"
+	"github.com/gin-gonic/gin"
+	"github.com/inclusi-blog/gola-utils/golaerror"
+	"github.com/inclusi-blog/gola-utils/logging"
+	"sync"
+	"time"
+)
+
+type Preferences interface {
+	OptOut(ctx *gin.Context, recipient string, category string) *golaerror.Error
+	OptedOut(ctx *gin.Context, recipients []string, category string) []string
+}
+
+type preferences struct {
+	store Store
+	mutex sync.Mutex
+}
+
+func NewPreferences(store Store) Preferences {
+	return &preferences{store: store}
+}
+
+// OptOut is idempotent, opting out again keeps the time of the first opt-out
+func (preferences *preferences) OptOut(ctx *gin.Context, recipient string, category string) *golaerror.Error {
+	logger := logging.GetLogger(ctx).WithField("class", "Preferences").WithField("method", "OptOut")
+	recipientHash := status.HashRecipient(recipient)
+	preferences.mutex.Lock()
+	defer preferences.mutex.Unlock()
+
+	preference, found, err := preferences.store.Get(recipientHash)
+	if err != nil {
+		logger.Errorf("Failed to read preferences of %s, error: %s", recipientHash, err)
+		return &constants.InternalServerError
+	}
+	if !found {
+		preference = models.Preference{RecipientHash: recipientHash}
+	}
+	if preference.IsOptedOut(category) {
+		return nil
+	}
+	if preference.OptOuts == nil {
+		preference.OptOuts = map[string]time.Time{}
+	}
+	preference.OptOuts[category] = time.Now()
+	if err := preferences.store.Save(preference); err != nil {
+		logger.Errorf("Failed to save preferences of %s, error: %s", recipientHash, err)
+		return &constants.InternalServerError
+	}
+	logger.Infof("Recipient %s opted out of %s", recipientHash, category)
+	return nil
+}
+
+// OptedOut returns the recipients that opted out of the category; one whose preferences cannot be read is treated as opted in
+func (preferences *preferences) OptedOut(ctx *gin.Context, recipients []string, category string) []string {
+	logger := logging.GetLogger(ctx).WithField("class", "Preferences").WithField("method", "OptedOut")
+	var optedOut []string
+	for _, recipient := range recipients {
+		recipientHash := status.HashRecipient(recipient)
+		preference, _, err := preferences.store.Get(recipientHash)
+		if err != nil {
+			logger.Errorf("Failed to read preferences of %s, error: %s", recipientHash, err)
+			continue
+		}
+		if preference.IsOptedOut(category) {
+			optedOut = append(optedOut, recipient)
+		}
+	}
+	return optedOut
+}
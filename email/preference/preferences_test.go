@@ -0,0 +1,81 @@
+package preference
+
+import (
+	"ccg-api/constants"
+	"ccg-api/email/models"
+	"ccg-api/email/preference/mocks"
+	"ccg-api/email/status"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/suite"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"testing"
+)
+
+type preferencesTestSuite struct {
+	suite.Suite
+	context     *gin.Context
+	mockCtrl    *gomock.Controller
+	directory   string
+	store       Store
+	preferences Preferences
+}
+
+func TestPreferencesTestSuite(t *testing.T) {
+	suite.Run(t, new(preferencesTestSuite))
+}
+
+func (suite *preferencesTestSuite) SetupTest() {
+	suite.context, _ = gin.CreateTestContext(httptest.NewRecorder())
+	suite.context.Request, _ = http.NewRequest("POST", "/", nil)
+	suite.mockCtrl = gomock.NewController(suite.T())
+	suite.directory = path.Join(os.TempDir(), "ccg-preferences-unit-test-dir")
+	_ = os.RemoveAll(suite.directory)
+	suite.store, _ = NewFileStore(suite.directory)
+	suite.preferences = NewPreferences(suite.store)
+}
+
+func (suite *preferencesTestSuite) TearDownTest() {
+	suite.mockCtrl.Finish()
+	_ = os.RemoveAll(suite.directory)
+}
+
+func (suite *preferencesTestSuite) TestOptOut_ShouldOnlyAffectTheGivenCategory() {
+	suite.Nil(suite.preferences.OptOut(suite.context, "someone@gmail.com", "marketing"))
+
+	suite.Equal([]string{"Someone@Gmail.com"}, suite.preferences.OptedOut(suite.context, []string{"Someone@Gmail.com", "other@gmail.com"}, "marketing"))
+	suite.Empty(suite.preferences.OptedOut(suite.context, []string{"someone@gmail.com"}, "digest"))
+}
+
+func (suite *preferencesTestSuite) TestOptOut_ShouldKeepTimeOfFirstOptOutAndStoreOnlyHashedRecipient() {
+	suite.Nil(suite.preferences.OptOut(suite.context, "someone@gmail.com", "marketing"))
+	first, _, _ := suite.store.Get(status.HashRecipient("someone@gmail.com"))
+
+	suite.Nil(suite.preferences.OptOut(suite.context, "someone@gmail.com", "marketing"))
+	second, found, _ := suite.store.Get(status.HashRecipient("someone@gmail.com"))
+
+	suite.True(found)
+	suite.Equal(status.HashRecipient("someone@gmail.com"), second.RecipientHash)
+	suite.True(first.OptOuts["marketing"].Equal(second.OptOuts["marketing"]))
+}
+
+func (suite *preferencesTestSuite) TestOptOut_ShouldReturnInternalServerErrorWhenPreferencesCannotBeSaved() {
+	store := mocks.NewMockStore(suite.mockCtrl)
+	store.EXPECT().Get(gomock.Any()).Return(models.Preference{}, false, nil)
+	store.EXPECT().Save(gomock.Any()).Return(errors.New("disk full"))
+
+	err := NewPreferences(store).OptOut(suite.context, "someone@gmail.com", "marketing")
+
+	suite.Equal(&constants.InternalServerError, err)
+}
+
+func (suite *preferencesTestSuite) TestOptedOut_ShouldTreatRecipientAsOptedInWhenPreferencesCannotBeRead() {
+	store := mocks.NewMockStore(suite.mockCtrl)
+	store.EXPECT().Get(gomock.Any()).Return(models.Preference{}, false, errors.New("disk failure"))
+
+	suite.Empty(NewPreferences(store).OptedOut(suite.context, []string{"someone@gmail.com"}, "marketing"))
+}
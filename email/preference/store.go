@@ -0,0 +1,56 @@
+package preference
+
+// mockgen -source=email/preference/store.go -destination=email/preference/mocks/mock_store.go -package=mocks
+import (
+	"ccg-api/email/models"
+	"ccg-api/util"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path"
+)
+
+const preferenceFileExtension = ".json"
+
+type Store interface {
+	Save(preference models.Preference) error
+	Get(recipientHash string) (models.Preference, bool, error)
+}
+
+type fileStore struct {
+	directory string
+}
+
+func NewFileStore(directory string) (Store, error) {
+	if err := os.MkdirAll(directory, 0755); err != nil {
+		return nil, err
+	}
+	return fileStore{directory: directory}, nil
+}
+
+func (store fileStore) Save(preference models.Preference) error {
+	data, err := json.Marshal(preference)
+	if err != nil {
+		return err
+	}
+	return util.WriteFileAtomically(store.preferencePath(preference.RecipientHash), data)
+}
+
+func (store fileStore) Get(recipientHash string) (models.Preference, bool, error) {
+	data, err := ioutil.ReadFile(store.preferencePath(recipientHash))
+	if os.IsNotExist(err) {
+		return models.Preference{}, false, nil
+	}
+	if err != nil {
+		return models.Preference{}, false, err
+	}
+	var preference models.Preference
+	if err := json.Unmarshal(data, &preference); err != nil {
+		return models.Preference{}, false, err
+	}
+	return preference, true, nil
+}
+
+func (store fileStore) preferencePath(recipientHash string) string {
+	return path.Join(store.directory, path.Base(path.Clean("/"+recipientHash))+preferenceFileExtension)
+}
@@ -0,0 +1,137 @@
+package ratelimit
+
+import (
+	"ccg-api/configuration"
+	"ccg-api/constants"
+	"ccg-api/email/models"
+	"github.com/gin-gonic/gin"
+	"github.com/inclusi-blog/gola-utils/golaerror"
+	"github.com/inclusi-blog/gola-utils/logging"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	clientScope       = "client"
+	senderDomainScope = "sender_domain"
+	recipientScope    = "recipient"
+)
+
+// RetryAfter is the AdditionalData of a rate limited error
+type RetryAfter struct {
+	Seconds int `json:"retry_after_seconds"`
+}
+
+// Limiter takes a token for the email from every bucket it counts against, client, sender domain and each recipient.
+// An empty bucket rejects the email without taking from any of the others.
+type Limiter interface {
+	Allow(ctx *gin.Context, clientID string, email models.Email) *golaerror.Error
+}
+
+type scopedLimit struct {
+	scope    string
+	index    int
+	category string
+	limit    Limit
+}
+
+type limiter struct {
+	store           Store
+	defaultCategory string
+	limits          []scopedLimit
+}
+
+func NewLimiter(store Store, config configuration.RateLimiting, defaultCategory string) Limiter {
+	scopes := []struct {
+		name   string
+		limits []configuration.RateLimit
+	}{
+		{clientScope, config.Client},
+		{senderDomainScope, config.SenderDomain},
+		{recipientScope, config.Recipient},
+	}
+	var limits []scopedLimit
+	for _, scope := range scopes {
+		for index, limit := range scope.limits {
+			if limit.Burst <= 0 || limit.PeriodInSeconds <= 0 {
+				continue
+			}
+			limits = append(limits, scopedLimit{
+				scope:    scope.name,
+				index:    index,
+				category: limit.Category,
+				limit:    Limit{Burst: limit.Burst, Period: time.Duration(limit.PeriodInSeconds) * time.Second},
+			})
+		}
+	}
+	return limiter{store: store, defaultCategory: defaultCategory, limits: limits}
+}
+
+// Allow lets the email through when the store fails, a broken limiter should not stop mail
+func (limiter limiter) Allow(ctx *gin.Context, clientID string, email models.Email) *golaerror.Error {
+	logger := logging.GetLogger(ctx).WithField("class", "RateLimiter").WithField("method", "Allow")
+	category := email.Category
+	if category == "" {
+		category = limiter.defaultCategory
+	}
+	var buckets []Bucket
+	for _, scoped := range limiter.limits {
+		if scoped.category != "" && scoped.category != category {
+			continue
+		}
+		for _, value := range scoped.values(clientID, email) {
+			buckets = append(buckets, Bucket{Key: scoped.key(value), Limit: scoped.limit})
+		}
+	}
+	allowed, retryAfter, err := limiter.store.Take(buckets)
+	if err != nil {
+		logger.Error("Failed to take from rate limit buckets, letting the email through ", err)
+		return nil
+	}
+	if !allowed {
+		logger.Warnf("Rate limit of client %s exceeded, retry after %s", clientID, retryAfter)
+		return ExceededError(retryAfter)
+	}
+	return nil
+}
+
+// ExceededError rounds the wait up to whole seconds, as Retry-After expects
+func ExceededError(retryAfter time.Duration) *golaerror.Error {
+	exceededError := golaerror.New(constants.RateLimitExceededCode, constants.RateLimitExceededError.ErrorMessage,
+		RetryAfter{Seconds: int(math.Ceil(retryAfter.Seconds()))})
+	return &exceededError
+}
+
+// RetryAfterSeconds is the Retry-After header value for a rate limited error, empty for any other response
+func RetryAfterSeconds(response interface{}) string {
+	if exceededError, ok := response.(*golaerror.Error); ok {
+		if retryAfter, ok := exceededError.AdditionalData.(RetryAfter); ok {
+			return strconv.Itoa(retryAfter.Seconds)
+		}
+	}
+	return ""
+}
+
+// values are the keys the email counts against in this scope, nothing for the client scope of an anonymous request
+func (scoped scopedLimit) values(clientID string, email models.Email) []string {
+	switch scoped.scope {
+	case clientScope:
+		if clientID == "" {
+			return nil
+		}
+		return []string{clientID}
+	case senderDomainScope:
+		return []string{strings.ToLower(email.From[strings.LastIndex(email.From, "@")+1:])}
+	}
+	var recipients []string
+	for _, recipient := range email.Recipients() {
+		recipients = append(recipients, strings.ToLower(recipient))
+	}
+	return recipients
+}
+
+func (scoped scopedLimit) key(value string) string {
+	return "ratelimit:" + scoped.scope + ":" + strconv.Itoa(scoped.index) + ":" + value
+}
@@ -0,0 +1,114 @@
+package ratelimit
+
+import (
+	"ccg-api/configuration"
+	"ccg-api/constants"
+	"ccg-api/email/models"
+	"ccg-api/util"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/suite"
+	"testing"
+	"time"
+)
+
+type recordingStore struct {
+	keys   []string
+	empty  map[string]time.Duration
+	broken bool
+}
+
+func (store *recordingStore) Take(buckets []Bucket) (bool, time.Duration, error) {
+	var wait time.Duration
+	for _, bucket := range buckets {
+		store.keys = append(store.keys, bucket.Key)
+		if retryAfter, empty := store.empty[bucket.Key]; empty && retryAfter > wait {
+			wait = retryAfter
+		}
+	}
+	if store.broken {
+		return false, 0, errors.New("connection refused")
+	}
+	return wait == 0, wait, nil
+}
+
+type limiterTestSuite struct {
+	suite.Suite
+	context *gin.Context
+	store   *recordingStore
+	limiter Limiter
+	email   models.Email
+}
+
+func TestLimiterTestSuite(t *testing.T) {
+	suite.Run(t, new(limiterTestSuite))
+}
+
+func (suite *limiterTestSuite) SetupTest() {
+	suite.context = util.NewBackgroundContext()
+	suite.store = &recordingStore{empty: map[string]time.Duration{}}
+	suite.limiter = NewLimiter(suite.store, configuration.RateLimiting{
+		Client:       []configuration.RateLimit{{Burst: 600, PeriodInSeconds: 60}},
+		SenderDomain: []configuration.RateLimit{{Burst: 1200, PeriodInSeconds: 60}},
+		Recipient: []configuration.RateLimit{
+			{Burst: 20, PeriodInSeconds: 3600},
+			{Category: "security", Burst: 5, PeriodInSeconds: 3600},
+			{Burst: 0, PeriodInSeconds: 60},
+		},
+	}, "transactional")
+	suite.email = models.Email{From: "noreply@Gola.xyz", To: []string{"Some@gmail.com"}, Cc: []string{"other@gmail.com"}}
+}
+
+func (suite *limiterTestSuite) TestAllow_ShouldTakeFromClientSenderDomainAndEveryRecipientBucket() {
+	suite.Nil(suite.limiter.Allow(suite.context, "gola-api", suite.email))
+
+	suite.Equal([]string{
+		"ratelimit:client:0:gola-api",
+		"ratelimit:sender_domain:0:gola.xyz",
+		"ratelimit:recipient:0:some@gmail.com",
+		"ratelimit:recipient:0:other@gmail.com",
+	}, suite.store.keys)
+}
+
+func (suite *limiterTestSuite) TestAllow_ShouldApplyCategoryLimitsOnlyToEmailsOfThatCategory() {
+	suite.email.Category = "security"
+	suite.email.Cc = nil
+
+	suite.Nil(suite.limiter.Allow(suite.context, "", suite.email))
+
+	suite.Equal([]string{
+		"ratelimit:sender_domain:0:gola.xyz",
+		"ratelimit:recipient:0:some@gmail.com",
+		"ratelimit:recipient:1:some@gmail.com",
+	}, suite.store.keys)
+}
+
+func (suite *limiterTestSuite) TestAllow_ShouldRejectWithRetryAfterOnceABucketIsEmpty() {
+	suite.store.empty["ratelimit:recipient:0:some@gmail.com"] = 90*time.Second + time.Millisecond
+
+	limitError := suite.limiter.Allow(suite.context, "gola-api", suite.email)
+
+	suite.Equal(constants.RateLimitExceededCode, limitError.ErrorCode)
+	suite.Equal(RetryAfter{Seconds: 91}, limitError.AdditionalData)
+	suite.Equal("91", RetryAfterSeconds(limitError))
+}
+
+func (suite *limiterTestSuite) TestAllow_ShouldRetryAfterTheLongestWaitOfAllEmptyBuckets() {
+	suite.store.empty["ratelimit:client:0:gola-api"] = 10 * time.Second
+	suite.store.empty["ratelimit:recipient:0:other@gmail.com"] = time.Minute
+
+	limitError := suite.limiter.Allow(suite.context, "gola-api", suite.email)
+
+	suite.Equal(RetryAfter{Seconds: 60}, limitError.AdditionalData)
+}
+
+func (suite *limiterTestSuite) TestAllow_ShouldLetEmailThroughWhenStoreFails() {
+	suite.store.broken = true
+
+	suite.Nil(suite.limiter.Allow(suite.context, "gola-api", suite.email))
+}
+
+func (suite *limiterTestSuite) TestRetryAfterSeconds_ShouldBeEmptyForOtherResponses() {
+	suite.Equal("", RetryAfterSeconds(&constants.PayloadValidationError))
+	suite.Equal("", RetryAfterSeconds(nil))
+}
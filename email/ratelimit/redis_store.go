@@ -0,0 +1,77 @@
+package ratelimit
+
+import (
+	"ccg-api/email/redis"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// takeScript refills and takes from every bucket in one step, so instances sharing them cannot both take the last token,
+// and writes the buckets back only when each of them had a token. ARGV holds now, then the burst and period of each key.
+// It returns the milliseconds to wait, 0 when the tokens were taken.
+const takeScript = `
+local now = tonumber(ARGV[1])
+local buckets = {}
+local wait = 0
+for index, key in ipairs(KEYS) do
+  local burst = tonumber(ARGV[2 * index])
+  local period = tonumber(ARGV[2 * index + 1])
+  local bucket = buckets[key]
+  if not bucket then
+    local stored = redis.call('HMGET', key, 'tokens', 'updated_at')
+    local tokens = tonumber(stored[1]) or burst
+    local updatedAt = tonumber(stored[2]) or now
+    bucket = {tokens = math.min(burst, tokens + math.max(0, now - updatedAt) * burst / period), period = period}
+    buckets[key] = bucket
+  end
+  if bucket.tokens < 1 then
+    wait = math.max(wait, math.ceil((1 - bucket.tokens) * period / burst))
+  end
+  bucket.tokens = bucket.tokens - 1
+end
+if wait > 0 then
+  return wait
+end
+for key, bucket in pairs(buckets) do
+  redis.call('HMSET', key, 'tokens', tostring(bucket.tokens), 'updated_at', now)
+  redis.call('PEXPIRE', key, bucket.period)
+end
+return 0
+`
+
+type redisStore struct {
+	client redis.Client
+	now    func() time.Time
+}
+
+// NewRedisStore shares buckets between instances
+func NewRedisStore(client redis.Client) Store {
+	return &redisStore{client: client, now: time.Now}
+}
+
+func (store *redisStore) Take(buckets []Bucket) (bool, time.Duration, error) {
+	if len(buckets) == 0 {
+		return true, 0, nil
+	}
+	args := []string{"EVAL", takeScript, strconv.Itoa(len(buckets))}
+	for _, bucket := range buckets {
+		args = append(args, bucket.Key)
+	}
+	args = append(args, strconv.FormatInt(store.now().UnixMilli(), 10))
+	for _, bucket := range buckets {
+		args = append(args, strconv.Itoa(bucket.Limit.Burst), strconv.FormatInt(bucket.Limit.Period.Milliseconds(), 10))
+	}
+	reply, err := store.client.Do(args...)
+	if err != nil {
+		return false, 0, err
+	}
+	waitInMillis, ok := reply.(int64)
+	if !ok {
+		return false, 0, fmt.Errorf("unexpected redis reply %v", reply)
+	}
+	if waitInMillis > 0 {
+		return false, time.Duration(waitInMillis) * time.Millisecond, nil
+	}
+	return true, 0, nil
+}
@@ -0,0 +1,112 @@
+package ratelimit
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+const (
+	MemoryStore = "memory"
+	RedisStore  = "redis"
+)
+
+// Limit is a token bucket holding up to Burst tokens, refilled at Burst tokens per Period
+type Limit struct {
+	Burst  int
+	Period time.Duration
+}
+
+// Bucket is the key a token is taken from and the limit it refills at
+type Bucket struct {
+	Key   string
+	Limit Limit
+}
+
+// Store keeps the buckets. Take removes a token from every bucket, once for each time it is listed, or from none of them
+// and tells how long until all of them have one.
+type Store interface {
+	Take(buckets []Bucket) (bool, time.Duration, error)
+}
+
+type bucket struct {
+	tokens    float64
+	updatedAt time.Time
+	period    time.Duration
+}
+
+type memoryStore struct {
+	mutex   sync.Mutex
+	buckets map[string]bucket
+	now     func() time.Time
+}
+
+// NewMemoryStore keeps buckets per instance, behind a load balancer every instance allows the full limit
+func NewMemoryStore() Store {
+	return &memoryStore{buckets: map[string]bucket{}, now: time.Now}
+}
+
+func (store *memoryStore) Take(buckets []Bucket) (bool, time.Duration, error) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+	now := store.now()
+	store.removeFull(now)
+
+	// taken holds the buckets after taking, they replace the stored ones only when every bucket had a token
+	taken := map[string]bucket{}
+	var wait time.Duration
+	for _, requested := range buckets {
+		current, found := taken[requested.Key]
+		if !found {
+			current, found = store.buckets[requested.Key]
+			if !found {
+				current = bucket{tokens: float64(requested.Limit.Burst), updatedAt: now}
+			}
+			current.tokens = refill(current, requested.Limit, now)
+			current.updatedAt = now
+			current.period = requested.Limit.Period
+		}
+		if current.tokens < 1 {
+			wait = maxDuration(wait, requested.Limit.waitFor(1-current.tokens))
+		}
+		current.tokens--
+		taken[requested.Key] = current
+	}
+	if wait > 0 {
+		return false, wait, nil
+	}
+	for key, current := range taken {
+		store.buckets[key] = current
+	}
+	return true, 0, nil
+}
+
+// removeFull forgets buckets that have been idle for a whole period, they would be full again anyway
+func (store *memoryStore) removeFull(now time.Time) {
+	for key, idle := range store.buckets {
+		if now.Sub(idle.updatedAt) >= idle.period {
+			delete(store.buckets, key)
+		}
+	}
+}
+
+func refill(current bucket, limit Limit, now time.Time) float64 {
+	elapsed := now.Sub(current.updatedAt)
+	return math.Min(float64(limit.Burst), current.tokens+float64(elapsed)*limit.rate())
+}
+
+// rate is the number of tokens added per nanosecond
+func (limit Limit) rate() float64 {
+	return float64(limit.Burst) / float64(limit.Period)
+}
+
+func (limit Limit) waitFor(missingTokens float64) time.Duration {
+	return time.Duration(math.Ceil(missingTokens / limit.rate()))
+}
+
+func maxDuration(first time.Duration, second time.Duration) time.Duration {
+	if first > second {
+		return first
+	}
+	return second
+}
@@ -0,0 +1,149 @@
+package ratelimit
+
+import (
+	"ccg-api/email/redis/mocks"
+	"errors"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/suite"
+	"strconv"
+	"testing"
+	"time"
+)
+
+type storeTestSuite struct {
+	suite.Suite
+	now   time.Time
+	store *memoryStore
+	limit Limit
+}
+
+func TestStoreTestSuite(t *testing.T) {
+	suite.Run(t, new(storeTestSuite))
+}
+
+func (suite *storeTestSuite) SetupTest() {
+	suite.now = time.Date(2020, 9, 20, 10, 0, 0, 0, time.UTC)
+	suite.store = NewMemoryStore().(*memoryStore)
+	suite.store.now = func() time.Time { return suite.now }
+	suite.limit = Limit{Burst: 5, Period: time.Hour}
+}
+
+func (suite *storeTestSuite) TestTake_ShouldAllowBurstAndThenTellHowLongToWait() {
+	for attempt := 0; attempt < 5; attempt++ {
+		allowed, _, err := suite.store.Take([]Bucket{{Key: "some-key", Limit: suite.limit}})
+		suite.Nil(err)
+		suite.True(allowed)
+	}
+
+	allowed, retryAfter, err := suite.store.Take([]Bucket{{Key: "some-key", Limit: suite.limit}})
+
+	suite.Nil(err)
+	suite.False(allowed)
+	suite.Equal(12*time.Minute, retryAfter)
+}
+
+func (suite *storeTestSuite) TestTake_ShouldRefillBucketOverThePeriod() {
+	for attempt := 0; attempt < 5; attempt++ {
+		_, _, _ = suite.store.Take([]Bucket{{Key: "some-key", Limit: suite.limit}})
+	}
+
+	suite.now = suite.now.Add(12 * time.Minute)
+	allowed, _, _ := suite.store.Take([]Bucket{{Key: "some-key", Limit: suite.limit}})
+	suite.True(allowed)
+	allowed, _, _ = suite.store.Take([]Bucket{{Key: "some-key", Limit: suite.limit}})
+	suite.False(allowed)
+}
+
+func (suite *storeTestSuite) TestTake_ShouldKeepBucketsOfDifferentKeysApart() {
+	for attempt := 0; attempt < 5; attempt++ {
+		_, _, _ = suite.store.Take([]Bucket{{Key: "some-key", Limit: suite.limit}})
+	}
+
+	allowed, _, _ := suite.store.Take([]Bucket{{Key: "other-key", Limit: suite.limit}})
+
+	suite.True(allowed)
+}
+
+func (suite *storeTestSuite) TestTake_ShouldForgetBucketsIdleForAWholePeriod() {
+	_, _, _ = suite.store.Take([]Bucket{{Key: "some-key", Limit: suite.limit}})
+	_, _, _ = suite.store.Take([]Bucket{{Key: "short-key", Limit: Limit{Burst: 1, Period: time.Minute}}})
+
+	suite.now = suite.now.Add(time.Hour)
+	_, _, _ = suite.store.Take([]Bucket{{Key: "other-key", Limit: suite.limit}})
+
+	suite.Len(suite.store.buckets, 1)
+}
+
+func (suite *storeTestSuite) TestRedisTake_ShouldRunTakeScriptAndReportWait() {
+	mockCtrl := gomock.NewController(suite.T())
+	defer mockCtrl.Finish()
+	client := mocks.NewMockClient(mockCtrl)
+	now := strconv.FormatInt(suite.now.UnixMilli(), 10)
+	gomock.InOrder(
+		client.EXPECT().Do("EVAL", takeScript, "1", "some-key", now, "5", "3600000").Return(int64(0), nil),
+		client.EXPECT().Do("EVAL", takeScript, "1", "some-key", now, "5", "3600000").Return(int64(720000), nil),
+	)
+	store := &redisStore{client: client, now: func() time.Time { return suite.now }}
+
+	allowed, _, err := store.Take([]Bucket{{Key: "some-key", Limit: suite.limit}})
+	suite.Nil(err)
+	suite.True(allowed)
+	allowed, retryAfter, err := store.Take([]Bucket{{Key: "some-key", Limit: suite.limit}})
+	suite.Nil(err)
+	suite.False(allowed)
+	suite.Equal(12*time.Minute, retryAfter)
+}
+
+func (suite *storeTestSuite) TestTake_ShouldTakeFromNoBucketWhenOneIsEmpty() {
+	_, _, _ = suite.store.Take([]Bucket{{Key: "empty-key", Limit: Limit{Burst: 1, Period: time.Hour}}})
+
+	allowed, retryAfter, err := suite.store.Take([]Bucket{
+		{Key: "some-key", Limit: suite.limit},
+		{Key: "empty-key", Limit: Limit{Burst: 1, Period: time.Hour}},
+	})
+
+	suite.Nil(err)
+	suite.False(allowed)
+	suite.Equal(time.Hour, retryAfter)
+	_, found := suite.store.buckets["some-key"]
+	suite.False(found)
+}
+
+func (suite *storeTestSuite) TestTake_ShouldTakeOnceForEveryTimeABucketIsListed() {
+	limit := Limit{Burst: 2, Period: time.Hour}
+
+	allowed, _, _ := suite.store.Take([]Bucket{{Key: "some-key", Limit: limit}, {Key: "some-key", Limit: limit}})
+	suite.True(allowed)
+	allowed, retryAfter, _ := suite.store.Take([]Bucket{{Key: "some-key", Limit: limit}})
+	suite.False(allowed)
+	suite.Equal(30*time.Minute, retryAfter)
+}
+
+func (suite *storeTestSuite) TestRedisTake_ShouldPassEveryBucketToTakeScript() {
+	mockCtrl := gomock.NewController(suite.T())
+	defer mockCtrl.Finish()
+	client := mocks.NewMockClient(mockCtrl)
+	now := strconv.FormatInt(suite.now.UnixMilli(), 10)
+	client.EXPECT().Do("EVAL", takeScript, "2", "some-key", "other-key", now, "5", "3600000", "1", "60000").Return(int64(0), nil)
+	store := &redisStore{client: client, now: func() time.Time { return suite.now }}
+
+	allowed, _, err := store.Take([]Bucket{
+		{Key: "some-key", Limit: suite.limit},
+		{Key: "other-key", Limit: Limit{Burst: 1, Period: time.Minute}},
+	})
+
+	suite.Nil(err)
+	suite.True(allowed)
+}
+
+func (suite *storeTestSuite) TestRedisTake_ShouldReturnErrorOfClient() {
+	mockCtrl := gomock.NewController(suite.T())
+	defer mockCtrl.Finish()
+	client := mocks.NewMockClient(mockCtrl)
+	client.EXPECT().Do(gomock.Any()).Return(nil, errors.New("NOSCRIPT no script"))
+	store := &redisStore{client: client, now: time.Now}
+
+	_, _, err := store.Take([]Bucket{{Key: "some-key", Limit: suite.limit}})
+
+	suite.EqualError(err, "NOSCRIPT no script")
+}
@@ -0,0 +1,45 @@
+package redis
+
+// mockgen -source=email/redis/client.go -destination=email/redis/mocks/mock_client.go -package=mocks
+import (
+	"ccg-api/configuration"
+	"context"
+	goredis "github.com/go-redis/redis/v8"
+	"time"
+)
+
+// Client runs a command on one of a pool of connections, replies are strings, integers or nil
+type Client interface {
+	Do(args ...string) (interface{}, error)
+}
+
+type client struct {
+	redis *goredis.Client
+}
+
+// NewClient connects on first use. A zero pool size or timeout keeps the go-redis default.
+func NewClient(config configuration.Redis, password string) Client {
+	timeout := time.Duration(config.TimeoutInMilliseconds) * time.Millisecond
+	return client{redis: goredis.NewClient(&goredis.Options{
+		Addr:         config.Address,
+		DB:           config.Database,
+		Password:     password,
+		PoolSize:     config.PoolSize,
+		DialTimeout:  timeout,
+		ReadTimeout:  timeout,
+		WriteTimeout: timeout,
+	})}
+}
+
+// Do reports a missing key as a nil reply rather than an error
+func (client client) Do(args ...string) (interface{}, error) {
+	commandArgs := make([]interface{}, len(args))
+	for index, arg := range args {
+		commandArgs[index] = arg
+	}
+	reply, err := client.redis.Do(context.Background(), commandArgs...).Result()
+	if err == goredis.Nil {
+		return nil, nil
+	}
+	return reply, err
+}
@@ -0,0 +1,150 @@
+package redis
+
+import (
+	"bufio"
+	"ccg-api/configuration"
+	"github.com/stretchr/testify/suite"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeServer answers each command, whose name go-redis sends in lower case, with the reply of its name, or of its name and first argument
+type fakeServer struct {
+	listener net.Listener
+	replies  map[string]string
+	mutex    sync.Mutex
+	commands [][]string
+}
+
+func newFakeServer(replies map[string]string) *fakeServer {
+	listener, _ := net.Listen("tcp", "127.0.0.1:0")
+	server := &fakeServer{listener: listener, replies: replies}
+	go func() {
+		for {
+			connection, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go server.serve(connection)
+		}
+	}()
+	return server
+}
+
+func (server *fakeServer) serve(connection net.Conn) {
+	defer connection.Close()
+	reader := bufio.NewReader(connection)
+	for {
+		command, err := readCommand(reader)
+		if err != nil {
+			return
+		}
+		command[0] = strings.ToUpper(command[0])
+		server.mutex.Lock()
+		server.commands = append(server.commands, command)
+		server.mutex.Unlock()
+		reply, found := "", false
+		if len(command) > 1 {
+			reply, found = server.replies[command[0]+" "+command[1]]
+		}
+		if !found {
+			reply = server.replies[command[0]]
+		}
+		_, _ = connection.Write([]byte(reply + "\r\n"))
+	}
+}
+
+func (server *fakeServer) received() [][]string {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+	return server.commands
+}
+
+type clientTestSuite struct {
+	suite.Suite
+	server *fakeServer
+	client Client
+}
+
+func TestClientTestSuite(t *testing.T) {
+	suite.Run(t, new(clientTestSuite))
+}
+
+func (suite *clientTestSuite) SetupTest() {
+	suite.server = newFakeServer(map[string]string{
+		"AUTH":             "+OK",
+		"SELECT":           "+OK",
+		"PTTL":             ":720000",
+		"GET some-key":     "$5\r\nvalue",
+		"GET other-key":    "$-1",
+		"EVALSHA":          "-NOSCRIPT no script",
+		"SET some-key":     "+OK",
+		"SET existing-key": "$-1",
+	})
+	suite.client = NewClient(configuration.Redis{Address: suite.server.listener.Addr().String(), Database: 2, PoolSize: 2}, "secret")
+}
+
+func (suite *clientTestSuite) TearDownTest() {
+	_ = suite.server.listener.Close()
+}
+
+func (suite *clientTestSuite) TestDo_ShouldAuthenticateSelectDatabaseAndReturnReplies() {
+	reply, err := suite.client.Do("PTTL", "some-key")
+	suite.Nil(err)
+	suite.Equal(int64(720000), reply)
+	reply, err = suite.client.Do("GET", "some-key")
+	suite.Nil(err)
+	suite.Equal("value", reply)
+
+	commands := suite.server.received()
+	suite.Equal([]string{"AUTH", "secret"}, commands[0])
+	suite.Equal([]string{"SELECT", "2"}, commands[1])
+	suite.Equal([]string{"PTTL", "some-key"}, commands[2])
+}
+
+func (suite *clientTestSuite) TestDo_ShouldReturnNilReplyForMissingValue() {
+	reply, err := suite.client.Do("GET", "other-key")
+	suite.Nil(err)
+	suite.Nil(reply)
+	reply, err = suite.client.Do("SET", "existing-key", "value", "NX")
+	suite.Nil(err)
+	suite.Nil(reply)
+	reply, err = suite.client.Do("SET", "some-key", "value", "NX")
+	suite.Nil(err)
+	suite.Equal("OK", reply)
+}
+
+func (suite *clientTestSuite) TestDo_ShouldReturnErrorReplyAndKeepServingCommands() {
+	_, err := suite.client.Do("EVALSHA", "sha", "0")
+	suite.EqualError(err, "NOSCRIPT no script")
+
+	reply, err := suite.client.Do("GET", "some-key")
+	suite.Nil(err)
+	suite.Equal("value", reply)
+}
+
+func readCommand(reader *bufio.Reader) ([]string, error) {
+	header, err := reader.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	count, _ := strconv.Atoi(strings.TrimSpace(header[1:]))
+	var args []string
+	for index := 0; index < count; index++ {
+		lengthLine, err := reader.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		length, _ := strconv.Atoi(strings.TrimSpace(lengthLine[1:]))
+		arg := make([]byte, length+2)
+		if _, err := io.ReadFull(reader, arg); err != nil {
+			return nil, err
+		}
+		args = append(args, string(arg[:length]))
+	}
+	return args, nil
+}
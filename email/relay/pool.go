@@ -0,0 +1,249 @@
+package relay
+
+import (
+	"ccg-api/configuration"
+	"ccg-api/email/email-client"
+	"ccg-api/email/email-client/email_client_request"
+	"ccg-api/email/retry"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/inclusi-blog/gola-utils/logging"
+	"gopkg.in/gomail.v2"
+	"math/rand"
+	"net/textproto"
+	"sort"
+	"sync"
+	"time"
+)
+
+type State string
+
+const (
+	Closed   State = "closed"
+	Open     State = "open"
+	HalfOpen State = "half_open"
+
+	defaultFailureThreshold = 3
+	defaultCooldown         = time.Minute
+)
+
+var ErrNoRelayAvailable = errors.New("all smtp relays are cooling down")
+
+// authenticationFailureCodes are 5xx replies that concern our credentials on the relay rather than the message
+var authenticationFailureCodes = map[int]bool{530: true, 534: true, 535: true, 538: true}
+
+type Relay struct {
+	Name     string
+	Host     string
+	Priority int
+	Weight   int
+	Dialer   email_client.GomailDialer
+}
+
+type Status struct {
+	Name                string     `json:"name"`
+	Host                string     `json:"host"`
+	State               State      `json:"state"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	Sent                uint64     `json:"sent"`
+	Failed              uint64     `json:"failed"`
+	LastError           string     `json:"last_error,omitempty"`
+	OpenUntil           *time.Time `json:"open_until,omitempty"`
+	// Tenant owns the relay, empty for the relays shared by tenants without their own
+	Tenant string `json:"tenant,omitempty"`
+}
+
+// Pool is a transport that routes through the most preferred healthy relay and fails over to the next one
+type Pool interface {
+	email_client.Transport
+	Statuses() []Status
+}
+
+type relayState struct {
+	relay  Relay
+	status Status
+	// probing is set while the one trial request of a half open relay is in flight
+	probing bool
+}
+
+type pool struct {
+	mutex            sync.Mutex
+	relays           []*relayState
+	failureThreshold int
+	cooldown         time.Duration
+	now              func() time.Time
+	random           func(n int) int
+}
+
+func NewPool(relays []Relay, circuitBreaker configuration.CircuitBreaker) Pool {
+	relayPool := &pool{
+		failureThreshold: circuitBreaker.FailureThreshold,
+		cooldown:         time.Duration(circuitBreaker.CooldownInSeconds) * time.Second,
+		now:              time.Now,
+		random:           rand.Intn,
+	}
+	if relayPool.failureThreshold <= 0 {
+		relayPool.failureThreshold = defaultFailureThreshold
+	}
+	if relayPool.cooldown <= 0 {
+		relayPool.cooldown = defaultCooldown
+	}
+	for _, relay := range relays {
+		relayPool.relays = append(relayPool.relays, &relayState{
+			relay:  relay,
+			status: Status{Name: relay.Name, Host: relay.Host, State: Closed},
+		})
+	}
+	return relayPool
+}
+
+func (relayPool *pool) Deliver(ctx *gin.Context, request *email_client_request.EmailClientRequest, message *gomail.Message) error {
+	logger := logging.GetLogger(ctx).WithField("class", "RelayPool").WithField("method", "Deliver")
+	candidates := relayPool.candidates()
+	if len(candidates) == 0 {
+		return ErrNoRelayAvailable
+	}
+
+	err := ErrNoRelayAvailable
+	for _, candidate := range candidates {
+		if !relayPool.admit(candidate) {
+			continue
+		}
+		err = candidate.relay.Dialer.DialAndSend(message)
+		if err == nil {
+			relayPool.recordHealthy(candidate, true)
+			return nil
+		}
+		if !shouldFailover(err) {
+			// the relay is fine, it is the message that was rejected
+			relayPool.recordHealthy(candidate, false)
+			return err
+		}
+		logger.Warnf("Relay %s failed, failing over to the next relay %v", candidate.relay.Name, err)
+		relayPool.recordFailure(candidate, err)
+	}
+	return err
+}
+
+func (relayPool *pool) Statuses() []Status {
+	relayPool.mutex.Lock()
+	defer relayPool.mutex.Unlock()
+	var statuses []Status
+	for _, state := range relayPool.relays {
+		statuses = append(statuses, state.status)
+	}
+	return statuses
+}
+
+// candidates orders relays that are not cooling down by priority, relays of equal priority are shuffled by weight
+func (relayPool *pool) candidates() []*relayState {
+	relayPool.mutex.Lock()
+	defer relayPool.mutex.Unlock()
+
+	byPriority := map[int][]*relayState{}
+	var priorities []int
+	for _, state := range relayPool.relays {
+		if !relayPool.available(state) {
+			continue
+		}
+		if _, seen := byPriority[state.relay.Priority]; !seen {
+			priorities = append(priorities, state.relay.Priority)
+		}
+		byPriority[state.relay.Priority] = append(byPriority[state.relay.Priority], state)
+	}
+	sort.Ints(priorities)
+
+	var candidates []*relayState
+	for _, priority := range priorities {
+		candidates = append(candidates, relayPool.weightedShuffle(byPriority[priority])...)
+	}
+	return candidates
+}
+
+// available is false for relays cooling down, and for half open relays already taking their trial request. Callers must hold the mutex
+func (relayPool *pool) available(state *relayState) bool {
+	switch state.status.State {
+	case Open:
+		return !relayPool.now().Before(*state.status.OpenUntil)
+	case HalfOpen:
+		return !state.probing
+	}
+	return true
+}
+
+// admit lets a relay whose cool down is over take a single trial request, concurrent requests carry on to the other relays
+func (relayPool *pool) admit(state *relayState) bool {
+	relayPool.mutex.Lock()
+	defer relayPool.mutex.Unlock()
+	if !relayPool.available(state) {
+		return false
+	}
+	if state.status.State != Closed {
+		state.status.State = HalfOpen
+		state.probing = true
+	}
+	return true
+}
+
+func (relayPool *pool) weightedShuffle(states []*relayState) []*relayState {
+	remaining := append([]*relayState{}, states...)
+	var shuffled []*relayState
+	for len(remaining) > 0 {
+		totalWeight := 0
+		for _, state := range remaining {
+			totalWeight += weight(state.relay)
+		}
+		pick := relayPool.random(totalWeight)
+		for index, state := range remaining {
+			pick -= weight(state.relay)
+			if pick < 0 {
+				shuffled = append(shuffled, state)
+				remaining = append(remaining[:index], remaining[index+1:]...)
+				break
+			}
+		}
+	}
+	return shuffled
+}
+
+func weight(relay Relay) int {
+	if relay.Weight <= 0 {
+		return 1
+	}
+	return relay.Weight
+}
+
+func (relayPool *pool) recordHealthy(state *relayState, sent bool) {
+	relayPool.mutex.Lock()
+	defer relayPool.mutex.Unlock()
+	state.status.State = Closed
+	state.probing = false
+	state.status.ConsecutiveFailures = 0
+	state.status.OpenUntil = nil
+	if sent {
+		state.status.Sent++
+	}
+}
+
+func (relayPool *pool) recordFailure(state *relayState, err error) {
+	relayPool.mutex.Lock()
+	defer relayPool.mutex.Unlock()
+	state.probing = false
+	state.status.Failed++
+	state.status.ConsecutiveFailures++
+	state.status.LastError = err.Error()
+	if state.status.State == HalfOpen || state.status.ConsecutiveFailures >= relayPool.failureThreshold {
+		openUntil := relayPool.now().Add(relayPool.cooldown)
+		state.status.State = Open
+		state.status.OpenUntil = &openUntil
+	}
+}
+
+// shouldFailover is true for connection, authentication and temporary failures, another relay may well accept those
+func shouldFailover(err error) bool {
+	var smtpError *textproto.Error
+	if errors.As(err, &smtpError) && authenticationFailureCodes[smtpError.Code] {
+		return true
+	}
+	return retry.Classify(err) == retry.Transient
+}
@@ -0,0 +1,196 @@
+package relay
+
+import (
+	"ccg-api/configuration"
+	"ccg-api/email/email-client/email_client_request"
+	mockemailclient "ccg-api/email/email-client/mocks"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/suite"
+	"gopkg.in/gomail.v2"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"testing"
+	"time"
+)
+
+type poolTestSuite struct {
+	suite.Suite
+	context   *gin.Context
+	mockCtrl  *gomock.Controller
+	primary   *mockemailclient.MockGomailDialer
+	secondary *mockemailclient.MockGomailDialer
+	message   *gomail.Message
+	request   *email_client_request.EmailClientRequest
+	now       time.Time
+}
+
+func TestPoolTestSuite(t *testing.T) {
+	suite.Run(t, new(poolTestSuite))
+}
+
+func (suite *poolTestSuite) SetupTest() {
+	suite.context, _ = gin.CreateTestContext(httptest.NewRecorder())
+	suite.context.Request, _ = http.NewRequest("POST", "/", nil)
+	suite.mockCtrl = gomock.NewController(suite.T())
+	suite.primary = mockemailclient.NewMockGomailDialer(suite.mockCtrl)
+	suite.secondary = mockemailclient.NewMockGomailDialer(suite.mockCtrl)
+	suite.message = gomail.NewMessage()
+	suite.request = &email_client_request.EmailClientRequest{}
+	suite.now = time.Date(2020, 9, 20, 10, 0, 0, 0, time.UTC)
+}
+
+func (suite *poolTestSuite) TearDownTest() {
+	suite.mockCtrl.Finish()
+}
+
+func (suite *poolTestSuite) newPool() *pool {
+	relayPool := NewPool([]Relay{
+		{Name: "secondary", Host: "smtp.backup.com", Priority: 1, Dialer: suite.secondary},
+		{Name: "primary", Host: "smtp-relay.sendinblue.com", Priority: 0, Dialer: suite.primary},
+	}, configuration.CircuitBreaker{FailureThreshold: 2, CooldownInSeconds: 60}).(*pool)
+	relayPool.now = func() time.Time { return suite.now }
+	return relayPool
+}
+
+func (suite *poolTestSuite) TestDeliver_ShouldSendThroughPrimaryRelayWhenHealthy() {
+	suite.primary.EXPECT().DialAndSend(suite.message).Return(nil)
+
+	relayPool := suite.newPool()
+
+	suite.Nil(relayPool.Deliver(suite.context, suite.request, suite.message))
+	suite.Equal(uint64(1), relayPool.Statuses()[1].Sent)
+}
+
+func (suite *poolTestSuite) TestDeliver_ShouldFailOverToNextRelayOnConnectionError() {
+	suite.primary.EXPECT().DialAndSend(suite.message).Return(errors.New("dial tcp: connection refused"))
+	suite.secondary.EXPECT().DialAndSend(suite.message).Return(nil)
+
+	relayPool := suite.newPool()
+
+	suite.Nil(relayPool.Deliver(suite.context, suite.request, suite.message))
+	statuses := relayPool.Statuses()
+	suite.Equal(Status{Name: "secondary", Host: "smtp.backup.com", State: Closed, Sent: 1}, statuses[0])
+	suite.Equal(Status{Name: "primary", Host: "smtp-relay.sendinblue.com", State: Closed, ConsecutiveFailures: 1, Failed: 1,
+		LastError: "dial tcp: connection refused"}, statuses[1])
+}
+
+func (suite *poolTestSuite) TestDeliver_ShouldFailOverOnAuthenticationFailureAndTemporaryReply() {
+	suite.primary.EXPECT().DialAndSend(suite.message).Return(&textproto.Error{Code: 535, Msg: "5.7.8 authentication failed"})
+	suite.secondary.EXPECT().DialAndSend(suite.message).Return(nil)
+	suite.primary.EXPECT().DialAndSend(suite.message).Return(&textproto.Error{Code: 421, Msg: "4.7.0 try again later"})
+	suite.secondary.EXPECT().DialAndSend(suite.message).Return(nil)
+
+	relayPool := suite.newPool()
+
+	suite.Nil(relayPool.Deliver(suite.context, suite.request, suite.message))
+	suite.Nil(relayPool.Deliver(suite.context, suite.request, suite.message))
+}
+
+func (suite *poolTestSuite) TestDeliver_ShouldNotFailOverIfRelayRejectsTheMessage() {
+	rejection := errors.New("gomail: could not send email 1: 550 5.1.1 mailbox unavailable")
+	suite.primary.EXPECT().DialAndSend(suite.message).Return(rejection)
+
+	relayPool := suite.newPool()
+
+	suite.Equal(rejection, relayPool.Deliver(suite.context, suite.request, suite.message))
+	suite.Equal(Closed, relayPool.Statuses()[1].State)
+	suite.Equal(uint64(0), relayPool.Statuses()[1].Failed)
+}
+
+func (suite *poolTestSuite) TestDeliver_ShouldReturnLastErrorIfEveryRelayFails() {
+	suite.primary.EXPECT().DialAndSend(suite.message).Return(errors.New("dial tcp: connection refused"))
+	suite.secondary.EXPECT().DialAndSend(suite.message).Return(errors.New("dial tcp: i/o timeout"))
+
+	err := suite.newPool().Deliver(suite.context, suite.request, suite.message)
+
+	suite.EqualError(err, "dial tcp: i/o timeout")
+}
+
+func (suite *poolTestSuite) TestDeliver_ShouldSkipRelayInCoolDownAndProbeItOnceCoolDownIsOver() {
+	relayPool := suite.newPool()
+	suite.primary.EXPECT().DialAndSend(suite.message).Return(errors.New("dial tcp: connection refused")).Times(2)
+	suite.secondary.EXPECT().DialAndSend(suite.message).Return(nil).Times(3)
+
+	suite.Nil(relayPool.Deliver(suite.context, suite.request, suite.message))
+	suite.Nil(relayPool.Deliver(suite.context, suite.request, suite.message))
+	suite.Equal(Open, relayPool.Statuses()[1].State)
+	suite.Equal(suite.now.Add(time.Minute), *relayPool.Statuses()[1].OpenUntil)
+
+	suite.Nil(relayPool.Deliver(suite.context, suite.request, suite.message))
+
+	suite.now = suite.now.Add(time.Minute)
+	suite.primary.EXPECT().DialAndSend(suite.message).Return(nil)
+	suite.Nil(relayPool.Deliver(suite.context, suite.request, suite.message))
+	suite.Equal(Closed, relayPool.Statuses()[1].State)
+	suite.Equal(0, relayPool.Statuses()[1].ConsecutiveFailures)
+}
+
+func (suite *poolTestSuite) TestDeliver_ShouldReopenCircuitIfProbeFails() {
+	relayPool := suite.newPool()
+	relayPool.relays[1].status.State = Open
+	openUntil := suite.now
+	relayPool.relays[1].status.OpenUntil = &openUntil
+	suite.primary.EXPECT().DialAndSend(suite.message).Return(errors.New("dial tcp: connection refused"))
+	suite.secondary.EXPECT().DialAndSend(suite.message).Return(nil)
+
+	suite.Nil(relayPool.Deliver(suite.context, suite.request, suite.message))
+
+	suite.Equal(Open, relayPool.Statuses()[1].State)
+	suite.Equal(suite.now.Add(time.Minute), *relayPool.Statuses()[1].OpenUntil)
+}
+
+func (suite *poolTestSuite) TestDeliver_ShouldKeepOtherRequestsOffHalfOpenRelayWhileItsTrialIsInFlight() {
+	relayPool := suite.newPool()
+	relayPool.relays[1].status.State = Open
+	openUntil := suite.now
+	relayPool.relays[1].status.OpenUntil = &openUntil
+	var concurrentError error
+	suite.primary.EXPECT().DialAndSend(suite.message).DoAndReturn(func(messages ...*gomail.Message) error {
+		concurrentError = relayPool.Deliver(suite.context, suite.request, suite.message)
+		return nil
+	})
+	suite.secondary.EXPECT().DialAndSend(suite.message).Return(nil)
+
+	suite.Nil(relayPool.Deliver(suite.context, suite.request, suite.message))
+
+	suite.Nil(concurrentError)
+	suite.Equal(Closed, relayPool.Statuses()[1].State)
+}
+
+func (suite *poolTestSuite) TestDeliver_ShouldReturnErrorWithoutDialingIfEveryRelayIsCoolingDown() {
+	relayPool := suite.newPool()
+	openUntil := suite.now.Add(time.Second)
+	for _, state := range relayPool.relays {
+		state.status.State = Open
+		state.status.OpenUntil = &openUntil
+	}
+
+	suite.Equal(ErrNoRelayAvailable, relayPool.Deliver(suite.context, suite.request, suite.message))
+}
+
+func (suite *poolTestSuite) TestCandidates_ShouldShareTrafficOfEqualPriorityRelaysByWeight() {
+	heavy := mockemailclient.NewMockGomailDialer(suite.mockCtrl)
+	light := mockemailclient.NewMockGomailDialer(suite.mockCtrl)
+	relayPool := NewPool([]Relay{
+		{Name: "light", Weight: 1, Dialer: light},
+		{Name: "heavy", Weight: 3, Dialer: heavy},
+		{Name: "fallback", Priority: 5, Dialer: suite.secondary},
+	}, configuration.CircuitBreaker{}).(*pool)
+
+	relayPool.random = func(n int) int { return 0 }
+	suite.Equal([]string{"light", "heavy", "fallback"}, names(relayPool.candidates()))
+
+	relayPool.random = func(n int) int { return n - 1 }
+	suite.Equal([]string{"heavy", "light", "fallback"}, names(relayPool.candidates()))
+}
+
+func names(states []*relayState) []string {
+	var relayNames []string
+	for _, state := range states {
+		relayNames = append(relayNames, state.relay.Name)
+	}
+	return relayNames
+}
@@ -0,0 +1,44 @@
+package relay
+
+import (
+	"ccg-api/email/email-client/email_client_request"
+	"github.com/gin-gonic/gin"
+	"gopkg.in/gomail.v2"
+	"sort"
+)
+
+type tenantPool struct {
+	shared  Pool
+	tenants map[string]Pool
+}
+
+// NewTenantPool sends the messages of every tenant through its own pool, and the messages of tenants without relays
+// of their own through the shared pool. Each pool keeps its own circuit state, a relay failing for one tenant does
+// not take it out of rotation for the others.
+func NewTenantPool(shared Pool, tenants map[string]Pool) Pool {
+	return tenantPool{shared: shared, tenants: tenants}
+}
+
+func (tenantPool tenantPool) Deliver(ctx *gin.Context, request *email_client_request.EmailClientRequest, message *gomail.Message) error {
+	if pool, found := tenantPool.tenants[request.Tenant]; found {
+		return pool.Deliver(ctx, request, message)
+	}
+	return tenantPool.shared.Deliver(ctx, request, message)
+}
+
+// Statuses lists the shared relays first, then the relays of each tenant by tenant name
+func (tenantPool tenantPool) Statuses() []Status {
+	statuses := tenantPool.shared.Statuses()
+	var tenants []string
+	for tenant := range tenantPool.tenants {
+		tenants = append(tenants, tenant)
+	}
+	sort.Strings(tenants)
+	for _, tenant := range tenants {
+		for _, status := range tenantPool.tenants[tenant].Statuses() {
+			status.Tenant = tenant
+			statuses = append(statuses, status)
+		}
+	}
+	return statuses
+}
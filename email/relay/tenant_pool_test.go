@@ -0,0 +1,68 @@
+package relay
+
+import (
+	"ccg-api/configuration"
+	"ccg-api/email/email-client/email_client_request"
+	mockemailclient "ccg-api/email/email-client/mocks"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/suite"
+	"gopkg.in/gomail.v2"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type tenantPoolTestSuite struct {
+	suite.Suite
+	context    *gin.Context
+	mockCtrl   *gomock.Controller
+	shared     *mockemailclient.MockGomailDialer
+	narratenet *mockemailclient.MockGomailDialer
+	message    *gomail.Message
+	tenantPool Pool
+}
+
+func TestTenantPoolTestSuite(t *testing.T) {
+	suite.Run(t, new(tenantPoolTestSuite))
+}
+
+func (suite *tenantPoolTestSuite) SetupTest() {
+	suite.context, _ = gin.CreateTestContext(httptest.NewRecorder())
+	suite.context.Request, _ = http.NewRequest("POST", "/", nil)
+	suite.mockCtrl = gomock.NewController(suite.T())
+	suite.shared = mockemailclient.NewMockGomailDialer(suite.mockCtrl)
+	suite.narratenet = mockemailclient.NewMockGomailDialer(suite.mockCtrl)
+	suite.message = gomail.NewMessage()
+	suite.tenantPool = NewTenantPool(
+		NewPool([]Relay{{Name: "shared", Host: "smtp-relay.sendinblue.com", Dialer: suite.shared}}, configuration.CircuitBreaker{}),
+		map[string]Pool{
+			"narratenet": NewPool([]Relay{{Name: "narratenet", Host: "smtp.narratenet.com", Dialer: suite.narratenet}}, configuration.CircuitBreaker{}),
+		})
+}
+
+func (suite *tenantPoolTestSuite) TearDownTest() {
+	suite.mockCtrl.Finish()
+}
+
+func (suite *tenantPoolTestSuite) TestDeliver_ShouldSendThroughRelaysOfTenant() {
+	suite.narratenet.EXPECT().DialAndSend(suite.message).Return(nil)
+
+	err := suite.tenantPool.Deliver(suite.context, &email_client_request.EmailClientRequest{Tenant: "narratenet"}, suite.message)
+
+	suite.Nil(err)
+}
+
+func (suite *tenantPoolTestSuite) TestDeliver_ShouldSendThroughSharedRelaysWhenTenantHasNoneOfItsOwn() {
+	suite.shared.EXPECT().DialAndSend(suite.message).Return(nil).Times(2)
+
+	suite.Nil(suite.tenantPool.Deliver(suite.context, &email_client_request.EmailClientRequest{Tenant: "gola"}, suite.message))
+	suite.Nil(suite.tenantPool.Deliver(suite.context, &email_client_request.EmailClientRequest{}, suite.message))
+}
+
+func (suite *tenantPoolTestSuite) TestStatuses_ShouldListSharedRelaysThenRelaysOfEachTenant() {
+	suite.Equal([]Status{
+		{Name: "shared", Host: "smtp-relay.sendinblue.com", State: Closed},
+		{Name: "narratenet", Host: "smtp.narratenet.com", State: Closed, Tenant: "narratenet"},
+	}, suite.tenantPool.Statuses())
+}
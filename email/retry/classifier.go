@@ -0,0 +1,83 @@
+package retry
+
+import (
+	"errors"
+	"io"
+	"net"
+	"net/textproto"
+	"regexp"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+type Classification int
+
+const (
+	Transient Classification = iota
+	Permanent
+)
+
+// gomail flattens SMTP replies into strings such as "gomail: could not send email 1: 550 5.1.1 mailbox unavailable"
+var smtpReplyCodePattern = regexp.MustCompile(`(?:^|: )([245]\d\d)[ -]`)
+
+var permanentErrorFragments = []string{
+	"gomail: invalid address",
+	"gomail: invalid message",
+}
+
+// PermanenceAware is implemented by delivery errors that know by themselves whether retrying can succeed
+type PermanenceAware interface {
+	Permanent() bool
+}
+
+func (classification Classification) String() string {
+	if classification == Permanent {
+		return "permanent"
+	}
+	return "transient"
+}
+
+// Classify treats 5xx SMTP replies and malformed messages as permanent, everything else is worth retrying
+func Classify(err error) Classification {
+	var permanenceAware PermanenceAware
+	if errors.As(err, &permanenceAware) {
+		if permanenceAware.Permanent() {
+			return Permanent
+		}
+		return Transient
+	}
+
+	var smtpError *textproto.Error
+	if errors.As(err, &smtpError) {
+		return classifySmtpReplyCode(smtpError.Code)
+	}
+
+	var netError net.Error
+	if errors.As(err, &netError) && netError.Timeout() {
+		return Transient
+	}
+	if errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.EPIPE) ||
+		errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return Transient
+	}
+
+	message := strings.ToLower(err.Error())
+	if match := smtpReplyCodePattern.FindStringSubmatch(message); match != nil {
+		code, _ := strconv.Atoi(match[1])
+		return classifySmtpReplyCode(code)
+	}
+	for _, fragment := range permanentErrorFragments {
+		if strings.Contains(message, fragment) {
+			return Permanent
+		}
+	}
+	return Transient
+}
+
+func classifySmtpReplyCode(code int) Classification {
+	if code >= 500 {
+		return Permanent
+	}
+	return Transient
+}
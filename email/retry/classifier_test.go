@@ -0,0 +1,70 @@
+package retry
+
+import (
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/suite"
+	"io"
+	"net"
+	"net/textproto"
+	"syscall"
+	"testing"
+)
+
+type classifierTestSuite struct {
+	suite.Suite
+}
+
+func TestClassifierTestSuite(t *testing.T) {
+	suite.Run(t, new(classifierTestSuite))
+}
+
+func (suite *classifierTestSuite) TestClassify_ShouldTreat5xxSmtpReplyAsPermanent() {
+	suite.Equal(Permanent, Classify(&textproto.Error{Code: 550, Msg: "5.1.1 mailbox unavailable"}))
+}
+
+func (suite *classifierTestSuite) TestClassify_ShouldTreat4xxSmtpReplyAsTransient() {
+	suite.Equal(Transient, Classify(&textproto.Error{Code: 421, Msg: "4.7.0 try again later"}))
+}
+
+func (suite *classifierTestSuite) TestClassify_ShouldReadSmtpReplyCodeFromErrorFlattenedByGomail() {
+	suite.Equal(Permanent, Classify(errors.New("gomail: could not send email 1: 550 5.1.1 mailbox unavailable")))
+	suite.Equal(Transient, Classify(errors.New("gomail: could not send email 1: 451 4.3.0 local error in processing")))
+}
+
+func (suite *classifierTestSuite) TestClassify_ShouldTreatWrappedSmtpReplyAsPermanent() {
+	suite.Equal(Permanent, Classify(fmt.Errorf("sending failed: %w", &textproto.Error{Code: 554, Msg: "transaction failed"})))
+}
+
+func (suite *classifierTestSuite) TestClassify_ShouldTreatConnectionFailuresAsTransient() {
+	suite.Equal(Transient, Classify(&net.OpError{Op: "read", Err: syscall.ECONNRESET}))
+	suite.Equal(Transient, Classify(&net.OpError{Op: "dial", Err: syscall.ECONNREFUSED}))
+	suite.Equal(Transient, Classify(io.EOF))
+	suite.Equal(Transient, Classify(timeoutError{}))
+}
+
+func (suite *classifierTestSuite) TestClassify_ShouldTreatInvalidAddressAsPermanent() {
+	suite.Equal(Permanent, Classify(errors.New(`gomail: invalid address "not-an-address": mail: missing '@' or angle-addr`)))
+}
+
+func (suite *classifierTestSuite) TestClassify_ShouldTreatUnknownErrorsAsTransient() {
+	suite.Equal(Transient, Classify(errors.New("failed to connect SMTP server")))
+}
+
+func (suite *classifierTestSuite) TestClassify_ShouldTrustErrorsThatKnowTheirPermanence() {
+	suite.Equal(Permanent, Classify(fmt.Errorf("delivery failed: %w", permanenceAwareError{permanent: true})))
+	suite.Equal(Transient, Classify(permanenceAwareError{permanent: false}))
+}
+
+type permanenceAwareError struct {
+	permanent bool
+}
+
+func (permanenceAwareError) Error() string       { return "api rejected email: 550 invalid sender" }
+func (err permanenceAwareError) Permanent() bool { return err.permanent }
+
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
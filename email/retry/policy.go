@@ -0,0 +1,91 @@
+package retry
+
+import (
+	"ccg-api/configuration"
+	"github.com/gin-gonic/gin"
+	"github.com/inclusi-blog/gola-utils/logging"
+	"math"
+	"math/rand"
+	"time"
+)
+
+const (
+	defaultMaxAttempts    = 3
+	defaultInitialBackoff = 500 * time.Millisecond
+	defaultMaxBackoff     = 30 * time.Second
+	defaultMultiplier     = 2
+)
+
+type Policy struct {
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	Multiplier     float64
+	JitterFactor   float64
+}
+
+func NewPolicy(config configuration.RetryPolicy) Policy {
+	policy := Policy{
+		MaxAttempts:    config.MaxAttempts,
+		InitialBackoff: time.Duration(config.InitialBackoffInMillis) * time.Millisecond,
+		MaxBackoff:     time.Duration(config.MaxBackoffInMillis) * time.Millisecond,
+		Multiplier:     config.Multiplier,
+		JitterFactor:   math.Max(0, math.Min(config.JitterFactor, 1)),
+	}
+	if policy.MaxAttempts <= 0 {
+		policy.MaxAttempts = defaultMaxAttempts
+	}
+	if policy.InitialBackoff <= 0 {
+		policy.InitialBackoff = defaultInitialBackoff
+	}
+	if policy.MaxBackoff <= 0 {
+		policy.MaxBackoff = defaultMaxBackoff
+	}
+	if policy.Multiplier < 1 {
+		policy.Multiplier = defaultMultiplier
+	}
+	return policy
+}
+
+// ShouldRetry reports whether another attempt is allowed after the given number of failed attempts ended with err
+func (policy Policy) ShouldRetry(failedAttempts int, err error) bool {
+	return failedAttempts < policy.MaxAttempts && Classify(err) == Transient
+}
+
+// Backoff returns the delay before the next attempt, growing exponentially with each failed attempt and spread by the jitter factor
+func (policy Policy) Backoff(failedAttempts int) time.Duration {
+	backoff := float64(policy.InitialBackoff) * math.Pow(policy.Multiplier, float64(failedAttempts-1))
+	backoff = math.Min(backoff, float64(policy.MaxBackoff))
+	if policy.JitterFactor > 0 {
+		// #nosec G404 -- jitter only spreads retries, it does not need a cryptographically secure source
+		backoff = backoff * (1 - policy.JitterFactor + 2*policy.JitterFactor*rand.Float64())
+	}
+	return time.Duration(backoff)
+}
+
+// Do runs operation until it succeeds, fails permanently or runs out of attempts, returning the last error
+func (policy Policy) Do(ctx *gin.Context, operation func() error) error {
+	return policy.DoWithNotify(ctx, operation, nil)
+}
+
+// DoWithNotify behaves like Do and calls notify, when given, before waiting for each retry
+func (policy Policy) DoWithNotify(ctx *gin.Context, operation func() error, notify func(failedAttempts int, err error)) error {
+	logger := logging.GetLogger(ctx).WithField("class", "RetryPolicy").WithField("method", "DoWithNotify")
+	for failedAttempts := 0; ; {
+		err := operation()
+		if err == nil {
+			return nil
+		}
+		failedAttempts++
+		if !policy.ShouldRetry(failedAttempts, err) {
+			logger.Errorf("Giving up after %d attempt(s), %s error: %s", failedAttempts, Classify(err), err)
+			return err
+		}
+		if notify != nil {
+			notify(failedAttempts, err)
+		}
+		backoff := policy.Backoff(failedAttempts)
+		logger.Warnf("Attempt %d failed with %s error, retrying in %s, error: %s", failedAttempts, Classify(err), backoff, err)
+		time.Sleep(backoff)
+	}
+}
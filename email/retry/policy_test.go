@@ -0,0 +1,127 @@
+package retry
+
+import (
+	"ccg-api/configuration"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/suite"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"testing"
+	"time"
+)
+
+type policyTestSuite struct {
+	suite.Suite
+	context *gin.Context
+}
+
+func TestPolicyTestSuite(t *testing.T) {
+	suite.Run(t, new(policyTestSuite))
+}
+
+func (suite *policyTestSuite) SetupTest() {
+	suite.context, _ = gin.CreateTestContext(httptest.NewRecorder())
+	suite.context.Request, _ = http.NewRequest("POST", "/", nil)
+}
+
+func (suite *policyTestSuite) TestNewPolicy_ShouldUseDefaultsForMissingConfiguration() {
+	policy := NewPolicy(configuration.RetryPolicy{})
+
+	suite.Equal(Policy{
+		MaxAttempts:    defaultMaxAttempts,
+		InitialBackoff: defaultInitialBackoff,
+		MaxBackoff:     defaultMaxBackoff,
+		Multiplier:     defaultMultiplier,
+	}, policy)
+}
+
+func (suite *policyTestSuite) TestBackoff_ShouldGrowExponentiallyUpToMaxBackoff() {
+	policy := NewPolicy(configuration.RetryPolicy{
+		MaxAttempts:            5,
+		InitialBackoffInMillis: 100,
+		MaxBackoffInMillis:     350,
+		Multiplier:             2,
+	})
+
+	suite.Equal(100*time.Millisecond, policy.Backoff(1))
+	suite.Equal(200*time.Millisecond, policy.Backoff(2))
+	suite.Equal(350*time.Millisecond, policy.Backoff(3))
+}
+
+func (suite *policyTestSuite) TestBackoff_ShouldStayWithinJitterBounds() {
+	policy := NewPolicy(configuration.RetryPolicy{
+		InitialBackoffInMillis: 1000,
+		JitterFactor:           0.2,
+	})
+
+	for i := 0; i < 100; i++ {
+		backoff := policy.Backoff(1)
+		suite.GreaterOrEqual(int64(backoff), int64(800*time.Millisecond))
+		suite.LessOrEqual(int64(backoff), int64(1200*time.Millisecond))
+	}
+}
+
+func (suite *policyTestSuite) TestShouldRetry_ShouldStopOnPermanentErrorOrWhenAttemptsAreExhausted() {
+	policy := NewPolicy(configuration.RetryPolicy{MaxAttempts: 3})
+
+	suite.True(policy.ShouldRetry(1, errors.New("connection reset by peer")))
+	suite.False(policy.ShouldRetry(3, errors.New("connection reset by peer")))
+	suite.False(policy.ShouldRetry(1, &textproto.Error{Code: 550, Msg: "mailbox unavailable"}))
+}
+
+func (suite *policyTestSuite) TestDo_ShouldRetryTransientErrorsUntilOperationSucceeds() {
+	policy := NewPolicy(configuration.RetryPolicy{MaxAttempts: 3, InitialBackoffInMillis: 1})
+	attempts := 0
+
+	err := policy.Do(suite.context, func() error {
+		attempts++
+		if attempts < 3 {
+			return &textproto.Error{Code: 421, Msg: "try again later"}
+		}
+		return nil
+	})
+
+	suite.Nil(err)
+	suite.Equal(3, attempts)
+}
+
+func (suite *policyTestSuite) TestDo_ShouldReturnLastErrorWhenAttemptsAreExhausted() {
+	policy := NewPolicy(configuration.RetryPolicy{MaxAttempts: 2, InitialBackoffInMillis: 1})
+	attempts := 0
+
+	err := policy.Do(suite.context, func() error {
+		attempts++
+		return errors.New("connection reset by peer")
+	})
+
+	suite.EqualError(err, "connection reset by peer")
+	suite.Equal(2, attempts)
+}
+
+func (suite *policyTestSuite) TestDo_ShouldNotRetryPermanentErrors() {
+	policy := NewPolicy(configuration.RetryPolicy{MaxAttempts: 3, InitialBackoffInMillis: 1})
+	attempts := 0
+
+	err := policy.Do(suite.context, func() error {
+		attempts++
+		return &textproto.Error{Code: 550, Msg: "mailbox unavailable"}
+	})
+
+	suite.NotNil(err)
+	suite.Equal(1, attempts)
+}
+
+func (suite *policyTestSuite) TestDoWithNotify_ShouldNotifyBeforeEachRetry() {
+	policy := NewPolicy(configuration.RetryPolicy{MaxAttempts: 3, InitialBackoffInMillis: 1})
+	var notifiedAttempts []int
+
+	_ = policy.DoWithNotify(suite.context, func() error {
+		return errors.New("connection reset by peer")
+	}, func(failedAttempts int, err error) {
+		notifiedAttempts = append(notifiedAttempts, failedAttempts)
+	})
+
+	suite.Equal([]int{1, 2}, notifiedAttempts)
+}
@@ -0,0 +1,19 @@
+package service
+
+import "expvar"
+
+const (
+	sentOutcome       = "sent"
+	failedOutcome     = "failed"
+	queuedOutcome     = "queued"
+	suppressedOutcome = "suppressed"
+)
+
+// categoryMetrics is served under /metrics of the metrics address, keyed by category and outcome e.g. marketing.sent
+var categoryMetrics = expvar.NewMap("email_categories")
+
+func countCategory(category string, outcome string, count int) {
+	if count > 0 {
+		categoryMetrics.Add(category+"."+outcome, int64(count))
+	}
+}
@@ -7,59 +7,317 @@ import (
 	"ccg-api/email/email-client"
 	"ccg-api/email/email-client/email_client_request"
 	"ccg-api/email/models"
+	"ccg-api/email/outbox"
+	"ccg-api/email/plain_text"
+	"ccg-api/email/preference"
+	"ccg-api/email/retry"
+	"ccg-api/email/status"
+	"ccg-api/email/suppression"
+	"ccg-api/email/tracking"
+	"ccg-api/email/unsubscribe"
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"github.com/inclusi-blog/gola-utils/golaerror"
 	"github.com/inclusi-blog/gola-utils/logging"
 	"github.com/inclusi-blog/gola-utils/mask_util"
 	"html/template"
 	"strings"
+	"time"
 )
 
+const htmlMimeType = "text/html"
+
 type EmailService interface {
-	Send(ctx *gin.Context, email models.Email) *golaerror.Error
+	Send(ctx *gin.Context, email models.Email) (models.SendReceipt, *golaerror.Error)
+	Enqueue(ctx *gin.Context, email models.Email) (models.SendReceipt, *golaerror.Error)
+	Cancel(ctx *gin.Context, clientID string, messageID string) *golaerror.Error
 }
 
 type emailService struct {
-	emailClient email_client.EmailClient
-	emailConfig configuration.EmailClientConfig
+	emailClient     email_client.EmailClient
+	emailConfig     configuration.EmailClientConfig
+	outbox          outbox.Outbox
+	tracker         status.Tracker
+	suppressions    suppression.List
+	preferences     preference.Preferences
+	tokens          unsubscribe.Tokens
+	trackingTokens  tracking.Tokens
+	sendRetryPolicy retry.Policy
+	// unsubscribableCategories get unsubscribe links and honour opt-outs, the rest bypass preferences
+	unsubscribableCategories map[string]bool
+	defaultCategory          string
+	unsubscribeBaseUrl       string
+	trackingBaseUrl          string
+}
+
+// NewEmailService accepts a nil outbox when asynchronous sending is disabled, nil tokens when no unsubscribe secret is set
+// and nil tracking tokens when no tracking secret is set
+func NewEmailService(
+	emailClient email_client.EmailClient,
+	emailConfig configuration.EmailClientConfig,
+	outbox outbox.Outbox,
+	tracker status.Tracker,
+	suppressions suppression.List,
+	preferences preference.Preferences,
+	tokens unsubscribe.Tokens,
+	trackingTokens tracking.Tokens) EmailService {
+	unsubscribableCategories := map[string]bool{}
+	for _, category := range emailConfig.Categories() {
+		unsubscribableCategories[category.Name] = !category.BypassPreferences
+	}
+	return emailService{
+		emailClient:              emailClient,
+		emailConfig:              emailConfig,
+		outbox:                   outbox,
+		tracker:                  tracker,
+		suppressions:             suppressions,
+		preferences:              preferences,
+		tokens:                   tokens,
+		trackingTokens:           trackingTokens,
+		sendRetryPolicy:          retry.NewPolicy(emailConfig.SendRetryPolicy()),
+		unsubscribableCategories: unsubscribableCategories,
+		defaultCategory:          emailConfig.DefaultCategory(),
+		unsubscribeBaseUrl:       strings.TrimSuffix(emailConfig.Unsubscribe().BaseUrl, "/"),
+		trackingBaseUrl:          strings.TrimSuffix(emailConfig.Tracking().BaseUrl, "/"),
+	}
+}
+
+func (emailService emailService) Send(ctx *gin.Context, email models.Email) (models.SendReceipt, *golaerror.Error) {
+	email = emailService.withCategory(email)
+	logger := logging.GetLogger(ctx).WithField("class", "EmailService").WithField("method", "Send").WithField("category", email.Category)
+	email, receipt, suppressionError := emailService.dropSuppressedRecipients(ctx, email)
+	if suppressionError != nil {
+		return receipt, suppressionError
+	}
+	messageID := uuid.New().String()
+	request, requestError := emailService.buildEmailClientRequest(ctx, messageID, email)
+	if requestError != nil {
+		return receipt, requestError
+	}
+
+	emailService.tracker.Accept(ctx, messageID, email.ClientID, email.From, email.Recipients())
+	err := emailService.sendRetryPolicy.DoWithNotify(ctx, func() error {
+		emailService.tracker.Update(ctx, messageID, models.Sending, nil)
+		return emailService.emailClient.Send(ctx, &request)
+	}, func(failedAttempts int, err error) {
+		emailService.tracker.Update(ctx, messageID, models.Retrying, err)
+	})
+	if err != nil {
+		logger.Error("Error received from email client ", err)
+		emailService.tracker.Update(ctx, messageID, models.Failed, err)
+		countCategory(email.Category, failedOutcome, 1)
+		if retry.Classify(err) == retry.Permanent {
+			return receipt, &constants.PermanentDeliveryFailureError
+		}
+		return receipt, &constants.InternalServerError
+	}
+
+	emailService.tracker.Update(ctx, messageID, models.Sent, nil)
+	countCategory(email.Category, sentOutcome, 1)
+	logger.Infof("Email %s sent successfully to %s", messageID, maskEmails(ctx, email.To))
+	receipt.MessageID = messageID
+	return receipt, nil
 }
 
-func NewEmailService(emailClient email_client.EmailClient, emailConfig configuration.EmailClientConfig) EmailService {
-	return emailService{emailClient: emailClient, emailConfig: emailConfig}
+func (emailService emailService) Enqueue(ctx *gin.Context, email models.Email) (models.SendReceipt, *golaerror.Error) {
+	email = emailService.withCategory(email)
+	logger := logging.GetLogger(ctx).WithField("class", "EmailService").WithField("method", "Enqueue").WithField("category", email.Category)
+	if emailService.outbox == nil {
+		logger.Error("Asynchronous send requested but outbox is not enabled")
+		return models.SendReceipt{}, &constants.AsyncSendDisabledError
+	}
+	email, receipt, suppressionError := emailService.dropSuppressedRecipients(ctx, email)
+	if suppressionError != nil {
+		return receipt, suppressionError
+	}
+	messageID := uuid.New().String()
+	request, requestError := emailService.buildEmailClientRequest(ctx, messageID, email)
+	if requestError != nil {
+		return receipt, requestError
+	}
+
+	emailService.tracker.Accept(ctx, messageID, email.ClientID, email.From, email.Recipients())
+	if !email.SendAt.IsZero() {
+		// Recorded before the hand-off so a dispatcher that picks the message up
+		// straight away cannot have its Sending or Sent overwritten.
+		emailService.tracker.Update(ctx, messageID, models.Scheduled, nil)
+	}
+	if err := emailService.outbox.Enqueue(ctx, messageID, request, email.SendAt); err != nil {
+		logger.Error("Error received from outbox ", err)
+		emailService.tracker.Update(ctx, messageID, models.Failed, err)
+		countCategory(email.Category, failedOutcome, 1)
+		return receipt, &constants.InternalServerError
+	}
+
+	receipt.MessageID = messageID
+	countCategory(email.Category, queuedOutcome, 1)
+	if !email.SendAt.IsZero() {
+		logger.Infof("Email to %s scheduled at %s with message id %s", maskEmails(ctx, email.To), email.SendAt.Format(time.RFC3339), messageID)
+		return receipt, nil
+	}
+	logger.Infof("Email to %s queued with message id %s", maskEmails(ctx, email.To), messageID)
+	return receipt, nil
+}
+
+func (emailService emailService) Cancel(ctx *gin.Context, clientID string, messageID string) *golaerror.Error {
+	logger := logging.GetLogger(ctx).WithField("class", "EmailService").WithField("method", "Cancel")
+	if _, statusError := emailService.tracker.GetForClient(ctx, clientID, messageID); statusError != nil {
+		return statusError
+	}
+	if emailService.outbox == nil {
+		return &constants.MessageNotCancellableError
+	}
+
+	err := emailService.outbox.Cancel(ctx, messageID)
+	switch err {
+	case nil:
+		emailService.tracker.Update(ctx, messageID, models.Cancelled, nil)
+		logger.Infof("Email %s cancelled", messageID)
+		return nil
+	case outbox.ErrMessageNotPending, outbox.ErrMessageInFlight:
+		logger.Warnf("Email %s cannot be cancelled, %s", messageID, err)
+		return &constants.MessageNotCancellableError
+	}
+	logger.Error("Error received from outbox ", err)
+	return &constants.InternalServerError
 }
 
-func (emailService emailService) Send(ctx *gin.Context, email models.Email) *golaerror.Error {
-	logger := logging.GetLogger(ctx).WithField("class", "EmailService").WithField("method", "Send")
+// dropSuppressedRecipients removes suppressed addresses, and those that opted out of the email's category, from To, Cc and Bcc.
+// It fails only when nobody is left to deliver to.
+func (emailService emailService) dropSuppressedRecipients(ctx *gin.Context, email models.Email) (models.Email, models.SendReceipt, *golaerror.Error) {
+	logger := logging.GetLogger(ctx).WithField("class", "EmailService").WithField("method", "dropSuppressedRecipients")
+	suppressed := emailService.suppressions.Check(ctx, email.Recipients())
+	suppressedAddresses := map[string]bool{}
+	for _, recipient := range suppressed {
+		suppressedAddresses[recipient.Address] = true
+	}
+	if emailService.unsubscribableCategories[email.Category] {
+		remaining := withoutAddresses(email.Recipients(), suppressedAddresses)
+		for _, recipient := range emailService.preferences.OptedOut(ctx, remaining, email.Category) {
+			suppressed = append(suppressed, models.SuppressedRecipient{Address: recipient, Reason: models.Unsubscribed})
+			suppressedAddresses[recipient] = true
+		}
+	}
+	receipt := models.SendReceipt{Suppressed: suppressed}
+	if len(suppressed) == 0 {
+		return email, receipt, nil
+	}
+	countCategory(email.Category, suppressedOutcome, len(suppressed))
+
+	email.To = withoutAddresses(email.To, suppressedAddresses)
+	email.Cc = withoutAddresses(email.Cc, suppressedAddresses)
+	email.Bcc = withoutAddresses(email.Bcc, suppressedAddresses)
+	logger.Warnf("Dropped %d suppressed recipient(s)", len(suppressed))
+	if len(email.Recipients()) == 0 {
+		allSuppressedError := constants.AllRecipientsSuppressedError
+		allSuppressedError.AdditionalData = suppressed
+		return email, receipt, &allSuppressedError
+	}
+	return email, receipt, nil
+}
+
+// withCategory files emails sent without a category under the configured default
+func (emailService emailService) withCategory(email models.Email) models.Email {
+	if email.Category == "" {
+		email.Category = emailService.defaultCategory
+	}
+	return email
+}
+
+func withoutAddresses(addresses []string, excluded map[string]bool) []string {
+	var remaining []string
+	for _, address := range addresses {
+		if !excluded[address] {
+			remaining = append(remaining, address)
+		}
+	}
+	return remaining
+}
+
+// unsubscribeUrl is personal to the recipient, so an email that needs one is refused when it is left with more than one recipient;
+// the caller sends a copy per recipient instead
+func (emailService emailService) unsubscribeUrl(ctx *gin.Context, email models.Email) (string, *golaerror.Error) {
+	if emailService.tokens == nil || !emailService.unsubscribableCategories[email.Category] {
+		return "", nil
+	}
+	recipients := email.Recipients()
+	if len(recipients) != 1 {
+		logging.GetLogger(ctx).WithField("class", "EmailService").WithField("method", "unsubscribeUrl").
+			Errorf("Refused %s email to %d recipients, it needs a personal unsubscribe link", email.Category, len(recipients))
+		return "", &constants.SingleRecipientRequiredError
+	}
+	return emailService.unsubscribeBaseUrl + "/" + emailService.tokens.Issue(recipients[0], email.Category), nil
+}
+
+// instrument runs after the base template is embedded, so that its links are tracked too. The plain text part keeps the original links.
+func (emailService emailService) instrument(ctx *gin.Context, messageID string, content string, unsubscribeUrl string) string {
+	logger := logging.GetLogger(ctx).WithField("class", "EmailService").WithField("method", "instrument")
+	if emailService.trackingTokens == nil {
+		logger.Warn("Tracking requested but no tracking secret is configured, email is sent untracked")
+		return content
+	}
+	clickUrl := func(link string) string {
+		if link == unsubscribeUrl || strings.HasPrefix(link, emailService.trackingBaseUrl+"/") {
+			return ""
+		}
+		return emailService.trackingBaseUrl + "/c/" + emailService.trackingTokens.Issue(tracking.Claim{MessageID: messageID, Url: link})
+	}
+	pixelUrl := emailService.trackingBaseUrl + "/o/" + emailService.trackingTokens.Issue(tracking.Claim{MessageID: messageID})
+	return tracking.Instrument(content, clickUrl, pixelUrl)
+}
+
+func (emailService emailService) buildEmailClientRequest(ctx *gin.Context, messageID string, email models.Email) (email_client_request.EmailClientRequest, *golaerror.Error) {
+	logger := logging.GetLogger(ctx).WithField("class", "EmailService").WithField("method", "buildEmailClientRequest")
+	unsubscribeUrl, unsubscribeError := emailService.unsubscribeUrl(ctx, email)
+	if unsubscribeError != nil {
+		return email_client_request.EmailClientRequest{}, unsubscribeError
+	}
 	if email.IncludeBaseTemplate {
+		tenantConfig := emailService.emailConfig.ForTenant(email.Tenant)
+		logoUrls, logoAttachments := inlineLogos(ctx, tenantConfig)
 		var templateParseError error
-		email.Body.Content, templateParseError = emailService.embedContentInBaseTemplate(ctx, email.Body.Content)
+		email.Body.Content, templateParseError = embedContentInBaseTemplate(ctx, tenantConfig, email.Body.Content, logoUrls, unsubscribeUrl)
 		if templateParseError != nil {
 			logger.Error("Could not parse template ", templateParseError)
-			return &constants.InternalServerError
+			return email_client_request.EmailClientRequest{}, &constants.InternalServerError
 		}
+		email.Body.MimeType = htmlMimeType
+		email.Attachments = append(email.Attachments, logoAttachments...)
 	}
-	request := email_client_request.EmailClientRequest{
-		From:        email.From,
-		To:          email.To,
-		Subject:     email.Subject,
-		Body:        email.Body,
-		Attachments: email.Attachments,
+	if email.Body.MimeType == htmlMimeType && len(strings.TrimSpace(email.Body.PlainText)) == 0 {
+		email.Body.PlainText = plain_text.FromHTML(email.Body.Content)
 	}
-	err := emailService.emailClient.Send(ctx, &request)
-	if err != nil {
-		logger.Error("Error received from email client ", err)
-		return &constants.InternalServerError
+	if email.Track && email.Body.MimeType == htmlMimeType {
+		email.Body.Content = emailService.instrument(ctx, messageID, email.Body.Content, unsubscribeUrl)
 	}
+	return email_client_request.EmailClientRequest{
+		MessageID:      messageID,
+		From:           email.From,
+		FromName:       email.FromName,
+		To:             email.To,
+		Cc:             email.Cc,
+		Bcc:            email.Bcc,
+		ReplyTo:        email.ReplyTo,
+		Headers:        email.Headers,
+		Subject:        email.Subject,
+		Body:           email.Body,
+		Attachments:    email.Attachments,
+		Category:       email.Category,
+		UnsubscribeUrl: unsubscribeUrl,
+		Tenant:         email.Tenant,
+	}, nil
+}
 
+func maskEmails(ctx *gin.Context, emails []string) string {
 	var maskedEmail []string
-	for _, mailId := range email.To {
+	for _, mailId := range emails {
 		maskedEmail = append(maskedEmail, mask_util.MaskEmail(ctx, mailId))
 	}
-	logger.Infof("Email sent successfully to %s", strings.Join(maskedEmail, ", "))
-	return nil
+	return strings.Join(maskedEmail, ", ")
 }
 
-func (emailService emailService) embedContentInBaseTemplate(ctx *gin.Context, content string) (string, error) {
+// embedContentInBaseTemplate renders the content within the base template of the tenant the config belongs to
+func embedContentInBaseTemplate(ctx *gin.Context, tenantConfig configuration.EmailClientConfig, content string, logoUrls map[string]interface{}, unsubscribeUrl string) (string, error) {
 	logger := logging.GetLogger(ctx).WithField("class", "EmailService").WithField("method", "embedContentInBaseTemplate")
 	contentBuffer := new(bytes.Buffer)
 	var err error
@@ -73,7 +331,7 @@ func (emailService emailService) embedContentInBaseTemplate(ctx *gin.Context, co
 	// adding func to avoid escaping conditional HTML comments
 	finalTemplate, err := baseTemplate.New("base").Funcs(template.FuncMap{
 		"safe": func(s string) template.HTML { return template.HTML(s) },
-	}).ParseFiles(emailService.emailConfig.BaseTemplateFilePath())
+	}).ParseFiles(tenantConfig.BaseTemplateFilePath())
 
 	if err != nil {
 		logger.Error("Error while parsing template files ", err)
@@ -81,8 +339,10 @@ func (emailService emailService) embedContentInBaseTemplate(ctx *gin.Context, co
 	}
 
 	fields := map[string]interface{}{
-		"LogoUrl": emailService.emailConfig.LogoUrls(),
-		"Urls":    emailService.emailConfig.OtherUrls(),
+		"LogoUrl":        logoUrls,
+		"Urls":           tenantConfig.OtherUrls(),
+		"FooterText":     tenantConfig.FooterText(),
+		"UnsubscribeUrl": unsubscribeUrl,
 	}
 	err = finalTemplate.ExecuteTemplate(contentBuffer, "base", fields)
 	if err != nil {
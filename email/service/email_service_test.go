@@ -7,23 +7,56 @@ import (
 	mockEmailClient "ccg-api/email/email-client/mocks"
 	"ccg-api/email/mocks"
 	"ccg-api/email/models"
+	"ccg-api/email/outbox"
+	mockOutbox "ccg-api/email/outbox/mocks"
+	mockPreference "ccg-api/email/preference/mocks"
+	mockStatus "ccg-api/email/status/mocks"
+	mockSuppression "ccg-api/email/suppression/mocks"
+	"ccg-api/email/tracking"
+	"ccg-api/email/unsubscribe"
 	"errors"
 	"github.com/gin-gonic/gin"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/suite"
+	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"net/textproto"
+	"os"
+	"path"
+	"strings"
 	"testing"
+	"time"
 )
 
 type emailServiceTestSuite struct {
 	suite.Suite
-	mockCtrl     *gomock.Controller
-	context      *gin.Context
-	recorder     *httptest.ResponseRecorder
-	emailClient  *mockEmailClient.MockEmailClient
-	emailConfig  *mocks.MockEmailClientConfig
-	emailService EmailService
+	mockCtrl       *gomock.Controller
+	context        *gin.Context
+	recorder       *httptest.ResponseRecorder
+	emailClient    *mockEmailClient.MockEmailClient
+	emailConfig    *mocks.MockEmailClientConfig
+	outbox         *mockOutbox.MockOutbox
+	tracker        *mockStatus.MockTracker
+	suppressions   *mockSuppression.MockList
+	preferences    *mockPreference.MockPreferences
+	tokens         unsubscribe.Tokens
+	trackingTokens tracking.Tokens
+	emailService   EmailService
+}
+
+var unsubscribeConfig = configuration.Unsubscribe{
+	BaseUrl: "https://ccg.gola.xyz/api/ccg/v1/unsubscribe/",
+}
+
+var trackingConfig = configuration.Tracking{
+	BaseUrl: "https://ccg.gola.xyz/api/ccg/t",
+}
+
+var categories = []configuration.Category{
+	{Name: "transactional", BypassPreferences: true},
+	{Name: "security", BypassPreferences: true},
+	{Name: "marketing"},
 }
 
 func TestEmailServiceTestSuite(t *testing.T) {
@@ -37,7 +70,23 @@ func (suite *emailServiceTestSuite) SetupTest() {
 	suite.context.Request, _ = http.NewRequest("GET", "some-url", nil)
 	suite.emailClient = mockEmailClient.NewMockEmailClient(suite.mockCtrl)
 	suite.emailConfig = mocks.NewMockEmailClientConfig(suite.mockCtrl)
-	suite.emailService = NewEmailService(suite.emailClient, suite.emailConfig)
+	suite.outbox = mockOutbox.NewMockOutbox(suite.mockCtrl)
+	suite.tracker = mockStatus.NewMockTracker(suite.mockCtrl)
+	suite.suppressions = mockSuppression.NewMockList(suite.mockCtrl)
+	suite.preferences = mockPreference.NewMockPreferences(suite.mockCtrl)
+	suite.tokens = unsubscribe.NewTokens("unit-test-secret")
+	suite.trackingTokens = tracking.NewTokens("unit-test-secret")
+	suite.emailConfig.EXPECT().SendRetryPolicy().Return(configuration.RetryPolicy{MaxAttempts: 1}).AnyTimes()
+	suite.emailConfig.EXPECT().Unsubscribe().Return(unsubscribeConfig).AnyTimes()
+	suite.emailConfig.EXPECT().Tracking().Return(trackingConfig).AnyTimes()
+	suite.emailConfig.EXPECT().Categories().Return(categories).AnyTimes()
+	suite.emailConfig.EXPECT().DefaultCategory().Return("transactional").AnyTimes()
+	suite.emailConfig.EXPECT().ForTenant("").Return(suite.emailConfig).AnyTimes()
+	suite.emailConfig.EXPECT().FooterText().Return("© Narratenet. All rights reserved.").AnyTimes()
+	suite.tracker.EXPECT().Accept(suite.context, gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+	suite.tracker.EXPECT().Update(suite.context, gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+	suite.suppressions.EXPECT().Check(suite.context, gomock.Any()).Return(nil).AnyTimes()
+	suite.emailService = NewEmailService(suite.emailClient, suite.emailConfig, suite.outbox, suite.tracker, suite.suppressions, suite.preferences, suite.tokens, suite.trackingTokens)
 }
 
 func (suite *emailServiceTestSuite) TearDownTest() {
@@ -61,18 +110,24 @@ func (suite emailServiceTestSuite) TestSendEmailShouldNotIncludeBaseTemplateAndS
 		},
 	}
 
-	expectedEmailClientRequest := email_client_request.EmailClientRequest{
+	var sentRequest *email_client_request.EmailClientRequest
+	suite.emailClient.EXPECT().Send(suite.context, gomock.Any()).Do(func(ctx *gin.Context, request *email_client_request.EmailClientRequest) {
+		sentRequest = request
+	}).Return(nil)
+
+	receipt, err := suite.emailService.Send(suite.context, email)
+	suite.Nil(err)
+	suite.NotEmpty(receipt.MessageID)
+	suite.Empty(receipt.Suppressed)
+	suite.Equal(&email_client_request.EmailClientRequest{
+		MessageID:   receipt.MessageID,
 		From:        email.From,
 		To:          email.To,
 		Subject:     email.Subject,
 		Body:        email.Body,
 		Attachments: email.Attachments,
-	}
-
-	suite.emailClient.EXPECT().Send(suite.context, &expectedEmailClientRequest)
-
-	err := suite.emailService.Send(suite.context, email)
-	suite.Nil(err)
+		Category:    "transactional",
+	}, sentRequest)
 }
 
 func (suite emailServiceTestSuite) TestSendEmailShouldIncludeBaseTemplateAndSendEmailSuccessfully() {
@@ -103,21 +158,69 @@ func (suite emailServiceTestSuite) TestSendEmailShouldIncludeBaseTemplateAndSend
 		DownloadIOS:     "https://cdn.discordapp.com/attachments/731434048135757898/757125873030660096/unknown.png",
 		DownloadAndroid: "https://cdn.discordapp.com/attachments/731434048135757898/757125873030660096/unknown.png",
 	})
+	suite.emailConfig.EXPECT().LogoFiles().Return(configuration.LogoFiles{})
+	suite.emailConfig.EXPECT().EmbedLogos().Return(false)
 	suite.emailConfig.EXPECT().OtherUrls().Return(configuration.Urls{
 		HelpCenter:    "https://cdn.discordapp.com/attachments/731434048135757898/757125873030660096/unknown.png",
 		PrivacyPolicy: "https://cdn.discordapp.com/attachments/731434048135757898/757125873030660096/unknown.png",
-		Unsubscribe:   "https://cdn.discordapp.com/attachments/731434048135757898/757125873030660096/unknown.png",
 		FAQUrl:        "https://cdn.discordapp.com/attachments/731434048135757898/757125873030660096/unknown.png",
 	})
 	suite.emailClient.EXPECT().Send(suite.context, gomock.Any()).Do(func(ctx *gin.Context, request *email_client_request.EmailClientRequest) {
 		suite.Equal(email.From, request.From)
 		suite.Equal(email.To, request.To)
 		suite.Equal(email.Subject, request.Subject)
-		suite.Equal(email.Body.MimeType, request.Body.MimeType)
+		suite.Equal("text/html", request.Body.MimeType)
+		suite.Contains(request.Body.PlainText, "Hello User!")
+		suite.NotContains(request.Body.PlainText, "<")
+		suite.NotContains(request.Body.Content, "Unsubscribe</a>")
 		suite.Equal(email.Attachments, request.Attachments)
 	}).Return(nil).Times(1)
 
-	err := suite.emailService.Send(suite.context, email)
+	_, err := suite.emailService.Send(suite.context, email)
+	suite.Nil(err)
+}
+
+func (suite emailServiceTestSuite) TestSendEmailShouldEmbedLogoFilesAsInlinePartsReferencedByContentId() {
+	email := models.Email{
+		From:    "gola@gola.xyz",
+		To:      []string{"some@gmail.com"},
+		Subject: "Hi!",
+		Body: models.MessageBody{
+			MimeType: "text/plain",
+			Content:  "Hello User!",
+		},
+		IncludeBaseTemplate: true,
+	}
+	logoDir, _ := ioutil.TempDir("", "ccg-logo-unit-test-dir")
+	defer os.RemoveAll(logoDir)
+	facebookLogo := path.Join(logoDir, "facebook.png")
+	_ = ioutil.WriteFile(facebookLogo, []byte("facebook logo"), 0600)
+
+	suite.emailConfig.EXPECT().BaseTemplateFilePath().Return("../../email_templates/base_email_template.html")
+	suite.emailConfig.EXPECT().LogoUrls().Return(configuration.LogoUrls{
+		Facebook:  "https://cdn.gola.xyz/facebook.png",
+		Instagram: "https://cdn.gola.xyz/instagram.png",
+	})
+	suite.emailConfig.EXPECT().EmbedLogos().Return(true)
+	suite.emailConfig.EXPECT().LogoFiles().Return(configuration.LogoFiles{
+		Facebook:  facebookLogo,
+		Instagram: path.Join(logoDir, "missing.png"),
+	})
+	suite.emailConfig.EXPECT().OtherUrls().Return(configuration.Urls{})
+	suite.emailClient.EXPECT().Send(suite.context, gomock.Any()).Do(func(ctx *gin.Context, request *email_client_request.EmailClientRequest) {
+		suite.Contains(request.Body.Content, `src="cid:logo-facebook@ccg-api"`)
+		suite.Contains(request.Body.Content, "https://cdn.gola.xyz/instagram.png")
+		suite.Equal([]models.Attachment{
+			{
+				FileName:  "logo-facebook.png",
+				Data:      []byte("facebook logo"),
+				Inline:    true,
+				ContentID: "logo-facebook@ccg-api",
+			},
+		}, request.Attachments)
+	}).Return(nil).Times(1)
+
+	_, err := suite.emailService.Send(suite.context, email)
 	suite.Nil(err)
 }
 
@@ -136,10 +239,65 @@ func (suite emailServiceTestSuite) TestSendEmailShouldSendEmailReturnErrorIfClie
 	suite.emailClient.EXPECT().Send(suite.context, gomock.Any()).Return(errors.New(errMsg))
 	expectedError := &constants.InternalServerError
 
-	actualError := suite.emailService.Send(suite.context, email)
+	_, actualError := suite.emailService.Send(suite.context, email)
 	suite.Equal(expectedError, actualError)
 }
 
+func (suite emailServiceTestSuite) TestSendEmailShouldRetryTransientFailuresAndSendEmailSuccessfully() {
+	email := models.Email{
+		From:    "gola@gola.xyz",
+		To:      []string{"some@gmail.com"},
+		Subject: "Hi!",
+		Body: models.MessageBody{
+			MimeType: "text/plain",
+			Content:  "Hello User!",
+		},
+	}
+
+	emailConfig := mocks.NewMockEmailClientConfig(suite.mockCtrl)
+	emailConfig.EXPECT().SendRetryPolicy().Return(configuration.RetryPolicy{MaxAttempts: 3, InitialBackoffInMillis: 1})
+	emailConfig.EXPECT().Unsubscribe().Return(unsubscribeConfig)
+	emailConfig.EXPECT().Tracking().Return(trackingConfig)
+	emailConfig.EXPECT().Categories().Return(categories)
+	emailConfig.EXPECT().DefaultCategory().Return("transactional")
+	emailService := NewEmailService(suite.emailClient, emailConfig, nil, suite.tracker, suite.suppressions, suite.preferences, suite.tokens, suite.trackingTokens)
+
+	gomock.InOrder(
+		suite.emailClient.EXPECT().Send(suite.context, gomock.Any()).Return(&textproto.Error{Code: 421, Msg: "try again later"}),
+		suite.emailClient.EXPECT().Send(suite.context, gomock.Any()).Return(errors.New("connection reset by peer")),
+		suite.emailClient.EXPECT().Send(suite.context, gomock.Any()).Return(nil),
+	)
+
+	_, err := emailService.Send(suite.context, email)
+	suite.Nil(err)
+}
+
+func (suite emailServiceTestSuite) TestSendEmailShouldReturnPermanentFailureWithoutRetryingIfRecipientIsRejected() {
+	email := models.Email{
+		From:    "gola@gola.xyz",
+		To:      []string{"some@gmail.com"},
+		Subject: "Hi!",
+		Body: models.MessageBody{
+			MimeType: "text/plain",
+			Content:  "Hello User!",
+		},
+	}
+
+	emailConfig := mocks.NewMockEmailClientConfig(suite.mockCtrl)
+	emailConfig.EXPECT().SendRetryPolicy().Return(configuration.RetryPolicy{MaxAttempts: 3, InitialBackoffInMillis: 1})
+	emailConfig.EXPECT().Unsubscribe().Return(unsubscribeConfig)
+	emailConfig.EXPECT().Tracking().Return(trackingConfig)
+	emailConfig.EXPECT().Categories().Return(categories)
+	emailConfig.EXPECT().DefaultCategory().Return("transactional")
+	emailService := NewEmailService(suite.emailClient, emailConfig, nil, suite.tracker, suite.suppressions, suite.preferences, suite.tokens, suite.trackingTokens)
+
+	suite.emailClient.EXPECT().Send(suite.context, gomock.Any()).
+		Return(errors.New("gomail: could not send email 1: 550 5.1.1 mailbox unavailable")).Times(1)
+
+	_, err := emailService.Send(suite.context, email)
+	suite.Equal(&constants.PermanentDeliveryFailureError, err)
+}
+
 func (suite emailServiceTestSuite) TestSendEmailShouldIncludeBaseTemplateAndThrowErrorIfTemplateNotFound() {
 	email := models.Email{
 		From:    "gola@gola.xyz",
@@ -158,8 +316,510 @@ func (suite emailServiceTestSuite) TestSendEmailShouldIncludeBaseTemplateAndThro
 		IncludeBaseTemplate: true,
 	}
 
+	suite.emailConfig.EXPECT().LogoUrls().Return(configuration.LogoUrls{})
+	suite.emailConfig.EXPECT().LogoFiles().Return(configuration.LogoFiles{})
+	suite.emailConfig.EXPECT().EmbedLogos().Return(false)
 	suite.emailConfig.EXPECT().BaseTemplateFilePath().Return("base_email_template.html")
 
-	err := suite.emailService.Send(suite.context, email)
+	_, err := suite.emailService.Send(suite.context, email)
 	suite.Equal(&constants.InternalServerError, err)
 }
+
+func (suite emailServiceTestSuite) TestEnqueueShouldAddEmailToOutboxAndReturnMessageId() {
+	email := models.Email{
+		From:    "gola@gola.xyz",
+		To:      []string{"some@gmail.com"},
+		Subject: "Hi!",
+		Body: models.MessageBody{
+			MimeType: "text/plain",
+			Content:  "Hello User!",
+		},
+	}
+
+	var enqueuedID string
+	suite.outbox.EXPECT().Enqueue(suite.context, gomock.Any(), gomock.Any(), time.Time{}).
+		Do(func(ctx *gin.Context, id string, request email_client_request.EmailClientRequest, sendAt time.Time) {
+			enqueuedID = id
+			suite.Equal(email_client_request.EmailClientRequest{
+				MessageID: id,
+				From:      email.From,
+				To:        email.To,
+				Subject:   email.Subject,
+				Body:      email.Body,
+				Category:  "transactional",
+			}, request)
+		}).Return(nil)
+
+	receipt, err := suite.emailService.Enqueue(suite.context, email)
+	suite.Nil(err)
+	suite.NotEmpty(receipt.MessageID)
+	suite.Equal(enqueuedID, receipt.MessageID)
+}
+
+func (suite emailServiceTestSuite) TestEnqueueShouldReturnErrorIfOutboxUnableToAcceptEmail() {
+	email := models.Email{
+		From:    "gola@gola.xyz",
+		To:      []string{"some@gmail.com"},
+		Subject: "Hi!",
+		Body: models.MessageBody{
+			MimeType: "text/plain",
+			Content:  "Hello User!",
+		},
+	}
+
+	suite.outbox.EXPECT().Enqueue(suite.context, gomock.Any(), gomock.Any(), gomock.Any()).Return(errors.New("disk full"))
+
+	_, err := suite.emailService.Enqueue(suite.context, email)
+	suite.Equal(&constants.InternalServerError, err)
+}
+
+func (suite emailServiceTestSuite) TestEnqueueShouldReturnErrorIfOutboxIsNotEnabled() {
+	email := models.Email{
+		From:    "gola@gola.xyz",
+		To:      []string{"some@gmail.com"},
+		Subject: "Hi!",
+		Body: models.MessageBody{
+			MimeType: "text/plain",
+			Content:  "Hello User!",
+		},
+	}
+
+	emailService := NewEmailService(suite.emailClient, suite.emailConfig, nil, suite.tracker, suite.suppressions, suite.preferences, suite.tokens, suite.trackingTokens)
+
+	_, err := emailService.Enqueue(suite.context, email)
+	suite.Equal(&constants.AsyncSendDisabledError, err)
+}
+
+func (suite emailServiceTestSuite) TestEnqueueShouldHoldScheduledEmailInOutboxUntilSendAt() {
+	email := models.Email{
+		From:    "gola@gola.xyz",
+		To:      []string{"some@gmail.com"},
+		Subject: "Weekly digest",
+		Body: models.MessageBody{
+			MimeType: "text/plain",
+			Content:  "Hello User!",
+		},
+		SendAt: time.Now().Add(time.Hour),
+	}
+
+	tracker := mockStatus.NewMockTracker(suite.mockCtrl)
+	emailService := NewEmailService(suite.emailClient, suite.emailConfig, suite.outbox, tracker, suite.suppressions, suite.preferences, suite.tokens, suite.trackingTokens)
+	gomock.InOrder(
+		tracker.EXPECT().Accept(suite.context, gomock.Any(), "", email.From, email.To),
+		tracker.EXPECT().Update(suite.context, gomock.Any(), models.Scheduled, nil),
+		suite.outbox.EXPECT().Enqueue(suite.context, gomock.Any(), gomock.Any(), email.SendAt).Return(nil),
+	)
+
+	receipt, err := emailService.Enqueue(suite.context, email)
+	suite.Nil(err)
+	suite.NotEmpty(receipt.MessageID)
+}
+
+func (suite emailServiceTestSuite) TestCancelShouldRemoveEmailFromOutboxAndRecordCancelledStatus() {
+	tracker := mockStatus.NewMockTracker(suite.mockCtrl)
+	emailService := NewEmailService(suite.emailClient, suite.emailConfig, suite.outbox, tracker, suite.suppressions, suite.preferences, suite.tokens, suite.trackingTokens)
+	gomock.InOrder(
+		tracker.EXPECT().GetForClient(suite.context, "some-client", "scheduled-message").Return(models.MessageStatus{ID: "scheduled-message", State: models.Scheduled}, nil),
+		suite.outbox.EXPECT().Cancel(suite.context, "scheduled-message").Return(nil),
+		tracker.EXPECT().Update(suite.context, "scheduled-message", models.Cancelled, nil),
+	)
+
+	suite.Nil(emailService.Cancel(suite.context, "some-client", "scheduled-message"))
+}
+
+func (suite emailServiceTestSuite) TestCancelShouldReturnErrorIfEmailIsUnknown() {
+	tracker := mockStatus.NewMockTracker(suite.mockCtrl)
+	emailService := NewEmailService(suite.emailClient, suite.emailConfig, suite.outbox, tracker, suite.suppressions, suite.preferences, suite.tokens, suite.trackingTokens)
+	tracker.EXPECT().GetForClient(suite.context, "some-client", "unknown-message").Return(models.MessageStatus{}, &constants.MessageNotFoundError)
+
+	suite.Equal(&constants.MessageNotFoundError, emailService.Cancel(suite.context, "some-client", "unknown-message"))
+}
+
+func (suite emailServiceTestSuite) TestCancelShouldReturnErrorIfEmailIsNoLongerPending() {
+	tracker := mockStatus.NewMockTracker(suite.mockCtrl)
+	emailService := NewEmailService(suite.emailClient, suite.emailConfig, suite.outbox, tracker, suite.suppressions, suite.preferences, suite.tokens, suite.trackingTokens)
+	tracker.EXPECT().GetForClient(suite.context, "some-client", "sent-message").Return(models.MessageStatus{ID: "sent-message", State: models.Sent}, nil)
+	suite.outbox.EXPECT().Cancel(suite.context, "sent-message").Return(outbox.ErrMessageNotPending)
+
+	suite.Equal(&constants.MessageNotCancellableError, emailService.Cancel(suite.context, "some-client", "sent-message"))
+}
+
+func (suite emailServiceTestSuite) TestCancelShouldReturnErrorIfEmailIsAlreadyBeingDelivered() {
+	tracker := mockStatus.NewMockTracker(suite.mockCtrl)
+	emailService := NewEmailService(suite.emailClient, suite.emailConfig, suite.outbox, tracker, suite.suppressions, suite.preferences, suite.tokens, suite.trackingTokens)
+	tracker.EXPECT().GetForClient(suite.context, "some-client", "due-message").Return(models.MessageStatus{ID: "due-message", State: models.Sending}, nil)
+	suite.outbox.EXPECT().Cancel(suite.context, "due-message").Return(outbox.ErrMessageInFlight)
+
+	suite.Equal(&constants.MessageNotCancellableError, emailService.Cancel(suite.context, "some-client", "due-message"))
+}
+
+func (suite emailServiceTestSuite) TestSendEmailShouldRecordEveryAttemptInMessageStatus() {
+	email := models.Email{
+		From:    "gola@gola.xyz",
+		To:      []string{"some@gmail.com"},
+		Subject: "Hi!",
+		Body: models.MessageBody{
+			MimeType: "text/plain",
+			Content:  "Hello User!",
+		},
+	}
+
+	emailConfig := mocks.NewMockEmailClientConfig(suite.mockCtrl)
+	emailConfig.EXPECT().SendRetryPolicy().Return(configuration.RetryPolicy{MaxAttempts: 2, InitialBackoffInMillis: 1})
+	emailConfig.EXPECT().Unsubscribe().Return(unsubscribeConfig)
+	emailConfig.EXPECT().Tracking().Return(trackingConfig)
+	emailConfig.EXPECT().Categories().Return(categories)
+	emailConfig.EXPECT().DefaultCategory().Return("transactional")
+	tracker := mockStatus.NewMockTracker(suite.mockCtrl)
+	emailService := NewEmailService(suite.emailClient, emailConfig, nil, tracker, suite.suppressions, suite.preferences, suite.tokens, suite.trackingTokens)
+	transientError := errors.New("connection reset by peer")
+
+	var messageID string
+	gomock.InOrder(
+		tracker.EXPECT().Accept(suite.context, gomock.Any(), "", email.From, email.To).Do(func(ctx *gin.Context, id string, clientID string, from string, recipients []string) {
+			messageID = id
+		}),
+		tracker.EXPECT().Update(suite.context, gomock.Any(), models.Sending, nil),
+		suite.emailClient.EXPECT().Send(suite.context, gomock.Any()).Return(transientError),
+		tracker.EXPECT().Update(suite.context, gomock.Any(), models.Retrying, transientError),
+		tracker.EXPECT().Update(suite.context, gomock.Any(), models.Sending, nil),
+		suite.emailClient.EXPECT().Send(suite.context, gomock.Any()).Return(nil),
+		tracker.EXPECT().Update(suite.context, gomock.Any(), models.Sent, nil),
+	)
+
+	receipt, err := emailService.Send(suite.context, email)
+	suite.Nil(err)
+	suite.Equal(messageID, receipt.MessageID)
+}
+
+func (suite emailServiceTestSuite) TestSendEmailShouldRecordFailedStatusIfEmailCannotBeSent() {
+	email := models.Email{
+		From:    "gola@gola.xyz",
+		To:      []string{"some@gmail.com"},
+		Subject: "Hi!",
+		Body: models.MessageBody{
+			MimeType: "text/plain",
+			Content:  "Hello User!",
+		},
+	}
+
+	tracker := mockStatus.NewMockTracker(suite.mockCtrl)
+	emailService := NewEmailService(suite.emailClient, suite.emailConfig, nil, tracker, suite.suppressions, suite.preferences, suite.tokens, suite.trackingTokens)
+	sendError := errors.New("failed to send email")
+
+	gomock.InOrder(
+		tracker.EXPECT().Accept(suite.context, gomock.Any(), "", email.From, email.To),
+		tracker.EXPECT().Update(suite.context, gomock.Any(), models.Sending, nil),
+		suite.emailClient.EXPECT().Send(suite.context, gomock.Any()).Return(sendError),
+		tracker.EXPECT().Update(suite.context, gomock.Any(), models.Failed, sendError),
+	)
+
+	_, err := emailService.Send(suite.context, email)
+	suite.Equal(&constants.InternalServerError, err)
+}
+
+func (suite emailServiceTestSuite) TestSendEmailShouldPassCcBccReplyToAndHeadersToClientAndTrackEveryRecipient() {
+	email := models.Email{
+		From:    "gola@gola.xyz",
+		To:      []string{"some@gmail.com"},
+		Cc:      []string{"cc@gmail.com"},
+		Bcc:     []string{"bcc@gmail.com"},
+		ReplyTo: "support@gola.xyz",
+		Headers: map[string]string{"X-Entity-Ref-ID": "order-42"},
+		Subject: "Hi!",
+		Body: models.MessageBody{
+			MimeType: "text/plain",
+			Content:  "Hello User!",
+		},
+	}
+
+	tracker := mockStatus.NewMockTracker(suite.mockCtrl)
+	emailService := NewEmailService(suite.emailClient, suite.emailConfig, nil, tracker, suite.suppressions, suite.preferences, suite.tokens, suite.trackingTokens)
+
+	tracker.EXPECT().Accept(suite.context, gomock.Any(), "", email.From, []string{"some@gmail.com", "cc@gmail.com", "bcc@gmail.com"})
+	tracker.EXPECT().Update(suite.context, gomock.Any(), gomock.Any(), nil).AnyTimes()
+	suite.emailClient.EXPECT().Send(suite.context, gomock.Any()).Do(func(ctx *gin.Context, request *email_client_request.EmailClientRequest) {
+		suite.Equal(email.Cc, request.Cc)
+		suite.Equal(email.Bcc, request.Bcc)
+		suite.Equal(email.ReplyTo, request.ReplyTo)
+		suite.Equal(email.Headers, request.Headers)
+	}).Return(nil)
+
+	_, err := emailService.Send(suite.context, email)
+	suite.Nil(err)
+}
+
+func (suite emailServiceTestSuite) TestSendEmailShouldGeneratePlainTextAlternativeForHtmlBody() {
+	email := models.Email{
+		From:    "gola@gola.xyz",
+		To:      []string{"some@gmail.com"},
+		Subject: "Hi!",
+		Body: models.MessageBody{
+			MimeType: "text/html",
+			Content:  `<p>Hello User!</p><p><a href="https://gola.xyz/verify">Verify your email</a></p>`,
+		},
+	}
+
+	suite.emailClient.EXPECT().Send(suite.context, gomock.Any()).Do(func(ctx *gin.Context, request *email_client_request.EmailClientRequest) {
+		suite.Equal("Hello User!\n\nVerify your email [1]\n\n[1] https://gola.xyz/verify", request.Body.PlainText)
+	}).Return(nil)
+
+	_, err := suite.emailService.Send(suite.context, email)
+	suite.Nil(err)
+}
+
+func (suite emailServiceTestSuite) TestSendEmailShouldKeepPlainTextAlternativeGivenByCaller() {
+	email := models.Email{
+		From:    "gola@gola.xyz",
+		To:      []string{"some@gmail.com"},
+		Subject: "Hi!",
+		Body: models.MessageBody{
+			MimeType:  "text/html",
+			Content:   "<p>Hello User!</p>",
+			PlainText: "Hello from the caller",
+		},
+	}
+
+	suite.emailClient.EXPECT().Send(suite.context, gomock.Any()).Do(func(ctx *gin.Context, request *email_client_request.EmailClientRequest) {
+		suite.Equal("Hello from the caller", request.Body.PlainText)
+	}).Return(nil)
+
+	_, err := suite.emailService.Send(suite.context, email)
+	suite.Nil(err)
+}
+
+func (suite emailServiceTestSuite) TestSendEmailShouldDropSuppressedRecipientsAndReportThem() {
+	email := models.Email{
+		From:    "gola@gola.xyz",
+		To:      []string{"some@gmail.com", "bounced@gmail.com"},
+		Cc:      []string{"someone@blocked.com"},
+		Subject: "Hi!",
+		Body: models.MessageBody{
+			MimeType: "text/plain",
+			Content:  "Hello User!",
+		},
+	}
+	suppressed := []models.SuppressedRecipient{
+		{Address: "bounced@gmail.com", Reason: models.HardBounce},
+		{Address: "someone@blocked.com", Reason: models.Manual},
+	}
+	suppressions := mockSuppression.NewMockList(suite.mockCtrl)
+	suppressions.EXPECT().Check(suite.context, email.Recipients()).Return(suppressed)
+	emailService := NewEmailService(suite.emailClient, suite.emailConfig, nil, suite.tracker, suppressions, suite.preferences, suite.tokens, suite.trackingTokens)
+
+	suite.emailClient.EXPECT().Send(suite.context, gomock.Any()).Do(func(ctx *gin.Context, request *email_client_request.EmailClientRequest) {
+		suite.Equal([]string{"some@gmail.com"}, request.To)
+		suite.Empty(request.Cc)
+	}).Return(nil)
+
+	receipt, err := emailService.Send(suite.context, email)
+	suite.Nil(err)
+	suite.NotEmpty(receipt.MessageID)
+	suite.Equal(suppressed, receipt.Suppressed)
+}
+
+func (suite emailServiceTestSuite) TestSendEmailShouldReturnErrorIfEveryRecipientIsSuppressed() {
+	email := models.Email{
+		From:    "gola@gola.xyz",
+		To:      []string{"bounced@gmail.com"},
+		Subject: "Hi!",
+		Body: models.MessageBody{
+			MimeType: "text/plain",
+			Content:  "Hello User!",
+		},
+	}
+	suppressed := []models.SuppressedRecipient{{Address: "bounced@gmail.com", Reason: models.HardBounce}}
+	suppressions := mockSuppression.NewMockList(suite.mockCtrl)
+	suppressions.EXPECT().Check(suite.context, email.Recipients()).Return(suppressed)
+	emailService := NewEmailService(suite.emailClient, suite.emailConfig, suite.outbox, suite.tracker, suppressions, suite.preferences, suite.tokens, suite.trackingTokens)
+
+	receipt, err := emailService.Enqueue(suite.context, email)
+	suite.Equal(constants.AllRecipientsSuppressedCode, err.ErrorCode)
+	suite.Equal(suppressed, err.AdditionalData)
+	suite.Empty(receipt.MessageID)
+}
+
+func (suite emailServiceTestSuite) TestSendEmailShouldAddPersonalUnsubscribeLinkToMarketingEmail() {
+	email := models.Email{
+		From:     "gola@gola.xyz",
+		To:       []string{"some@gmail.com"},
+		Subject:  "This week on gola",
+		Category: "marketing",
+		Body: models.MessageBody{
+			MimeType: "text/plain",
+			Content:  "Hello User!",
+		},
+		IncludeBaseTemplate: true,
+	}
+	suite.preferences.EXPECT().OptedOut(suite.context, email.To, "marketing").Return(nil)
+	suite.emailConfig.EXPECT().BaseTemplateFilePath().Return("../../email_templates/base_email_template.html")
+	suite.emailConfig.EXPECT().LogoUrls().Return(configuration.LogoUrls{})
+	suite.emailConfig.EXPECT().LogoFiles().Return(configuration.LogoFiles{})
+	suite.emailConfig.EXPECT().EmbedLogos().Return(false)
+	suite.emailConfig.EXPECT().OtherUrls().Return(configuration.Urls{HelpCenter: "https://www.google.com"})
+
+	var sentRequest *email_client_request.EmailClientRequest
+	suite.emailClient.EXPECT().Send(suite.context, gomock.Any()).Do(func(ctx *gin.Context, request *email_client_request.EmailClientRequest) {
+		sentRequest = request
+	}).Return(nil)
+
+	_, err := suite.emailService.Send(suite.context, email)
+	suite.Nil(err)
+
+	suite.True(strings.HasPrefix(sentRequest.UnsubscribeUrl, "https://ccg.gola.xyz/api/ccg/v1/unsubscribe/"))
+	token := strings.TrimPrefix(sentRequest.UnsubscribeUrl, "https://ccg.gola.xyz/api/ccg/v1/unsubscribe/")
+	claim, tokenError := suite.tokens.Verify(token)
+	suite.Nil(tokenError)
+	suite.Equal(unsubscribe.Claim{Recipient: "some@gmail.com", Category: "marketing"}, claim)
+	suite.Contains(sentRequest.Body.Content, `href="`+sentRequest.UnsubscribeUrl+`"`)
+}
+
+func (suite emailServiceTestSuite) TestSendEmailShouldRenderBaseTemplateOfTenantAndPassTenantToClient() {
+	email := models.Email{
+		From:    "gola@gola.xyz",
+		To:      []string{"some@gmail.com"},
+		Subject: "Hi!",
+		Body: models.MessageBody{
+			MimeType: "text/plain",
+			Content:  "Hello User!",
+		},
+		IncludeBaseTemplate: true,
+		Tenant:              "gola",
+	}
+	tenantConfig := mocks.NewMockEmailClientConfig(suite.mockCtrl)
+	suite.emailConfig.EXPECT().ForTenant("gola").Return(tenantConfig)
+	tenantConfig.EXPECT().BaseTemplateFilePath().Return("../../email_templates/base_email_template.html")
+	tenantConfig.EXPECT().LogoUrls().Return(configuration.LogoUrls{Facebook: "https://cdn.gola.xyz/facebook.png"})
+	tenantConfig.EXPECT().LogoFiles().Return(configuration.LogoFiles{})
+	tenantConfig.EXPECT().EmbedLogos().Return(false)
+	tenantConfig.EXPECT().OtherUrls().Return(configuration.Urls{HelpCenter: "https://help.gola.xyz"})
+	tenantConfig.EXPECT().FooterText().Return("© Gola. All rights reserved.")
+
+	var sentRequest *email_client_request.EmailClientRequest
+	suite.emailClient.EXPECT().Send(suite.context, gomock.Any()).Do(func(ctx *gin.Context, request *email_client_request.EmailClientRequest) {
+		sentRequest = request
+	}).Return(nil)
+
+	_, err := suite.emailService.Send(suite.context, email)
+	suite.Nil(err)
+
+	suite.Equal("gola", sentRequest.Tenant)
+	suite.Contains(sentRequest.Body.Content, "https://cdn.gola.xyz/facebook.png")
+	suite.Contains(sentRequest.Body.Content, "https://help.gola.xyz")
+	suite.Contains(sentRequest.Body.Content, "© Gola. All rights reserved.")
+}
+
+func (suite emailServiceTestSuite) TestSendEmailShouldRefuseMarketingEmailWithSeveralRecipients() {
+	email := models.Email{
+		From:     "gola@gola.xyz",
+		To:       []string{"some@gmail.com"},
+		Cc:       []string{"other@gmail.com"},
+		Subject:  "This week on gola",
+		Category: "marketing",
+		Body: models.MessageBody{
+			MimeType: "text/plain",
+			Content:  "Hello User!",
+		},
+	}
+	suite.preferences.EXPECT().OptedOut(suite.context, email.Recipients(), "marketing").Return(nil)
+	suite.emailClient.EXPECT().Send(gomock.Any(), gomock.Any()).Times(0)
+
+	_, err := suite.emailService.Send(suite.context, email)
+	suite.Equal(&constants.SingleRecipientRequiredError, err)
+}
+
+func (suite emailServiceTestSuite) TestSendEmailShouldDropRecipientsThatOptedOutOfCategory() {
+	email := models.Email{
+		From:     "gola@gola.xyz",
+		To:       []string{"some@gmail.com", "opted-out@gmail.com"},
+		Subject:  "This week on gola",
+		Category: "marketing",
+		Body: models.MessageBody{
+			MimeType: "text/plain",
+			Content:  "Hello User!",
+		},
+	}
+	suite.preferences.EXPECT().OptedOut(suite.context, email.To, "marketing").Return([]string{"opted-out@gmail.com"})
+	suite.emailClient.EXPECT().Send(suite.context, gomock.Any()).Do(func(ctx *gin.Context, request *email_client_request.EmailClientRequest) {
+		suite.Equal([]string{"some@gmail.com"}, request.To)
+	}).Return(nil)
+
+	receipt, err := suite.emailService.Send(suite.context, email)
+	suite.Nil(err)
+	suite.Equal([]models.SuppressedRecipient{{Address: "opted-out@gmail.com", Reason: models.Unsubscribed}}, receipt.Suppressed)
+}
+
+func (suite emailServiceTestSuite) TestSendEmailShouldNotConsultPreferencesForCategoryThatBypassesThem() {
+	email := models.Email{
+		From:     "gola@gola.xyz",
+		To:       []string{"some@gmail.com"},
+		Subject:  "Your password was changed",
+		Category: "security",
+		Body: models.MessageBody{
+			MimeType: "text/plain",
+			Content:  "Hello User!",
+		},
+	}
+	suite.emailClient.EXPECT().Send(suite.context, gomock.Any()).Do(func(ctx *gin.Context, request *email_client_request.EmailClientRequest) {
+		suite.Equal("security", request.Category)
+		suite.Empty(request.UnsubscribeUrl)
+	}).Return(nil)
+
+	receipt, err := suite.emailService.Send(suite.context, email)
+	suite.Nil(err)
+	suite.Empty(receipt.Suppressed)
+}
+
+func (suite emailServiceTestSuite) TestSendEmailShouldTrackLinksAndOpensOfTrackedEmail() {
+	email := models.Email{
+		From:     "gola@gola.xyz",
+		To:       []string{"some@gmail.com"},
+		Subject:  "This week on gola",
+		Category: "marketing",
+		Track:    true,
+		Body: models.MessageBody{
+			MimeType: "text/html",
+			Content:  `<p><a href="https://gola.xyz/posts/1">Read more</a></p>`,
+		},
+		IncludeBaseTemplate: true,
+	}
+	suite.preferences.EXPECT().OptedOut(suite.context, email.To, "marketing").Return(nil)
+	suite.emailConfig.EXPECT().BaseTemplateFilePath().Return("../../email_templates/base_email_template.html")
+	suite.emailConfig.EXPECT().LogoUrls().Return(configuration.LogoUrls{})
+	suite.emailConfig.EXPECT().LogoFiles().Return(configuration.LogoFiles{})
+	suite.emailConfig.EXPECT().EmbedLogos().Return(false)
+	suite.emailConfig.EXPECT().OtherUrls().Return(configuration.Urls{})
+
+	var sentRequest *email_client_request.EmailClientRequest
+	suite.emailClient.EXPECT().Send(suite.context, gomock.Any()).Do(func(ctx *gin.Context, request *email_client_request.EmailClientRequest) {
+		sentRequest = request
+	}).Return(nil)
+
+	receipt, err := suite.emailService.Send(suite.context, email)
+	suite.Nil(err)
+
+	content := sentRequest.Body.Content
+	suite.NotContains(content, `href="https://gola.xyz/posts/1"`)
+	suite.Contains(content, `href="https://ccg.gola.xyz/api/ccg/t/c/`)
+	suite.Contains(content, `<img src="https://ccg.gola.xyz/api/ccg/t/o/`+suite.trackingTokens.Issue(tracking.Claim{MessageID: receipt.MessageID})+`"`)
+	suite.Contains(content, `href="`+sentRequest.UnsubscribeUrl+`"`)
+	suite.Contains(sentRequest.Body.PlainText, "https://gola.xyz/posts/1")
+}
+
+func (suite emailServiceTestSuite) TestSendEmailShouldNotTrackEmailThatDidNotAskForIt() {
+	email := models.Email{
+		From:    "gola@gola.xyz",
+		To:      []string{"some@gmail.com"},
+		Subject: "Hi!",
+		Body: models.MessageBody{
+			MimeType: "text/html",
+			Content:  `<p><a href="https://gola.xyz/posts/1">Read more</a></p>`,
+		},
+	}
+	suite.emailClient.EXPECT().Send(suite.context, gomock.Any()).Do(func(ctx *gin.Context, request *email_client_request.EmailClientRequest) {
+		suite.Equal(`<p><a href="https://gola.xyz/posts/1">Read more</a></p>`, request.Body.Content)
+	}).Return(nil)
+
+	_, err := suite.emailService.Send(suite.context, email)
+	suite.Nil(err)
+}
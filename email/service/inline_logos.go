@@ -0,0 +1,61 @@
+package service
+
+import (
+	"ccg-api/email/configuration"
+	"ccg-api/email/models"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/inclusi-blog/gola-utils/logging"
+	"html/template"
+	"io/ioutil"
+	"path/filepath"
+)
+
+const logoContentIDDomain = "ccg-api"
+
+// inlineLogos returns the LogoUrl fields of the base template of the tenant, pointing at inline parts for every logo file
+// that could be read when embedding is enabled, along with those parts. Unreadable files fall back to the remote url.
+func inlineLogos(ctx *gin.Context, tenantConfig configuration.EmailClientConfig) (map[string]interface{}, []models.Attachment) {
+	logger := logging.GetLogger(ctx).WithField("class", "EmailService").WithField("method", "inlineLogos")
+	logoUrls := tenantConfig.LogoUrls()
+	logoFiles := tenantConfig.LogoFiles()
+	embedLogos := tenantConfig.EmbedLogos()
+	logos := []struct {
+		field string
+		name  string
+		url   string
+		path  string
+	}{
+		{"Mensuvadi", "mensuvadi", logoUrls.Mensuvadi, logoFiles.Mensuvadi},
+		{"Facebook", "facebook", logoUrls.Facebook, logoFiles.Facebook},
+		{"Instagram", "instagram", logoUrls.Instagram, logoFiles.Instagram},
+		{"Twitter", "twitter", logoUrls.Twitter, logoFiles.Twitter},
+		{"LinkedIn", "linkedin", logoUrls.LinkedIn, logoFiles.LinkedIn},
+		{"DownloadIOS", "download-ios", logoUrls.DownloadIOS, logoFiles.DownloadIOS},
+		{"DownloadAndroid", "download-android", logoUrls.DownloadAndroid, logoFiles.DownloadAndroid},
+	}
+
+	fields := map[string]interface{}{}
+	var attachments []models.Attachment
+	for _, logo := range logos {
+		fields[logo.field] = logo.url
+		if !embedLogos || len(logo.path) == 0 {
+			continue
+		}
+		data, err := ioutil.ReadFile(logo.path)
+		if err != nil {
+			logger.Errorf("Could not read logo file %s, falling back to its url %v", logo.path, err)
+			continue
+		}
+		contentID := fmt.Sprintf("logo-%s@%s", logo.name, logoContentIDDomain)
+		attachments = append(attachments, models.Attachment{
+			FileName:  "logo-" + logo.name + filepath.Ext(logo.path),
+			Data:      data,
+			Inline:    true,
+			ContentID: contentID,
+		})
+		// html/template rejects the cid scheme unless the url is marked as safe
+		fields[logo.field] = template.URL("cid:" + contentID)
+	}
+	return fields, attachments
+}
@@ -0,0 +1,134 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: email/status/tracker.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	models "ccg-api/email/models"
+	gin "github.com/gin-gonic/gin"
+	gomock "github.com/golang/mock/gomock"
+	golaerror "github.com/inclusi-blog/gola-utils/golaerror"
+	reflect "reflect"
+	time "time"
+)
+
+// MockTracker is a mock of Tracker interface
+type MockTracker struct {
+	ctrl     *gomock.Controller
+	recorder *MockTrackerMockRecorder
+}
+
+// MockTrackerMockRecorder is the mock recorder for MockTracker
+type MockTrackerMockRecorder struct {
+	mock *MockTracker
+}
+
+// NewMockTracker creates a new mock instance
+func NewMockTracker(ctrl *gomock.Controller) *MockTracker {
+	mock := &MockTracker{ctrl: ctrl}
+	mock.recorder = &MockTrackerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockTracker) EXPECT() *MockTrackerMockRecorder {
+	return m.recorder
+}
+
+// Accept mocks base method
+func (m *MockTracker) Accept(ctx *gin.Context, id, clientID, from string, recipients []string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Accept", ctx, id, clientID, from, recipients)
+}
+
+// Accept indicates an expected call of Accept
+func (mr *MockTrackerMockRecorder) Accept(ctx, id, clientID, from, recipients interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Accept", reflect.TypeOf((*MockTracker)(nil).Accept), ctx, id, clientID, from, recipients)
+}
+
+// Update mocks base method
+func (m *MockTracker) Update(ctx *gin.Context, id string, state models.MessageState, cause error) *golaerror.Error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", ctx, id, state, cause)
+	ret0, _ := ret[0].(*golaerror.Error)
+	return ret0
+}
+
+// Update indicates an expected call of Update
+func (mr *MockTrackerMockRecorder) Update(ctx, id, state, cause interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockTracker)(nil).Update), ctx, id, state, cause)
+}
+
+// RecordOutcome mocks base method
+func (m *MockTracker) RecordOutcome(ctx *gin.Context, id, recipient string, state models.MessageState, cause error, at time.Time) *golaerror.Error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordOutcome", ctx, id, recipient, state, cause, at)
+	ret0, _ := ret[0].(*golaerror.Error)
+	return ret0
+}
+
+// RecordOutcome indicates an expected call of RecordOutcome
+func (mr *MockTrackerMockRecorder) RecordOutcome(ctx, id, recipient, state, cause, at interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordOutcome", reflect.TypeOf((*MockTracker)(nil).RecordOutcome), ctx, id, recipient, state, cause, at)
+}
+
+// RecordEngagement mocks base method
+func (m *MockTracker) RecordEngagement(ctx *gin.Context, id string, eventType models.DeliveryEventType, at time.Time) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "RecordEngagement", ctx, id, eventType, at)
+}
+
+// RecordEngagement indicates an expected call of RecordEngagement
+func (mr *MockTrackerMockRecorder) RecordEngagement(ctx, id, eventType, at interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordEngagement", reflect.TypeOf((*MockTracker)(nil).RecordEngagement), ctx, id, eventType, at)
+}
+
+// Get mocks base method
+func (m *MockTracker) Get(ctx *gin.Context, id string) (models.MessageStatus, *golaerror.Error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", ctx, id)
+	ret0, _ := ret[0].(models.MessageStatus)
+	ret1, _ := ret[1].(*golaerror.Error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get
+func (mr *MockTrackerMockRecorder) Get(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockTracker)(nil).Get), ctx, id)
+}
+
+// GetForClient mocks base method
+func (m *MockTracker) GetForClient(ctx *gin.Context, clientID, id string) (models.MessageStatus, *golaerror.Error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetForClient", ctx, clientID, id)
+	ret0, _ := ret[0].(models.MessageStatus)
+	ret1, _ := ret[1].(*golaerror.Error)
+	return ret0, ret1
+}
+
+// GetForClient indicates an expected call of GetForClient
+func (mr *MockTrackerMockRecorder) GetForClient(ctx, clientID, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetForClient", reflect.TypeOf((*MockTracker)(nil).GetForClient), ctx, clientID, id)
+}
+
+// List mocks base method
+func (m *MockTracker) List(ctx *gin.Context, filter models.MessageStatusFilter) (models.MessageStatusPage, *golaerror.Error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx, filter)
+	ret0, _ := ret[0].(models.MessageStatusPage)
+	ret1, _ := ret[1].(*golaerror.Error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List
+func (mr *MockTrackerMockRecorder) List(ctx, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockTracker)(nil).List), ctx, filter)
+}
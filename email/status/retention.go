@@ -0,0 +1,54 @@
+package status
+
+import (
+	"fmt"
+	"github.com/inclusi-blog/gola-utils/logging"
+	"time"
+)
+
+const (
+	defaultRetention     = 60 * 24 * time.Hour
+	retentionSweepPeriod = time.Hour
+)
+
+// Retention purges statuses of messages that have not changed for the retention period, so the store stays bounded
+type Retention struct {
+	store     Store
+	retention time.Duration
+}
+
+// NewRetention refuses a retention period that does not outlast scheduleAhead, the status of a message scheduled that
+// far ahead is not updated until it is sent and would be purged while still waiting in the outbox
+func NewRetention(store Store, retentionInDays int, scheduleAhead time.Duration) (Retention, error) {
+	retention := defaultRetention
+	if retentionInDays > 0 {
+		retention = time.Duration(retentionInDays) * 24 * time.Hour
+	}
+	if retention <= scheduleAhead {
+		return Retention{}, fmt.Errorf("retention of %s must be longer than the %s messages can be scheduled ahead", retention, scheduleAhead)
+	}
+	return Retention{store: store, retention: retention}, nil
+}
+
+func (retention Retention) Start() {
+	go func() {
+		ticker := time.NewTicker(retentionSweepPeriod)
+		defer ticker.Stop()
+		for {
+			retention.Sweep(time.Now())
+			<-ticker.C
+		}
+	}()
+}
+
+func (retention Retention) Sweep(now time.Time) {
+	logger := logging.NewLoggerEntry().WithField("class", "StatusRetention").WithField("method", "Sweep")
+	purged, err := retention.store.Purge(now.Add(-retention.retention))
+	if err != nil {
+		logger.Error("Failed to purge expired message statuses ", err)
+		return
+	}
+	if purged > 0 {
+		logger.Infof("Purged %d message status(es) older than %s", purged, retention.retention)
+	}
+}
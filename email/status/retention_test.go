@@ -0,0 +1,50 @@
+package status
+
+import (
+	"ccg-api/email/status/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/suite"
+	"testing"
+	"time"
+)
+
+type retentionTestSuite struct {
+	suite.Suite
+	mockCtrl *gomock.Controller
+	store    *mocks.MockStore
+}
+
+func TestRetentionTestSuite(t *testing.T) {
+	suite.Run(t, new(retentionTestSuite))
+}
+
+func (suite *retentionTestSuite) SetupTest() {
+	suite.mockCtrl = gomock.NewController(suite.T())
+	suite.store = mocks.NewMockStore(suite.mockCtrl)
+}
+
+func (suite *retentionTestSuite) TearDownTest() {
+	suite.mockCtrl.Finish()
+}
+
+func (suite *retentionTestSuite) TestNewRetention_ShouldRefuseRetentionNotLongerThanScheduleAhead() {
+	_, err := NewRetention(suite.store, 30, 30*24*time.Hour)
+
+	suite.EqualError(err, "retention of 720h0m0s must be longer than the 720h0m0s messages can be scheduled ahead")
+}
+
+func (suite *retentionTestSuite) TestNewRetention_ShouldRefuseDefaultRetentionNotLongerThanScheduleAhead() {
+	_, err := NewRetention(suite.store, 0, 90*24*time.Hour)
+
+	suite.NotNil(err)
+}
+
+func (suite *retentionTestSuite) TestSweep_ShouldPurgeStatusesNotUpdatedForTheRetentionPeriod() {
+	now := time.Now()
+	retention, err := NewRetention(suite.store, 60, 30*24*time.Hour)
+	suite.store.EXPECT().Purge(now.Add(-60*24*time.Hour)).Return(3, nil)
+
+	retention.Sweep(now)
+
+	suite.Nil(err)
+}
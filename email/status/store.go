@@ -0,0 +1,309 @@
+package status
+
+// mockgen -source=email/status/store.go -destination=email/status/mocks/mock_store.go -package=mocks
+import (
+	"ccg-api/email/models"
+	"ccg-api/util"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	statusFileExtension = ".json"
+	messagesDirectory   = "messages"
+	indexDirectory      = "index"
+	clientIndexPrefix   = "client-"
+	maxSwapAttempts     = 100
+)
+
+var ErrConcurrentUpdate = errors.New("message status kept changing while being updated")
+
+type Store interface {
+	Save(status models.MessageStatus) error
+	Update(id string, change func(status *models.MessageStatus) bool) (bool, error)
+	Get(id string) (models.MessageStatus, bool, error)
+	List(filter models.MessageStatusFilter) (models.MessageStatusPage, error)
+	Purge(before time.Time) (int, error)
+}
+
+// fileStore keeps a directory per message holding one file per revision of its status, the highest being current,
+// and an index of empty files per client named after the creation time and id, so that listing reads only the
+// statuses of one client, newest first, until the page is full.
+// A revision is hard linked into place, which fails when another replica wrote that revision first, so every write
+// is a compare and swap against the revision it was based on. Revisions are only removed along with their message
+type fileStore struct {
+	directory string
+}
+
+func NewFileStore(directory string) (Store, error) {
+	store := fileStore{directory: directory}
+	if err := os.MkdirAll(path.Join(directory, messagesDirectory), 0755); err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(path.Join(directory, indexDirectory), 0755); err != nil {
+		return nil, err
+	}
+	return store, store.migrate()
+}
+
+// Save records status as the next revision, whatever the current one is
+func (store fileStore) Save(status models.MessageStatus) error {
+	_, err := store.swap(status.ID, func(current *models.MessageStatus, found bool) bool {
+		*current = status
+		return true
+	})
+	return err
+}
+
+// Update applies change to the current status and records the result, it is false when there is no status to update.
+// change may run more than once when other replicas update the same status, and returns false to leave it as it is
+func (store fileStore) Update(id string, change func(status *models.MessageStatus) bool) (bool, error) {
+	return store.swap(id, func(current *models.MessageStatus, found bool) bool {
+		return found && change(current)
+	})
+}
+
+func (store fileStore) Get(id string) (models.MessageStatus, bool, error) {
+	status, revision, err := store.current(id)
+	return status, revision > 0, err
+}
+
+// List returns the matching statuses of the client of the filter, most recently created first.
+// NextCursor is set when there may be more, and lists the ones after the page when passed back as Cursor
+func (store fileStore) List(filter models.MessageStatusFilter) (models.MessageStatusPage, error) {
+	page := models.MessageStatusPage{Statuses: []models.MessageStatus{}}
+	entries, err := ioutil.ReadDir(store.clientIndexPath(filter.ClientID))
+	if os.IsNotExist(err) {
+		return page, nil
+	}
+	if err != nil {
+		return page, err
+	}
+
+	for index := len(entries) - 1; index >= 0; index-- {
+		entry := entries[index].Name()
+		if filter.Cursor != "" && entry >= filter.Cursor {
+			continue
+		}
+		createdAt, id, valid := parseIndexEntry(entry)
+		if !valid || (!filter.Until.IsZero() && createdAt.After(filter.Until)) {
+			continue
+		}
+		if !filter.Since.IsZero() && createdAt.Before(filter.Since) {
+			break
+		}
+		status, found, err := store.Get(id)
+		if err != nil {
+			return page, err
+		}
+		if !found || !filter.Matches(status) {
+			continue
+		}
+		page.Statuses = append(page.Statuses, status)
+		if filter.Limit > 0 && len(page.Statuses) == filter.Limit {
+			page.NextCursor = entry
+			break
+		}
+	}
+	return page, nil
+}
+
+// Purge removes the statuses not updated since before, along with their index entries
+func (store fileStore) Purge(before time.Time) (int, error) {
+	messages, err := ioutil.ReadDir(path.Join(store.directory, messagesDirectory))
+	if err != nil {
+		return 0, err
+	}
+
+	purged := 0
+	for _, message := range messages {
+		revisions, err := store.revisions(message.Name())
+		if err != nil {
+			return purged, err
+		}
+		updatedAt := message.ModTime()
+		if len(revisions) > 0 {
+			updatedAt = revisions[len(revisions)-1].ModTime()
+		}
+		if !updatedAt.Before(before) {
+			continue
+		}
+		if err := os.RemoveAll(store.messagePath(message.Name())); err != nil {
+			return purged, err
+		}
+		purged++
+	}
+
+	clients, err := ioutil.ReadDir(path.Join(store.directory, indexDirectory))
+	if err != nil {
+		return purged, err
+	}
+	for _, client := range clients {
+		clientIndexPath := path.Join(store.directory, indexDirectory, client.Name())
+		entries, err := ioutil.ReadDir(clientIndexPath)
+		if err != nil {
+			return purged, err
+		}
+		for _, entry := range entries {
+			createdAt, id, valid := parseIndexEntry(entry.Name())
+			if valid && !createdAt.Before(before) {
+				break
+			}
+			if _, err := os.Stat(store.messagePath(id)); valid && err == nil {
+				continue
+			}
+			if err := os.Remove(path.Join(clientIndexPath, entry.Name())); err != nil && !os.IsNotExist(err) {
+				return purged, err
+			}
+		}
+	}
+	return purged, nil
+}
+
+// swap retries change against the current revision until its result is the next revision
+func (store fileStore) swap(id string, change func(current *models.MessageStatus, found bool) bool) (bool, error) {
+	for attempt := 0; attempt < maxSwapAttempts; attempt++ {
+		status, revision, err := store.current(id)
+		if err != nil {
+			return false, err
+		}
+		if !change(&status, revision > 0) {
+			return revision > 0, nil
+		}
+		data, err := json.Marshal(status)
+		if err != nil {
+			return false, err
+		}
+		if err := os.MkdirAll(store.messagePath(id), 0755); err != nil {
+			return false, err
+		}
+		created, err := util.CreateFileAtomically(store.revisionPath(id, revision+1), data)
+		if err != nil {
+			return false, err
+		}
+		if created {
+			return true, store.index(status)
+		}
+	}
+	return false, ErrConcurrentUpdate
+}
+
+// current reads the highest revision of the status, a revision of 0 meaning there is none
+func (store fileStore) current(id string) (models.MessageStatus, int64, error) {
+	revisions, err := store.revisions(id)
+	if err != nil || len(revisions) == 0 {
+		return models.MessageStatus{}, 0, err
+	}
+	latest := revisions[len(revisions)-1]
+	revision, err := strconv.ParseInt(strings.TrimSuffix(latest.Name(), statusFileExtension), 10, 64)
+	if err != nil {
+		return models.MessageStatus{}, 0, err
+	}
+	data, err := ioutil.ReadFile(path.Join(store.messagePath(id), latest.Name()))
+	if err != nil {
+		return models.MessageStatus{}, 0, err
+	}
+	var status models.MessageStatus
+	if err := json.Unmarshal(data, &status); err != nil {
+		return models.MessageStatus{}, 0, err
+	}
+	return status, revision, nil
+}
+
+// revisions lists the revision files of a message in revision order, skipping temp files of writes in progress
+func (store fileStore) revisions(id string) ([]os.FileInfo, error) {
+	files, err := ioutil.ReadDir(store.messagePath(id))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var revisions []os.FileInfo
+	for _, file := range files {
+		if !file.IsDir() && strings.HasSuffix(file.Name(), statusFileExtension) {
+			revisions = append(revisions, file)
+		}
+	}
+	return revisions, nil
+}
+
+func (store fileStore) index(status models.MessageStatus) error {
+	clientIndexPath := store.clientIndexPath(status.ClientID)
+	if err := os.MkdirAll(clientIndexPath, 0755); err != nil {
+		return err
+	}
+	entry, err := os.OpenFile(path.Join(clientIndexPath, indexEntry(status)), os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	return entry.Close()
+}
+
+// migrate moves statuses recorded before the store kept revisions into their first revision, and indexes them
+func (store fileStore) migrate() error {
+	files, err := ioutil.ReadDir(store.directory)
+	if err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		if file.IsDir() || !strings.HasSuffix(file.Name(), statusFileExtension) {
+			continue
+		}
+		data, err := ioutil.ReadFile(path.Join(store.directory, file.Name()))
+		if err != nil {
+			return err
+		}
+		var status models.MessageStatus
+		if err := json.Unmarshal(data, &status); err != nil {
+			return err
+		}
+		if err := store.Save(status); err != nil {
+			return err
+		}
+		if err := os.Remove(path.Join(store.directory, file.Name())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// messagePath keeps lookups inside the store directory even for ids taken straight from a URL
+func (store fileStore) messagePath(id string) string {
+	return path.Join(store.directory, messagesDirectory, path.Base(path.Clean("/"+id)))
+}
+
+// revisionPath names sort in revision order
+func (store fileStore) revisionPath(id string, revision int64) string {
+	return path.Join(store.messagePath(id), fmt.Sprintf("%019d%s", revision, statusFileExtension))
+}
+
+func (store fileStore) clientIndexPath(clientID string) string {
+	return path.Join(store.directory, indexDirectory, clientIndexPrefix+hex.EncodeToString([]byte(clientID)))
+}
+
+// indexEntry names sort in the order the statuses were created
+func indexEntry(status models.MessageStatus) string {
+	return fmt.Sprintf("%019d_%s", status.CreatedAt.UnixNano(), path.Base(path.Clean("/"+status.ID)))
+}
+
+func parseIndexEntry(entry string) (time.Time, string, bool) {
+	parts := strings.SplitN(entry, "_", 2)
+	if len(parts) != 2 {
+		return time.Time{}, "", false
+	}
+	createdAt, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return time.Time{}, "", false
+	}
+	return time.Unix(0, createdAt), parts[1], true
+}
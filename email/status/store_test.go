@@ -0,0 +1,218 @@
+package status
+
+import (
+	"ccg-api/email/models"
+	"github.com/stretchr/testify/suite"
+	"io/ioutil"
+	"os"
+	"path"
+	"sync"
+	"testing"
+	"time"
+)
+
+type fileStoreTestSuite struct {
+	suite.Suite
+	directory string
+	store     Store
+}
+
+func TestFileStoreTestSuite(t *testing.T) {
+	suite.Run(t, new(fileStoreTestSuite))
+}
+
+func (suite *fileStoreTestSuite) SetupTest() {
+	suite.directory = path.Join(os.TempDir(), "ccg-status-file-store-unit-test-dir")
+	_ = os.RemoveAll(suite.directory)
+	suite.store, _ = NewFileStore(suite.directory)
+}
+
+func (suite *fileStoreTestSuite) TearDownTest() {
+	_ = os.RemoveAll(suite.directory)
+}
+
+func (suite *fileStoreTestSuite) TestGet_ShouldReturnSavedStatus() {
+	createdAt := time.Date(2020, 9, 20, 10, 0, 0, 0, time.UTC)
+	status := models.MessageStatus{
+		ID:              "message-1",
+		From:            "gola@gola.xyz",
+		RecipientHashes: []string{HashRecipient("someone@gmail.com")},
+		State:           models.Sent,
+		Attempts:        1,
+		CreatedAt:       createdAt,
+		UpdatedAt:       createdAt,
+		History:         []models.StateTransition{{State: models.Sent, At: createdAt}},
+	}
+	suite.Nil(suite.store.Save(status))
+
+	actualStatus, found, err := suite.store.Get("message-1")
+
+	suite.Nil(err)
+	suite.True(found)
+	suite.Equal(status, actualStatus)
+}
+
+func (suite *fileStoreTestSuite) TestGet_ShouldReportMissingStatusAsNotFound() {
+	_, found, err := suite.store.Get("unknown-message")
+
+	suite.Nil(err)
+	suite.False(found)
+}
+
+func (suite *fileStoreTestSuite) TestGet_ShouldNotReadOutsideStoreDirectory() {
+	_ = os.MkdirAll(path.Join(suite.directory, "nested"), 0755)
+	_ = suite.store.Save(models.MessageStatus{ID: "secret"})
+	nestedStore, _ := NewFileStore(path.Join(suite.directory, "nested"))
+
+	_, found, err := nestedStore.Get("../secret")
+
+	suite.Nil(err)
+	suite.False(found)
+}
+
+func (suite *fileStoreTestSuite) TestList_ShouldReturnMatchingStatusesMostRecentFirstWithinLimit() {
+	now := time.Now()
+	_ = suite.store.Save(models.MessageStatus{ID: "oldest", From: "gola@gola.xyz", CreatedAt: now.Add(-3 * time.Hour)})
+	_ = suite.store.Save(models.MessageStatus{ID: "other-sender", From: "other@gola.xyz", CreatedAt: now.Add(-2 * time.Hour)})
+	_ = suite.store.Save(models.MessageStatus{ID: "older", From: "gola@gola.xyz", CreatedAt: now.Add(-2 * time.Hour)})
+	_ = suite.store.Save(models.MessageStatus{ID: "newest", From: "gola@gola.xyz", CreatedAt: now.Add(-time.Hour)})
+
+	page, err := suite.store.List(models.MessageStatusFilter{From: "GOLA@gola.xyz", Limit: 2})
+
+	suite.Nil(err)
+	suite.Len(page.Statuses, 2)
+	suite.Equal("newest", page.Statuses[0].ID)
+	suite.Equal("older", page.Statuses[1].ID)
+}
+
+func (suite *fileStoreTestSuite) TestList_ShouldContinueFromCursor() {
+	now := time.Now()
+	_ = suite.store.Save(models.MessageStatus{ID: "oldest", CreatedAt: now.Add(-3 * time.Hour)})
+	_ = suite.store.Save(models.MessageStatus{ID: "older", CreatedAt: now.Add(-2 * time.Hour)})
+	_ = suite.store.Save(models.MessageStatus{ID: "newest", CreatedAt: now.Add(-time.Hour)})
+
+	firstPage, _ := suite.store.List(models.MessageStatusFilter{Limit: 2})
+	secondPage, err := suite.store.List(models.MessageStatusFilter{Limit: 2, Cursor: firstPage.NextCursor})
+
+	suite.Nil(err)
+	suite.NotEmpty(firstPage.NextCursor)
+	suite.Len(secondPage.Statuses, 1)
+	suite.Equal("oldest", secondPage.Statuses[0].ID)
+	suite.Empty(secondPage.NextCursor)
+}
+
+func (suite *fileStoreTestSuite) TestList_ShouldOnlyListStatusesOfTheClient() {
+	now := time.Now()
+	_ = suite.store.Save(models.MessageStatus{ID: "mine", ClientID: "gola-api", CreatedAt: now})
+	_ = suite.store.Save(models.MessageStatus{ID: "theirs", ClientID: "narratenet-app", CreatedAt: now})
+	_ = suite.store.Save(models.MessageStatus{ID: "anonymous", CreatedAt: now})
+
+	page, err := suite.store.List(models.MessageStatusFilter{ClientID: "gola-api"})
+
+	suite.Nil(err)
+	suite.Len(page.Statuses, 1)
+	suite.Equal("mine", page.Statuses[0].ID)
+}
+
+func (suite *fileStoreTestSuite) TestNewFileStore_ShouldMoveStatusesSavedWithoutRevisionsIntoPlace() {
+	_ = ioutil.WriteFile(path.Join(suite.directory, "unrevised.json"), []byte(`{"id":"unrevised","state":"sent"}`), 0644)
+
+	store, err := NewFileStore(suite.directory)
+
+	suite.Nil(err)
+	status, found, _ := store.Get("unrevised")
+	suite.True(found)
+	suite.Equal(models.Sent, status.State)
+	page, _ := store.List(models.MessageStatusFilter{})
+	suite.Len(page.Statuses, 1)
+	_, err = os.Stat(path.Join(suite.directory, "unrevised.json"))
+	suite.True(os.IsNotExist(err))
+}
+
+func (suite *fileStoreTestSuite) TestUpdate_ShouldReportMissingStatusAsNotFoundWithoutCreatingIt() {
+	found, err := suite.store.Update("unknown-message", func(status *models.MessageStatus) bool {
+		status.State = models.Sent
+		return true
+	})
+
+	suite.Nil(err)
+	suite.False(found)
+	_, found, _ = suite.store.Get("unknown-message")
+	suite.False(found)
+}
+
+func (suite *fileStoreTestSuite) TestUpdate_ShouldNotLoseConcurrentUpdatesOfReplicas() {
+	_ = suite.store.Save(models.MessageStatus{ID: "message-1"})
+	otherReplica, _ := NewFileStore(suite.directory)
+
+	var waitGroup sync.WaitGroup
+	for _, store := range []Store{suite.store, otherReplica} {
+		waitGroup.Add(1)
+		go func(store Store) {
+			defer waitGroup.Done()
+			for i := 0; i < 25; i++ {
+				_, err := store.Update("message-1", func(status *models.MessageStatus) bool {
+					status.Attempts++
+					return true
+				})
+				suite.Nil(err)
+			}
+		}(store)
+	}
+	waitGroup.Wait()
+
+	status, _, _ := suite.store.Get("message-1")
+	suite.Equal(50, status.Attempts)
+}
+
+func (suite *fileStoreTestSuite) TestUpdate_ShouldLeaveStatusAsItIsWhenChangeIsDeclined() {
+	_ = suite.store.Save(models.MessageStatus{ID: "message-1", State: models.Delivered})
+
+	found, err := suite.store.Update("message-1", func(status *models.MessageStatus) bool {
+		status.State = models.Sent
+		return false
+	})
+
+	suite.Nil(err)
+	suite.True(found)
+	status, _, _ := suite.store.Get("message-1")
+	suite.Equal(models.Delivered, status.State)
+}
+
+func (suite *fileStoreTestSuite) TestPurge_ShouldRemoveStatusesNotUpdatedSinceCutOff() {
+	now := time.Now()
+	_ = suite.store.Save(models.MessageStatus{ID: "expired", CreatedAt: now.Add(-48 * time.Hour)})
+	_ = suite.store.Save(models.MessageStatus{ID: "recent", CreatedAt: now.Add(-48 * time.Hour)})
+	expiredAt := now.Add(-47 * time.Hour)
+	_ = os.Chtimes(path.Join(suite.directory, "messages", "expired", "0000000000000000001.json"), expiredAt, expiredAt)
+
+	purged, err := suite.store.Purge(now.Add(-24 * time.Hour))
+
+	suite.Nil(err)
+	suite.Equal(1, purged)
+	_, found, _ := suite.store.Get("expired")
+	suite.False(found)
+	page, _ := suite.store.List(models.MessageStatusFilter{})
+	suite.Len(page.Statuses, 1)
+	suite.Equal("recent", page.Statuses[0].ID)
+	entries, _ := ioutil.ReadDir(path.Join(suite.directory, "index", "client-"))
+	suite.Len(entries, 1)
+}
+
+func (suite *fileStoreTestSuite) TestList_ShouldFilterByRecipientHashAndTimeRange() {
+	now := time.Now()
+	recipientHash := HashRecipient("someone@gmail.com")
+	_ = suite.store.Save(models.MessageStatus{ID: "too-old", RecipientHashes: []string{recipientHash}, CreatedAt: now.Add(-48 * time.Hour)})
+	_ = suite.store.Save(models.MessageStatus{ID: "other-recipient", RecipientHashes: []string{HashRecipient("other@gmail.com")}, CreatedAt: now})
+	_ = suite.store.Save(models.MessageStatus{ID: "matching", RecipientHashes: []string{recipientHash}, CreatedAt: now})
+
+	page, err := suite.store.List(models.MessageStatusFilter{
+		RecipientHash: recipientHash,
+		Since:         now.Add(-time.Hour),
+		Until:         now.Add(time.Hour),
+	})
+
+	suite.Nil(err)
+	suite.Len(page.Statuses, 1)
+	suite.Equal("matching", page.Statuses[0].ID)
+}
@@ -0,0 +1,242 @@
+package status
+
+// mockgen -source=email/status/tracker.go -destination=email/status/mocks/mock_tracker.go -package=mocks
+import (
+	"ccg-api/constants"
+	"ccg-api/email/models"
+	"crypto/sha256"
+	"encoding/hex"
+	"github.com/gin-gonic/gin"
+	"github.com/inclusi-blog/gola-utils/golaerror"
+	"github.com/inclusi-blog/gola-utils/logging"
+	"strings"
+	"time"
+)
+
+type Tracker interface {
+	Accept(ctx *gin.Context, id string, clientID string, from string, recipients []string)
+	Update(ctx *gin.Context, id string, state models.MessageState, cause error) *golaerror.Error
+	RecordOutcome(ctx *gin.Context, id string, recipient string, state models.MessageState, cause error, at time.Time) *golaerror.Error
+	RecordEngagement(ctx *gin.Context, id string, eventType models.DeliveryEventType, at time.Time)
+	Get(ctx *gin.Context, id string) (models.MessageStatus, *golaerror.Error)
+	GetForClient(ctx *gin.Context, clientID string, id string) (models.MessageStatus, *golaerror.Error)
+	List(ctx *gin.Context, filter models.MessageStatusFilter) (models.MessageStatusPage, *golaerror.Error)
+}
+
+// tracker holds no lock of its own, the store makes every change a compare and swap so replicas can share it
+type tracker struct {
+	store Store
+}
+
+func NewTracker(store Store) Tracker {
+	return &tracker{store: store}
+}
+
+// HashRecipient is the only form in which recipients are kept in message statuses
+func HashRecipient(address string) string {
+	hash := sha256.Sum256([]byte(strings.ToLower(strings.TrimSpace(address))))
+	return hex.EncodeToString(hash[:])
+}
+
+func (tracker *tracker) Accept(ctx *gin.Context, id string, clientID string, from string, recipients []string) {
+	logger := logging.GetLogger(ctx).WithField("class", "StatusTracker").WithField("method", "Accept")
+	now := time.Now()
+	recipientHashes := make([]string, 0, len(recipients))
+	for _, recipient := range recipients {
+		recipientHashes = append(recipientHashes, HashRecipient(recipient))
+	}
+	status := models.MessageStatus{
+		ID:              id,
+		ClientID:        clientID,
+		From:            from,
+		RecipientHashes: recipientHashes,
+		State:           models.Accepted,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+		History:         []models.StateTransition{{State: models.Accepted, At: now}},
+	}
+
+	if err := tracker.store.Save(status); err != nil {
+		logger.Errorf("Failed to record status of message %s, error: %s", id, err)
+	}
+}
+
+// Update records the transition on a message accepted earlier, unless the message already got further, e.g. when
+// the delivered event of the provider arrived before the outbox recorded the message as sent.
+// A failure to record is logged and never fails the delivery itself
+func (tracker *tracker) Update(ctx *gin.Context, id string, state models.MessageState, cause error) *golaerror.Error {
+	logger := logging.GetLogger(ctx).WithField("class", "StatusTracker").WithField("method", "Update")
+	var current models.MessageState
+	found, err := tracker.store.Update(id, func(status *models.MessageStatus) bool {
+		current = status.State
+		if !state.Supersedes(status.State) {
+			return false
+		}
+		now := time.Now()
+		transition := models.StateTransition{State: state, At: now}
+		if cause != nil {
+			transition.Error = cause.Error()
+			status.LastError = cause.Error()
+		}
+		if state == models.Sending {
+			status.Attempts++
+		}
+		status.State = state
+		status.UpdatedAt = now
+		status.History = append(status.History, transition)
+		return true
+	})
+	if err != nil {
+		logger.Errorf("Failed to record %s status of message %s, error: %s", state, id, err)
+		return &constants.InternalServerError
+	}
+	if !found {
+		logger.Warnf("Status %s received for message %s that is not tracked", state, id)
+		return &constants.MessageNotFoundError
+	}
+	if !state.Supersedes(current) {
+		logger.Infof("Ignoring %s status of message %s that is already %s", state, id, current)
+	}
+	return nil
+}
+
+// RecordOutcome records what happened to one recipient. The message takes the furthest state of its recipients once
+// each of them has an outcome, a later event never takes a recipient back to an earlier state
+func (tracker *tracker) RecordOutcome(ctx *gin.Context, id string, recipient string, state models.MessageState, cause error, at time.Time) *golaerror.Error {
+	logger := logging.GetLogger(ctx).WithField("class", "StatusTracker").WithField("method", "RecordOutcome")
+	outcome := models.RecipientOutcome{RecipientHash: HashRecipient(recipient), State: state, At: at}
+	if cause != nil {
+		outcome.Error = cause.Error()
+	}
+	found, err := tracker.store.Update(id, func(status *models.MessageStatus) bool {
+		if !recordOutcome(status, outcome) {
+			return false
+		}
+		if cause != nil {
+			status.LastError = cause.Error()
+		}
+		status.UpdatedAt = time.Now()
+		if messageState, complete := outcomeOfAllRecipients(*status); complete && messageState != status.State && messageState.Supersedes(status.State) {
+			status.State = messageState
+			status.History = append(status.History, models.StateTransition{State: messageState, At: at, Error: outcome.Error})
+		}
+		return true
+	})
+	if err != nil {
+		logger.Errorf("Failed to record %s outcome of message %s, error: %s", state, id, err)
+		return &constants.InternalServerError
+	}
+	if !found {
+		logger.Warnf("Outcome %s received for message %s that is not tracked", state, id)
+		return &constants.MessageNotFoundError
+	}
+	return nil
+}
+
+// RecordEngagement counts an open or click without changing the state, engagement of messages we do not track is ignored
+func (tracker *tracker) RecordEngagement(ctx *gin.Context, id string, eventType models.DeliveryEventType, at time.Time) {
+	logger := logging.GetLogger(ctx).WithField("class", "StatusTracker").WithField("method", "RecordEngagement")
+	found, err := tracker.store.Update(id, func(status *models.MessageStatus) bool {
+		if status.Engagement == nil {
+			status.Engagement = &models.Engagement{}
+		}
+		switch eventType {
+		case models.OpenedEvent:
+			status.Engagement.Opens++
+			if status.Engagement.FirstOpenedAt == nil {
+				status.Engagement.FirstOpenedAt = &at
+			}
+		case models.ClickedEvent:
+			status.Engagement.Clicks++
+			if status.Engagement.FirstClickedAt == nil {
+				status.Engagement.FirstClickedAt = &at
+			}
+		default:
+			return false
+		}
+		return true
+	})
+	if err != nil {
+		logger.Errorf("Failed to record %s of message %s, error: %s", eventType, id, err)
+		return
+	}
+	if !found {
+		logger.Warnf("Engagement received for message %s that is not tracked", id)
+	}
+}
+
+// recordOutcome is false for a recipient the message was not sent to, or one whose outcome already got further
+func recordOutcome(status *models.MessageStatus, outcome models.RecipientOutcome) bool {
+	sentTo := false
+	for _, recipientHash := range status.RecipientHashes {
+		sentTo = sentTo || recipientHash == outcome.RecipientHash
+	}
+	if !sentTo {
+		return false
+	}
+	for index, recorded := range status.Outcomes {
+		if recorded.RecipientHash != outcome.RecipientHash {
+			continue
+		}
+		if recorded.State == outcome.State || !outcome.State.Supersedes(recorded.State) {
+			return false
+		}
+		status.Outcomes[index] = outcome
+		return true
+	}
+	status.Outcomes = append(status.Outcomes, outcome)
+	return true
+}
+
+func outcomeOfAllRecipients(status models.MessageStatus) (models.MessageState, bool) {
+	outcomes := map[string]models.MessageState{}
+	for _, outcome := range status.Outcomes {
+		outcomes[outcome.RecipientHash] = outcome.State
+	}
+	var furthest models.MessageState
+	for _, recipientHash := range status.RecipientHashes {
+		state, found := outcomes[recipientHash]
+		if !found {
+			return "", false
+		}
+		if furthest == "" || state.Supersedes(furthest) {
+			furthest = state
+		}
+	}
+	return furthest, furthest != ""
+}
+
+func (tracker *tracker) Get(ctx *gin.Context, id string) (models.MessageStatus, *golaerror.Error) {
+	logger := logging.GetLogger(ctx).WithField("class", "StatusTracker").WithField("method", "Get")
+	status, found, err := tracker.store.Get(id)
+	if err != nil {
+		logger.Errorf("Failed to read status of message %s, error: %s", id, err)
+		return models.MessageStatus{}, &constants.InternalServerError
+	}
+	if !found {
+		return models.MessageStatus{}, &constants.MessageNotFoundError
+	}
+	return status, nil
+}
+
+// GetForClient hides messages of other clients as if they did not exist
+func (tracker *tracker) GetForClient(ctx *gin.Context, clientID string, id string) (models.MessageStatus, *golaerror.Error) {
+	status, statusError := tracker.Get(ctx, id)
+	if statusError != nil {
+		return models.MessageStatus{}, statusError
+	}
+	if status.ClientID != clientID {
+		return models.MessageStatus{}, &constants.MessageNotFoundError
+	}
+	return status, nil
+}
+
+func (tracker *tracker) List(ctx *gin.Context, filter models.MessageStatusFilter) (models.MessageStatusPage, *golaerror.Error) {
+	logger := logging.GetLogger(ctx).WithField("class", "StatusTracker").WithField("method", "List")
+	page, err := tracker.store.List(filter)
+	if err != nil {
+		logger.Error("Failed to list message statuses ", err)
+		return models.MessageStatusPage{}, &constants.InternalServerError
+	}
+	return page, nil
+}
@@ -0,0 +1,210 @@
+package status
+
+import (
+	"ccg-api/constants"
+	"ccg-api/email/models"
+	"ccg-api/email/status/mocks"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/suite"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"testing"
+	"time"
+)
+
+type trackerTestSuite struct {
+	suite.Suite
+	context   *gin.Context
+	mockCtrl  *gomock.Controller
+	directory string
+	tracker   Tracker
+}
+
+func TestTrackerTestSuite(t *testing.T) {
+	suite.Run(t, new(trackerTestSuite))
+}
+
+func (suite *trackerTestSuite) SetupTest() {
+	suite.context, _ = gin.CreateTestContext(httptest.NewRecorder())
+	suite.context.Request, _ = http.NewRequest("GET", "/", nil)
+	suite.mockCtrl = gomock.NewController(suite.T())
+	suite.directory = path.Join(os.TempDir(), "ccg-status-tracker-unit-test-dir")
+	_ = os.RemoveAll(suite.directory)
+	store, _ := NewFileStore(suite.directory)
+	suite.tracker = NewTracker(store)
+}
+
+func (suite *trackerTestSuite) TearDownTest() {
+	suite.mockCtrl.Finish()
+	_ = os.RemoveAll(suite.directory)
+}
+
+func (suite *trackerTestSuite) TestAccept_ShouldRecordAcceptedStatusWithHashedRecipients() {
+	suite.tracker.Accept(suite.context, "message-1", "some-client", "gola@gola.xyz", []string{"Someone@Gmail.com "})
+
+	status, err := suite.tracker.Get(suite.context, "message-1")
+
+	suite.Nil(err)
+	suite.Equal(models.Accepted, status.State)
+	suite.Equal("gola@gola.xyz", status.From)
+	suite.Equal([]string{HashRecipient("someone@gmail.com")}, status.RecipientHashes)
+	suite.Len(status.History, 1)
+	suite.False(status.CreatedAt.IsZero())
+}
+
+func (suite *trackerTestSuite) TestUpdate_ShouldRecordLifecycleWithAttemptsAndLastError() {
+	suite.tracker.Accept(suite.context, "message-1", "some-client", "gola@gola.xyz", []string{"someone@gmail.com"})
+	suite.tracker.Update(suite.context, "message-1", models.Sending, nil)
+	suite.tracker.Update(suite.context, "message-1", models.Retrying, errors.New("421 try again later"))
+	suite.tracker.Update(suite.context, "message-1", models.Sending, nil)
+	suite.tracker.Update(suite.context, "message-1", models.Sent, nil)
+
+	status, err := suite.tracker.Get(suite.context, "message-1")
+
+	suite.Nil(err)
+	suite.Equal(models.Sent, status.State)
+	suite.Equal(2, status.Attempts)
+	suite.Equal("421 try again later", status.LastError)
+	var states []models.MessageState
+	for _, transition := range status.History {
+		states = append(states, transition.State)
+	}
+	suite.Equal([]models.MessageState{models.Accepted, models.Sending, models.Retrying, models.Sending, models.Sent}, states)
+	suite.Equal("421 try again later", status.History[2].Error)
+}
+
+func (suite *trackerTestSuite) TestRecordEngagement_ShouldCountOpensAndClicksWithoutChangingState() {
+	suite.tracker.Accept(suite.context, "message-1", "some-client", "gola@gola.xyz", []string{"someone@gmail.com"})
+	suite.tracker.Update(suite.context, "message-1", models.Sent, nil)
+	firstOpen := time.Date(2022, 1, 3, 9, 0, 0, 0, time.UTC)
+	suite.tracker.RecordEngagement(suite.context, "message-1", models.OpenedEvent, firstOpen)
+	suite.tracker.RecordEngagement(suite.context, "message-1", models.OpenedEvent, firstOpen.Add(time.Hour))
+	suite.tracker.RecordEngagement(suite.context, "message-1", models.ClickedEvent, firstOpen.Add(time.Minute))
+
+	status, err := suite.tracker.Get(suite.context, "message-1")
+
+	suite.Nil(err)
+	suite.Equal(models.Sent, status.State)
+	suite.Equal(2, status.Engagement.Opens)
+	suite.Equal(1, status.Engagement.Clicks)
+	suite.True(firstOpen.Equal(*status.Engagement.FirstOpenedAt))
+	suite.True(firstOpen.Add(time.Minute).Equal(*status.Engagement.FirstClickedAt))
+}
+
+func (suite *trackerTestSuite) TestUpdate_ShouldReturnNotFoundForUnknownMessage() {
+	err := suite.tracker.Update(suite.context, "unknown-message", models.Delivered, nil)
+
+	suite.Equal(&constants.MessageNotFoundError, err)
+	_, err = suite.tracker.Get(suite.context, "unknown-message")
+	suite.Equal(&constants.MessageNotFoundError, err)
+}
+
+func (suite *trackerTestSuite) TestUpdate_ShouldNotTakeMessageBackWhenDeliveredArrivesBeforeSent() {
+	suite.tracker.Accept(suite.context, "message-1", "some-client", "gola@gola.xyz", []string{"someone@gmail.com"})
+	suite.tracker.Update(suite.context, "message-1", models.Sending, nil)
+	suite.tracker.Update(suite.context, "message-1", models.Delivered, nil)
+
+	suite.Nil(suite.tracker.Update(suite.context, "message-1", models.Sent, nil))
+
+	status, _ := suite.tracker.Get(suite.context, "message-1")
+	suite.Equal(models.Delivered, status.State)
+	suite.Len(status.History, 3)
+}
+
+func (suite *trackerTestSuite) TestRecordOutcome_ShouldKeepMessageStateUntilEveryRecipientHasAnOutcome() {
+	suite.tracker.Accept(suite.context, "message-1", "some-client", "gola@gola.xyz", []string{"someone@gmail.com", "missing@gmail.com"})
+	suite.tracker.Update(suite.context, "message-1", models.Sent, nil)
+	deliveredAt := time.Now()
+
+	suite.Nil(suite.tracker.RecordOutcome(suite.context, "message-1", "someone@gmail.com", models.Delivered, nil, deliveredAt))
+
+	status, _ := suite.tracker.Get(suite.context, "message-1")
+	suite.Equal(models.Sent, status.State)
+	suite.Len(status.Outcomes, 1)
+	suite.Equal(HashRecipient("someone@gmail.com"), status.Outcomes[0].RecipientHash)
+	suite.Equal(models.Delivered, status.Outcomes[0].State)
+
+	suite.Nil(suite.tracker.RecordOutcome(suite.context, "message-1", "Missing@gmail.com", models.Bounced, errors.New("550 user unknown"), deliveredAt.Add(time.Minute)))
+
+	status, _ = suite.tracker.Get(suite.context, "message-1")
+	suite.Equal(models.Bounced, status.State)
+	suite.Equal("550 user unknown", status.LastError)
+	suite.Equal(models.Delivered, status.Outcomes[0].State)
+	suite.Equal(models.Bounced, status.Outcomes[1].State)
+}
+
+func (suite *trackerTestSuite) TestRecordOutcome_ShouldNotTakeRecipientBackWhenDeliveredArrivesAfterBounce() {
+	suite.tracker.Accept(suite.context, "message-1", "some-client", "gola@gola.xyz", []string{"missing@gmail.com"})
+	suite.tracker.Update(suite.context, "message-1", models.Sent, nil)
+	suite.tracker.RecordOutcome(suite.context, "message-1", "missing@gmail.com", models.Bounced, errors.New("550 user unknown"), time.Now())
+
+	suite.Nil(suite.tracker.RecordOutcome(suite.context, "message-1", "missing@gmail.com", models.Delivered, nil, time.Now().Add(-time.Minute)))
+
+	status, _ := suite.tracker.Get(suite.context, "message-1")
+	suite.Equal(models.Bounced, status.State)
+	suite.Equal([]models.RecipientOutcome{{
+		RecipientHash: HashRecipient("missing@gmail.com"),
+		State:         models.Bounced,
+		At:            status.Outcomes[0].At,
+		Error:         "550 user unknown",
+	}}, status.Outcomes)
+}
+
+func (suite *trackerTestSuite) TestRecordOutcome_ShouldIgnoreRecipientMessageWasNotSentTo() {
+	suite.tracker.Accept(suite.context, "message-1", "some-client", "gola@gola.xyz", []string{"someone@gmail.com"})
+
+	suite.Nil(suite.tracker.RecordOutcome(suite.context, "message-1", "stranger@gmail.com", models.Bounced, nil, time.Now()))
+
+	status, _ := suite.tracker.Get(suite.context, "message-1")
+	suite.Equal(models.Accepted, status.State)
+	suite.Empty(status.Outcomes)
+}
+
+func (suite *trackerTestSuite) TestRecordEngagement_ShouldIgnoreUnknownMessage() {
+	suite.tracker.RecordEngagement(suite.context, "unknown-message", models.OpenedEvent, time.Now())
+
+	_, err := suite.tracker.Get(suite.context, "unknown-message")
+
+	suite.Equal(&constants.MessageNotFoundError, err)
+}
+
+func (suite *trackerTestSuite) TestGet_ShouldReturnNotFoundForUnknownMessage() {
+	_, err := suite.tracker.Get(suite.context, "unknown-message")
+
+	suite.Equal(&constants.MessageNotFoundError, err)
+}
+
+func (suite *trackerTestSuite) TestGet_ShouldReturnInternalServerErrorIfStoreFails() {
+	store := mocks.NewMockStore(suite.mockCtrl)
+	store.EXPECT().Get("message-1").Return(models.MessageStatus{}, false, errors.New("disk failure"))
+
+	_, err := NewTracker(store).Get(suite.context, "message-1")
+
+	suite.Equal(&constants.InternalServerError, err)
+}
+
+func (suite *trackerTestSuite) TestList_ShouldReturnStatusesMatchingFilter() {
+	suite.tracker.Accept(suite.context, "message-1", "some-client", "gola@gola.xyz", []string{"someone@gmail.com"})
+	suite.tracker.Accept(suite.context, "message-2", "some-client", "gola@gola.xyz", []string{"other@gmail.com"})
+
+	page, err := suite.tracker.List(suite.context, models.MessageStatusFilter{ClientID: "some-client", RecipientHash: HashRecipient("other@gmail.com")})
+
+	suite.Nil(err)
+	suite.Len(page.Statuses, 1)
+	suite.Equal("message-2", page.Statuses[0].ID)
+}
+
+func (suite *trackerTestSuite) TestGetForClient_ShouldHideMessagesOfOtherClients() {
+	suite.tracker.Accept(suite.context, "message-1", "some-client", "gola@gola.xyz", []string{"someone@gmail.com"})
+
+	status, err := suite.tracker.GetForClient(suite.context, "some-client", "message-1")
+	suite.Nil(err)
+	suite.Equal("some-client", status.ClientID)
+
+	_, err = suite.tracker.GetForClient(suite.context, "other-client", "message-1")
+	suite.Equal(&constants.MessageNotFoundError, err)
+}
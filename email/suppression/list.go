@@ -0,0 +1,136 @@
+package suppression
+
+// mockgen -source=email/suppression/list.go -destination=email/suppression/mocks/mock_list.go -package=mocks
+import (
+	"ccg-api/constants"
+	"ccg-api/email/models"
+	"github.com/gin-gonic/gin"
+	"github.com/inclusi-blog/gola-utils/golaerror"
+	"github.com/inclusi-blog/gola-utils/logging"
+	"github.com/inclusi-blog/gola-utils/mask_util"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	ApiSource       = "api"
+	CsvImportSource = "csv_import"
+	BounceSource    = "bounce"
+)
+
+type List interface {
+	Add(ctx *gin.Context, suppression models.Suppression) (models.Suppression, *golaerror.Error)
+	Remove(ctx *gin.Context, address string) *golaerror.Error
+	Get(ctx *gin.Context, address string) (models.Suppression, *golaerror.Error)
+	All(ctx *gin.Context) ([]models.Suppression, *golaerror.Error)
+	Check(ctx *gin.Context, recipients []string) []models.SuppressedRecipient
+}
+
+type list struct {
+	store Store
+	mutex sync.Mutex
+}
+
+func NewList(store Store) List {
+	return &list{store: store}
+}
+
+// Add keeps the original entry when the address is already suppressed, so that its first reason and source are not lost
+func (list *list) Add(ctx *gin.Context, suppression models.Suppression) (models.Suppression, *golaerror.Error) {
+	logger := logging.GetLogger(ctx).WithField("class", "SuppressionList").WithField("method", "Add")
+	suppression.Address = models.NormalizeSuppressionAddress(suppression.Address)
+	list.mutex.Lock()
+	defer list.mutex.Unlock()
+
+	existing, found, err := list.store.Get(suppression.Address)
+	if err != nil {
+		logger.Errorf("Failed to read suppression of %s, error: %s", maskAddress(ctx, suppression.Address), err)
+		return models.Suppression{}, &constants.InternalServerError
+	}
+	if found {
+		return existing, nil
+	}
+	if suppression.CreatedAt.IsZero() {
+		suppression.CreatedAt = time.Now()
+	}
+	created, err := list.store.Create(suppression)
+	if err != nil {
+		logger.Errorf("Failed to save suppression of %s, error: %s", maskAddress(ctx, suppression.Address), err)
+		return models.Suppression{}, &constants.InternalServerError
+	}
+	if !created {
+		return list.Get(ctx, suppression.Address)
+	}
+	logger.Infof("Suppressed %s for %s from %s", maskAddress(ctx, suppression.Address), suppression.Reason, suppression.Source)
+	return suppression, nil
+}
+
+func (list *list) Remove(ctx *gin.Context, address string) *golaerror.Error {
+	logger := logging.GetLogger(ctx).WithField("class", "SuppressionList").WithField("method", "Remove")
+	list.mutex.Lock()
+	defer list.mutex.Unlock()
+
+	removed, err := list.store.Delete(models.NormalizeSuppressionAddress(address))
+	if err != nil {
+		logger.Errorf("Failed to remove suppression of %s, error: %s", maskAddress(ctx, address), err)
+		return &constants.InternalServerError
+	}
+	if !removed {
+		return &constants.SuppressionNotFoundError
+	}
+	return nil
+}
+
+func (list *list) Get(ctx *gin.Context, address string) (models.Suppression, *golaerror.Error) {
+	logger := logging.GetLogger(ctx).WithField("class", "SuppressionList").WithField("method", "Get")
+	suppression, found, err := list.store.Get(models.NormalizeSuppressionAddress(address))
+	if err != nil {
+		logger.Errorf("Failed to read suppression of %s, error: %s", maskAddress(ctx, address), err)
+		return models.Suppression{}, &constants.InternalServerError
+	}
+	if !found {
+		return models.Suppression{}, &constants.SuppressionNotFoundError
+	}
+	return suppression, nil
+}
+
+func (list *list) All(ctx *gin.Context) ([]models.Suppression, *golaerror.Error) {
+	logger := logging.GetLogger(ctx).WithField("class", "SuppressionList").WithField("method", "All")
+	suppressions, err := list.store.List()
+	if err != nil {
+		logger.Error("Failed to list suppressions ", err)
+		return nil, &constants.InternalServerError
+	}
+	return suppressions, nil
+}
+
+// Check matches every recipient against both its own address and its domain.
+// A recipient whose suppression cannot be read is let through, since a store failure must not stop all mail.
+func (list *list) Check(ctx *gin.Context, recipients []string) []models.SuppressedRecipient {
+	logger := logging.GetLogger(ctx).WithField("class", "SuppressionList").WithField("method", "Check")
+	var suppressed []models.SuppressedRecipient
+	for _, recipient := range recipients {
+		address := models.NormalizeSuppressionAddress(recipient)
+		for _, key := range []string{address, address[strings.LastIndex(address, "@")+1:]} {
+			suppression, found, err := list.store.Get(key)
+			if err != nil {
+				logger.Errorf("Failed to check suppression of %s, error: %s", mask_util.MaskEmail(ctx, recipient), err)
+				continue
+			}
+			if found {
+				suppressed = append(suppressed, models.SuppressedRecipient{Address: recipient, Reason: suppression.Reason})
+				break
+			}
+		}
+	}
+	return suppressed
+}
+
+// maskAddress masks email addresses only, domains are not personal data and MaskEmail cannot handle them
+func maskAddress(ctx *gin.Context, address string) string {
+	if !strings.Contains(address, "@") {
+		return address
+	}
+	return mask_util.MaskEmail(ctx, address)
+}
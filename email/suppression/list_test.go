@@ -0,0 +1,117 @@
+package suppression
+
+import (
+	"ccg-api/constants"
+	"ccg-api/email/models"
+	"ccg-api/email/suppression/mocks"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/suite"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"testing"
+)
+
+type listTestSuite struct {
+	suite.Suite
+	context   *gin.Context
+	mockCtrl  *gomock.Controller
+	directory string
+	list      List
+}
+
+func TestListTestSuite(t *testing.T) {
+	suite.Run(t, new(listTestSuite))
+}
+
+func (suite *listTestSuite) SetupTest() {
+	suite.context, _ = gin.CreateTestContext(httptest.NewRecorder())
+	suite.context.Request, _ = http.NewRequest("GET", "/", nil)
+	suite.mockCtrl = gomock.NewController(suite.T())
+	suite.directory = path.Join(os.TempDir(), "ccg-suppression-list-unit-test-dir")
+	_ = os.RemoveAll(suite.directory)
+	store, _ := NewFileStore(suite.directory)
+	suite.list = NewList(store)
+}
+
+func (suite *listTestSuite) TearDownTest() {
+	suite.mockCtrl.Finish()
+	_ = os.RemoveAll(suite.directory)
+}
+
+func (suite *listTestSuite) TestAdd_ShouldNormalizeAddressAndStampCreationTime() {
+	suppression, err := suite.list.Add(suite.context, models.Suppression{Address: " Bounced@Gmail.com", Reason: models.HardBounce, Source: ApiSource})
+
+	suite.Nil(err)
+	suite.Equal("bounced@gmail.com", suppression.Address)
+	suite.False(suppression.CreatedAt.IsZero())
+	stored, err := suite.list.Get(suite.context, "BOUNCED@gmail.com")
+	suite.Nil(err)
+	suite.Equal(models.HardBounce, stored.Reason)
+}
+
+func (suite *listTestSuite) TestAdd_ShouldKeepOriginalEntryWhenAddressIsAlreadySuppressed() {
+	original, _ := suite.list.Add(suite.context, models.Suppression{Address: "bounced@gmail.com", Reason: models.HardBounce, Source: ApiSource})
+
+	suppression, err := suite.list.Add(suite.context, models.Suppression{Address: "bounced@gmail.com", Reason: models.Manual, Source: CsvImportSource})
+
+	suite.Nil(err)
+	suite.Equal(original.Reason, suppression.Reason)
+	suite.Equal(original.Source, suppression.Source)
+	suite.True(original.CreatedAt.Equal(suppression.CreatedAt))
+}
+
+func (suite *listTestSuite) TestRemove_ShouldReturnNotFoundForAddressThatIsNotSuppressed() {
+	_, _ = suite.list.Add(suite.context, models.Suppression{Address: "bounced@gmail.com", Reason: models.HardBounce})
+
+	suite.Nil(suite.list.Remove(suite.context, "bounced@gmail.com"))
+	suite.Equal(&constants.SuppressionNotFoundError, suite.list.Remove(suite.context, "bounced@gmail.com"))
+}
+
+func (suite *listTestSuite) TestCheck_ShouldMatchAddressesAndWholeDomains() {
+	_, _ = suite.list.Add(suite.context, models.Suppression{Address: "bounced@gmail.com", Reason: models.HardBounce})
+	_, _ = suite.list.Add(suite.context, models.Suppression{Address: "blocked.com", Reason: models.Manual})
+
+	suppressed := suite.list.Check(suite.context, []string{"Bounced@Gmail.com", "someone@gmail.com", "anyone@blocked.com"})
+
+	suite.Equal([]models.SuppressedRecipient{
+		{Address: "Bounced@Gmail.com", Reason: models.HardBounce},
+		{Address: "anyone@blocked.com", Reason: models.Manual},
+	}, suppressed)
+}
+
+func (suite *listTestSuite) TestCheck_ShouldLetRecipientThroughWhenStoreCannotBeRead() {
+	store := mocks.NewMockStore(suite.mockCtrl)
+	store.EXPECT().Get(gomock.Any()).Return(models.Suppression{}, false, errors.New("disk failure")).Times(2)
+
+	suppressed := NewList(store).Check(suite.context, []string{"someone@gmail.com"})
+
+	suite.Empty(suppressed)
+}
+
+func (suite *listTestSuite) TestAll_ShouldReturnInternalServerErrorWhenStoreCannotBeRead() {
+	store := mocks.NewMockStore(suite.mockCtrl)
+	store.EXPECT().List().Return(nil, errors.New("disk failure"))
+
+	_, err := NewList(store).All(suite.context)
+
+	suite.Equal(&constants.InternalServerError, err)
+}
+
+func (suite *listTestSuite) TestAdd_ShouldReturnEntryOfAnotherReplicaThatSuppressedAddressFirst() {
+	store := mocks.NewMockStore(suite.mockCtrl)
+	original := models.Suppression{Address: "bounced@gmail.com", Reason: models.HardBounce, Source: BounceSource}
+	gomock.InOrder(
+		store.EXPECT().Get("bounced@gmail.com").Return(models.Suppression{}, false, nil),
+		store.EXPECT().Create(gomock.Any()).Return(false, nil),
+		store.EXPECT().Get("bounced@gmail.com").Return(original, true, nil),
+	)
+
+	suppression, err := NewList(store).Add(suite.context, models.Suppression{Address: "bounced@gmail.com", Reason: models.Manual, Source: ApiSource})
+
+	suite.Nil(err)
+	suite.Equal(original, suppression)
+}
@@ -0,0 +1,109 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: email/suppression/list.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	models "ccg-api/email/models"
+	gin "github.com/gin-gonic/gin"
+	gomock "github.com/golang/mock/gomock"
+	golaerror "github.com/inclusi-blog/gola-utils/golaerror"
+	reflect "reflect"
+)
+
+// MockList is a mock of List interface
+type MockList struct {
+	ctrl     *gomock.Controller
+	recorder *MockListMockRecorder
+}
+
+// MockListMockRecorder is the mock recorder for MockList
+type MockListMockRecorder struct {
+	mock *MockList
+}
+
+// NewMockList creates a new mock instance
+func NewMockList(ctrl *gomock.Controller) *MockList {
+	mock := &MockList{ctrl: ctrl}
+	mock.recorder = &MockListMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockList) EXPECT() *MockListMockRecorder {
+	return m.recorder
+}
+
+// Add mocks base method
+func (m *MockList) Add(ctx *gin.Context, suppression models.Suppression) (models.Suppression, *golaerror.Error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Add", ctx, suppression)
+	ret0, _ := ret[0].(models.Suppression)
+	ret1, _ := ret[1].(*golaerror.Error)
+	return ret0, ret1
+}
+
+// Add indicates an expected call of Add
+func (mr *MockListMockRecorder) Add(ctx, suppression interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Add", reflect.TypeOf((*MockList)(nil).Add), ctx, suppression)
+}
+
+// Remove mocks base method
+func (m *MockList) Remove(ctx *gin.Context, address string) *golaerror.Error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Remove", ctx, address)
+	ret0, _ := ret[0].(*golaerror.Error)
+	return ret0
+}
+
+// Remove indicates an expected call of Remove
+func (mr *MockListMockRecorder) Remove(ctx, address interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Remove", reflect.TypeOf((*MockList)(nil).Remove), ctx, address)
+}
+
+// Get mocks base method
+func (m *MockList) Get(ctx *gin.Context, address string) (models.Suppression, *golaerror.Error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", ctx, address)
+	ret0, _ := ret[0].(models.Suppression)
+	ret1, _ := ret[1].(*golaerror.Error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get
+func (mr *MockListMockRecorder) Get(ctx, address interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockList)(nil).Get), ctx, address)
+}
+
+// All mocks base method
+func (m *MockList) All(ctx *gin.Context) ([]models.Suppression, *golaerror.Error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "All", ctx)
+	ret0, _ := ret[0].([]models.Suppression)
+	ret1, _ := ret[1].(*golaerror.Error)
+	return ret0, ret1
+}
+
+// All indicates an expected call of All
+func (mr *MockListMockRecorder) All(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "All", reflect.TypeOf((*MockList)(nil).All), ctx)
+}
+
+// Check mocks base method
+func (m *MockList) Check(ctx *gin.Context, recipients []string) []models.SuppressedRecipient {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Check", ctx, recipients)
+	ret0, _ := ret[0].([]models.SuppressedRecipient)
+	return ret0
+}
+
+// Check indicates an expected call of Check
+func (mr *MockListMockRecorder) Check(ctx, recipients interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Check", reflect.TypeOf((*MockList)(nil).Check), ctx, recipients)
+}
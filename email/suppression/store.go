@@ -0,0 +1,107 @@
+package suppression
+
+// mockgen -source=email/suppression/store.go -destination=email/suppression/mocks/mock_store.go -package=mocks
+import (
+	"ccg-api/email/models"
+	"ccg-api/util"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path"
+	"sort"
+	"strings"
+)
+
+const suppressionFileExtension = ".json"
+
+type Store interface {
+	Save(suppression models.Suppression) error
+	Create(suppression models.Suppression) (bool, error)
+	Get(address string) (models.Suppression, bool, error)
+	Delete(address string) (bool, error)
+	List() ([]models.Suppression, error)
+}
+
+type fileStore struct {
+	directory string
+}
+
+func NewFileStore(directory string) (Store, error) {
+	if err := os.MkdirAll(directory, 0755); err != nil {
+		return nil, err
+	}
+	return fileStore{directory: directory}, nil
+}
+
+func (store fileStore) Save(suppression models.Suppression) error {
+	data, err := json.Marshal(suppression)
+	if err != nil {
+		return err
+	}
+	return util.WriteFileAtomically(store.suppressionPath(suppression.Address), data)
+}
+
+// Create saves the suppression only when the address has none yet, reporting false when another
+// writer got there first
+func (store fileStore) Create(suppression models.Suppression) (bool, error) {
+	data, err := json.Marshal(suppression)
+	if err != nil {
+		return false, err
+	}
+	return util.CreateFileAtomically(store.suppressionPath(suppression.Address), data)
+}
+
+func (store fileStore) Get(address string) (models.Suppression, bool, error) {
+	data, err := ioutil.ReadFile(store.suppressionPath(address))
+	if os.IsNotExist(err) {
+		return models.Suppression{}, false, nil
+	}
+	if err != nil {
+		return models.Suppression{}, false, err
+	}
+	var suppression models.Suppression
+	if err := json.Unmarshal(data, &suppression); err != nil {
+		return models.Suppression{}, false, err
+	}
+	return suppression, true, nil
+}
+
+func (store fileStore) Delete(address string) (bool, error) {
+	err := os.Remove(store.suppressionPath(address))
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// List returns every suppression, most recently created first
+func (store fileStore) List() ([]models.Suppression, error) {
+	files, err := ioutil.ReadDir(store.directory)
+	if err != nil {
+		return nil, err
+	}
+
+	suppressions := []models.Suppression{}
+	for _, file := range files {
+		if file.IsDir() || !strings.HasSuffix(file.Name(), suppressionFileExtension) {
+			continue
+		}
+		suppression, found, err := store.Get(strings.TrimSuffix(file.Name(), suppressionFileExtension))
+		if err != nil {
+			return nil, err
+		}
+		if found {
+			suppressions = append(suppressions, suppression)
+		}
+	}
+
+	sort.Slice(suppressions, func(i, j int) bool {
+		return suppressions[i].CreatedAt.After(suppressions[j].CreatedAt)
+	})
+	return suppressions, nil
+}
+
+// suppressionPath keeps lookups inside the store directory even for addresses taken straight from a URL
+func (store fileStore) suppressionPath(address string) string {
+	return path.Join(store.directory, path.Base(path.Clean("/"+address))+suppressionFileExtension)
+}
@@ -0,0 +1,97 @@
+package suppression
+
+import (
+	"ccg-api/email/models"
+	"github.com/stretchr/testify/suite"
+	"os"
+	"path"
+	"testing"
+	"time"
+)
+
+type fileStoreTestSuite struct {
+	suite.Suite
+	directory string
+	store     Store
+}
+
+func TestFileStoreTestSuite(t *testing.T) {
+	suite.Run(t, new(fileStoreTestSuite))
+}
+
+func (suite *fileStoreTestSuite) SetupTest() {
+	suite.directory = path.Join(os.TempDir(), "ccg-suppression-file-store-unit-test-dir")
+	_ = os.RemoveAll(suite.directory)
+	suite.store, _ = NewFileStore(suite.directory)
+}
+
+func (suite *fileStoreTestSuite) TearDownTest() {
+	_ = os.RemoveAll(suite.directory)
+}
+
+func (suite *fileStoreTestSuite) TestGet_ShouldReturnSavedSuppression() {
+	suppression := models.Suppression{
+		Address:   "bounced@gmail.com",
+		Reason:    models.HardBounce,
+		Source:    ApiSource,
+		CreatedAt: time.Date(2020, 9, 20, 10, 0, 0, 0, time.UTC),
+	}
+	suite.Nil(suite.store.Save(suppression))
+
+	actualSuppression, found, err := suite.store.Get("bounced@gmail.com")
+
+	suite.Nil(err)
+	suite.True(found)
+	suite.Equal(suppression, actualSuppression)
+}
+
+func (suite *fileStoreTestSuite) TestGet_ShouldNotFindUnknownAddress() {
+	_, found, err := suite.store.Get("someone@gmail.com")
+
+	suite.Nil(err)
+	suite.False(found)
+}
+
+func (suite *fileStoreTestSuite) TestGet_ShouldNotReadOutsideStoreDirectory() {
+	_, found, err := suite.store.Get("../../etc/passwd")
+
+	suite.Nil(err)
+	suite.False(found)
+}
+
+func (suite *fileStoreTestSuite) TestDelete_ShouldRemoveSuppressionAndReportWhetherItExisted() {
+	suite.Nil(suite.store.Save(models.Suppression{Address: "blocked.com", Reason: models.Manual}))
+
+	removed, err := suite.store.Delete("blocked.com")
+	suite.Nil(err)
+	suite.True(removed)
+
+	removed, err = suite.store.Delete("blocked.com")
+	suite.Nil(err)
+	suite.False(removed)
+}
+
+func (suite *fileStoreTestSuite) TestList_ShouldReturnMostRecentSuppressionFirst() {
+	createdAt := time.Date(2020, 9, 20, 10, 0, 0, 0, time.UTC)
+	suite.Nil(suite.store.Save(models.Suppression{Address: "older@gmail.com", Reason: models.Complaint, CreatedAt: createdAt}))
+	suite.Nil(suite.store.Save(models.Suppression{Address: "newer@gmail.com", Reason: models.HardBounce, CreatedAt: createdAt.Add(time.Hour)}))
+
+	suppressions, err := suite.store.List()
+
+	suite.Nil(err)
+	suite.Len(suppressions, 2)
+	suite.Equal("newer@gmail.com", suppressions[0].Address)
+	suite.Equal("older@gmail.com", suppressions[1].Address)
+}
+
+func (suite *fileStoreTestSuite) TestCreate_ShouldKeepSuppressionAlreadyWrittenByAnotherWriter() {
+	original := models.Suppression{Address: "bounced@gmail.com", Reason: models.HardBounce}
+	suite.Nil(suite.store.Save(original))
+
+	created, err := suite.store.Create(models.Suppression{Address: "bounced@gmail.com", Reason: models.Manual})
+
+	suite.Nil(err)
+	suite.False(created)
+	stored, _, _ := suite.store.Get("bounced@gmail.com")
+	suite.Equal(models.HardBounce, stored.Reason)
+}
@@ -0,0 +1,89 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: email/templates/registry.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	templates "ccg-api/email/templates"
+	gin "github.com/gin-gonic/gin"
+	gomock "github.com/golang/mock/gomock"
+	golaerror "github.com/inclusi-blog/gola-utils/golaerror"
+	io "io"
+	reflect "reflect"
+)
+
+// MockRegistry is a mock of Registry interface
+type MockRegistry struct {
+	ctrl     *gomock.Controller
+	recorder *MockRegistryMockRecorder
+}
+
+// MockRegistryMockRecorder is the mock recorder for MockRegistry
+type MockRegistryMockRecorder struct {
+	mock *MockRegistry
+}
+
+// NewMockRegistry creates a new mock instance
+func NewMockRegistry(ctrl *gomock.Controller) *MockRegistry {
+	mock := &MockRegistry{ctrl: ctrl}
+	mock.recorder = &MockRegistryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockRegistry) EXPECT() *MockRegistryMockRecorder {
+	return m.recorder
+}
+
+// Render mocks base method
+func (m *MockRegistry) Render(ctx *gin.Context, name string, variables map[string]interface{}) (templates.Rendered, *golaerror.Error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Render", ctx, name, variables)
+	ret0, _ := ret[0].(templates.Rendered)
+	ret1, _ := ret[1].(*golaerror.Error)
+	return ret0, ret1
+}
+
+// Render indicates an expected call of Render
+func (mr *MockRegistryMockRecorder) Render(ctx, name, variables interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Render", reflect.TypeOf((*MockRegistry)(nil).Render), ctx, name, variables)
+}
+
+// Mockexecutable is a mock of executable interface
+type Mockexecutable struct {
+	ctrl     *gomock.Controller
+	recorder *MockexecutableMockRecorder
+}
+
+// MockexecutableMockRecorder is the mock recorder for Mockexecutable
+type MockexecutableMockRecorder struct {
+	mock *Mockexecutable
+}
+
+// NewMockexecutable creates a new mock instance
+func NewMockexecutable(ctrl *gomock.Controller) *Mockexecutable {
+	mock := &Mockexecutable{ctrl: ctrl}
+	mock.recorder = &MockexecutableMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *Mockexecutable) EXPECT() *MockexecutableMockRecorder {
+	return m.recorder
+}
+
+// Execute mocks base method
+func (m *Mockexecutable) Execute(writer io.Writer, data interface{}) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Execute", writer, data)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Execute indicates an expected call of Execute
+func (mr *MockexecutableMockRecorder) Execute(writer, data interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Execute", reflect.TypeOf((*Mockexecutable)(nil).Execute), writer, data)
+}
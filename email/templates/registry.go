@@ -0,0 +1,153 @@
+package templates
+
+// mockgen -source=email/templates/registry.go -destination=email/templates/mocks/mock_registry.go -package=mocks
+import (
+	"bytes"
+	"ccg-api/constants"
+	"encoding/json"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/inclusi-blog/gola-utils/golaerror"
+	"github.com/inclusi-blog/gola-utils/logging"
+	htmlTemplate "html/template"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"sort"
+	textTemplate "text/template"
+)
+
+const (
+	manifestFileName = "manifest.json"
+	subjectFileName  = "subject.txt"
+	htmlFileName     = "body.html"
+	textFileName     = "body.txt"
+	missingKeyOption = "missingkey=error"
+)
+
+type Manifest struct {
+	Description         string   `json:"description"`
+	RequiredVariables   []string `json:"required_variables"`
+	IncludeBaseTemplate bool     `json:"include_base_template"`
+}
+
+type Rendered struct {
+	Subject             string
+	HTML                string
+	Text                string
+	IncludeBaseTemplate bool
+}
+
+type Registry interface {
+	Render(ctx *gin.Context, name string, variables map[string]interface{}) (Rendered, *golaerror.Error)
+}
+
+type namedTemplate struct {
+	manifest Manifest
+	subject  *textTemplate.Template
+	html     *htmlTemplate.Template
+	text     *textTemplate.Template
+}
+
+type registry struct {
+	templates map[string]namedTemplate
+}
+
+// LoadRegistry parses every sub directory of the given directory as a template named after the sub directory.
+// Each template needs a manifest.json, subject.txt and body.html; body.txt is optional
+func LoadRegistry(directory string) (Registry, error) {
+	entries, err := ioutil.ReadDir(directory)
+	if err != nil {
+		return nil, err
+	}
+	templates := map[string]namedTemplate{}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		template, err := loadTemplate(path.Join(directory, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("template %s: %w", entry.Name(), err)
+		}
+		templates[entry.Name()] = template
+	}
+	return registry{templates: templates}, nil
+}
+
+func loadTemplate(directory string) (namedTemplate, error) {
+	var template namedTemplate
+	manifest, err := ioutil.ReadFile(path.Join(directory, manifestFileName))
+	if err != nil {
+		return template, err
+	}
+	if err = json.Unmarshal(manifest, &template.manifest); err != nil {
+		return template, err
+	}
+	if template.subject, err = parseTextTemplate(path.Join(directory, subjectFileName)); err != nil {
+		return template, err
+	}
+	if template.html, err = htmlTemplate.New(htmlFileName).Option(missingKeyOption).ParseFiles(path.Join(directory, htmlFileName)); err != nil {
+		return template, err
+	}
+	textFilePath := path.Join(directory, textFileName)
+	if _, err = os.Stat(textFilePath); err == nil {
+		template.text, err = parseTextTemplate(textFilePath)
+	} else if os.IsNotExist(err) {
+		err = nil
+	}
+	return template, err
+}
+
+func parseTextTemplate(filePath string) (*textTemplate.Template, error) {
+	return textTemplate.New(path.Base(filePath)).Option(missingKeyOption).ParseFiles(filePath)
+}
+
+func (registry registry) Render(ctx *gin.Context, name string, variables map[string]interface{}) (Rendered, *golaerror.Error) {
+	logger := logging.GetLogger(ctx).WithField("class", "TemplateRegistry").WithField("method", "Render")
+	template, found := registry.templates[name]
+	if !found {
+		logger.Errorf("Template %s is not registered", name)
+		return Rendered{}, &constants.TemplateNotFoundError
+	}
+	if missingVariables := template.missingVariables(variables); len(missingVariables) > 0 {
+		logger.Errorf("Template %s is missing required variables %v", name, missingVariables)
+		missingVariablesError := golaerror.New(constants.MissingTemplateVariablesCode,
+			constants.MissingTemplateVariablesError.ErrorMessage, map[string][]string{"missing_variables": missingVariables})
+		return Rendered{}, &missingVariablesError
+	}
+
+	rendered := Rendered{IncludeBaseTemplate: template.manifest.IncludeBaseTemplate}
+	var err error
+	if rendered.Subject, err = execute(template.subject, variables); err == nil {
+		if rendered.HTML, err = execute(template.html, variables); err == nil && template.text != nil {
+			rendered.Text, err = execute(template.text, variables)
+		}
+	}
+	if err != nil {
+		logger.Errorf("Failed to render template %s, error: %s", name, err)
+		return Rendered{}, &constants.InternalServerError
+	}
+	return rendered, nil
+}
+
+func (template namedTemplate) missingVariables(variables map[string]interface{}) []string {
+	missingVariables := []string{}
+	for _, name := range template.manifest.RequiredVariables {
+		if value, found := variables[name]; !found || value == nil || value == "" {
+			missingVariables = append(missingVariables, name)
+		}
+	}
+	sort.Strings(missingVariables)
+	return missingVariables
+}
+
+type executable interface {
+	Execute(writer io.Writer, data interface{}) error
+}
+
+func execute(template executable, variables map[string]interface{}) (string, error) {
+	var buffer bytes.Buffer
+	err := template.Execute(&buffer, variables)
+	return buffer.String(), err
+}
@@ -0,0 +1,124 @@
+package templates
+
+import (
+	"ccg-api/constants"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/suite"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"testing"
+)
+
+type registryTestSuite struct {
+	suite.Suite
+	context   *gin.Context
+	directory string
+	registry  Registry
+}
+
+func TestRegistryTestSuite(t *testing.T) {
+	suite.Run(t, new(registryTestSuite))
+}
+
+func (suite *registryTestSuite) SetupTest() {
+	suite.context, _ = gin.CreateTestContext(httptest.NewRecorder())
+	suite.context.Request, _ = http.NewRequest("POST", "/", nil)
+	suite.directory = path.Join(os.TempDir(), "ccg-template-registry-unit-test-dir")
+	_ = os.RemoveAll(suite.directory)
+	var err error
+	suite.registry, err = LoadRegistry("../../email_templates/registry")
+	suite.Nil(err)
+}
+
+func (suite *registryTestSuite) TearDownTest() {
+	_ = os.RemoveAll(suite.directory)
+}
+
+func (suite *registryTestSuite) TestRender_ShouldRenderSubjectHtmlAndTextOfRegisteredTemplate() {
+	rendered, err := suite.registry.Render(suite.context, "password_reset", map[string]interface{}{
+		"name":              "Gola",
+		"reset_url":         "https://gola.xyz/reset?token=abc&user=1",
+		"expiry_in_minutes": 30,
+	})
+
+	suite.Nil(err)
+	suite.Equal("Reset your Narratenet password", rendered.Subject)
+	suite.Contains(rendered.HTML, `<a href="https://gola.xyz/reset?token=abc&amp;user=1">`)
+	suite.Contains(rendered.HTML, "expires in 30 minutes")
+	suite.Contains(rendered.Text, "Reset your password: https://gola.xyz/reset?token=abc&user=1")
+	suite.True(rendered.IncludeBaseTemplate)
+}
+
+func (suite *registryTestSuite) TestRender_ShouldEscapeVariablesInHtml() {
+	rendered, err := suite.registry.Render(suite.context, "welcome", map[string]interface{}{"name": "<script>alert(1)</script>"})
+
+	suite.Nil(err)
+	suite.NotContains(rendered.HTML, "<script>")
+	suite.Contains(rendered.HTML, "&lt;script&gt;")
+}
+
+func (suite *registryTestSuite) TestRender_ShouldReturnNotFoundForUnknownTemplate() {
+	_, err := suite.registry.Render(suite.context, "unknown", map[string]interface{}{})
+
+	suite.Equal(&constants.TemplateNotFoundError, err)
+}
+
+func (suite *registryTestSuite) TestRender_ShouldListEveryMissingRequiredVariable() {
+	_, err := suite.registry.Render(suite.context, "password_reset", map[string]interface{}{"name": "Gola", "reset_url": ""})
+
+	suite.NotNil(err)
+	suite.Equal(constants.MissingTemplateVariablesCode, err.ErrorCode)
+	suite.Equal(map[string][]string{"missing_variables": {"expiry_in_minutes", "reset_url"}}, err.AdditionalData)
+}
+
+func (suite *registryTestSuite) TestRender_ShouldLeaveTextEmptyForTemplateWithoutTextBody() {
+	suite.writeTemplate("receipt", `{"required_variables": ["amount"]}`, "Your receipt", "<p>{{ .amount }}</p>", "")
+	registry, loadError := LoadRegistry(suite.directory)
+	suite.Nil(loadError)
+
+	rendered, err := registry.Render(suite.context, "receipt", map[string]interface{}{"amount": "42"})
+
+	suite.Nil(err)
+	suite.Equal("<p>42</p>", rendered.HTML)
+	suite.Empty(rendered.Text)
+	suite.False(rendered.IncludeBaseTemplate)
+}
+
+func (suite *registryTestSuite) TestRender_ShouldFailForVariableUsedButNotDeclaredAsRequired() {
+	suite.writeTemplate("receipt", `{}`, "Your receipt", "<p>{{ .amount }}</p>", "")
+	registry, _ := LoadRegistry(suite.directory)
+
+	_, err := registry.Render(suite.context, "receipt", map[string]interface{}{})
+
+	suite.Equal(&constants.InternalServerError, err)
+}
+
+func (suite *registryTestSuite) TestLoadRegistry_ShouldFailForTemplateWithInvalidSyntax() {
+	suite.writeTemplate("broken", `{}`, "Subject", "<p>{{ .amount </p>", "")
+
+	_, err := LoadRegistry(suite.directory)
+
+	suite.NotNil(err)
+}
+
+func (suite *registryTestSuite) TestLoadRegistry_ShouldFailForTemplateWithoutManifest() {
+	suite.writeTemplate("broken", "", "Subject", "<p>Hi</p>", "")
+
+	_, err := LoadRegistry(suite.directory)
+
+	suite.NotNil(err)
+}
+
+func (suite *registryTestSuite) writeTemplate(name string, manifest string, subject string, html string, text string) {
+	directory := path.Join(suite.directory, name)
+	_ = os.MkdirAll(directory, 0755)
+	files := map[string]string{manifestFileName: manifest, subjectFileName: subject, htmlFileName: html, textFileName: text}
+	for fileName, content := range files {
+		if content != "" {
+			_ = ioutil.WriteFile(path.Join(directory, fileName), []byte(content), 0644)
+		}
+	}
+}
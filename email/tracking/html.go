@@ -0,0 +1,69 @@
+package tracking
+
+import (
+	"html"
+	"regexp"
+	"strings"
+)
+
+const (
+	commentStart = "<!--"
+	commentEnd   = "-->"
+)
+
+var (
+	anchorHref = regexp.MustCompile(`(?i)(<a\b[^>]*?\bhref\s*=\s*)(["'])(https?://[^"']+)(["'])`)
+	bodyEnd    = regexp.MustCompile(`(?i)</body\s*>`)
+)
+
+// Instrument points every http(s) link of the HTML at the click url and adds the open pixel at the end of the body.
+// Comments are copied untouched, so that the conditional comments of the base template (e.g. <!--[if mso]>) keep working.
+// Links for which clickUrl returns "" are left as they are.
+func Instrument(content string, clickUrl func(link string) string, pixelUrl string) string {
+	var instrumented strings.Builder
+	lastBodyEnd := -1
+	for remaining := content; len(remaining) > 0; {
+		start := strings.Index(remaining, commentStart)
+		if start < 0 {
+			start = len(remaining)
+		}
+		markup := rewriteLinks(remaining[:start], clickUrl)
+		if matches := bodyEnd.FindAllStringIndex(markup, -1); len(matches) > 0 {
+			lastBodyEnd = instrumented.Len() + matches[len(matches)-1][0]
+		}
+		instrumented.WriteString(markup)
+		remaining = remaining[start:]
+		if len(remaining) == 0 {
+			break
+		}
+		end := strings.Index(remaining, commentEnd)
+		if end < 0 {
+			end = len(remaining)
+		} else {
+			end += len(commentEnd)
+		}
+		instrumented.WriteString(remaining[:end])
+		remaining = remaining[end:]
+	}
+
+	result := instrumented.String()
+	if pixelUrl == "" {
+		return result
+	}
+	pixel := `<img src="` + html.EscapeString(pixelUrl) + `" width="1" height="1" alt="" style="display:none;border:0" />`
+	if lastBodyEnd < 0 {
+		return result + pixel
+	}
+	return result[:lastBodyEnd] + pixel + result[lastBodyEnd:]
+}
+
+func rewriteLinks(markup string, clickUrl func(link string) string) string {
+	return anchorHref.ReplaceAllStringFunc(markup, func(anchor string) string {
+		parts := anchorHref.FindStringSubmatch(anchor)
+		trackedUrl := clickUrl(html.UnescapeString(parts[3]))
+		if trackedUrl == "" {
+			return anchor
+		}
+		return parts[1] + parts[2] + html.EscapeString(trackedUrl) + parts[4]
+	})
+}
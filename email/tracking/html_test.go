@@ -0,0 +1,48 @@
+package tracking
+
+import (
+	"github.com/stretchr/testify/suite"
+	"strings"
+	"testing"
+)
+
+type htmlTestSuite struct {
+	suite.Suite
+}
+
+func TestHtmlTestSuite(t *testing.T) {
+	suite.Run(t, new(htmlTestSuite))
+}
+
+func trackedUrl(link string) string {
+	if strings.Contains(link, "unsubscribe") {
+		return ""
+	}
+	return "https://ccg.gola.xyz/api/ccg/t/c/" + strings.TrimPrefix(link, "https://")
+}
+
+func (suite *htmlTestSuite) TestInstrument_ShouldRewriteLinksAndAddPixelBeforeBodyEnd() {
+	content := `<html><body><a class="cta" href="https://gola.xyz/posts?id=1&amp;ref=mail">Read</a>` +
+		`<a href='mailto:support@gola.xyz'>Mail us</a></body></html>`
+
+	instrumented := Instrument(content, trackedUrl, "https://ccg.gola.xyz/api/ccg/t/o/token")
+
+	suite.Equal(`<html><body><a class="cta" href="https://ccg.gola.xyz/api/ccg/t/c/gola.xyz/posts?id=1&amp;ref=mail">Read</a>`+
+		`<a href='mailto:support@gola.xyz'>Mail us</a>`+
+		`<img src="https://ccg.gola.xyz/api/ccg/t/o/token" width="1" height="1" alt="" style="display:none;border:0" /></body></html>`, instrumented)
+}
+
+func (suite *htmlTestSuite) TestInstrument_ShouldLeaveConditionalCommentsAndSkippedLinksUntouched() {
+	content := `<head><link href="https://fonts.googleapis.com/css" rel="stylesheet"><!--[if mso]><v:roundrect href="https://gola.xyz/mso"></v:roundrect><![endif]-->` +
+		`<!--[if !mso]><!--><a href="https://gola.xyz/unsubscribe">Unsubscribe</a><!-- <![endif]--></head>`
+
+	instrumented := Instrument(content, trackedUrl, "")
+
+	suite.Equal(content, instrumented)
+}
+
+func (suite *htmlTestSuite) TestInstrument_ShouldAppendPixelWhenThereIsNoBody() {
+	instrumented := Instrument(`<p>Hi</p>`, trackedUrl, "https://ccg.gola.xyz/api/ccg/t/o/token")
+
+	suite.True(strings.HasPrefix(instrumented, `<p>Hi</p><img src="https://ccg.gola.xyz/api/ccg/t/o/token"`))
+}
@@ -0,0 +1,67 @@
+package tracking
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+)
+
+// EventSource is the source of opens and clicks recorded by our own tracking links
+const EventSource = "tracking"
+
+var ErrInvalidToken = errors.New("tracking token is malformed or its signature does not match")
+
+// Claim is what a tracking token vouches for: the message it was issued for and, for clicks, the link to redirect to
+type Claim struct {
+	MessageID string `json:"m"`
+	Url       string `json:"u,omitempty"`
+}
+
+type Tokens interface {
+	Issue(claim Claim) string
+	Verify(token string) (Claim, error)
+}
+
+type tokens struct {
+	secret []byte
+}
+
+// NewTokens signs with HMAC-SHA256, so that click tokens cannot be crafted into an open redirect
+func NewTokens(secret string) Tokens {
+	return tokens{secret: []byte(secret)}
+}
+
+func (tokens tokens) Issue(claim Claim) string {
+	payload, _ := json.Marshal(claim)
+	encodedPayload := base64.RawURLEncoding.EncodeToString(payload)
+	return encodedPayload + "." + base64.RawURLEncoding.EncodeToString(tokens.sign(encodedPayload))
+}
+
+func (tokens tokens) Verify(token string) (Claim, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 2 {
+		return Claim{}, ErrInvalidToken
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil || !hmac.Equal(signature, tokens.sign(parts[0])) {
+		return Claim{}, ErrInvalidToken
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return Claim{}, ErrInvalidToken
+	}
+	var claim Claim
+	if err := json.Unmarshal(payload, &claim); err != nil || claim.MessageID == "" {
+		return Claim{}, ErrInvalidToken
+	}
+	return claim, nil
+}
+
+func (tokens tokens) sign(encodedPayload string) []byte {
+	mac := hmac.New(sha256.New, tokens.secret)
+	mac.Write([]byte(encodedPayload))
+	return mac.Sum(nil)
+}
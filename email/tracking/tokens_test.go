@@ -0,0 +1,43 @@
+package tracking
+
+import (
+	"github.com/stretchr/testify/suite"
+	"testing"
+)
+
+type tokensTestSuite struct {
+	suite.Suite
+	tokens Tokens
+}
+
+func TestTokensTestSuite(t *testing.T) {
+	suite.Run(t, new(tokensTestSuite))
+}
+
+func (suite *tokensTestSuite) SetupTest() {
+	suite.tokens = NewTokens("unit-test-secret")
+}
+
+func (suite *tokensTestSuite) TestVerify_ShouldReturnClaimOfIssuedToken() {
+	token := suite.tokens.Issue(Claim{MessageID: "message-id", Url: "https://gola.xyz/posts?id=1&ref=mail"})
+
+	claim, err := suite.tokens.Verify(token)
+
+	suite.Nil(err)
+	suite.Equal(Claim{MessageID: "message-id", Url: "https://gola.xyz/posts?id=1&ref=mail"}, claim)
+}
+
+func (suite *tokensTestSuite) TestVerify_ShouldRejectTokenWithTamperedUrl() {
+	token := suite.tokens.Issue(Claim{MessageID: "message-id", Url: "https://gola.xyz"})
+	tampered := NewTokens("other-secret").Issue(Claim{MessageID: "message-id", Url: "https://evil.example"})
+
+	_, err := suite.tokens.Verify(tampered[:len(tampered)-43] + token[len(token)-43:])
+
+	suite.Equal(ErrInvalidToken, err)
+}
+
+func (suite *tokensTestSuite) TestVerify_ShouldRejectMalformedToken() {
+	_, err := suite.tokens.Verify("not-a-token")
+
+	suite.Equal(ErrInvalidToken, err)
+}
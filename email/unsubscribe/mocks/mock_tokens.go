@@ -0,0 +1,63 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: email/unsubscribe/tokens.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	unsubscribe "ccg-api/email/unsubscribe"
+	gomock "github.com/golang/mock/gomock"
+	reflect "reflect"
+)
+
+// MockTokens is a mock of Tokens interface
+type MockTokens struct {
+	ctrl     *gomock.Controller
+	recorder *MockTokensMockRecorder
+}
+
+// MockTokensMockRecorder is the mock recorder for MockTokens
+type MockTokensMockRecorder struct {
+	mock *MockTokens
+}
+
+// NewMockTokens creates a new mock instance
+func NewMockTokens(ctrl *gomock.Controller) *MockTokens {
+	mock := &MockTokens{ctrl: ctrl}
+	mock.recorder = &MockTokensMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockTokens) EXPECT() *MockTokensMockRecorder {
+	return m.recorder
+}
+
+// Issue mocks base method
+func (m *MockTokens) Issue(recipient, category string) string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Issue", recipient, category)
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// Issue indicates an expected call of Issue
+func (mr *MockTokensMockRecorder) Issue(recipient, category interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Issue", reflect.TypeOf((*MockTokens)(nil).Issue), recipient, category)
+}
+
+// Verify mocks base method
+func (m *MockTokens) Verify(token string) (unsubscribe.Claim, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Verify", token)
+	ret0, _ := ret[0].(unsubscribe.Claim)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Verify indicates an expected call of Verify
+func (mr *MockTokensMockRecorder) Verify(token interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Verify", reflect.TypeOf((*MockTokens)(nil).Verify), token)
+}
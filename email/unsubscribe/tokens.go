@@ -0,0 +1,65 @@
+package unsubscribe
+
+// mockgen -source=email/unsubscribe/tokens.go -destination=email/unsubscribe/mocks/mock_tokens.go -package=mocks
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+)
+
+var ErrInvalidToken = errors.New("unsubscribe token is malformed or its signature does not match")
+
+// Claim is what an unsubscribe token vouches for: the recipient it was issued to and the category to opt out of
+type Claim struct {
+	Recipient string `json:"r"`
+	Category  string `json:"c"`
+}
+
+type Tokens interface {
+	Issue(recipient string, category string) string
+	Verify(token string) (Claim, error)
+}
+
+type tokens struct {
+	secret []byte
+}
+
+// NewTokens signs with HMAC-SHA256; tokens never expire, since unsubscribe links in old emails must keep working
+func NewTokens(secret string) Tokens {
+	return tokens{secret: []byte(secret)}
+}
+
+func (tokens tokens) Issue(recipient string, category string) string {
+	payload, _ := json.Marshal(Claim{Recipient: strings.ToLower(strings.TrimSpace(recipient)), Category: category})
+	encodedPayload := base64.RawURLEncoding.EncodeToString(payload)
+	return encodedPayload + "." + base64.RawURLEncoding.EncodeToString(tokens.sign(encodedPayload))
+}
+
+func (tokens tokens) Verify(token string) (Claim, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 2 {
+		return Claim{}, ErrInvalidToken
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil || !hmac.Equal(signature, tokens.sign(parts[0])) {
+		return Claim{}, ErrInvalidToken
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return Claim{}, ErrInvalidToken
+	}
+	var claim Claim
+	if err := json.Unmarshal(payload, &claim); err != nil || claim.Recipient == "" || claim.Category == "" {
+		return Claim{}, ErrInvalidToken
+	}
+	return claim, nil
+}
+
+func (tokens tokens) sign(encodedPayload string) []byte {
+	mac := hmac.New(sha256.New, tokens.secret)
+	mac.Write([]byte(encodedPayload))
+	return mac.Sum(nil)
+}
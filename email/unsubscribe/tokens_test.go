@@ -0,0 +1,59 @@
+package unsubscribe
+
+import (
+	"github.com/stretchr/testify/suite"
+	"strings"
+	"testing"
+)
+
+type tokensTestSuite struct {
+	suite.Suite
+	tokens Tokens
+}
+
+func TestTokensTestSuite(t *testing.T) {
+	suite.Run(t, new(tokensTestSuite))
+}
+
+func (suite *tokensTestSuite) SetupTest() {
+	suite.tokens = NewTokens("unit-test-secret")
+}
+
+func (suite *tokensTestSuite) TestVerify_ShouldReturnClaimOfIssuedToken() {
+	token := suite.tokens.Issue(" Someone@Gmail.com", "marketing")
+
+	claim, err := suite.tokens.Verify(token)
+
+	suite.Nil(err)
+	suite.Equal(Claim{Recipient: "someone@gmail.com", Category: "marketing"}, claim)
+}
+
+func (suite *tokensTestSuite) TestIssue_ShouldProduceUrlSafeToken() {
+	token := suite.tokens.Issue("someone+news@gmail.com", "digest")
+
+	suite.False(strings.ContainsAny(token, "/+=?&"))
+}
+
+func (suite *tokensTestSuite) TestVerify_ShouldRejectTokenSignedWithAnotherSecret() {
+	token := NewTokens("another-secret").Issue("someone@gmail.com", "marketing")
+
+	_, err := suite.tokens.Verify(token)
+
+	suite.Equal(ErrInvalidToken, err)
+}
+
+func (suite *tokensTestSuite) TestVerify_ShouldRejectTokenWhosePayloadWasChanged() {
+	token := suite.tokens.Issue("someone@gmail.com", "marketing")
+	forged := suite.tokens.Issue("someone@gmail.com", "digest")
+
+	_, err := suite.tokens.Verify(strings.Split(forged, ".")[0] + "." + strings.Split(token, ".")[1])
+
+	suite.Equal(ErrInvalidToken, err)
+}
+
+func (suite *tokensTestSuite) TestVerify_ShouldRejectMalformedToken() {
+	for _, token := range []string{"", "no-signature", "a.b.c", "!!!.???"} {
+		_, err := suite.tokens.Verify(token)
+		suite.Equal(ErrInvalidToken, err, token)
+	}
+}
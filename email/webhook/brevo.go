@@ -0,0 +1,90 @@
+package webhook
+
+import (
+	"bytes"
+	"ccg-api/email/email-client/email_client_request"
+	"ccg-api/email/models"
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const BrevoProvider = "brevo"
+
+var brevoEventTypes = map[string]models.DeliveryEventType{
+	"delivered":     models.DeliveredEvent,
+	"soft_bounce":   models.DeferredEvent,
+	"deferred":      models.DeferredEvent,
+	"hard_bounce":   models.HardBounceEvent,
+	"invalid_email": models.HardBounceEvent,
+	"spam":          models.ComplaintEvent,
+	"complaint":     models.ComplaintEvent,
+	"unsubscribed":  models.UnsubscribedEvent,
+	"opened":        models.OpenedEvent,
+	"unique_opened": models.OpenedEvent,
+	"click":         models.ClickedEvent,
+}
+
+type brevoEvent struct {
+	Event     string `json:"event"`
+	Email     string `json:"email"`
+	MessageID string `json:"message-id"`
+	Reason    string `json:"reason"`
+	TsEvent   int64  `json:"ts_event"`
+}
+
+type brevo struct {
+	secret string
+}
+
+// NewBrevo expects the webhook to be configured with an Authorization: Bearer <secret> header, an empty secret rejects every call
+func NewBrevo(secret string) Provider {
+	return brevo{secret: secret}
+}
+
+func (provider brevo) Verify(request *http.Request, body []byte) bool {
+	if provider.secret == "" {
+		return false
+	}
+	token := strings.TrimPrefix(request.Header.Get("Authorization"), "Bearer ")
+	return subtle.ConstantTimeCompare([]byte(token), []byte(provider.secret)) == 1
+}
+
+// Parse accepts a single event or, from batched webhooks, an array of them. Events we have no use for are dropped.
+func (provider brevo) Parse(body []byte) ([]models.DeliveryEvent, error) {
+	var brevoEvents []brevoEvent
+	if trimmed := bytes.TrimSpace(body); len(trimmed) > 0 && trimmed[0] == '[' {
+		if err := json.Unmarshal(trimmed, &brevoEvents); err != nil {
+			return nil, err
+		}
+	} else {
+		var single brevoEvent
+		if err := json.Unmarshal(trimmed, &single); err != nil {
+			return nil, err
+		}
+		brevoEvents = append(brevoEvents, single)
+	}
+
+	var events []models.DeliveryEvent
+	for _, brevoEvent := range brevoEvents {
+		eventType, known := brevoEventTypes[brevoEvent.Event]
+		if !known {
+			continue
+		}
+		occurredAt := time.Now()
+		if brevoEvent.TsEvent > 0 {
+			occurredAt = time.Unix(brevoEvent.TsEvent, 0)
+		}
+		events = append(events, models.DeliveryEvent{
+			Source:     BrevoProvider,
+			Type:       eventType,
+			MessageID:  email_client_request.MessageIDFromHeader(brevoEvent.MessageID),
+			Recipient:  strings.ToLower(strings.TrimSpace(brevoEvent.Email)),
+			Reason:     brevoEvent.Reason,
+			OccurredAt: occurredAt,
+		})
+	}
+	return events, nil
+}
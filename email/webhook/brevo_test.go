@@ -0,0 +1,83 @@
+package webhook
+
+import (
+	"ccg-api/email/models"
+	"github.com/stretchr/testify/suite"
+	"net/http"
+	"testing"
+	"time"
+)
+
+type brevoTestSuite struct {
+	suite.Suite
+	provider Provider
+}
+
+func TestBrevoTestSuite(t *testing.T) {
+	suite.Run(t, new(brevoTestSuite))
+}
+
+func (suite *brevoTestSuite) SetupTest() {
+	suite.provider = NewBrevo("webhook-secret")
+}
+
+func (suite *brevoTestSuite) TestVerify_ShouldAcceptMatchingBearerToken() {
+	request, _ := http.NewRequest("POST", "/", nil)
+	request.Header.Set("Authorization", "Bearer webhook-secret")
+
+	suite.True(suite.provider.Verify(request, nil))
+}
+
+func (suite *brevoTestSuite) TestVerify_ShouldRejectWrongOrMissingToken() {
+	request, _ := http.NewRequest("POST", "/", nil)
+	suite.False(suite.provider.Verify(request, nil))
+
+	request.Header.Set("Authorization", "Bearer guessed")
+	suite.False(suite.provider.Verify(request, nil))
+}
+
+func (suite *brevoTestSuite) TestVerify_ShouldRejectEverythingWithoutSecret() {
+	request, _ := http.NewRequest("POST", "/", nil)
+	request.Header.Set("Authorization", "Bearer ")
+
+	suite.False(NewBrevo("").Verify(request, nil))
+}
+
+func (suite *brevoTestSuite) TestParse_ShouldNormaliseSingleEvent() {
+	body := `{"event":"hard_bounce","email":"Missing@Gmail.com","id":123,"message-id":"<9b2f6c1e-3f0a-4a8e-8f5e-2d7c1b0a9e11@gola.xyz>","reason":"550 user unknown","ts_event":1641180600}`
+
+	events, err := suite.provider.Parse([]byte(body))
+
+	suite.Nil(err)
+	suite.Equal([]models.DeliveryEvent{{
+		Source:     BrevoProvider,
+		Type:       models.HardBounceEvent,
+		MessageID:  "9b2f6c1e-3f0a-4a8e-8f5e-2d7c1b0a9e11",
+		Recipient:  "missing@gmail.com",
+		Reason:     "550 user unknown",
+		OccurredAt: time.Unix(1641180600, 0),
+	}}, events)
+}
+
+func (suite *brevoTestSuite) TestParse_ShouldNormaliseBatchAndDropUnknownEvents() {
+	body := `[
+		{"event":"delivered","email":"some@gmail.com","message-id":"<first@gola.xyz>"},
+		{"event":"spam","email":"annoyed@gmail.com","message-id":"<second@gola.xyz>"},
+		{"event":"list_addition","email":"some@gmail.com"}
+	]`
+
+	events, err := suite.provider.Parse([]byte(body))
+
+	suite.Nil(err)
+	suite.Len(events, 2)
+	suite.Equal(models.DeliveredEvent, events[0].Type)
+	suite.Equal("first", events[0].MessageID)
+	suite.Equal(models.ComplaintEvent, events[1].Type)
+	suite.Equal("annoyed@gmail.com", events[1].Recipient)
+}
+
+func (suite *brevoTestSuite) TestParse_ShouldFailOnMalformedPayload() {
+	_, err := suite.provider.Parse([]byte(`{"event":`))
+
+	suite.NotNil(err)
+}
@@ -0,0 +1,25 @@
+package webhook
+
+import (
+	"ccg-api/configuration"
+	"ccg-api/email/models"
+	"net/http"
+)
+
+// Provider authenticates and normalises the delivery events posted by one email provider
+type Provider interface {
+	Verify(request *http.Request, body []byte) bool
+	Parse(body []byte) ([]models.DeliveryEvent, error)
+}
+
+// NewProviders builds the configured providers keyed by name; providers we cannot parse are left out
+func NewProviders(webhooks []configuration.Webhook, secret func(webhook configuration.Webhook) string) map[string]Provider {
+	providers := map[string]Provider{}
+	for _, webhook := range webhooks {
+		switch webhook.Provider {
+		case BrevoProvider:
+			providers[webhook.Provider] = NewBrevo(secret(webhook))
+		}
+	}
+	return providers
+}
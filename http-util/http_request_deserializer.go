@@ -9,6 +9,8 @@ import (
 
 type HttpRequestDeserializer interface {
 	ShouldBindJsonBodyIfValid(request interface{}, ctx *gin.Context) error
+	ShouldBindQueryIfValid(request interface{}, ctx *gin.Context) error
+	Validate(request interface{}, ctx *gin.Context) error
 }
 
 type httpRequestDeserializer struct {
@@ -31,3 +33,30 @@ func (deserializer httpRequestDeserializer) ShouldBindJsonBodyIfValid(request in
 	}
 	return nil
 }
+
+func (deserializer httpRequestDeserializer) ShouldBindQueryIfValid(request interface{}, ctx *gin.Context) error {
+	if bindError := ctx.ShouldBindQuery(request); bindError != nil {
+		logging.GetLogger(ctx).Error("Failed to bind query parameters due to error: ", bindError.Error())
+		return bindError
+	}
+
+	if validationError := deserializer.validator.Struct(request); validationError != nil {
+		logging.GetLogger(ctx).Error("Failed to validate request: ", validationError.Error())
+		return validationError
+	}
+	return nil
+}
+
+// Validate runs the binding and validate checks of ShouldBindJsonBodyIfValid on a request decoded as part of another one
+func (deserializer httpRequestDeserializer) Validate(request interface{}, ctx *gin.Context) error {
+	if bindingError := binding.Validator.ValidateStruct(request); bindingError != nil {
+		logging.GetLogger(ctx).Error("Failed to validate request: ", bindingError.Error())
+		return bindingError
+	}
+
+	if validationError := deserializer.validator.Struct(request); validationError != nil {
+		logging.GetLogger(ctx).Error("Failed to validate request: ", validationError.Error())
+		return validationError
+	}
+	return nil
+}
@@ -27,6 +27,11 @@ type TestRequest struct {
 	FixedWidthString     string   `json:"fixed_width_string" validate:"omitempty,len=5"`
 }
 
+type TestQueryRequest struct {
+	Name  string `form:"name" binding:"required"`
+	Limit int    `form:"limit" validate:"omitempty,max=10"`
+}
+
 func TestHttpDeserializerTestSuite(t *testing.T) {
 	suite.Run(t, new(httpDeserializerTestSuite))
 }
@@ -99,3 +104,48 @@ func (suite httpDeserializerTestSuite) TestShouldBindJsonIfValid_ShouldReturnErr
 
 	suite.NotNil(deserializationError)
 }
+
+func (suite httpDeserializerTestSuite) TestShouldBindQueryIfValid_ShouldDeserializeValidQuery() {
+	suite.context.Request, _ = http.NewRequest("GET", "/?name=gola&limit=5", nil)
+
+	var actualRequest TestQueryRequest
+	bindError := suite.httpRequestDeserializer.ShouldBindQueryIfValid(&actualRequest, suite.context)
+
+	suite.Equal(TestQueryRequest{Name: "gola", Limit: 5}, actualRequest)
+	suite.Nil(bindError)
+}
+
+func (suite httpDeserializerTestSuite) TestShouldBindQueryIfValid_ShouldReturnErrorForMissingParameter() {
+	suite.context.Request, _ = http.NewRequest("GET", "/?limit=5", nil)
+
+	var actualRequest TestQueryRequest
+	bindError := suite.httpRequestDeserializer.ShouldBindQueryIfValid(&actualRequest, suite.context)
+
+	suite.NotNil(bindError)
+}
+
+func (suite httpDeserializerTestSuite) TestShouldBindQueryIfValid_ShouldReturnErrorForInvalidParameter() {
+	suite.context.Request, _ = http.NewRequest("GET", "/?name=gola&limit=50", nil)
+
+	var actualRequest TestQueryRequest
+	bindError := suite.httpRequestDeserializer.ShouldBindQueryIfValid(&actualRequest, suite.context)
+
+	suite.NotNil(bindError)
+}
+
+func (suite httpDeserializerTestSuite) TestValidate_ShouldApplyBindingAndValidateConstraints() {
+	suite.context.Request, _ = http.NewRequest("POST", "/", nil)
+
+	suite.Nil(suite.httpRequestDeserializer.Validate(TestRequest{
+		MandatoryStringField: "Some Value",
+		NonEmptyStringArray:  []string{"First Element"},
+	}, suite.context))
+	suite.NotNil(suite.httpRequestDeserializer.Validate(TestRequest{
+		NonEmptyStringArray: []string{"First Element"},
+	}, suite.context))
+	suite.NotNil(suite.httpRequestDeserializer.Validate(TestRequest{
+		MandatoryStringField: "Some Value",
+		NonEmptyStringArray:  []string{"First Element"},
+		FixedWidthString:     "ABCDE12345",
+	}, suite.context))
+}
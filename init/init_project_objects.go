@@ -1,30 +1,328 @@
 package init
 
 import (
+	"ccg-api/auth"
 	"ccg-api/configuration"
 	"ccg-api/controller"
+	"ccg-api/email/bounce"
 	. "ccg-api/email/configuration"
 	emailControllers "ccg-api/email/controller"
+	"ccg-api/email/dkim"
 	emailClient "ccg-api/email/email-client"
+	"ccg-api/email/event"
+	"ccg-api/email/idempotency"
+	"ccg-api/email/outbox"
+	"ccg-api/email/preference"
+	"ccg-api/email/ratelimit"
+	"ccg-api/email/redis"
+	"ccg-api/email/relay"
 	"ccg-api/email/service"
+	"ccg-api/email/status"
+	"ccg-api/email/suppression"
+	"ccg-api/email/templates"
+	"ccg-api/email/tracking"
+	"ccg-api/email/unsubscribe"
+	"ccg-api/email/webhook"
 	"crypto/tls"
+	"expvar"
+	"github.com/inclusi-blog/gola-utils/logging"
 	"gopkg.in/gomail.v2"
+	"os"
 )
 
 var (
-	healthController = controller.HealthController{}
-	emailController  emailControllers.EmailController
+	authenticator           auth.Authenticator
+	healthController        controller.HealthController
+	emailController         emailControllers.EmailController
+	messageStatusController emailControllers.MessageStatusController
+	suppressionController   emailControllers.SuppressionController
+	unsubscribeController   emailControllers.UnsubscribeController
+	webhookController       emailControllers.WebhookController
+	trackingController      emailControllers.TrackingController
 )
 
 func Objects(configData *configuration.ConfigData) {
 	emailClientConfig := NewEmailClientConfig(configData.Email)
-	gomailDialer := buildGomailDialer(emailClientConfig)
-	emailService := service.NewEmailService(emailClient.NewEmailClient(emailClientConfig.TempDir(), gomailDialer), emailClientConfig)
-	emailController = emailControllers.NewEmailController(emailService, emailClientConfig)
+	redisClient := buildRedisClient(emailClientConfig)
+	var err error
+	if authenticator, err = auth.NewAuthenticator(configData.Auth, hmacSecret, buildReplayCache(configData.Auth, redisClient)); err != nil {
+		logging.NewLoggerEntry().Fatalf("Failed to initialise authenticator, error: %s", err)
+	}
+	transport, relayPool := buildTransport(emailClientConfig)
+	healthController = controller.HealthController{Relays: relayPool}
+	publishRelayMetrics(relayPool)
+	client := emailClient.NewEmailClient(emailClientConfig.TempDir(), transport)
+	tracker := buildStatusTracker(emailClientConfig)
+	suppressions := buildSuppressionList(emailClientConfig)
+	eventRecorder := event.NewRecorder(tracker, suppressions)
+	startBounceProcessor(emailClientConfig, eventRecorder)
+	preferences := buildPreferences(emailClientConfig)
+	unsubscribeTokens := buildUnsubscribeTokens(emailClientConfig)
+	trackingTokens := buildTrackingTokens(emailClientConfig)
+	emailService := service.NewEmailService(client, emailClientConfig, buildOutbox(emailClientConfig, client, tracker), tracker,
+		suppressions, preferences, unsubscribeTokens, trackingTokens)
+	emailController = emailControllers.NewEmailController(emailService, buildTemplateRegistry(emailClientConfig),
+		buildIdempotencyGuard(emailClientConfig, redisClient), buildRateLimiter(emailClientConfig, redisClient), emailClientConfig)
+	messageStatusController = emailControllers.NewMessageStatusController(tracker)
+	suppressionController = emailControllers.NewSuppressionController(suppressions)
+	unsubscribeController = emailControllers.NewUnsubscribeController(unsubscribeTokens, preferences)
+	trackingController = emailControllers.NewTrackingController(trackingTokens, eventRecorder)
+	webhookController = emailControllers.NewWebhookController(webhook.NewProviders(emailClientConfig.Webhooks(), emailClientConfig.WebhookSecret), eventRecorder)
 }
 
-func buildGomailDialer(config EmailClientConfig) emailClient.GomailDialer {
-	gomailDialer := gomail.NewDialer(config.SmtpHost(), config.SmtpPort(), config.Username(), config.Password())
-	gomailDialer.TLSConfig = &tls.Config{InsecureSkipVerify: config.InsecureSkipVerify()}
-	return gomailDialer
+func buildReplayCache(config configuration.Auth, redisClient redis.Client) auth.ReplayCache {
+	var replays auth.ReplayCache
+	switch config.ReplayStore {
+	case "", auth.MemoryReplayStore:
+		replays = auth.NewMemoryReplayCache()
+	case auth.RedisReplayStore:
+		replays = auth.NewRedisReplayCache(redisClient)
+	default:
+		logging.NewLoggerEntry().Fatalf("Unknown replay store %s", config.ReplayStore)
+	}
+	return replays
+}
+
+func hmacSecret(client configuration.AuthClient) string {
+	if len(client.HmacSecretEnv) == 0 {
+		return ""
+	}
+	return os.Getenv(client.HmacSecretEnv)
+}
+
+func buildTemplateRegistry(config EmailClientConfig) templates.Registry {
+	registry, err := templates.LoadRegistry(config.TemplateDirectory())
+	if err != nil {
+		logging.NewLoggerEntry().Fatalf("Failed to load email templates from %s, error: %s", config.TemplateDirectory(), err)
+	}
+	return registry
+}
+
+func buildStatusTracker(config EmailClientConfig) status.Tracker {
+	statusConfig := config.MessageStatus()
+	store, err := status.NewFileStore(statusConfig.Directory)
+	if err != nil {
+		logging.NewLoggerEntry().Fatalf("Failed to initialise message status store at %s, error: %s", statusConfig.Directory, err)
+	}
+	scheduleAhead := emailControllers.NewScheduledSendAtValidator(config.MaxScheduleAheadInDays()).MaxScheduleAhead()
+	retention, err := status.NewRetention(store, statusConfig.RetentionInDays, scheduleAhead)
+	if err != nil {
+		logging.NewLoggerEntry().Fatalf("Invalid message status retention, error: %s", err)
+	}
+	retention.Start()
+	return status.NewTracker(store)
+}
+
+func buildSuppressionList(config EmailClientConfig) suppression.List {
+	directory := config.Suppression().Directory
+	store, err := suppression.NewFileStore(directory)
+	if err != nil {
+		logging.NewLoggerEntry().Fatalf("Failed to initialise suppression store at %s, error: %s", directory, err)
+	}
+	return suppression.NewList(store)
+}
+
+func startBounceProcessor(config EmailClientConfig, recorder event.Recorder) {
+	bounceConfig := config.Bounce()
+	if !bounceConfig.Enabled {
+		return
+	}
+	logger := logging.NewLoggerEntry()
+	var source bounce.Source
+	switch bounceConfig.Source {
+	case "", bounce.MaildirSource:
+		var err error
+		source, err = bounce.NewMaildirSource(bounceConfig.Maildir.Directory)
+		if err != nil {
+			logger.Fatalf("Failed to initialise bounce maildir at %s, error: %s", bounceConfig.Maildir.Directory, err)
+		}
+	case bounce.Pop3Source:
+		pop3 := bounceConfig.Pop3
+		source = bounce.NewPop3Source(pop3.Host, pop3.Port, pop3.Username, config.BounceMailboxPassword(), pop3.InsecureSkipVerify)
+	default:
+		logger.Fatalf("Unknown bounce source %s", bounceConfig.Source)
+	}
+	bounce.NewProcessor(source, recorder, bounceConfig.PollIntervalInSeconds).Start()
+}
+
+// buildRedisClient connects on first use, so nothing is dialled unless a store is configured to use redis
+func buildRedisClient(config EmailClientConfig) redis.Client {
+	return redis.NewClient(config.Redis(), config.RedisPassword())
+}
+
+func buildIdempotencyGuard(config EmailClientConfig, redisClient redis.Client) idempotency.Guard {
+	idempotencyConfig := config.Idempotency()
+	var store idempotency.Store
+	switch idempotencyConfig.Store {
+	case "", idempotency.MemoryStore:
+		store = idempotency.NewMemoryStore()
+	case idempotency.RedisStore:
+		store = idempotency.NewRedisStore(redisClient)
+	default:
+		logging.NewLoggerEntry().Fatalf("Unknown idempotency store %s", idempotencyConfig.Store)
+	}
+	return idempotency.NewGuard(store, idempotencyConfig.WindowInSeconds)
+}
+
+// buildRateLimiter returns nil when rate limiting is disabled
+func buildRateLimiter(config EmailClientConfig, redisClient redis.Client) ratelimit.Limiter {
+	rateLimiting := config.RateLimiting()
+	if !rateLimiting.Enabled {
+		return nil
+	}
+	var store ratelimit.Store
+	switch rateLimiting.Store {
+	case "", ratelimit.MemoryStore:
+		store = ratelimit.NewMemoryStore()
+	case ratelimit.RedisStore:
+		store = ratelimit.NewRedisStore(redisClient)
+	default:
+		logging.NewLoggerEntry().Fatalf("Unknown rate limit store %s", rateLimiting.Store)
+	}
+	return ratelimit.NewLimiter(store, rateLimiting, config.DefaultCategory())
+}
+
+func buildPreferences(config EmailClientConfig) preference.Preferences {
+	directory := config.Unsubscribe().PreferenceDirectory
+	store, err := preference.NewFileStore(directory)
+	if err != nil {
+		logging.NewLoggerEntry().Fatalf("Failed to initialise preference store at %s, error: %s", directory, err)
+	}
+	return preference.NewPreferences(store)
+}
+
+// buildUnsubscribeTokens returns nil without a secret, emails then go out without unsubscribe links
+func buildUnsubscribeTokens(config EmailClientConfig) unsubscribe.Tokens {
+	if config.UnsubscribeSecret() == "" {
+		logging.NewLoggerEntry().Warn("No unsubscribe token secret configured, unsubscribe links are disabled")
+		return nil
+	}
+	return unsubscribe.NewTokens(config.UnsubscribeSecret())
+}
+
+// buildTrackingTokens returns nil without a secret, tracking is then skipped for emails that ask for it
+func buildTrackingTokens(config EmailClientConfig) tracking.Tokens {
+	if config.TrackingSecret() == "" {
+		logging.NewLoggerEntry().Warn("No tracking token secret configured, open and click tracking is disabled")
+		return nil
+	}
+	return tracking.NewTokens(config.TrackingSecret())
+}
+
+func buildOutbox(config EmailClientConfig, client emailClient.EmailClient, tracker status.Tracker) outbox.Outbox {
+	outboxConfig := config.Outbox()
+	if !outboxConfig.Enabled {
+		return nil
+	}
+	logger := logging.NewLoggerEntry()
+	store, err := outbox.NewFileStore(outboxConfig.Directory)
+	if err != nil {
+		logger.Fatalf("Failed to initialise outbox store at %s, error: %s", outboxConfig.Directory, err)
+	}
+	emailOutbox := outbox.NewOutbox(store, client, tracker, outboxConfig)
+	if err := emailOutbox.Start(); err != nil {
+		logger.Fatalf("Failed to start outbox, error: %s", err)
+	}
+	return emailOutbox
+}
+
+// buildTransport also returns the relay pool when mail goes out over SMTP, nil otherwise.
+// Every transport that submits MIME signs it, the http_api transport cannot and so refuses DKIM keys.
+// The API has no Content-ID for attachments either, so it also refuses embedded logos.
+func buildTransport(config EmailClientConfig) (emailClient.Transport, relay.Pool) {
+	transportConfig := config.Transport()
+	logger := logging.NewLoggerEntry()
+	switch transportConfig.Type {
+	case "", emailClient.SmtpTransportType:
+		relayPool := buildRelayPool(config)
+		return relayPool, relayPool
+	case emailClient.HttpApiTransportType:
+		if len(config.Dkim().Keys) > 0 {
+			logger.Fatal("DKIM keys are configured but the http_api transport cannot sign, the provider has to sign for the domains instead")
+		}
+		if config.EmbedLogos() {
+			logger.Fatal("embed_logos is enabled but the http_api transport cannot send inline parts, logos have to be linked by url instead")
+		}
+		return emailClient.NewHttpApiTransport(transportConfig.HttpApi, config.ApiKey()), nil
+	case emailClient.MaildirTransportType:
+		transport, err := emailClient.NewMaildirTransport(transportConfig.Maildir.Directory, buildDkimSigner(config))
+		if err != nil {
+			logger.Fatalf("Failed to initialise maildir at %s, error: %s", transportConfig.Maildir.Directory, err)
+		}
+		return transport, nil
+	case emailClient.SendmailTransportType:
+		return emailClient.NewSendmailTransport(transportConfig.Sendmail.Path, transportConfig.Sendmail.Args, buildDkimSigner(config)), nil
+	}
+	logger.Fatalf("Unknown email transport %s", transportConfig.Type)
+	return nil, nil
+}
+
+// buildRelayPool gives every tenant with relays of its own a pool of them, the other tenants share the top level relays
+func buildRelayPool(config EmailClientConfig) relay.Pool {
+	signer := buildDkimSigner(config)
+	shared := newRelayPool(config, signer)
+	tenantPools := map[string]relay.Pool{}
+	for _, tenant := range config.Tenants() {
+		if len(tenant.Relays) > 0 {
+			tenantPools[tenant.Name] = newRelayPool(config.ForTenant(tenant.Name), signer)
+		}
+	}
+	if len(tenantPools) == 0 {
+		return shared
+	}
+	return relay.NewTenantPool(shared, tenantPools)
+}
+
+func newRelayPool(config EmailClientConfig, signer dkim.Signer) relay.Pool {
+	var relays []relay.Relay
+	for _, relayConfig := range config.Relays() {
+		relays = append(relays, relay.Relay{
+			Name:     relayConfig.Name,
+			Host:     relayConfig.Host,
+			Priority: relayConfig.Priority,
+			Weight:   relayConfig.Weight,
+			Dialer:   buildGomailDialer(config, relayConfig, signer),
+		})
+	}
+	return relay.NewPool(relays, config.RelayCircuitBreaker())
+}
+
+func buildGomailDialer(config EmailClientConfig, relayConfig configuration.Relay, signer dkim.Signer) emailClient.GomailDialer {
+	tlsConfig := &tls.Config{ServerName: relayConfig.Host, InsecureSkipVerify: relayConfig.InsecureSkipVerify}
+	var dialer interface {
+		emailClient.GomailDialer
+		emailClient.SmtpDialer
+	}
+	if config.ConnectionPool().MaxIdleConnections > 0 {
+		sessionDialer := emailClient.NewSmtpSessionDialer(relayConfig.Host, relayConfig.Port, relayConfig.Username, config.RelayPassword(relayConfig), tlsConfig)
+		dialer = emailClient.NewConnectionPool(sessionDialer, config.ConnectionPool())
+	} else {
+		gomailDialer := gomail.NewDialer(relayConfig.Host, relayConfig.Port, relayConfig.Username, config.RelayPassword(relayConfig))
+		gomailDialer.TLSConfig = tlsConfig
+		dialer = gomailDialer
+	}
+	if signer == nil {
+		return dialer
+	}
+	return emailClient.NewDkimSigningDialer(dialer, signer)
+}
+
+func buildDkimSigner(config EmailClientConfig) dkim.Signer {
+	if len(config.Dkim().Keys) == 0 {
+		return nil
+	}
+	signer, err := dkim.NewSigner(config.Dkim(), config.AllValidGolaEmailDomains())
+	if err != nil {
+		logging.NewLoggerEntry().Fatalf("Failed to initialise dkim signer, error: %s", err)
+	}
+	return signer
+}
+
+func publishRelayMetrics(relayPool relay.Pool) {
+	if relayPool == nil {
+		return
+	}
+	expvar.Publish("smtp_relays", expvar.Func(func() interface{} {
+		return relayPool.Statuses()
+	}))
 }
@@ -3,14 +3,23 @@ package init
 import (
 	"ccg-api/configuration"
 	"context"
+	"expvar"
 	"github.com/gin-gonic/gin"
 	"github.com/inclusi-blog/gola-utils/logging"
 	"github.com/inclusi-blog/gola-utils/middleware/request_response_trace"
 	middleware "github.com/inclusi-blog/gola-utils/middleware/session_trace"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
+	"net/http"
 )
 
+// MetricsHandler serves the expvar metrics, relay hosts and errors included, on the internal metrics address only
+func MetricsHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", expvar.Handler())
+	return mux
+}
+
 func RegisterRouter(router *gin.Engine, configData *configuration.ConfigData) {
 	routerGroup := router.Group("/api")
 	routerGroup.GET("/ccg/healthz", healthController.GetHealth)
@@ -39,8 +48,28 @@ func RegisterRouter(router *gin.Engine, configData *configuration.ConfigData) {
 
 	router.GET("api/ccg/v1/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
+	authenticated := routerGroup.Group("", authenticator.Authenticate)
+	{
+		authenticated.POST("/ccg/v1/email/send", emailController.SendEmail)
+		authenticated.POST("/ccg/v1/email/send-template", emailController.SendTemplateEmail)
+		authenticated.POST("/ccg/v1/email/batch", emailController.SendBatch)
+		authenticated.GET("/ccg/v1/email", messageStatusController.ListStatuses)
+		authenticated.GET("/ccg/v1/email/:id", messageStatusController.GetStatus)
+		authenticated.DELETE("/ccg/v1/email/:id", emailController.CancelEmail)
+		authenticated.GET("/ccg/v1/suppressions", suppressionController.ListSuppressions)
+		authenticated.POST("/ccg/v1/suppressions", suppressionController.AddSuppression)
+		authenticated.POST("/ccg/v1/suppressions/import", suppressionController.ImportSuppressions)
+		authenticated.GET("/ccg/v1/suppressions/:address", suppressionController.GetSuppression)
+		authenticated.DELETE("/ccg/v1/suppressions/:address", suppressionController.RemoveSuppression)
+	}
+
+	// unsubscribe links, tracking links and provider webhooks are reached by recipients and providers, not our clients
 	{
-		routerGroup.POST("/ccg/v1/email/send", emailController.SendEmail)
+		routerGroup.GET("/ccg/v1/unsubscribe/:token", unsubscribeController.UnsubscribeFromLink)
+		routerGroup.POST("/ccg/v1/unsubscribe/:token", unsubscribeController.UnsubscribeOneClick)
+		routerGroup.POST("/ccg/v1/webhooks/:provider", webhookController.ReceiveEvents)
+		routerGroup.GET("/ccg/t/o/:token", trackingController.TrackOpen)
+		routerGroup.GET("/ccg/t/c/:token", trackingController.TrackClick)
 	}
 
 }
@@ -23,6 +23,13 @@ func main() {
 	} else {
 		port = ":8080"
 	}
+	if configData.MetricsAddress != "" {
+		go func() {
+			if err := http.ListenAndServe(configData.MetricsAddress, MetricsHandler()); err != nil {
+				logging.GetLogger(context.TODO()).Error("Could not start the metrics server", err)
+			}
+		}()
+	}
 	err := http.ListenAndServe(port, tracing.WithTracing(router, "/api/ccg/healthz"))
 	if err != nil {
 		logging.GetLogger(context.TODO()).Error("Could not start the server", err)
@@ -1,5 +1,12 @@
 package response
 
 type HealthResponse struct {
-	Status string `json:"status"`
+	Status string        `json:"status"`
+	Relays []RelayHealth `json:"relays,omitempty"`
+}
+
+// RelayHealth is all the public health check tells about a relay, hosts and errors are only in the internal metrics
+type RelayHealth struct {
+	Name  string `json:"name"`
+	State string `json:"state"`
 }
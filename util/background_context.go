@@ -0,0 +1,13 @@
+package util
+
+import (
+	"context"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+// NewBackgroundContext builds a gin context for work that runs outside of an HTTP request, e.g. background workers
+func NewBackgroundContext() *gin.Context {
+	request, _ := http.NewRequestWithContext(context.Background(), http.MethodGet, "/", nil)
+	return &gin.Context{Request: request}
+}
@@ -0,0 +1,56 @@
+package util
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+)
+
+// WriteFileAtomically writes to a temp file in the same directory and renames it into place,
+// so that a crash never leaves a partially written file behind
+func WriteFileAtomically(filePath string, data []byte) error {
+	tempFile, err := writeTempFile(filePath, data)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tempFile)
+	return os.Rename(tempFile, filePath)
+}
+
+// CreateFileAtomically is WriteFileAtomically that leaves an existing file alone. The temp file is
+// hard linked into place, which fails when the target exists even if another replica wrote it.
+func CreateFileAtomically(filePath string, data []byte) (bool, error) {
+	tempFile, err := writeTempFile(filePath, data)
+	if err != nil {
+		return false, err
+	}
+	defer os.Remove(tempFile)
+	err = os.Link(tempFile, filePath)
+	if os.IsExist(err) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func writeTempFile(filePath string, data []byte) (string, error) {
+	tempFile, err := ioutil.TempFile(path.Dir(filePath), path.Base(filePath)+".*.tmp")
+	if err != nil {
+		return "", err
+	}
+
+	if _, err = tempFile.Write(data); err != nil {
+		_ = tempFile.Close()
+		_ = os.Remove(tempFile.Name())
+		return "", err
+	}
+	if err = tempFile.Sync(); err != nil {
+		_ = tempFile.Close()
+		_ = os.Remove(tempFile.Name())
+		return "", err
+	}
+	if err = tempFile.Close(); err != nil {
+		_ = os.Remove(tempFile.Name())
+		return "", err
+	}
+	return tempFile.Name(), nil
+}